@@ -14,9 +14,16 @@ import (
 )
 
 type CommandHolder struct {
-	Version command.VersionCommand `command:"version" description:"Print version information and exit"`
-	Migrate command.Migrate        `command:"migrate" description:"Migrates an entire foundation from one vcenter to another"`
-	Revert  command.Revert         `command:"revert" description:"Reverts a prior migration back to the source vcenter"`
+	Version        command.VersionCommand `command:"version" description:"Print version information and exit"`
+	Init           command.Init           `command:"init" description:"Generates a migrate.yml skeleton from the BOSH cloud config and source vCenter inventory"`
+	Migrate        command.Migrate        `command:"migrate" description:"Migrates an entire foundation from one vcenter to another"`
+	Revert         command.Revert         `command:"revert" description:"Reverts a prior migration back to the source vcenter"`
+	Verify         command.Verify         `command:"verify" description:"Verifies every migrated VM is on the target vcenter where the mappings place it"`
+	Leftovers      command.Leftovers      `command:"leftovers" description:"Reports the VMs, folders and disks left on the source vcenter after a migration"`
+	CleanupFolders command.CleanupFolders `command:"cleanup-folders" description:"Deletes the empty BOSH VM and template folders left on the source vcenter after a migration"`
+	DirectorDisk   command.DirectorDisk   `command:"director-disk" description:"Moves the migrated BOSH director persistent disk and updates the bosh-state.json"`
+	DirectorConfig command.DirectorConfig `command:"director-config" description:"Rewrites an Operations Manager director or TKGI config to use the migrated vSphere objects"`
+	CloudConfig    command.CloudConfig    `command:"cloud-config" description:"Rewrites the BOSH director cloud and CPI configs to use the migrated vSphere objects"`
 }
 
 var Command CommandHolder
@@ -0,0 +1,35 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package command
+
+import (
+	"context"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+)
+
+type CleanupFolders struct {
+	ConfigOptions
+}
+
+// Execute - deletes the empty source vcenter BOSH VM and template folders left behind by a migration
+func (cf *CleanupFolders) Execute([]string) error {
+	log.Initialize(cf.Debug, cf.RedactSecrets)
+	ctx := context.Background()
+
+	c, err := cf.combinedConfig()
+	if err != nil {
+		return err
+	}
+
+	fc, err := migrate.NewFolderCleanerFromConfig(c)
+	if err != nil {
+		return err
+	}
+	_, err = fc.Cleanup(ctx)
+	return err
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+)
+
+type CloudConfig struct {
+	ConfigOptions
+	OutputDir string `long:"output-dir" description:"directory to write the updated cloud and CPI configs, defaults to the current directory"`
+	Revert    bool   `long:"revert" description:"generates the configs for a reverted migration"`
+}
+
+// Execute - rewrites the BOSH director's cloud and CPI configs to use the migrated vSphere objects
+func (cc *CloudConfig) Execute([]string) error {
+	log.Initialize(cc.Debug, cc.RedactSecrets)
+
+	c, err := cc.combinedConfig()
+	if err != nil {
+		return err
+	}
+	if cc.Revert {
+		if err := c.ReverseMappingRulesErr(); err != nil {
+			return err
+		}
+		c = c.Reversed()
+	}
+
+	converter, err := migrate.NewCloudConfigConverterFromConfig(c)
+	if err != nil {
+		return err
+	}
+	configs, err := converter.Convert(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if cc.OutputDir == "" {
+		cc.OutputDir = "."
+	}
+	for _, cfg := range configs {
+		diff, err := cfg.Diff()
+		if err != nil {
+			return err
+		}
+		fmt.Print(diff)
+		if cc.DryRun {
+			continue
+		}
+
+		outputPath := filepath.Join(cc.OutputDir, cfg.FileName())
+		err = os.WriteFile(outputPath, []byte(cfg.Content), 0600)
+		if err != nil {
+			return fmt.Errorf("could not write %s: %w", outputPath, err)
+		}
+		fmt.Printf("Wrote %s, apply it with: %s\n\n", outputPath, cfg.UpdateCommand(outputPath))
+	}
+	return nil
+}
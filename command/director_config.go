@@ -0,0 +1,58 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/opsman"
+)
+
+type DirectorConfig struct {
+	ConfigFilePath   string `long:"config"  description:"path to the migrate.yml, defaults to ./migrate.yml"`
+	OpsManConfigPath string `long:"opsman-config" required:"true" description:"path to the config exported by om staged-director-config or om staged-config"`
+	OutputPath       string `long:"output" description:"path to write the updated config, defaults to stdout"`
+	Revert           bool   `long:"revert" description:"generates the config for a reverted migration"`
+	Debug            bool   `long:"debug"  description:"sets log level to debug"`
+	RedactSecrets    bool   `long:"no-redact" description:"do not redact sensitive information when printing debug logs"`
+}
+
+// Execute - rewrites the Operations Manager config to use the migrated vSphere objects
+func (d *DirectorConfig) Execute([]string) error {
+	log.Initialize(d.Debug, d.RedactSecrets)
+
+	if d.ConfigFilePath == "" {
+		d.ConfigFilePath = "migrate.yml"
+	}
+	c, err := config.NewConfigFromFile(d.ConfigFilePath)
+	if err != nil {
+		return err
+	}
+	if d.Revert {
+		if err := c.ReverseMappingRulesErr(); err != nil {
+			return err
+		}
+		c = c.Reversed()
+	}
+
+	in, err := os.ReadFile(d.OpsManConfigPath)
+	if err != nil {
+		return fmt.Errorf("could not read Operations Manager config %s: %w", d.OpsManConfigPath, err)
+	}
+	out, err := opsman.NewConverter(c).Convert(in)
+	if err != nil {
+		return err
+	}
+
+	if d.OutputPath == "" {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	return os.WriteFile(d.OutputPath, out, 0600)
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/bosh"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+)
+
+type DirectorDisk struct {
+	ConfigOptions
+	BoshStatePath string `long:"bosh-state" required:"true" description:"path to the Operations Manager bosh-state.json"`
+	OutputPath    string `long:"output" description:"path to write the updated bosh-state.json, defaults to overwriting --bosh-state after copying it to <file>.bak"`
+}
+
+// Execute - moves the migrated director's persistent disk and updates the bosh-state.json
+func (d *DirectorDisk) Execute([]string) error {
+	log.Initialize(d.Debug, d.RedactSecrets)
+	ctx := context.Background()
+
+	c, err := d.combinedConfig()
+	if err != nil {
+		return err
+	}
+
+	state, err := bosh.NewStateFromFile(d.BoshStatePath)
+	if err != nil {
+		return err
+	}
+
+	m, err := migrate.NewDirectorDiskMigratorFromConfig(c)
+	if err != nil {
+		return err
+	}
+	err = m.Migrate(ctx, state)
+	if err != nil {
+		return err
+	}
+
+	if d.DryRun {
+		log.WithoutContext().Info("Dry run, not writing the updated BOSH state")
+		return nil
+	}
+	if d.OutputPath == "" {
+		err = backupFile(d.BoshStatePath)
+		if err != nil {
+			return err
+		}
+		d.OutputPath = d.BoshStatePath
+	}
+	return state.WriteFile(d.OutputPath)
+}
+
+// backupFile copies the file to <file>.bak before it's overwritten
+func backupFile(filePath string) error {
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("could not read %s to back it up: %w", filePath, err)
+	}
+	backupPath := filePath + ".bak"
+	err = os.WriteFile(backupPath, b, 0600)
+	if err != nil {
+		return fmt.Errorf("could not back up %s to %s: %w", filePath, backupPath, err)
+	}
+	log.WithoutContext().Infof("Backed up %s to %s", filePath, backupPath)
+	return nil
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+)
+
+type Init struct {
+	BoshEnvironment  string `long:"bosh-environment" env:"BOSH_ENVIRONMENT" required:"true" description:"BOSH director host or https URL"`
+	BoshClient       string `long:"bosh-client" env:"BOSH_CLIENT" required:"true" description:"BOSH director UAA client"`
+	BoshClientSecret string `long:"bosh-client-secret" env:"BOSH_CLIENT_SECRET" required:"true" description:"BOSH director UAA client secret"`
+	BoshCACert       string `long:"bosh-ca-cert" env:"BOSH_CA_CERT" description:"BOSH director CA certificate path or PEM contents"`
+	BoshInsecure     bool   `long:"bosh-insecure" description:"skip BOSH director certificate verification"`
+	SourceHost       string `long:"source-host" required:"true" description:"source vCenter host"`
+	SourceUsername   string `long:"source-username" required:"true" description:"source vCenter username"`
+	SourcePassword   string `long:"source-password" env:"SOURCE_VCENTER_PASSWORD" required:"true" description:"source vCenter password"`
+	SourceInsecure   bool   `long:"source-insecure" description:"skip source vCenter certificate verification"`
+	SourceCACertFile string `long:"source-ca-cert-file" description:"path to the source vCenter CA certificate"`
+	TargetHost       string `long:"target-host" description:"optional target vCenter host, used to match target names"`
+	TargetUsername   string `long:"target-username" description:"target vCenter username"`
+	TargetPassword   string `long:"target-password" env:"TARGET_VCENTER_PASSWORD" description:"target vCenter password"`
+	TargetDatacenter string `long:"target-datacenter" description:"target vCenter datacenter"`
+	TargetInsecure   bool   `long:"target-insecure" description:"skip target vCenter certificate verification"`
+	TargetCACertFile string `long:"target-ca-cert-file" description:"path to the target vCenter CA certificate"`
+	MatchNames       bool   `long:"match-names" description:"use target clusters, resource pools, datastores and networks with the same name as the source"`
+	Proxy            string `long:"proxy" description:"optional proxy URL used to connect to BOSH and vCenter"`
+	OutputPath       string `long:"output" description:"path to write the generated migrate.yml, defaults to stdout"`
+	Debug            bool   `long:"debug"  description:"sets log level to debug"`
+	RedactSecrets    bool   `long:"no-redact" description:"do not redact sensitive information when printing debug logs"`
+}
+
+// Execute - generates a migrate.yml skeleton from the BOSH cloud config and source vCenter inventory
+func (i *Init) Execute([]string) error {
+	log.Initialize(i.Debug, i.RedactSecrets)
+
+	b := &config.Bosh{
+		ClientID:     i.BoshClient,
+		ClientSecret: i.BoshClientSecret,
+		Insecure:     i.BoshInsecure,
+	}
+	if strings.HasPrefix(i.BoshEnvironment, "https://") {
+		b.URL = i.BoshEnvironment
+	} else {
+		b.Host = i.BoshEnvironment
+	}
+	if strings.Contains(i.BoshCACert, "-----BEGIN") {
+		b.CACert = i.BoshCACert
+	} else {
+		b.CACertFile = i.BoshCACert
+	}
+
+	source := config.VCenter{
+		Host:       i.SourceHost,
+		Username:   i.SourceUsername,
+		Password:   i.SourcePassword,
+		Insecure:   i.SourceInsecure,
+		CACertFile: i.SourceCACertFile,
+	}
+	var target *config.VCenter
+	if i.TargetHost != "" {
+		if i.TargetUsername == "" || i.TargetPassword == "" || i.TargetDatacenter == "" {
+			return errors.New("the target vCenter requires a username, password and datacenter")
+		}
+		target = &config.VCenter{
+			Host:       i.TargetHost,
+			Username:   i.TargetUsername,
+			Password:   i.TargetPassword,
+			Datacenter: i.TargetDatacenter,
+			Insecure:   i.TargetInsecure,
+			CACertFile: i.TargetCACertFile,
+		}
+	}
+
+	initializer, err := migrate.NewConfigInitializerFromConfig(b, i.Proxy, source, target)
+	if err != nil {
+		return err
+	}
+	out, err := initializer.WithMatchNames(i.MatchNames).Generate(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if i.OutputPath == "" {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	err = os.WriteFile(i.OutputPath, out, 0600)
+	if err != nil {
+		return fmt.Errorf("could not write %s: %w", i.OutputPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package command
+
+import (
+	"context"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+)
+
+type Leftovers struct {
+	ConfigOptions
+	CleanupEmptyFolders bool `long:"cleanup-empty-folders" description:"delete any empty VM folders found on the source vcenter"`
+}
+
+// Execute - reports the VMs, folders and disks left on the source vcenter after a migration
+func (lo *Leftovers) Execute([]string) error {
+	log.Initialize(lo.Debug, lo.RedactSecrets)
+	ctx := context.Background()
+
+	c, err := lo.combinedConfig()
+	if err != nil {
+		return err
+	}
+
+	s, err := migrate.NewLeftoverScannerFromConfig(c)
+	if err != nil {
+		return err
+	}
+	_, err = s.WithCleanup(lo.CleanupEmptyFolders).Scan(ctx)
+	return err
+}
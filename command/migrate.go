@@ -10,15 +10,26 @@ import (
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+	"time"
 )
 
-type Migrate struct {
+// ConfigOptions are the flags shared by every command that reads the migrate.yml
+type ConfigOptions struct {
 	ConfigFilePath string `long:"config"  description:"path to the migrate.yml, defaults to ./migrate.yml"`
 	DryRun         bool   `long:"dry-run"  description:"does not perform any migration operations when true"`
 	Debug          bool   `long:"debug"  description:"sets log level to debug"`
 	RedactSecrets  bool   `long:"no-redact" description:"do not redact sensitive information when printing debug logs"`
 }
 
+type Migrate struct {
+	ConfigOptions
+
+	Canaries          int           `long:"canaries" description:"number of VMs per AZ in each wave to migrate before pausing for confirmation"`
+	AutoContinueAfter time.Duration `long:"auto-continue-after" description:"continue after the canaries without confirmation once this duration passes, e.g. 5m"`
+
+	CleanupSourceFolders bool `long:"cleanup-source-folders" description:"delete the source BOSH VM folders emptied by the migration"`
+}
+
 // Execute - runs the migration
 func (m *Migrate) Execute([]string) error {
 	log.Initialize(m.Debug, m.RedactSecrets)
@@ -36,15 +47,43 @@ func (m *Migrate) Execute([]string) error {
 	return fm.Migrate(ctx)
 }
 
+// combinedConfig returns the migrate.yml with the migration flags applied
 func (m *Migrate) combinedConfig() (config.Config, error) {
-	if m.ConfigFilePath == "" {
-		m.ConfigFilePath = "migrate.yml"
+	c, err := m.loadConfig()
+	if err != nil {
+		return config.Config{}, err
+	}
+	if m.Canaries > 0 {
+		c.Canaries = m.Canaries
+	}
+	if m.AutoContinueAfter > 0 {
+		c.AutoContinueAfter = m.AutoContinueAfter
+	}
+	if m.CleanupSourceFolders {
+		c.CleanupSourceFolders = true
 	}
-	c, err := config.NewConfigFromFile(m.ConfigFilePath)
+	log.WithoutContext().Debugf("Combined config: \n%s", c)
+	return c, nil
+}
+
+// combinedConfig returns the migrate.yml with the shared flags applied
+func (o *ConfigOptions) combinedConfig() (config.Config, error) {
+	c, err := o.loadConfig()
 	if err != nil {
 		return config.Config{}, err
 	}
-	c.DryRun = m.DryRun
 	log.WithoutContext().Debugf("Combined config: \n%s", c)
 	return c, nil
 }
+
+func (o *ConfigOptions) loadConfig() (config.Config, error) {
+	if o.ConfigFilePath == "" {
+		o.ConfigFilePath = "migrate.yml"
+	}
+	c, err := config.NewConfigFromFile(o.ConfigFilePath)
+	if err != nil {
+		return config.Config{}, err
+	}
+	c.DryRun = o.DryRun
+	return c, nil
+}
@@ -25,6 +25,9 @@ func (r *Revert) Execute([]string) error {
 		return err
 	}
 
+	if err := c.ReverseMappingRulesErr(); err != nil {
+		return err
+	}
 	fm, err := migrate.NewFoundationMigratorFromConfig(c.Reversed())
 	if err != nil {
 		return err
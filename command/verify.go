@@ -0,0 +1,42 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package command
+
+import (
+	"context"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+)
+
+type Verify struct {
+	ConfigOptions
+	Revert bool `long:"revert" description:"verify a prior revert, expecting the VMs back on the source vcenter"`
+}
+
+// Execute - verifies every VM was migrated where the migrate.yml mappings place it
+func (v *Verify) Execute([]string) error {
+	log.Initialize(v.Debug, v.RedactSecrets)
+	ctx := context.Background()
+
+	c, err := v.combinedConfig()
+	if err != nil {
+		return err
+	}
+
+	if v.Revert {
+		if err := c.ReverseMappingRulesErr(); err != nil {
+			return err
+		}
+		c = c.Reversed()
+	}
+	verifier, err := migrate.NewVerifierFromConfig(c)
+	if err != nil {
+		return err
+	}
+	_, err = verifier.Verify(ctx)
+	return err
+}
@@ -47,6 +47,18 @@ type FakeGogoBoshClient struct {
 		result1 []gogobosh.Deployment
 		result2 error
 	}
+	GetOrphanedDisksStub        func() ([]bosh.OrphanedDisk, error)
+	getOrphanedDisksMutex       sync.RWMutex
+	getOrphanedDisksArgsForCall []struct {
+	}
+	getOrphanedDisksReturns struct {
+		result1 []bosh.OrphanedDisk
+		result2 error
+	}
+	getOrphanedDisksReturnsOnCall map[int]struct {
+		result1 []bosh.OrphanedDisk
+		result2 error
+	}
 	GetStemcellsStub        func() ([]gogobosh.Stemcell, error)
 	getStemcellsMutex       sync.RWMutex
 	getStemcellsArgsForCall []struct {
@@ -247,6 +259,62 @@ func (fake *FakeGogoBoshClient) GetDeploymentsReturnsOnCall(i int, result1 []gog
 	}{result1, result2}
 }
 
+func (fake *FakeGogoBoshClient) GetOrphanedDisks() ([]bosh.OrphanedDisk, error) {
+	fake.getOrphanedDisksMutex.Lock()
+	ret, specificReturn := fake.getOrphanedDisksReturnsOnCall[len(fake.getOrphanedDisksArgsForCall)]
+	fake.getOrphanedDisksArgsForCall = append(fake.getOrphanedDisksArgsForCall, struct {
+	}{})
+	stub := fake.GetOrphanedDisksStub
+	fakeReturns := fake.getOrphanedDisksReturns
+	fake.recordInvocation("GetOrphanedDisks", []interface{}{})
+	fake.getOrphanedDisksMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGogoBoshClient) GetOrphanedDisksCallCount() int {
+	fake.getOrphanedDisksMutex.RLock()
+	defer fake.getOrphanedDisksMutex.RUnlock()
+	return len(fake.getOrphanedDisksArgsForCall)
+}
+
+func (fake *FakeGogoBoshClient) GetOrphanedDisksCalls(stub func() ([]bosh.OrphanedDisk, error)) {
+	fake.getOrphanedDisksMutex.Lock()
+	defer fake.getOrphanedDisksMutex.Unlock()
+	fake.GetOrphanedDisksStub = stub
+}
+
+func (fake *FakeGogoBoshClient) GetOrphanedDisksReturns(result1 []bosh.OrphanedDisk, result2 error) {
+	fake.getOrphanedDisksMutex.Lock()
+	defer fake.getOrphanedDisksMutex.Unlock()
+	fake.GetOrphanedDisksStub = nil
+	fake.getOrphanedDisksReturns = struct {
+		result1 []bosh.OrphanedDisk
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGogoBoshClient) GetOrphanedDisksReturnsOnCall(i int, result1 []bosh.OrphanedDisk, result2 error) {
+	fake.getOrphanedDisksMutex.Lock()
+	defer fake.getOrphanedDisksMutex.Unlock()
+	fake.GetOrphanedDisksStub = nil
+	if fake.getOrphanedDisksReturnsOnCall == nil {
+		fake.getOrphanedDisksReturnsOnCall = make(map[int]struct {
+			result1 []bosh.OrphanedDisk
+			result2 error
+		})
+	}
+	fake.getOrphanedDisksReturnsOnCall[i] = struct {
+		result1 []bosh.OrphanedDisk
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeGogoBoshClient) GetStemcells() ([]gogobosh.Stemcell, error) {
 	fake.getStemcellsMutex.Lock()
 	ret, specificReturn := fake.getStemcellsReturnsOnCall[len(fake.getStemcellsArgsForCall)]
@@ -312,6 +380,8 @@ func (fake *FakeGogoBoshClient) Invocations() map[string][][]interface{} {
 	defer fake.getDeploymentVMsMutex.RUnlock()
 	fake.getDeploymentsMutex.RLock()
 	defer fake.getDeploymentsMutex.RUnlock()
+	fake.getOrphanedDisksMutex.RLock()
+	defer fake.getOrphanedDisksMutex.RUnlock()
 	fake.getStemcellsMutex.RLock()
 	defer fake.getStemcellsMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
@@ -9,13 +9,17 @@ package bosh
 
 import (
 	"context"
-	"errors"
+	"crypto/tls"
 	"fmt"
-	"gopkg.in/yaml.v3"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/cloudfoundry-community/gogobosh"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/certs"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/proxy"
 )
 
 //counterfeiter:generate . GogoBoshClient
@@ -24,13 +28,25 @@ type GogoBoshClient interface {
 	GetCloudConfig(latest bool) ([]gogobosh.Cfg, error)
 	GetDeployments() ([]gogobosh.Deployment, error)
 	GetStemcells() ([]gogobosh.Stemcell, error)
+	GetOrphanedDisks() ([]OrphanedDisk, error)
 }
 
 type Client struct {
 	ClientID     string
 	ClientSecret string
 	Environment  string
+	Username     string
+	Password     string
+	UAAURL       string
+	URL          string
+	Port         int
+	CACert       string
+	CACertFile   string
+	Insecure     bool
 	client       GogoBoshClient
+
+	// optional proxy, otherwise connects directly
+	dialer *proxy.Dialer
 }
 
 func New(environment, clientID, clientSecret string) *Client {
@@ -41,6 +57,56 @@ func New(environment, clientID, clientSecret string) *Client {
 	}
 }
 
+// WithUser authenticates with UAA using the password grant instead of the client credentials grant,
+// the client ID and secret are optional and default to the BOSH CLI's client
+func (c *Client) WithUser(username, password string) *Client {
+	c.Username = username
+	c.Password = password
+	return c
+}
+
+// WithUAAURL uses the UAA URL instead of the URL advertised by the director
+func (c *Client) WithUAAURL(uaaURL string) *Client {
+	c.UAAURL = uaaURL
+	return c
+}
+
+// WithURL connects to the director URL instead of the environment, e.g. via a load balancer
+func (c *Client) WithURL(directorURL string) *Client {
+	c.URL = directorURL
+	return c
+}
+
+// WithPort connects to the environment on the port instead of the default 25555
+func (c *Client) WithPort(port int) *Client {
+	c.Port = port
+	return c
+}
+
+// WithCACert trusts the PEM encoded CA certificate(s) when verifying the director certificate
+func (c *Client) WithCACert(caCert string) *Client {
+	c.CACert = caCert
+	return c
+}
+
+// WithCACertFile trusts the CA certificate(s) in the PEM file when verifying the director certificate
+func (c *Client) WithCACertFile(caCertFile string) *Client {
+	c.CACertFile = caCertFile
+	return c
+}
+
+// WithInsecure skips verification of the director certificate
+func (c *Client) WithInsecure(insecure bool) *Client {
+	c.Insecure = insecure
+	return c
+}
+
+// WithProxy connects to the director using the proxy dialer
+func (c *Client) WithProxy(dialer *proxy.Dialer) *Client {
+	c.dialer = dialer
+	return c
+}
+
 func NewFromGogoBoshClient(client GogoBoshClient) *Client {
 	return &Client{
 		client: client,
@@ -50,41 +116,20 @@ func NewFromGogoBoshClient(client GogoBoshClient) *Client {
 func (c *Client) VMsAndStemcells(ctx context.Context) ([]VM, error) {
 	l := log.FromContext(ctx)
 
-	client, err := c.getOrCreateUnderlyingClient()
+	client, err := c.getOrCreateUnderlyingClient(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	l.Debug("Getting BOSH cloud config")
+	l.Debug("Getting BOSH cloud and CPI configs")
 	configs, err := client.GetCloudConfig(true)
 	if err != nil {
 		return nil, err
 	}
 
-	// find the default cloud config
-	var cc string
-	for _, cfg := range configs {
-		if cfg.Name == "default" && cfg.Type == "cloud" {
-			cc = cfg.Content
-		}
-	}
-	if cc == "" {
-		return nil, errors.New("could not find BOSH default cloud config")
-	}
-
-	cloudConfig := CloudConfig{}
-	err = yaml.Unmarshal([]byte(cc), &cloudConfig)
+	cpiToAZs, err := newCPIToAZs(configs)
 	if err != nil {
-		return nil, fmt.Errorf("could not unmarshal BOSH cloud config: %w", err)
-	}
-
-	// create map of CPI IDs -> AZs/Clusters
-	cpiToAZ := map[string]string{}
-	for _, az := range cloudConfig.AZs {
-		// just add the first AZ - we don't care which AZ as long as it's maps to a vCenter/CPI
-		if _, ok := cpiToAZ[az.Name]; !ok {
-			cpiToAZ[az.CPI] = az.Name
-		}
+		return nil, err
 	}
 
 	l.Debug("Getting all BOSH managed stemcells")
@@ -94,18 +139,21 @@ func (c *Client) VMsAndStemcells(ctx context.Context) ([]VM, error) {
 			"failed to get bosh stemcells, this can happen because of incorrect login details: %w", err)
 	}
 
+	// add each stemcell once for every AZ served by its CPI, as the stemcell may be in any of those AZs'
+	// clusters and each AZ may map to a different target
 	var result []VM
 	for _, s := range stemcells {
 		l.Debugf("  %s - %s", s.Name, s.CID)
-		az, ok := cpiToAZ[s.CPI]
-		if !ok {
+		azs := cpiToAZs[s.CPI]
+		if len(azs) == 0 {
 			return nil, fmt.Errorf("could not find a CPI to AZ mapping for stemcell %s", s.CID)
 		}
-		v := VM{
-			Name: s.CID,
-			AZ:   az,
+		for _, az := range azs {
+			result = append(result, VM{
+				Name: s.CID,
+				AZ:   az,
+			})
 		}
-		result = append(result, v)
 	}
 
 	deployments, err := client.GetDeployments()
@@ -125,8 +173,10 @@ func (c *Client) VMsAndStemcells(ctx context.Context) ([]VM, error) {
 			instanceName := vm.JobName + "/" + vm.ID
 			l.Debugf("  %s - %s", vm.VMCID, instanceName)
 			v := VM{
-				Name: vm.VMCID,
-				AZ:   vm.AZ,
+				Name:          vm.VMCID,
+				AZ:            vm.AZ,
+				Deployment:    d.Name,
+				InstanceGroup: vm.JobName,
 			}
 			result = append(result, v)
 		}
@@ -135,21 +185,104 @@ func (c *Client) VMsAndStemcells(ctx context.Context) ([]VM, error) {
 	return result, nil
 }
 
-func (c *Client) getOrCreateUnderlyingClient() (GogoBoshClient, error) {
+// CloudAndCPIConfigs returns the latest default and named cloud configs and the CPI config, if any
+func (c *Client) CloudAndCPIConfigs(ctx context.Context) ([]gogobosh.Cfg, error) {
+	l := log.FromContext(ctx)
+
+	client, err := c.getOrCreateUnderlyingClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Debug("Getting BOSH cloud and CPI configs")
+	configs, err := client.GetCloudConfig(true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bosh configs: %w", err)
+	}
+
+	var result []gogobosh.Cfg
+	for _, cfg := range configs {
+		if cfg.Type == "cloud" || cfg.Type == "cpi" {
+			result = append(result, cfg)
+		}
+	}
+	return result, nil
+}
+
+// OrphanedDisks returns all orphaned persistent disks, these aren't attached to any VM
+func (c *Client) OrphanedDisks(ctx context.Context) ([]Disk, error) {
+	l := log.FromContext(ctx)
+
+	client, err := c.getOrCreateUnderlyingClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Debug("Getting all BOSH orphaned disks")
+	orphanedDisks, err := client.GetOrphanedDisks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bosh orphaned disks: %w", err)
+	}
+	l.Infof("Found %d BOSH orphaned disks", len(orphanedDisks))
+
+	var result []Disk
+	for _, d := range orphanedDisks {
+		l.Debugf("  %s - %s/%s", d.CID, d.DeploymentName, d.InstanceName)
+		result = append(result, Disk{
+			CID: d.CID,
+			AZ:  d.AZ,
+		})
+	}
+	return result, nil
+}
+
+// UnhealthyInstances returns the names of the deployment's instances whose processes aren't all running,
+// instances BOSH has been told to ignore are skipped
+func (c *Client) UnhealthyInstances(ctx context.Context, deployment string) ([]string, error) {
+	l := log.FromContext(ctx)
+
+	client, err := c.getOrCreateUnderlyingClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Debugf("Getting deployment %s instance health", deployment)
+	vms, err := client.GetDeploymentVMs(deployment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployment %s VMs: %w", deployment, err)
+	}
+
+	var result []string
+	for _, vm := range vms {
+		if vm.Ignore || vm.JobState == "running" {
+			continue
+		}
+		instanceName := vm.JobName + "/" + vm.ID
+		l.Debugf("  %s is %s", instanceName, vm.JobState)
+		result = append(result, instanceName)
+	}
+	return result, nil
+}
+
+func (c *Client) getOrCreateUnderlyingClient(ctx context.Context) (GogoBoshClient, error) {
 	if c.client != nil {
 		return c.client, nil
 	}
 
-	config := &gogobosh.Config{
-		BOSHAddress:       fmt.Sprintf("https://%s:25555", c.Environment),
-		ClientID:          c.ClientID,
-		ClientSecret:      c.ClientSecret,
-		HttpClient:        http.DefaultClient,
-		SkipSslValidation: true,
+	transport, err := c.transport(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	log.WithoutContext().Debugf("Creating bosh client to connect to %s", config.BOSHAddress)
-	client, err := gogobosh.NewClient(config)
+	address := c.address()
+	log.FromContext(ctx).Debugf("Creating bosh client to connect to %s", address)
+	client, err := newDirector(ctx, address, credentials{
+		clientID:     c.ClientID,
+		clientSecret: c.ClientSecret,
+		username:     c.Username,
+		password:     c.Password,
+		uaaURL:       c.UAAURL,
+	}, transport)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create bosh client: %w", err)
 	}
@@ -157,3 +290,43 @@ func (c *Client) getOrCreateUnderlyingClient() (GogoBoshClient, error) {
 	c.client = client
 	return client, nil
 }
+
+// address returns the director URL, using the default director port unless the environment or
+// configured port specifies otherwise
+func (c *Client) address() string {
+	if c.URL != "" {
+		return strings.TrimSuffix(c.URL, "/")
+	}
+	if c.Port != 0 {
+		return "https://" + net.JoinHostPort(c.Environment, strconv.Itoa(c.Port))
+	}
+	if _, _, err := net.SplitHostPort(c.Environment); err == nil {
+		return "https://" + c.Environment
+	}
+	return fmt.Sprintf("https://%s:25555", c.Environment)
+}
+
+func (c *Client) transport(ctx context.Context) (*http.Transport, error) {
+	rootCAs, err := certs.NewPool(c.CACert, c.CACertFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not load bosh director CA certs: %w", err)
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	// without a configured CA the director certificate is verified against the host's root CAs
+	t.TLSClientConfig = &tls.Config{
+		RootCAs: rootCAs,
+	}
+	if c.Insecure {
+		log.FromContext(ctx).Warn("Bosh insecure is set, skipping director certificate verification")
+		t.TLSClientConfig.InsecureSkipVerify = true
+	}
+	if c.dialer != nil {
+		t.DialContext = c.dialer.DialContext
+		if c.dialer.Proxied() {
+			// the configured proxy takes precedence over any proxy environment variables
+			t.Proxy = nil
+		}
+	}
+	return t, nil
+}
@@ -37,7 +37,7 @@ func TestVMsAndStemcells_ReturnsErrorWhenCloudConfigNotFound(t *testing.T) {
 	c := bosh.NewFromGogoBoshClient(gb)
 	_, err := c.VMsAndStemcells(context.Background())
 	require.Error(t, err)
-	require.Equal(t, "could not find BOSH default cloud config", err.Error())
+	require.Equal(t, "could not find any BOSH cloud config", err.Error())
 }
 
 func TestVMsAndStemcells_ReturnsErrorWhenCloudConfigNotYaml(t *testing.T) {
@@ -199,11 +199,123 @@ func TestVMsAndStemcells_ReturnsStemcellsAndVMs(t *testing.T) {
 
 	require.Equal(t, "vm-guid1", vms[2].Name)
 	require.Equal(t, "az1", vms[2].AZ)
+	require.Equal(t, "pivotal-container-service-guid", vms[2].Deployment)
+	require.Equal(t, "pks-db", vms[2].InstanceGroup)
 
 	require.Equal(t, "vm-guid2", vms[3].Name)
 	require.Equal(t, "az2", vms[3].AZ)
 }
 
+func TestVMsAndStemcells_MapsStemcellsToEveryAZOfTheirCPI(t *testing.T) {
+	configs := []gogobosh.Cfg{
+		{
+			ID:      "8",
+			Name:    "default",
+			Type:    "cpi",
+			Content: cpiConfigYaml,
+		},
+		{
+			ID:      "6",
+			Name:    "default",
+			Type:    "cloud",
+			Content: multiCPICloudConfigYaml,
+		},
+		{
+			ID:      "7",
+			Name:    "vc02-azs",
+			Type:    "cloud",
+			Content: namedCloudConfigYaml,
+		},
+	}
+
+	gb := &boshfakes.FakeGogoBoshClient{}
+	gb.GetCloudConfigReturns(configs, nil)
+	gb.GetStemcellsReturns([]gogobosh.Stemcell{
+		{CID: "sc1-guid", CPI: "vc01"},
+		{CID: "sc2-guid", CPI: "vc02"},
+		{CID: "sc3-guid", CPI: ""},
+	}, nil)
+
+	c := bosh.NewFromGogoBoshClient(gb)
+	vms, err := c.VMsAndStemcells(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []bosh.VM{
+		{Name: "sc1-guid", AZ: "az1"},
+		{Name: "sc1-guid", AZ: "az2"},
+		{Name: "sc2-guid", AZ: "az3"},
+		{Name: "sc2-guid", AZ: "az4"},
+		{Name: "sc3-guid", AZ: "az1"},
+		{Name: "sc3-guid", AZ: "az2"},
+	}, vms)
+}
+
+func TestVMsAndStemcells_ReturnsErrorWhenNamedCloudConfigNotYaml(t *testing.T) {
+	configs := []gogobosh.Cfg{
+		{
+			ID:      "6",
+			Name:    "default",
+			Type:    "cloud",
+			Content: cloudConfigYaml,
+		},
+		{
+			ID:      "7",
+			Name:    "vc02-azs",
+			Type:    "cloud",
+			Content: "garbage",
+		},
+	}
+
+	gb := &boshfakes.FakeGogoBoshClient{}
+	gb.GetCloudConfigReturns(configs, nil)
+
+	c := bosh.NewFromGogoBoshClient(gb)
+	_, err := c.VMsAndStemcells(context.Background())
+	require.ErrorContains(t, err, "could not unmarshal BOSH vc02-azs cloud config: yaml")
+}
+
+// the CPI config after migrating a single vCenter director to multiple vCenters
+const cpiConfigYaml = `
+cpis:
+- name: vc01
+  type: vsphere
+  migrated_from:
+  - name: ""
+  properties:
+    host: vc01.example.com
+- name: vc02
+  type: vsphere
+  properties:
+    host: vc02.example.com
+`
+
+const multiCPICloudConfigYaml = `
+azs:
+- name: az1
+  cpi: vc01
+  cloud_properties:
+    datacenters:
+    - name: dc1
+      clusters:
+      - cl1: {}
+- name: az2
+  cpi: vc01
+  cloud_properties:
+    datacenters:
+    - name: dc1
+      clusters:
+      - cl2: {}
+`
+
+const namedCloudConfigYaml = `
+azs:
+- name: az3
+  cpi: vc02
+- name: az4
+  cpi: vc02
+- name: az1
+  cpi: vc01
+`
+
 const cloudConfigYaml = `
 azs:
 - cpi: 1e668fac900079c31a44
@@ -223,3 +335,101 @@ azs:
       - vc01cl01:
           resource_pool: 
 `
+
+func TestOrphanedDisks(t *testing.T) {
+	gb := &boshfakes.FakeGogoBoshClient{}
+	gb.GetOrphanedDisksReturns([]bosh.OrphanedDisk{
+		{
+			CID:            "disk-guid1",
+			Size:           10240,
+			AZ:             "az1",
+			DeploymentName: "cf-guid",
+			InstanceName:   "mysql/guid",
+		},
+		{
+			CID:            "disk-guid2.eyJ0YXJnZXRfZGF0YXN0b3JlX3BhdHRlcm4iOiJEUzEifQ",
+			Size:           10240,
+			AZ:             "az2",
+			DeploymentName: "cf-guid",
+			InstanceName:   "mysql/guid",
+		},
+	}, nil)
+
+	c := bosh.NewFromGogoBoshClient(gb)
+	disks, err := c.OrphanedDisks(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []bosh.Disk{
+		{CID: "disk-guid1", AZ: "az1"},
+		{CID: "disk-guid2.eyJ0YXJnZXRfZGF0YXN0b3JlX3BhdHRlcm4iOiJEUzEifQ", AZ: "az2"},
+	}, disks)
+}
+
+func TestOrphanedDisks_ReturnsError(t *testing.T) {
+	gb := &boshfakes.FakeGogoBoshClient{}
+	gb.GetOrphanedDisksReturns(nil, errors.New("connection refused"))
+
+	c := bosh.NewFromGogoBoshClient(gb)
+	_, err := c.OrphanedDisks(context.Background())
+	require.EqualError(t, err, "failed to get bosh orphaned disks: connection refused")
+}
+
+func TestUnhealthyInstances(t *testing.T) {
+	gb := &boshfakes.FakeGogoBoshClient{}
+	gb.GetDeploymentVMsReturns([]gogobosh.VM{
+		{JobName: "router", ID: "guid1", JobState: "running"},
+		{JobName: "diego_cell", ID: "guid2", JobState: "failing"},
+		{JobName: "diego_cell", ID: "guid3", JobState: "unresponsive agent"},
+		{JobName: "uaa", ID: "guid4", JobState: "stopped", Ignore: true},
+	}, nil)
+
+	c := bosh.NewFromGogoBoshClient(gb)
+	unhealthy, err := c.UnhealthyInstances(context.Background(), "cf-guid")
+	require.NoError(t, err)
+	require.Equal(t, []string{"diego_cell/guid2", "diego_cell/guid3"}, unhealthy)
+	require.Equal(t, "cf-guid", gb.GetDeploymentVMsArgsForCall(0))
+
+	gb.GetDeploymentVMsReturns(nil, errors.New("connection refused"))
+	_, err = c.UnhealthyInstances(context.Background(), "cf-guid")
+	require.EqualError(t, err, "failed to get deployment cf-guid VMs: connection refused")
+}
+
+func TestDeploymentHealth(t *testing.T) {
+	gb := &boshfakes.FakeGogoBoshClient{}
+	gb.GetDeploymentVMsReturns([]gogobosh.VM{
+		{
+			VMCID:    "vm-guid1",
+			JobName:  "router",
+			ID:       "guid1",
+			JobState: "running",
+			Processes: []gogobosh.Process{
+				{Name: "gorouter", State: "running"},
+			},
+		},
+		{
+			VMCID:    "vm-guid2",
+			JobName:  "diego_cell",
+			ID:       "guid2",
+			JobState: "failing",
+			Processes: []gogobosh.Process{
+				{Name: "rep", State: "running"},
+				{Name: "garden", State: "failing"},
+			},
+		},
+	}, nil)
+
+	c := bosh.NewFromGogoBoshClient(gb)
+	health, err := c.DeploymentHealth(context.Background(), "cf-guid")
+	require.NoError(t, err)
+	require.Len(t, health, 2)
+	require.Equal(t, 1, gb.GetDeploymentVMsCallCount())
+	require.Equal(t, "cf-guid", gb.GetDeploymentVMsArgsForCall(0))
+
+	require.True(t, health["vm-guid1"].Healthy())
+	require.Equal(t, "router/guid1 is running", health["vm-guid1"].String())
+	require.False(t, health["vm-guid2"].Healthy())
+	require.Equal(t, "diego_cell/guid2 is failing: garden is failing", health["vm-guid2"].String())
+
+	gb.GetDeploymentVMsReturns(nil, errors.New("connection refused"))
+	_, err = c.DeploymentHealth(context.Background(), "cf-guid")
+	require.EqualError(t, err, "failed to get deployment cf-guid VMs: connection refused")
+}
@@ -5,6 +5,14 @@
 
 package bosh
 
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cloudfoundry-community/gogobosh"
+	"gopkg.in/yaml.v3"
+)
+
 type CloudConfig struct {
 	AZs []AZs `yaml:"azs"`
 }
@@ -13,3 +21,73 @@ type AZs struct {
 	CPI  string `yaml:"cpi"`
 	Name string `yaml:"name"`
 }
+
+// CPIConfig is only present on directors with multiple CPIs, i.e. multiple vCenters or datacenters
+type CPIConfig struct {
+	CPIs []CPI `yaml:"cpis"`
+}
+
+type CPI struct {
+	Name         string            `yaml:"name"`
+	Type         string            `yaml:"type"`
+	MigratedFrom []CPIMigratedFrom `yaml:"migrated_from"`
+}
+
+// CPIMigratedFrom is a previous CPI name, stemcells uploaded before the CPI was renamed still reference it
+type CPIMigratedFrom struct {
+	Name string `yaml:"name"`
+}
+
+// newCPIToAZs maps each CPI name to every AZ it serves using the latest director configs, which include
+// the CPI config (if any) and the default and any named cloud configs
+func newCPIToAZs(configs []gogobosh.Cfg) (map[string][]string, error) {
+	var cloudConfigs []CloudConfig
+	var cpiConfig CPIConfig
+	for _, cfg := range configs {
+		switch cfg.Type {
+		case "cloud":
+			cc := CloudConfig{}
+			err := yaml.Unmarshal([]byte(cfg.Content), &cc)
+			if err != nil {
+				if cfg.Name == "default" {
+					return nil, fmt.Errorf("could not unmarshal BOSH cloud config: %w", err)
+				}
+				return nil, fmt.Errorf("could not unmarshal BOSH %s cloud config: %w", cfg.Name, err)
+			}
+			cloudConfigs = append(cloudConfigs, cc)
+		case "cpi":
+			err := yaml.Unmarshal([]byte(cfg.Content), &cpiConfig)
+			if err != nil {
+				return nil, fmt.Errorf("could not unmarshal BOSH CPI config: %w", err)
+			}
+		}
+	}
+	if len(cloudConfigs) == 0 {
+		return nil, errors.New("could not find any BOSH cloud config")
+	}
+
+	cpiToAZs := map[string][]string{}
+	seen := map[string]bool{}
+	for _, cc := range cloudConfigs {
+		for _, az := range cc.AZs {
+			// the same AZ can be declared in more than one cloud config
+			if seen[az.Name] {
+				continue
+			}
+			seen[az.Name] = true
+			cpiToAZs[az.CPI] = append(cpiToAZs[az.CPI], az.Name)
+		}
+	}
+
+	// stemcells uploaded before a CPI was renamed, or before the CPI config existed, still reference
+	// the old CPI name
+	for _, cpi := range cpiConfig.CPIs {
+		for _, old := range cpi.MigratedFrom {
+			if _, ok := cpiToAZs[old.Name]; !ok {
+				cpiToAZs[old.Name] = cpiToAZs[cpi.Name]
+			}
+		}
+	}
+
+	return cpiToAZs, nil
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package bosh
+
+import (
+	"fmt"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/yamlnode"
+)
+
+// CloudConfigAZ is a cloud config AZ and the vSphere datacenter and clusters it places VMs in
+type CloudConfigAZ struct {
+	Name       string
+	Datacenter string
+	Clusters   []CloudConfigCluster
+}
+
+// CloudConfigCluster is a cloud config AZ cluster and its optional resource pool
+type CloudConfigCluster struct {
+	Name         string
+	ResourcePool string
+}
+
+// CloudConfigObjects are the vSphere objects a cloud config references
+type CloudConfigObjects struct {
+	AZs        []CloudConfigAZ
+	Datastores []string
+	Networks   []string
+}
+
+// ParseCloudConfigObjects returns the AZs, datastores and networks used by the cloud config
+func ParseCloudConfigObjects(content string) (*CloudConfigObjects, error) {
+	_, root, err := yamlnode.Parse([]byte(content))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse BOSH cloud config: %w", err)
+	}
+
+	objects := &CloudConfigObjects{}
+	for _, az := range yamlnode.Items(yamlnode.MapValue(root, "azs")) {
+		cloudAZ := CloudConfigAZ{
+			Name: yamlnode.ScalarValue(az, "name"),
+		}
+		dcs := yamlnode.Items(yamlnode.MapValue(yamlnode.MapValue(az, "cloud_properties"), "datacenters"))
+		if len(dcs) > 0 {
+			cloudAZ.Datacenter = yamlnode.ScalarValue(dcs[0], "name")
+			for _, cl := range yamlnode.Items(yamlnode.MapValue(dcs[0], "clusters")) {
+				name, props := clusterEntry(cl)
+				cloudAZ.Clusters = append(cloudAZ.Clusters, CloudConfigCluster{
+					Name:         name,
+					ResourcePool: yamlnode.ScalarValue(props, "resource_pool"),
+				})
+			}
+		}
+		objects.AZs = append(objects.AZs, cloudAZ)
+	}
+
+	for _, section := range []string{"vm_types", "vm_extensions", "disk_types"} {
+		for _, item := range yamlnode.Items(yamlnode.MapValue(root, section)) {
+			cp := yamlnode.MapValue(item, "cloud_properties")
+			for _, ds := range yamlnode.Items(yamlnode.MapValue(cp, "datastores")) {
+				objects.Datastores = appendUnique(objects.Datastores, ds.Value)
+			}
+		}
+	}
+
+	for _, network := range yamlnode.Items(yamlnode.MapValue(root, "networks")) {
+		objects.Networks = appendUnique(objects.Networks,
+			yamlnode.ScalarValue(yamlnode.MapValue(network, "cloud_properties"), "name"))
+		for _, subnet := range yamlnode.Items(yamlnode.MapValue(network, "subnets")) {
+			objects.Networks = appendUnique(objects.Networks,
+				yamlnode.ScalarValue(yamlnode.MapValue(subnet, "cloud_properties"), "name"))
+		}
+	}
+	return objects, nil
+}
+
+func appendUnique(values []string, v string) []string {
+	if v == "" {
+		return values
+	}
+	for _, existing := range values {
+		if existing == v {
+			return values
+		}
+	}
+	return append(values, v)
+}
@@ -0,0 +1,41 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package bosh_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/bosh"
+)
+
+func TestParseCloudConfigObjects(t *testing.T) {
+	in, err := os.ReadFile("fixtures/cloud-config.yml")
+	require.NoError(t, err)
+
+	objects, err := bosh.ParseCloudConfigObjects(string(in))
+	require.NoError(t, err)
+	require.Equal(t, []bosh.CloudConfigAZ{
+		{
+			Name:       "az1",
+			Datacenter: "Datacenter1",
+			Clusters:   []bosh.CloudConfigCluster{{Name: "cf1", ResourcePool: "pas-az1"}},
+		},
+		{
+			Name:       "az2",
+			Datacenter: "Datacenter1",
+			Clusters:   []bosh.CloudConfigCluster{{Name: "cf2", ResourcePool: "pas-az2"}},
+		},
+	}, objects.AZs)
+	require.Equal(t, []string{"irvine-ds1", "irvine-ds2"}, objects.Datastores)
+	require.Equal(t, []string{"PAS-Deployment-01", "DSwitch/PAS-Services-01"}, objects.Networks)
+}
+
+func TestParseCloudConfigObjectsNotAMap(t *testing.T) {
+	_, err := bosh.ParseCloudConfigObjects("garbage")
+	require.EqualError(t, err, "could not parse BOSH cloud config: expected a yaml map")
+}
@@ -0,0 +1,400 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package bosh
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cloudfoundry-community/gogobosh"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/inventory"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/yamlnode"
+	"gopkg.in/yaml.v3"
+)
+
+// literalDatastorePattern matches CPI datastore patterns that are a list of datastore names, e.g. ^(ds1|ds\-2)$
+var literalDatastorePattern = regexp.MustCompile(`^\^\(?([^()*+?\[\]{}]+?)\)?\$$`)
+
+// ConfigConverter rewrites the director's cloud and CPI configs to use the migrated vSphere objects
+// using the same migrate.yml mappings that drive the VM migration
+type ConfigConverter struct {
+	config config.Config
+}
+
+// NewConfigConverter creates a new ConfigConverter using the migrate config's network, datastore and compute mappings
+func NewConfigConverter(c config.Config) *ConfigConverter {
+	return &ConfigConverter{
+		config: c,
+	}
+}
+
+// Convert returns the rewritten cloud or CPI config content, other config types are returned as-is
+func (c *ConfigConverter) Convert(cfg gogobosh.Cfg) (string, error) {
+	if cfg.Type != "cloud" && cfg.Type != "cpi" {
+		return cfg.Content, nil
+	}
+
+	doc, root, err := yamlnode.Parse([]byte(cfg.Content))
+	if err != nil {
+		return "", fmt.Errorf("could not parse BOSH %s config %s: %w", cfg.Type, cfg.Name, err)
+	}
+	if cfg.Type == "cloud" {
+		err = c.convertCloudConfig(root)
+	} else {
+		err = c.convertCPIConfig(root)
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not convert BOSH %s config %s: %w", cfg.Type, cfg.Name, err)
+	}
+
+	out, err := yamlnode.Encode(doc)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func (c *ConfigConverter) convertCloudConfig(root *yaml.Node) error {
+	for _, az := range yamlnode.Items(yamlnode.MapValue(root, "azs")) {
+		if err := c.convertAZ(az); err != nil {
+			return err
+		}
+	}
+
+	for _, section := range []string{"vm_types", "vm_extensions", "disk_types"} {
+		for _, item := range yamlnode.Items(yamlnode.MapValue(root, section)) {
+			cp := yamlnode.MapValue(item, "cloud_properties")
+			if err := c.convertDatastores(cp); err != nil {
+				return err
+			}
+			for _, dc := range yamlnode.Items(yamlnode.MapValue(cp, "datacenters")) {
+				if err := c.convertDatacenter(dc); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, network := range yamlnode.Items(yamlnode.MapValue(root, "networks")) {
+		// dynamic networks have their cloud properties on the network rather than on each subnet
+		if err := c.convertNetworkName(yamlnode.MapValue(network, "cloud_properties")); err != nil {
+			return err
+		}
+		for _, subnet := range yamlnode.Items(yamlnode.MapValue(network, "subnets")) {
+			if err := c.convertNetworkName(yamlnode.MapValue(subnet, "cloud_properties")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *ConfigConverter) convertCPIConfig(root *yaml.Node) error {
+	for _, cpi := range yamlnode.Items(yamlnode.MapValue(root, "cpis")) {
+		if t := yamlnode.ScalarValue(cpi, "type"); t != "vsphere" {
+			log.WithoutContext().Debugf("Skipping %s CPI %s", t, yamlnode.ScalarValue(cpi, "name"))
+			continue
+		}
+
+		props := yamlnode.MapValue(cpi, "properties")
+		host := yamlnode.ScalarValue(props, "host")
+		for _, dc := range yamlnode.Items(yamlnode.MapValue(props, "datacenters")) {
+			target, err := c.config.Compute.TargetVCenter(host, yamlnode.ScalarValue(dc, "name"))
+			if err != nil {
+				return err
+			}
+			yamlnode.SetScalar(props, "host", target.Host)
+			yamlnode.SetScalarUnlessVariable(props, "user", target.Username)
+			yamlnode.SetScalarUnlessVariable(props, "password", target.Password)
+
+			if err := c.convertCPIDatacenter(dc); err != nil {
+				return err
+			}
+			for _, key := range []string{"datastore_pattern", "persistent_datastore_pattern"} {
+				if err := c.convertDatastorePattern(dc, key); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// convertAZ replaces the AZ's clusters with all the target AZ's clusters
+func (c *ConfigConverter) convertAZ(az *yaml.Node) error {
+	dcs := yamlnode.Items(yamlnode.MapValue(yamlnode.MapValue(az, "cloud_properties"), "datacenters"))
+	if len(dcs) == 0 {
+		return nil
+	}
+
+	name := yamlnode.ScalarValue(az, "name")
+	target := c.config.Compute.TargetByAZ(name)
+	if target == nil || target.VCenter == nil {
+		return fmt.Errorf("could not find cloud config AZ %s in the compute target section", name)
+	}
+	if len(dcs) != 1 {
+		return fmt.Errorf("expected cloud config AZ %s to have 1 datacenter but found %d", name, len(dcs))
+	}
+
+	yamlnode.SetScalar(dcs[0], "name", target.VCenter.Datacenter)
+	clusters := yamlnode.MapValue(dcs[0], "clusters")
+	if clusters == nil {
+		return nil
+	}
+	var converted []*yaml.Node
+	for i, tcl := range target.Clusters {
+		// keep any other cluster settings like DRS rules and host groups
+		var props *yaml.Node
+		if i < len(clusters.Content) {
+			_, props = clusterEntry(clusters.Content[i])
+		}
+		converted = append(converted, newClusterEntry(inventory.HostFolderPath(tcl.Name), tcl.ResourcePool, props))
+	}
+	clusters.Content = converted
+	return nil
+}
+
+// convertDatacenter maps the datacenter and each of its clusters to the target
+func (c *ConfigConverter) convertDatacenter(dc *yaml.Node) error {
+	sourceDC := yamlnode.ScalarValue(dc, "name")
+	targetDC := ""
+	clusters := yamlnode.MapValue(dc, "clusters")
+	for i, cl := range yamlnode.Items(clusters) {
+		name, props := clusterEntry(cl)
+		target, tcl, err := c.targetCluster(sourceDC, name)
+		if err != nil {
+			return err
+		}
+		targetDC = target.Datacenter
+
+		if cl.Kind == yaml.ScalarNode {
+			cl.Value = inventory.HostFolderPath(tcl.Name)
+			continue
+		}
+		// only update the resource pool if the source cluster entry specifies one
+		rp := ""
+		if yamlnode.MapValue(props, "resource_pool") != nil {
+			rp = tcl.ResourcePool
+		}
+		clusters.Content[i] = newClusterEntry(inventory.HostFolderPath(tcl.Name), rp, props)
+	}
+
+	if targetDC == "" {
+		target, err := c.targetDatacenter(sourceDC)
+		if err != nil {
+			return err
+		}
+		targetDC = target
+	}
+	yamlnode.SetScalar(dc, "name", targetDC)
+	return nil
+}
+
+// convertCPIDatacenter replaces the CPI datacenter's clusters with every target cluster of the AZs using them,
+// so the CPI can place VMs in all target clusters when a source cluster is mapped to more than one
+func (c *ConfigConverter) convertCPIDatacenter(dc *yaml.Node) error {
+	sourceDC := yamlnode.ScalarValue(dc, "name")
+	targetDC, err := c.targetDatacenter(sourceDC)
+	if err != nil {
+		return err
+	}
+	yamlnode.SetScalar(dc, "name", targetDC)
+
+	clusters := yamlnode.MapValue(dc, "clusters")
+	var converted []*yaml.Node
+	seen := map[string]bool{}
+	for _, cl := range yamlnode.Items(clusters) {
+		name, props := clusterEntry(cl)
+		tcls := c.targetClusters(sourceDC, name)
+		if len(tcls) == 0 {
+			return fmt.Errorf("could not find cluster %s in datacenter %s in the compute source section", name, sourceDC)
+		}
+		for _, tcl := range tcls {
+			if seen[tcl.Name] {
+				continue
+			}
+			seen[tcl.Name] = true
+			if cl.Kind == yaml.ScalarNode {
+				converted = append(converted, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: inventory.HostFolderPath(tcl.Name)})
+				continue
+			}
+			rp := ""
+			if yamlnode.MapValue(props, "resource_pool") != nil {
+				rp = tcl.ResourcePool
+			}
+			converted = append(converted, newClusterEntry(inventory.HostFolderPath(tcl.Name), rp, copyNode(props)))
+		}
+	}
+	if clusters != nil {
+		clusters.Content = converted
+	}
+	return nil
+}
+
+func (c *ConfigConverter) convertDatastores(cloudProperties *yaml.Node) error {
+	for _, ds := range yamlnode.Items(yamlnode.MapValue(cloudProperties, "datastores")) {
+		if ds.Kind != yaml.ScalarNode {
+			continue
+		}
+		tds, err := c.config.TargetDatastore(ds.Value)
+		if err != nil {
+			return err
+		}
+		ds.Value = tds
+	}
+	return nil
+}
+
+// convertDatastorePattern maps patterns consisting of datastore names, any other regex is left as-is
+func (c *ConfigConverter) convertDatastorePattern(dc *yaml.Node, key string) error {
+	pattern := yamlnode.ScalarValue(dc, key)
+	if pattern == "" {
+		return nil
+	}
+	m := literalDatastorePattern.FindStringSubmatch(pattern)
+	if m == nil {
+		log.WithoutContext().Warnf("Could not map CPI %s %s, update it manually", key, pattern)
+		return nil
+	}
+
+	var converted []string
+	for _, ds := range strings.Split(m[1], "|") {
+		tds, err := c.config.TargetDatastore(unescapeRegex(ds))
+		if err != nil {
+			return err
+		}
+		converted = append(converted, regexp.QuoteMeta(tds))
+	}
+	yamlnode.SetScalar(dc, key, "^("+strings.Join(converted, "|")+")$")
+	return nil
+}
+
+func (c *ConfigConverter) convertNetworkName(cloudProperties *yaml.Node) error {
+	name := yamlnode.ScalarValue(cloudProperties, "name")
+	if name == "" {
+		return nil
+	}
+	tn, err := c.config.TargetNetwork(name)
+	if err != nil {
+		return err
+	}
+	yamlnode.SetScalar(cloudProperties, "name", tn)
+	return nil
+}
+
+// targetCluster returns the target vCenter and cluster mapped to the source datacenter's cluster. A source
+// cluster maps to the target AZ's only cluster, otherwise to the target cluster at the same position
+func (c *ConfigConverter) targetCluster(sourceDC, sourceCluster string) (*config.VCenter, config.ComputeCluster, error) {
+	for _, saz := range c.config.Compute.Source {
+		if saz.VCenter == nil || !inventory.Same(saz.VCenter.Datacenter, sourceDC) {
+			continue
+		}
+		for i, scl := range saz.Clusters {
+			if !inventory.Same(scl.Name, sourceCluster) {
+				continue
+			}
+			taz := c.config.Compute.TargetByAZ(saz.Name)
+			if taz == nil || taz.VCenter == nil || len(taz.Clusters) == 0 {
+				return nil, config.ComputeCluster{}, fmt.Errorf("could not find a corresponding compute target for AZ %s", saz.Name)
+			}
+			if len(taz.Clusters) == 1 {
+				return taz.VCenter, taz.Clusters[0], nil
+			}
+			if i < len(taz.Clusters) {
+				return taz.VCenter, taz.Clusters[i], nil
+			}
+			return nil, config.ComputeCluster{}, fmt.Errorf("could not map cluster %s to a single target cluster in AZ %s",
+				sourceCluster, taz.Name)
+		}
+	}
+	return nil, config.ComputeCluster{}, fmt.Errorf("could not find cluster %s in datacenter %s in the compute source section",
+		sourceCluster, sourceDC)
+}
+
+// targetClusters returns all target clusters of the AZs using the source datacenter's cluster
+func (c *ConfigConverter) targetClusters(sourceDC, sourceCluster string) []config.ComputeCluster {
+	var result []config.ComputeCluster
+	for _, saz := range c.config.Compute.Source {
+		if saz.VCenter == nil || !inventory.Same(saz.VCenter.Datacenter, sourceDC) {
+			continue
+		}
+		for _, scl := range saz.Clusters {
+			if !inventory.Same(scl.Name, sourceCluster) {
+				continue
+			}
+			if taz := c.config.Compute.TargetByAZ(saz.Name); taz != nil {
+				result = append(result, taz.Clusters...)
+			}
+		}
+	}
+	return result
+}
+
+func (c *ConfigConverter) targetDatacenter(sourceDC string) (string, error) {
+	for _, saz := range c.config.Compute.Source {
+		if saz.VCenter != nil && inventory.Same(saz.VCenter.Datacenter, sourceDC) {
+			taz := c.config.Compute.TargetByAZ(saz.Name)
+			if taz != nil && taz.VCenter != nil {
+				return taz.VCenter.Datacenter, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("could not find datacenter %s in the compute source section", sourceDC)
+}
+
+// clusterEntry returns the cluster name and properties of a `- cluster1: {resource_pool: rp1}` style entry
+func clusterEntry(cl *yaml.Node) (string, *yaml.Node) {
+	if cl.Kind == yaml.ScalarNode {
+		return cl.Value, nil
+	}
+	if cl.Kind != yaml.MappingNode || len(cl.Content) < 2 {
+		return "", nil
+	}
+	return cl.Content[0].Value, cl.Content[1]
+}
+
+func newClusterEntry(name, resourcePool string, props *yaml.Node) *yaml.Node {
+	if props == nil || props.Kind != yaml.MappingNode {
+		props = &yaml.Node{Kind: yaml.MappingNode, Style: yaml.FlowStyle}
+	}
+	yamlnode.SetOptionalScalar(props, "resource_pool", resourcePool)
+	return &yaml.Node{
+		Kind: yaml.MappingNode,
+		Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: name},
+			props,
+		},
+	}
+}
+
+func copyNode(n *yaml.Node) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	c := *n
+	c.Content = make([]*yaml.Node, len(n.Content))
+	for i, child := range n.Content {
+		c.Content[i] = copyNode(child)
+	}
+	return &c
+}
+
+func unescapeRegex(s string) string {
+	var b strings.Builder
+	escaped := false
+	for _, r := range s {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
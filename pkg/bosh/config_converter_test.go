@@ -0,0 +1,220 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package bosh_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/cloudfoundry-community/gogobosh"
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/bosh"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/bosh/boshfakes"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+)
+
+func migrateConfig() config.Config {
+	vc1 := &config.VCenter{
+		Host:       "vc01.example.com",
+		Username:   "administrator@vsphere.local",
+		Password:   "secret",
+		Datacenter: "Datacenter1",
+	}
+	vc2 := &config.VCenter{
+		Host:       "vc02.example.com",
+		Username:   "administrator2@vsphere.local",
+		Password:   "secret2",
+		Datacenter: "Datacenter2",
+	}
+	return config.Config{
+		NetworkMap: map[string]string{
+			"PAS-Deployment-01": "TAS-Deployment",
+			"PAS-Services-01":   "TAS-Services",
+		},
+		DatastoreMap: map[string]string{
+			"irvine-ds1": "ssd_ds1",
+			"irvine-ds2": "ssd_ds2",
+		},
+		Compute: config.Compute{
+			Source: []config.ComputeAZ{
+				{
+					Name:     "az1",
+					VCenter:  vc1,
+					Clusters: []config.ComputeCluster{{Name: "cf1", ResourcePool: "pas-az1"}},
+				},
+				{
+					Name:     "az2",
+					VCenter:  vc1,
+					Clusters: []config.ComputeCluster{{Name: "cf2", ResourcePool: "pas-az2"}},
+				},
+			},
+			Target: []config.ComputeAZ{
+				{
+					Name:     "az1",
+					VCenter:  vc2,
+					Clusters: []config.ComputeCluster{{Name: "tanzu-1", ResourcePool: "tas-az1"}},
+				},
+				{
+					Name:     "az2",
+					VCenter:  vc2,
+					Clusters: []config.ComputeCluster{{Name: "tanzu-2"}, {Name: "tanzu-3"}},
+				},
+			},
+		},
+	}
+}
+
+func requireConvertedConfig(t *testing.T, cfgType, inFile, expectedFile string) {
+	in, err := os.ReadFile(inFile)
+	require.NoError(t, err)
+	expected, err := os.ReadFile(expectedFile)
+	require.NoError(t, err)
+
+	out, err := bosh.NewConfigConverter(migrateConfig()).Convert(gogobosh.Cfg{
+		Name:    "default",
+		Type:    cfgType,
+		Content: string(in),
+	})
+	require.NoError(t, err)
+	require.YAMLEq(t, string(expected), out)
+}
+
+func TestConvertCloudConfig(t *testing.T) {
+	requireConvertedConfig(t, "cloud", "fixtures/cloud-config.yml", "fixtures/cloud-config-migrated.yml")
+}
+
+func TestConvertCPIConfig(t *testing.T) {
+	requireConvertedConfig(t, "cpi", "fixtures/cpi-config.yml", "fixtures/cpi-config-migrated.yml")
+}
+
+func TestConvertOtherConfigTypesUnchanged(t *testing.T) {
+	out, err := bosh.NewConfigConverter(migrateConfig()).Convert(gogobosh.Cfg{
+		Name:    "dns",
+		Type:    "runtime",
+		Content: "garbage: [",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "garbage: [", out)
+}
+
+func TestConvertCloudConfigErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfgType     string
+		content     string
+		expectedErr string
+	}{
+		{
+			name:        "not yaml",
+			cfgType:     "cloud",
+			content:     "garbage",
+			expectedErr: "could not parse BOSH cloud config default: expected a yaml map",
+		},
+		{
+			name:    "unknown AZ",
+			cfgType: "cloud",
+			content: "azs:\n- name: az9\n  cloud_properties:\n    datacenters:\n    - name: Datacenter1\n",
+			expectedErr: "could not convert BOSH cloud config default: " +
+				"could not find cloud config AZ az9 in the compute target section",
+		},
+		{
+			name:    "unmapped datastore",
+			cfgType: "cloud",
+			content: "disk_types:\n- name: default\n  cloud_properties:\n    datastores: [irvine-ds3]\n",
+			expectedErr: "could not convert BOSH cloud config default: " +
+				"could not find a target datastore mapping for irvine-ds3",
+		},
+		{
+			name:    "unmapped network",
+			cfgType: "cloud",
+			content: "networks:\n- name: default\n  subnets:\n  - cloud_properties:\n      name: PAS-Other\n",
+			expectedErr: "could not convert BOSH cloud config default: " +
+				"could not find a target network mapping for PAS-Other",
+		},
+		{
+			name:    "unknown vm extension cluster",
+			cfgType: "cloud",
+			content: "vm_extensions:\n- name: drs\n  cloud_properties:\n    datacenters:\n" +
+				"    - name: Datacenter1\n      clusters:\n      - cf9: {}\n",
+			expectedErr: "could not convert BOSH cloud config default: " +
+				"could not find cluster cf9 in datacenter Datacenter1 in the compute source section",
+		},
+		{
+			name:    "unknown CPI vcenter",
+			cfgType: "cpi",
+			content: "cpis:\n- name: vc09\n  type: vsphere\n  properties:\n    host: vc09.example.com\n" +
+				"    datacenters:\n    - name: Datacenter1\n",
+			expectedErr: "could not convert BOSH cpi config default: " +
+				"could not find vcenter vc09.example.com datacenter Datacenter1 in the compute source section",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := bosh.NewConfigConverter(migrateConfig()).Convert(gogobosh.Cfg{
+				Name:    "default",
+				Type:    tt.cfgType,
+				Content: tt.content,
+			})
+			require.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestConvertCPIConfigLeavesComplexDatastorePatterns(t *testing.T) {
+	in := `cpis:
+- name: vc01
+  type: vsphere
+  properties:
+    host: vc01.example.com
+    datacenters:
+    - name: Datacenter1
+      datastore_pattern: ^irvine-ds.*$
+`
+	out, err := bosh.NewConfigConverter(migrateConfig()).Convert(gogobosh.Cfg{Name: "default", Type: "cpi", Content: in})
+	require.NoError(t, err)
+	require.Contains(t, out, "datastore_pattern: ^irvine-ds.*$")
+	require.Contains(t, out, "host: vc02.example.com")
+}
+
+func TestConvertCPIConfigNestedInventory(t *testing.T) {
+	c := migrateConfig()
+	c.Compute.Source[0].VCenter.Datacenter = "/Region1/Datacenter1"
+	c.Compute.Source[0].Clusters[0].Name = "/Region1/Datacenter1/host/Folder1/cf1"
+	c.Compute.Target[0].Clusters[0].Name = "/Datacenter2/host/Folder2/tanzu-1"
+
+	in := `cpis:
+- name: vc01
+  type: vsphere
+  properties:
+    host: vc01.example.com
+    datacenters:
+    - name: Datacenter1
+      clusters:
+      - Folder1/cf1
+`
+	out, err := bosh.NewConfigConverter(c).Convert(gogobosh.Cfg{Name: "default", Type: "cpi", Content: in})
+	require.NoError(t, err)
+	require.Contains(t, out, "- Folder2/tanzu-1")
+	require.Contains(t, out, "name: Datacenter2")
+}
+
+func TestCloudAndCPIConfigs(t *testing.T) {
+	gb := &boshfakes.FakeGogoBoshClient{}
+	gb.GetCloudConfigReturns([]gogobosh.Cfg{
+		{Name: "default", Type: "cloud", Content: cloudConfigYaml},
+		{Name: "default", Type: "runtime", Content: ""},
+		{Name: "default", Type: "cpi", Content: cpiConfigYaml},
+	}, nil)
+
+	c := bosh.NewFromGogoBoshClient(gb)
+	configs, err := c.CloudAndCPIConfigs(context.Background())
+	require.NoError(t, err)
+	require.Len(t, configs, 2)
+	require.Equal(t, "cloud", configs[0].Type)
+	require.Equal(t, "cpi", configs[1].Type)
+	require.True(t, gb.GetCloudConfigArgsForCall(0))
+}
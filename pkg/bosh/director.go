@@ -0,0 +1,289 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package bosh
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry-community/gogobosh"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const (
+	taskPollInterval = time.Second
+	taskTimeout      = 5 * time.Minute
+)
+
+// director is a minimal BOSH director API client that implements GogoBoshClient
+// gogobosh.NewClient always replaces the HTTP transport, so this client is used instead to
+// ensure the configured director CA certs are honored
+type director struct {
+	address    *url.URL
+	httpClient *http.Client
+}
+
+// credentials used to authenticate with the director, either a UAA client or a user
+type credentials struct {
+	clientID     string
+	clientSecret string
+	username     string
+	password     string
+
+	// optional UAA URL that overrides the URL advertised by the director
+	uaaURL string
+}
+
+// newDirector creates a director client authenticated with the credentials either via UAA
+// or basic auth, depending on what the director's /info endpoint reports
+func newDirector(ctx context.Context, address string, creds credentials, transport http.RoundTripper) (*director, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bosh director address %s: %w", address, err)
+	}
+	d := &director{
+		address: u,
+		httpClient: &http.Client{
+			Transport: transport,
+		},
+	}
+
+	var info gogobosh.Info
+	err = d.get("/info", &info)
+	if err != nil {
+		return nil, fmt.Errorf("could not get bosh director info: %w", err)
+	}
+
+	var httpClient *http.Client
+	if info.UserAuthentication.Type == "uaa" {
+		uaaURL := info.UserAuthentication.Options.URL
+		if creds.uaaURL != "" {
+			uaaURL = creds.uaaURL
+		}
+		httpClient, err = uaaClient(context.WithValue(ctx, oauth2.HTTPClient, d.httpClient), uaaURL, creds)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		username, password := creds.clientID, creds.clientSecret
+		if creds.username != "" {
+			username, password = creds.username, creds.password
+		}
+		httpClient = &http.Client{
+			Transport: &basicAuthTransport{
+				username: username,
+				password: password,
+				base:     transport,
+			},
+		}
+	}
+	httpClient.CheckRedirect = d.checkRedirect
+	d.httpClient = httpClient
+
+	return d, nil
+}
+
+// uaaClient creates an HTTP client which authenticates using the UAA client credentials grant, or the
+// password grant when a username is specified
+func uaaClient(ctx context.Context, uaaURL string, creds credentials) (*http.Client, error) {
+	tokenURL := strings.TrimSuffix(uaaURL, "/") + "/oauth/token"
+	if creds.username == "" {
+		cc := &clientcredentials.Config{
+			ClientID:     creds.clientID,
+			ClientSecret: creds.clientSecret,
+			TokenURL:     tokenURL,
+		}
+		return cc.Client(ctx), nil
+	}
+
+	// same default client as the BOSH CLI
+	clientID := creds.clientID
+	if clientID == "" {
+		clientID = "bosh_cli"
+	}
+	pc := &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: creds.clientSecret,
+		Endpoint: oauth2.Endpoint{
+			TokenURL:  tokenURL,
+			AuthStyle: oauth2.AuthStyleInHeader,
+		},
+	}
+	token, err := pc.PasswordCredentialsToken(ctx, creds.username, creds.password)
+	if err != nil {
+		return nil, fmt.Errorf("could not get UAA token for user %s: %w", creds.username, err)
+	}
+	return pc.Client(ctx, token), nil
+}
+
+func (d *director) GetStemcells() ([]gogobosh.Stemcell, error) {
+	var stemcells []gogobosh.Stemcell
+	err := d.get("/stemcells", &stemcells)
+	if err != nil {
+		return nil, fmt.Errorf("error getting stemcells: %w", err)
+	}
+	return stemcells, nil
+}
+
+func (d *director) GetDeployments() ([]gogobosh.Deployment, error) {
+	var deployments []gogobosh.Deployment
+	err := d.get("/deployments", &deployments)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting deployments: %w", err)
+	}
+	return deployments, nil
+}
+
+func (d *director) GetCloudConfig(latest bool) ([]gogobosh.Cfg, error) {
+	var cfg []gogobosh.Cfg
+	err := d.get("/configs?latest="+strconv.FormatBool(latest), &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error getting cloud config: %w", err)
+	}
+	return cfg, nil
+}
+
+func (d *director) GetOrphanedDisks() ([]OrphanedDisk, error) {
+	var disks []OrphanedDisk
+	err := d.get("/disks", &disks)
+	if err != nil {
+		return nil, fmt.Errorf("error getting orphaned disks: %w", err)
+	}
+	return disks, nil
+}
+
+func (d *director) GetDeploymentVMs(deployment string) ([]gogobosh.VM, error) {
+	// the director redirects to the task which lists the VMs
+	var task gogobosh.Task
+	err := d.get("/deployments/"+url.PathEscape(deployment)+"/vms?format=full", &task)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting deployment %s VMs: %w", deployment, err)
+	}
+
+	err = d.waitForTask(task.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error waiting for deployment %s VM task to complete: %w", deployment, err)
+	}
+
+	output, err := d.taskResult(task.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting deployment %s VMs task result: %w", deployment, err)
+	}
+
+	var vms []gogobosh.VM
+	for _, line := range strings.Split(output, "\n") {
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+		var vm gogobosh.VM
+		err = json.Unmarshal([]byte(line), &vm)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshalling deployment %s VMs response: %w", deployment, err)
+		}
+		vms = append(vms, vm)
+	}
+	return vms, nil
+}
+
+func (d *director) waitForTask(id int) error {
+	deadline := time.Now().Add(taskTimeout)
+	for {
+		var task gogobosh.Task
+		err := d.get("/tasks/"+strconv.Itoa(id), &task)
+		if err != nil {
+			return fmt.Errorf("error getting task %d status: %w", id, err)
+		}
+
+		switch task.State {
+		case "done":
+			return nil
+		case "error", "cancelled", "timeout":
+			return fmt.Errorf("task %d %s: %s", id, task.State, task.Result)
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for task %d", taskTimeout, id)
+		}
+		time.Sleep(taskPollInterval)
+	}
+}
+
+func (d *director) taskResult(id int) (string, error) {
+	res, err := d.do(http.MethodGet, "/tasks/"+strconv.Itoa(id)+"/output?type=result")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading task output response: %w", err)
+	}
+	return string(b), nil
+}
+
+func (d *director) get(path string, obj interface{}) error {
+	res, err := d.do(http.MethodGet, path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	err = json.NewDecoder(res.Body).Decode(obj)
+	if err != nil {
+		return fmt.Errorf("error unmarshalling http response: %w", err)
+	}
+	return nil
+}
+
+func (d *director) do(method, path string) (*http.Response, error) {
+	req, err := http.NewRequest(method, d.address.String()+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making bosh client http request: %w", err)
+	}
+	if res.StatusCode >= 400 {
+		_ = res.Body.Close()
+		return nil, fmt.Errorf("http %s request to %s failed with %s", req.Method, req.URL, res.Status)
+	}
+	return res, nil
+}
+
+// checkRedirect ensures task redirects are sent to the configured director address, which may
+// differ from the director's own idea of its address, e.g. behind a load balancer or proxy
+func (d *director) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > 10 {
+		return fmt.Errorf("stopped after 10 redirects")
+	}
+	req.URL.Scheme = d.address.Scheme
+	req.URL.Host = d.address.Host
+	req.Header.Del("Referer")
+	return nil
+}
+
+type basicAuthTransport struct {
+	username string
+	password string
+	base     http.RoundTripper
+}
+
+func (t *basicAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.SetBasicAuth(t.username, t.password)
+	return t.base.RoundTrip(r)
+}
@@ -0,0 +1,269 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package bosh_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/bosh"
+)
+
+func TestDirectorWithCACertAndBasicAuth(t *testing.T) {
+	srv := newFakeDirector(t, "basic")
+	defer srv.Close()
+
+	c := bosh.New(directorHost(t, srv), "admin", "secret").WithCACert(directorCACert(srv))
+	vms, err := c.VMsAndStemcells(context.Background())
+	require.NoError(t, err)
+	requireFakeDirectorVMs(t, vms)
+}
+
+func TestDirectorWithCACertAndUAA(t *testing.T) {
+	srv := newFakeDirector(t, "uaa")
+	defer srv.Close()
+
+	c := bosh.New(directorHost(t, srv), "admin", "secret").WithCACert(directorCACert(srv))
+	vms, err := c.VMsAndStemcells(context.Background())
+	require.NoError(t, err)
+	requireFakeDirectorVMs(t, vms)
+}
+
+func TestDirectorWithUAAUser(t *testing.T) {
+	srv := newFakeDirector(t, "uaa")
+	defer srv.Close()
+
+	c := bosh.New(directorHost(t, srv), "", "").
+		WithUser("director-admin", "password").
+		WithCACert(directorCACert(srv))
+	vms, err := c.VMsAndStemcells(context.Background())
+	require.NoError(t, err)
+	requireFakeDirectorVMs(t, vms)
+}
+
+func TestDirectorWithInvalidUAAUser(t *testing.T) {
+	srv := newFakeDirector(t, "uaa")
+	defer srv.Close()
+
+	c := bosh.New(directorHost(t, srv), "", "").
+		WithUser("director-admin", "wrong").
+		WithCACert(directorCACert(srv))
+	_, err := c.VMsAndStemcells(context.Background())
+	require.ErrorContains(t, err, "could not get UAA token for user director-admin")
+}
+
+func TestDirectorWithUAAURLOverride(t *testing.T) {
+	srv := newFakeDirector(t, "uaa-internal")
+	defer srv.Close()
+
+	c := bosh.New(directorHost(t, srv), "admin", "secret").
+		WithUAAURL(srv.URL + "/uaa").
+		WithCACert(directorCACert(srv))
+	vms, err := c.VMsAndStemcells(context.Background())
+	require.NoError(t, err)
+	requireFakeDirectorVMs(t, vms)
+}
+
+func TestDirectorWithURL(t *testing.T) {
+	srv := newFakeDirector(t, "basic")
+	defer srv.Close()
+
+	c := bosh.New("", "admin", "secret").WithURL(srv.URL + "/").WithCACert(directorCACert(srv))
+	vms, err := c.VMsAndStemcells(context.Background())
+	require.NoError(t, err)
+	requireFakeDirectorVMs(t, vms)
+}
+
+func TestDirectorWithPort(t *testing.T) {
+	srv := newFakeDirector(t, "basic")
+	defer srv.Close()
+
+	host, port, err := net.SplitHostPort(directorHost(t, srv))
+	require.NoError(t, err)
+	p, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	c := bosh.New(host, "admin", "secret").WithPort(p).WithCACert(directorCACert(srv))
+	vms, err := c.VMsAndStemcells(context.Background())
+	require.NoError(t, err)
+	requireFakeDirectorVMs(t, vms)
+}
+
+func TestDirectorWithUntrustedCert(t *testing.T) {
+	srv := newFakeDirector(t, "basic")
+	defer srv.Close()
+
+	c := bosh.New(directorHost(t, srv), "admin", "secret").WithCACert(selfSignedCACert(t))
+	_, err := c.VMsAndStemcells(context.Background())
+	require.ErrorContains(t, err, "failed to create bosh client")
+	require.ErrorContains(t, err, "certificate")
+}
+
+func TestDirectorWithoutCACertVerifiesCert(t *testing.T) {
+	srv := newFakeDirector(t, "basic")
+	defer srv.Close()
+
+	// the fake director's certificate isn't signed by a system root CA
+	c := bosh.New(directorHost(t, srv), "admin", "secret")
+	_, err := c.VMsAndStemcells(context.Background())
+	require.ErrorContains(t, err, "failed to create bosh client")
+	require.ErrorContains(t, err, "certificate")
+}
+
+func TestDirectorInsecure(t *testing.T) {
+	srv := newFakeDirector(t, "basic")
+	defer srv.Close()
+
+	c := bosh.New(directorHost(t, srv), "admin", "secret").WithInsecure(true)
+	vms, err := c.VMsAndStemcells(context.Background())
+	require.NoError(t, err)
+	requireFakeDirectorVMs(t, vms)
+}
+
+func TestDirectorWithInvalidCACertFile(t *testing.T) {
+	c := bosh.New("10.0.0.5", "admin", "secret").WithCACertFile("./does-not-exist.pem")
+	_, err := c.VMsAndStemcells(context.Background())
+	require.ErrorContains(t, err, "could not load bosh director CA certs")
+}
+
+func requireFakeDirectorVMs(t *testing.T, vms []bosh.VM) {
+	require.Len(t, vms, 3)
+	require.Equal(t, "sc-guid", vms[0].Name)
+	require.Equal(t, "az1", vms[0].AZ)
+	require.Equal(t, "vm-guid1", vms[1].Name)
+	require.Equal(t, "az1", vms[1].AZ)
+	require.Equal(t, "vm-guid2", vms[2].Name)
+	require.Equal(t, "az2", vms[2].AZ)
+}
+
+// newFakeDirector creates a TLS server that behaves enough like a BOSH director to list VMs and stemcells
+// The auth type is either basic, uaa or uaa-internal which advertises an unreachable UAA URL
+func newFakeDirector(t *testing.T, authType string) *httptest.Server {
+	var srv *httptest.Server
+	uaa := authType != "basic"
+	authorized := func(r *http.Request) bool {
+		if uaa {
+			return r.Header.Get("Authorization") == "Bearer uaa-token"
+		}
+		u, p, ok := r.BasicAuth()
+		return ok && u == "admin" && p == "secret"
+	}
+	writeJSON := func(w http.ResponseWriter, r *http.Request, v interface{}) {
+		if !authorized(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(v))
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/info", func(w http.ResponseWriter, r *http.Request) {
+		userAuth := map[string]interface{}{"type": "basic"}
+		switch authType {
+		case "uaa":
+			userAuth = map[string]interface{}{"type": "uaa", "options": map[string]string{"url": srv.URL + "/uaa"}}
+		case "uaa-internal":
+			userAuth = map[string]interface{}{"type": "uaa", "options": map[string]string{"url": "https://uaa.invalid:8443"}}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{"user_authentication": userAuth}))
+	})
+	mux.HandleFunc("/uaa/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		u, p, _ := r.BasicAuth()
+		require.NoError(t, r.ParseForm())
+		var ok bool
+		switch r.PostForm.Get("grant_type") {
+		case "client_credentials":
+			ok = u == "admin" && p == "secret"
+		case "password":
+			ok = u == "bosh_cli" && p == "" &&
+				r.PostForm.Get("username") == "director-admin" && r.PostForm.Get("password") == "password"
+		}
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"uaa-token","token_type":"bearer","expires_in":3600}`))
+	})
+	mux.HandleFunc("/configs", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, r, []map[string]string{
+			{"id": "1", "name": "default", "type": "cloud", "content": cloudConfigYaml},
+		})
+	})
+	mux.HandleFunc("/stemcells", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, r, []map[string]string{
+			{"name": "bosh-vsphere-esxi-ubuntu-jammy-go_agent", "cid": "sc-guid", "cpi": "1e668fac900079c31a44"},
+		})
+	})
+	mux.HandleFunc("/deployments", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, r, []map[string]string{{"name": "cf"}})
+	})
+	mux.HandleFunc("/deployments/cf/vms", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		// the director redirects using its own internal address
+		http.Redirect(w, r, "https://10.0.0.6:25555/tasks/42", http.StatusFound)
+	})
+	mux.HandleFunc("/tasks/42", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, r, map[string]interface{}{"id": 42, "state": "done"})
+	})
+	mux.HandleFunc("/tasks/42/output", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		require.Equal(t, "result", r.URL.Query().Get("type"))
+		_, _ = w.Write([]byte(`{"vm_cid":"vm-guid1","job_name":"router","az":"az1"}` + "\n" +
+			`{"vm_cid":"vm-guid2","job_name":"router","az":"az2"}` + "\n"))
+	})
+
+	srv = httptest.NewTLSServer(mux)
+	return srv
+}
+
+func directorHost(t *testing.T, srv *httptest.Server) string {
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	return u.Host
+}
+
+// selfSignedCACert creates a CA cert unrelated to the fake director's cert
+func selfSignedCACert(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "untrusted CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func directorCACert(srv *httptest.Server) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}))
+}
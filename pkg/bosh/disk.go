@@ -0,0 +1,22 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package bosh
+
+// OrphanedDisk is a persistent disk the director kept after its deployment or instance was deleted
+type OrphanedDisk struct {
+	CID            string `json:"disk_cid"`
+	Size           int    `json:"size"`
+	AZ             string `json:"az"`
+	DeploymentName string `json:"deployment_name"`
+	InstanceName   string `json:"instance_name"`
+	OrphanedAt     string `json:"orphaned_at"`
+}
+
+// Disk is a BOSH managed persistent disk that's not attached to any VM
+type Disk struct {
+	CID string
+	AZ  string
+}
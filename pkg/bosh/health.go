@@ -0,0 +1,67 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package bosh
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+)
+
+// InstanceHealth is the BOSH agent's view of a VM's instance and its processes
+type InstanceHealth struct {
+	Instance         string
+	JobState         string
+	FailingProcesses []string
+}
+
+// Healthy returns true if the agent reports the instance and all of its processes are running
+func (h InstanceHealth) Healthy() bool {
+	return h.JobState == "running" && len(h.FailingProcesses) == 0
+}
+
+// String describes the instance health, e.g. router/guid is failing: gorouter is failing
+func (h InstanceHealth) String() string {
+	s := fmt.Sprintf("%s is %s", h.Instance, h.JobState)
+	if len(h.FailingProcesses) > 0 {
+		s += ": " + strings.Join(h.FailingProcesses, ", ")
+	}
+	return s
+}
+
+// DeploymentHealth returns the health of every instance in the deployment by VM CID, using a single request so
+// many VMs can be checked at once
+func (c *Client) DeploymentHealth(ctx context.Context, deployment string) (map[string]InstanceHealth, error) {
+	l := log.FromContext(ctx)
+
+	client, err := c.getOrCreateUnderlyingClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Debugf("Getting deployment %s VM health", deployment)
+	vms, err := client.GetDeploymentVMs(deployment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployment %s VMs: %w", deployment, err)
+	}
+
+	result := map[string]InstanceHealth{}
+	for _, vm := range vms {
+		h := InstanceHealth{
+			Instance: vm.JobName + "/" + vm.ID,
+			JobState: vm.JobState,
+		}
+		for _, p := range vm.Processes {
+			if p.State != "running" {
+				h.FailingProcesses = append(h.FailingProcesses, p.Name+" is "+p.State)
+			}
+		}
+		result[vm.VMCID] = h
+	}
+	return result, nil
+}
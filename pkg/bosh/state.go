@@ -0,0 +1,180 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package bosh
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// State is a BOSH create-env state file, i.e. Operations Manager's bosh-state.json. Any properties
+// not explicitly modeled are preserved as-is.
+type State struct {
+	raw   map[string]json.RawMessage
+	disks []map[string]json.RawMessage
+}
+
+// StateDisk is a persistent disk entry in the BOSH state file
+type StateDisk struct {
+	ID  string
+	CID string
+}
+
+// NewStateFromFile reads and parses a BOSH state file
+func NewStateFromFile(stateFilePath string) (*State, error) {
+	b, err := os.ReadFile(stateFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read BOSH state file %s: %w", stateFilePath, err)
+	}
+	s, err := NewState(b)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse BOSH state file %s: %w", stateFilePath, err)
+	}
+	return s, nil
+}
+
+// NewState parses the BOSH state file contents
+func NewState(b []byte) (*State, error) {
+	s := &State{}
+	err := json.Unmarshal(b, &s.raw)
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := s.raw["disks"]; ok {
+		err = json.Unmarshal(d, &s.disks)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse disks: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// VMCID returns the director VM's CID, which is the VM's name in vCenter
+func (s *State) VMCID() string {
+	return s.stringValue(s.raw, "current_vm_cid")
+}
+
+// CurrentDisk returns the director's currently attached persistent disk
+func (s *State) CurrentDisk() (*StateDisk, error) {
+	id := s.stringValue(s.raw, "current_disk_id")
+	if id == "" {
+		return nil, fmt.Errorf("could not find current_disk_id in BOSH state")
+	}
+	for _, d := range s.disks {
+		if s.stringValue(d, "id") == id {
+			return &StateDisk{
+				ID:  id,
+				CID: s.stringValue(d, "cid"),
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("could not find disk with id %s in BOSH state", id)
+}
+
+// SetDiskCID updates the CID of the disk with the specified id
+func (s *State) SetDiskCID(id, cid string) error {
+	for _, d := range s.disks {
+		if s.stringValue(d, "id") == id {
+			b, err := json.Marshal(cid)
+			if err != nil {
+				return err
+			}
+			d["cid"] = b
+			return nil
+		}
+	}
+	return fmt.Errorf("could not find disk with id %s in BOSH state", id)
+}
+
+// Marshal returns the indented JSON state file contents
+func (s *State) Marshal() ([]byte, error) {
+	if s.disks != nil {
+		d, err := json.Marshal(s.disks)
+		if err != nil {
+			return nil, err
+		}
+		s.raw["disks"] = d
+	}
+	return json.MarshalIndent(s.raw, "", "    ")
+}
+
+// WriteFile writes the state to the specified file path. The state is written to a temporary file in the same
+// directory which then replaces the file, so an existing state file is never left partially written.
+func (s *State) WriteFile(stateFilePath string) error {
+	b, err := s.Marshal()
+	if err != nil {
+		return err
+	}
+	err = writeFileAtomic(stateFilePath, b)
+	if err != nil {
+		return fmt.Errorf("could not write BOSH state file %s: %w", stateFilePath, err)
+	}
+	return nil
+}
+
+func writeFileAtomic(filePath string, b []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// no-op once renamed
+		_ = os.Remove(f.Name())
+	}()
+
+	_, err = f.Write(b)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filePath)
+}
+
+// DiskCIDWithoutMetadata strips the base64 encoded metadata suffix newer vSphere CPIs append to disk CIDs
+func DiskCIDWithoutMetadata(cid string) string {
+	c, _, _ := strings.Cut(cid, ".")
+	return c
+}
+
+// DiskCIDDatastorePattern returns the target_datastore_pattern regex newer vSphere CPIs encode in the disk CID
+// metadata suffix, or empty if the CID has none
+func DiskCIDDatastorePattern(cid string) string {
+	_, encoded, ok := strings.Cut(cid, ".")
+	if !ok {
+		return ""
+	}
+	encoded = strings.TrimRight(encoded, "=")
+	b, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		b, err = base64.RawStdEncoding.DecodeString(encoded)
+		if err != nil {
+			return ""
+		}
+	}
+	var metadata struct {
+		TargetDatastorePattern string `json:"target_datastore_pattern"`
+	}
+	if json.Unmarshal(b, &metadata) != nil {
+		return ""
+	}
+	return metadata.TargetDatastorePattern
+}
+
+func (s *State) stringValue(m map[string]json.RawMessage, key string) string {
+	var v string
+	if r, ok := m[key]; ok {
+		_ = json.Unmarshal(r, &v)
+	}
+	return v
+}
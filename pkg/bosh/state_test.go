@@ -0,0 +1,97 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package bosh_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/bosh"
+)
+
+func TestStateFromFile(t *testing.T) {
+	s, err := bosh.NewStateFromFile("fixtures/bosh-state.json")
+	require.NoError(t, err)
+	require.Equal(t, "vm-98d6bd0a-6a4e-4f1c-9a6e-b0c2a8d0d0d1", s.VMCID())
+
+	d, err := s.CurrentDisk()
+	require.NoError(t, err)
+	require.Equal(t, &bosh.StateDisk{
+		ID:  "2e4ab2f5-2d3c-4e93-4a44-3d1c7e5b1c8a",
+		CID: "disk-43edf7b2-467b-4913-8142-91b24896b482.eyJ0YXJnZXRfZGF0YXN0b3JlX3BhdHRlcm4iOiJeKE5GU1xcLURhdGFzdG9yZTEpJCJ9",
+	}, d)
+}
+
+func TestStateSetDiskCIDPreservesOtherProperties(t *testing.T) {
+	s, err := bosh.NewStateFromFile("fixtures/bosh-state.json")
+	require.NoError(t, err)
+
+	err = s.SetDiskCID("2e4ab2f5-2d3c-4e93-4a44-3d1c7e5b1c8a", "disk-43edf7b2-467b-4913-8142-91b24896b482")
+	require.NoError(t, err)
+	err = s.SetDiskCID("does-not-exist", "disk-guid")
+	require.EqualError(t, err, "could not find disk with id does-not-exist in BOSH state")
+
+	out := filepath.Join(t.TempDir(), "bosh-state.json")
+	require.NoError(t, s.WriteFile(out))
+
+	b, err := os.ReadFile(out)
+	require.NoError(t, err)
+	var actual map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &actual))
+
+	b, err = os.ReadFile("fixtures/bosh-state.json")
+	require.NoError(t, err)
+	var expected map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &expected))
+	expected["disks"].([]interface{})[0].(map[string]interface{})["cid"] = "disk-43edf7b2-467b-4913-8142-91b24896b482"
+
+	require.Equal(t, expected, actual)
+}
+
+func TestStateWriteFileReplacesExistingFile(t *testing.T) {
+	s, err := bosh.NewState([]byte(`{"current_vm_cid": "vm-guid"}`))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "bosh-state.json")
+	require.NoError(t, os.WriteFile(out, []byte(`{"current_vm_cid": "old-vm-guid", "stale": true}`), 0600))
+	require.NoError(t, s.WriteFile(out))
+
+	b, err := os.ReadFile(out)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"current_vm_cid": "vm-guid"}`, string(b))
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestStateWithoutCurrentDisk(t *testing.T) {
+	s, err := bosh.NewState([]byte(`{"current_vm_cid": "vm-guid"}`))
+	require.NoError(t, err)
+	_, err = s.CurrentDisk()
+	require.EqualError(t, err, "could not find current_disk_id in BOSH state")
+
+	_, err = bosh.NewState([]byte(`garbage`))
+	require.Error(t, err)
+}
+
+func TestDiskCIDWithoutMetadata(t *testing.T) {
+	require.Equal(t, "disk-guid", bosh.DiskCIDWithoutMetadata("disk-guid"))
+	require.Equal(t, "disk-guid", bosh.DiskCIDWithoutMetadata("disk-guid.eyJ0YXJnZXRfZGF0YXN0b3JlX3BhdHRlcm4iOiJEUzEifQ"))
+}
+
+func TestDiskCIDDatastorePattern(t *testing.T) {
+	require.Equal(t, "", bosh.DiskCIDDatastorePattern("disk-guid"))
+	require.Equal(t, "DS1", bosh.DiskCIDDatastorePattern("disk-guid.eyJ0YXJnZXRfZGF0YXN0b3JlX3BhdHRlcm4iOiJEUzEifQ"))
+	require.Equal(t, "DS1", bosh.DiskCIDDatastorePattern("disk-guid.eyJ0YXJnZXRfZGF0YXN0b3JlX3BhdHRlcm4iOiJEUzEifQ=="))
+	require.Equal(t, "", bosh.DiskCIDDatastorePattern("disk-guid.eyJ0YXJnZXQiOiJ4In0"))
+	require.Equal(t, "", bosh.DiskCIDDatastorePattern("disk-guid.not-base64!"))
+}
@@ -8,4 +8,8 @@ package bosh
 type VM struct {
 	Name string
 	AZ   string
+
+	// empty for stemcells
+	Deployment    string
+	InstanceGroup string
 }
@@ -0,0 +1,40 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package certs
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// NewPool creates a cert pool from the PEM encoded CA certificate(s) and/or the CA certificate(s) in the
+// specified PEM file. If neither are specified nil is returned so the host's root CAs are used instead.
+func NewPool(caCert, caCertFile string) (*x509.CertPool, error) {
+	if caCert == "" && caCertFile == "" {
+		return nil, nil
+	}
+
+	pool := x509.NewCertPool()
+	if caCert != "" {
+		if ok := pool.AppendCertsFromPEM([]byte(caCert)); !ok {
+			return nil, fmt.Errorf("could not parse any PEM encoded certificates from the CA cert")
+		}
+	}
+
+	if caCertFile != "" {
+		pem, err := os.ReadFile(filepath.Clean(caCertFile))
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA cert file: %w", err)
+		}
+		if ok := pool.AppendCertsFromPEM(pem); !ok {
+			return nil, fmt.Errorf("could not parse any PEM encoded certificates from CA cert file %s", caCertFile)
+		}
+	}
+
+	return pool, nil
+}
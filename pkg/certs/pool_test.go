@@ -0,0 +1,48 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package certs_test
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/certs"
+)
+
+func TestNewPoolEmpty(t *testing.T) {
+	pool, err := certs.NewPool("", "")
+	require.NoError(t, err)
+	require.Nil(t, pool)
+}
+
+func TestNewPoolFromCertAndFile(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	pool, err := certs.NewPool(string(caCert), "")
+	require.NoError(t, err)
+	require.NotNil(t, pool)
+
+	caCertFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caCertFile, caCert, 0600))
+	pool, err = certs.NewPool("", caCertFile)
+	require.NoError(t, err)
+	require.NotNil(t, pool)
+}
+
+func TestNewPoolInvalid(t *testing.T) {
+	_, err := certs.NewPool("garbage", "")
+	require.ErrorContains(t, err, "could not parse any PEM encoded certificates from the CA cert")
+
+	_, err = certs.NewPool("", "./does-not-exist.pem")
+	require.ErrorContains(t, err, "could not read CA cert file")
+}
@@ -8,10 +8,16 @@ package config
 import (
 	"errors"
 	"fmt"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/certs"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/inventory"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/proxy"
 	"gopkg.in/yaml.v3"
+	"net/url"
 	"os"
+	"regexp"
 	"strings"
+	"time"
 )
 
 func NewConfigFromFile(configFilePath string) (Config, error) {
@@ -37,8 +43,82 @@ func NewConfigFromFile(configFilePath string) (Config, error) {
 
 type Bosh struct {
 	Host         string `yaml:"host"`
+	Port         int    `yaml:"port,omitempty"`
+	URL          string `yaml:"url,omitempty"`
 	ClientID     string `yaml:"client_id"`
 	ClientSecret string `yaml:"client_secret"`
+	Username     string `yaml:"username,omitempty"`
+	Password     string `yaml:"password,omitempty"`
+	UAAURL       string `yaml:"uaa_url,omitempty"`
+	CACert       string `yaml:"ca_cert,omitempty"`
+	CACertFile   string `yaml:"ca_cert_file,omitempty"`
+	Insecure     bool   `yaml:"insecure,omitempty"`
+	DiskPath     string `yaml:"disk_path,omitempty"`
+
+	// the vSphere CPI VM and stemcell folders under each datacenter's VM folder
+	VMFolder       string `yaml:"vm_folder,omitempty"`
+	TemplateFolder string `yaml:"template_folder,omitempty"`
+}
+
+func (b *Bosh) validate() error {
+	if b.Host == "" && b.URL == "" {
+		return errors.New("expected optional bosh config section to have a host or url")
+	}
+	if b.Host != "" && b.URL != "" {
+		return errors.New("expected optional bosh config section to have either a host or url, not both")
+	}
+	if b.URL != "" {
+		if b.Port != 0 {
+			return errors.New("expected optional bosh config section to have either a url or port, not both")
+		}
+		if err := validateHTTPSURL(b.URL); err != nil {
+			return fmt.Errorf("invalid bosh url: %w", err)
+		}
+	}
+	if b.Port < 0 || b.Port > 65535 {
+		return fmt.Errorf("expected bosh port %d to be between 1 and 65535", b.Port)
+	}
+
+	// either a UAA user or a client is required, the client is optional for a UAA user
+	if b.Username != "" || b.Password != "" {
+		if b.Username == "" {
+			return errors.New("expected optional bosh config section to have a username")
+		}
+		if b.Password == "" {
+			return errors.New("expected optional bosh config section to have a password")
+		}
+	} else {
+		if b.ClientID == "" {
+			return errors.New("expected optional bosh config section to have a client_id")
+		}
+		if b.ClientSecret == "" {
+			return errors.New("expected optional bosh config section to have a client_secret")
+		}
+	}
+
+	if b.UAAURL != "" {
+		if err := validateHTTPSURL(b.UAAURL); err != nil {
+			return fmt.Errorf("invalid bosh uaa_url: %w", err)
+		}
+	}
+	if b.Insecure && (b.CACert != "" || b.CACertFile != "") {
+		return errors.New("expected optional bosh config section to be insecure or have a ca_cert or ca_cert_file, not both")
+	}
+	if _, err := certs.NewPool(b.CACert, b.CACertFile); err != nil {
+		return fmt.Errorf("invalid bosh CA cert: %w", err)
+	}
+	return nil
+}
+
+func validateHTTPSURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("expected %s to be an absolute https URL", rawURL)
+	}
+	return nil
 }
 
 type VCenter struct {
@@ -47,6 +127,27 @@ type VCenter struct {
 	Password   string `yaml:"password"`
 	Insecure   bool   `yaml:"insecure"`
 	Datacenter string `yaml:"datacenter"`
+	CACert     string `yaml:"ca_cert,omitempty"`
+	CACertFile string `yaml:"ca_cert_file,omitempty"`
+	Thumbprint string `yaml:"thumbprint,omitempty"`
+}
+
+var thumbprintRegex = regexp.MustCompile(`^([0-9A-Fa-f]{2}:){19}[0-9A-Fa-f]{2}$`)
+
+func (v *VCenter) validateTrust() error {
+	if v == nil {
+		return nil
+	}
+	if v.Insecure && (v.CACert != "" || v.CACertFile != "" || v.Thumbprint != "") {
+		return fmt.Errorf("vcenter %s cannot be insecure and also have a ca_cert, ca_cert_file or thumbprint", v.Host)
+	}
+	if v.Thumbprint != "" && !thumbprintRegex.MatchString(v.Thumbprint) {
+		return fmt.Errorf("expected vcenter %s thumbprint to be a colon separated SHA1 fingerprint", v.Host)
+	}
+	if _, err := certs.NewPool(v.CACert, v.CACertFile); err != nil {
+		return fmt.Errorf("invalid vcenter %s CA cert: %w", v.Host, err)
+	}
+	return nil
 }
 
 type ComputeCluster struct {
@@ -63,6 +164,22 @@ type ComputeAZ struct {
 type Compute struct {
 	Source []ComputeAZ `yaml:"source"`
 	Target []ComputeAZ `yaml:"target"`
+
+	// PlacementStrategy chooses the target cluster when a source cluster maps to multiple target clusters
+	PlacementStrategy string `yaml:"placement_strategy,omitempty"`
+}
+
+func (c *Compute) validatePlacementStrategy() error {
+	if c.PlacementStrategy == "" {
+		return nil
+	}
+	for _, s := range PlacementStrategies {
+		if c.PlacementStrategy == s {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected compute placement_strategy to be one of %s but found %s",
+		strings.Join(PlacementStrategies, ", "), c.PlacementStrategy)
 }
 
 func (c *Compute) TargetByAZ(azName string) *ComputeAZ {
@@ -83,8 +200,38 @@ func (c *Compute) SourceByAZ(azName string) *ComputeAZ {
 	return nil
 }
 
+// TargetVCenter returns the target vCenter all source AZs in the source vCenter datacenter are mapped to,
+// an empty source datacenter matches any datacenter
+func (c *Compute) TargetVCenter(sourceHost, sourceDatacenter string) (*VCenter, error) {
+	var target *VCenter
+	for _, saz := range c.Source {
+		if saz.VCenter == nil || !strings.EqualFold(saz.VCenter.Host, sourceHost) ||
+			(sourceDatacenter != "" && !inventory.Same(saz.VCenter.Datacenter, sourceDatacenter)) {
+			continue
+		}
+		taz := c.TargetByAZ(saz.Name)
+		if taz == nil || taz.VCenter == nil {
+			return nil, fmt.Errorf("could not find a corresponding compute target vcenter for AZ %s", saz.Name)
+		}
+		if target != nil && (target.Host != taz.VCenter.Host || !inventory.Same(target.Datacenter, taz.VCenter.Datacenter)) {
+			return nil, fmt.Errorf("vcenter %s datacenter %s is mapped to more than one target vcenter datacenter",
+				sourceHost, sourceDatacenter)
+		}
+		target = taz.VCenter
+	}
+	if target == nil {
+		return nil, fmt.Errorf("could not find vcenter %s datacenter %s in the compute source section",
+			sourceHost, sourceDatacenter)
+	}
+	return target, nil
+}
+
+// Placeholder marks the values a generated migrate.yml skeleton still needs filled in
+const Placeholder = "TODO"
+
 type Config struct {
-	Bosh *Bosh `yaml:"bosh"`
+	Bosh  *Bosh  `yaml:"bosh"`
+	Proxy string `yaml:"proxy,omitempty"`
 
 	DryRun         bool
 	WorkerPoolSize int `yaml:"worker_pool_size"`
@@ -93,14 +240,82 @@ type Config struct {
 	DatastoreMap map[string]string `yaml:"datastores"`
 	Compute      Compute           `yaml:"compute"`
 
+	NetworkRules     []MappingRule `yaml:"network_rules,omitempty"`
+	NetworkDefault   string        `yaml:"network_default,omitempty"`
+	DatastoreRules   []MappingRule `yaml:"datastore_rules,omitempty"`
+	DatastoreDefault string        `yaml:"datastore_default,omitempty"`
+
+	NetworkMappingMode  string `yaml:"network_mapping_mode,omitempty"`
+	NetworkTargetSwitch string `yaml:"network_target_switch,omitempty"`
+
 	AdditionalVMs map[string][]string `yaml:"additional_vms"`
+	Overrides     []Override          `yaml:"overrides,omitempty"`
+	Waves         []Wave              `yaml:"waves,omitempty"`
+
+	// FolderMap rewrites source VM folder path prefixes to target folder paths, unmapped folders keep their path
+	// under the target datacenter's VM folder
+	FolderMap map[string]string `yaml:"folders,omitempty"`
+
+	// Canaries is the number of VMs per AZ in each wave migrated before pausing for confirmation
+	Canaries          int           `yaml:"canaries,omitempty"`
+	AutoContinueAfter time.Duration `yaml:"auto_continue_after,omitempty"`
+
+	// MaxFailures and MaxFailurePercent stop starting new VM migrations once exceeded, zero is unlimited
+	MaxFailures       int `yaml:"max_failures,omitempty"`
+	MaxFailurePercent int `yaml:"max_failure_percent,omitempty"`
+
+	HealthVerification *HealthVerification `yaml:"health_verification,omitempty"`
+
+	// CleanupSourceFolders deletes the source VM folders emptied by the migration once all VMs are migrated
+	CleanupSourceFolders bool `yaml:"cleanup_source_folders,omitempty"`
+}
+
+// TargetDatastore returns the mapped target datastore name
+func (c Config) TargetDatastore(sourceDatastore string) (string, error) {
+	tds, ok := c.DatastoreNameMap().Target(sourceDatastore)
+	if !ok {
+		return "", fmt.Errorf("could not find a target datastore mapping for %s", sourceDatastore)
+	}
+	return tds, nil
+}
+
+// TargetNetwork returns the mapped target network name, the network may be prefixed with a folder or switch name
+func (c Config) TargetNetwork(sourceNetwork string) (string, error) {
+	networks := c.NetworkNameMap()
+	if tn, ok := networks.Target(sourceNetwork); ok {
+		return tn, nil
+	}
+	i := strings.LastIndex(sourceNetwork, "/")
+	if i >= 0 {
+		if tn, ok := networks.Target(sourceNetwork[i+1:]); ok {
+			return sourceNetwork[:i+1] + tn, nil
+		}
+	}
+	return "", fmt.Errorf("could not find a target network mapping for %s", sourceNetwork)
 }
 
 func (c Config) Reversed() Config {
 	rc := Config{
+		Proxy:          c.Proxy,
 		DryRun:         c.DryRun,
 		WorkerPoolSize: c.WorkerPoolSize,
 		AdditionalVMs:  c.AdditionalVMs,
+		Waves:          c.Waves,
+
+		Canaries:          c.Canaries,
+		AutoContinueAfter: c.AutoContinueAfter,
+		MaxFailures:       c.MaxFailures,
+		MaxFailurePercent: c.MaxFailurePercent,
+
+		HealthVerification:   c.HealthVerification,
+		CleanupSourceFolders: c.CleanupSourceFolders,
+
+		// the identity default is its own inverse, see ReverseMappingRulesErr for the rules
+		NetworkDefault:   c.NetworkDefault,
+		DatastoreDefault: c.DatastoreDefault,
+
+		// the target switch and overrides place VMs on the target vCenter so they don't apply when reverting
+		NetworkMappingMode: c.NetworkMappingMode,
 	}
 
 	rc.NetworkMap = make(map[string]string, len(c.NetworkMap))
@@ -113,15 +328,20 @@ func (c Config) Reversed() Config {
 		rc.DatastoreMap[v] = k
 	}
 
+	if len(c.FolderMap) > 0 {
+		rc.FolderMap = make(map[string]string, len(c.FolderMap))
+		for k, v := range c.FolderMap {
+			rc.FolderMap[v] = k
+		}
+	}
+
 	rc.Compute.Source = c.Compute.Target
 	rc.Compute.Target = c.Compute.Source
+	rc.Compute.PlacementStrategy = c.Compute.PlacementStrategy
 
 	if c.Bosh != nil {
-		rc.Bosh = &Bosh{
-			Host:         c.Bosh.Host,
-			ClientID:     c.Bosh.ClientID,
-			ClientSecret: c.Bosh.ClientSecret,
-		}
+		b := *c.Bosh
+		rc.Bosh = &b
 	}
 
 	return rc
@@ -145,14 +365,24 @@ func (c Config) Validate() error {
 
 	// if bosh section exists, make sure all the details have been provided
 	if c.Bosh != nil {
-		if c.Bosh.ClientID == "" {
-			return errors.New("expected optional bosh config section to have a client_id")
+		if err := c.Bosh.validate(); err != nil {
+			return err
 		}
-		if c.Bosh.ClientSecret == "" {
-			return errors.New("expected optional bosh config section to have a client_secret")
+	}
+
+	if _, err := proxy.NewDialer(c.Proxy); err != nil {
+		return err
+	}
+
+	// check each vcenter's TLS trust settings
+	for _, az := range c.Compute.Source {
+		if err := az.VCenter.validateTrust(); err != nil {
+			return err
 		}
-		if c.Bosh.Host == "" {
-			return errors.New("expected optional bosh config section to have a host")
+	}
+	for _, az := range c.Compute.Target {
+		if err := az.VCenter.validateTrust(); err != nil {
+			return err
 		}
 	}
 
@@ -181,6 +411,48 @@ func (c Config) Validate() error {
 		}
 	}
 
+	if err := c.validateMappingRules(); err != nil {
+		return err
+	}
+
+	if err := c.validateOverrides(); err != nil {
+		return err
+	}
+
+	if err := c.validateFolderMap(); err != nil {
+		return err
+	}
+
+	if c.Canaries < 0 {
+		return errors.New("expected canaries >= 0")
+	}
+	if c.AutoContinueAfter < 0 {
+		return errors.New("expected auto_continue_after >= 0")
+	}
+
+	if c.MaxFailures < 0 {
+		return errors.New("expected max_failures >= 0")
+	}
+	if c.MaxFailurePercent < 0 || c.MaxFailurePercent > 100 {
+		return errors.New("expected max_failure_percent between 0 and 100")
+	}
+
+	if err := c.validateHealthVerification(); err != nil {
+		return err
+	}
+
+	if err := c.validateWaves(); err != nil {
+		return err
+	}
+
+	if err := c.Compute.validatePlacementStrategy(); err != nil {
+		return err
+	}
+
+	if p := c.placeholders(); len(p) > 0 {
+		return fmt.Errorf("found %s placeholders that must be replaced: %s", Placeholder, strings.Join(p, ", "))
+	}
+
 	// check that each source AZ has at least one cluster
 	for _, az := range c.Compute.Source {
 		if len(az.Clusters) == 0 {
@@ -195,5 +467,46 @@ func (c Config) Validate() error {
 		}
 	}
 
+	// check each cluster is a name or an inventory path under a datacenter's host folder
+	for _, az := range append(append([]ComputeAZ{}, c.Compute.Source...), c.Compute.Target...) {
+		for _, cl := range az.Clusters {
+			if !inventory.IsClusterPath(cl.Name) {
+				return fmt.Errorf("expected AZ %s cluster %s to be a name or an inventory path like "+
+					"/datacenter/host/folder/cluster", az.Name, cl.Name)
+			}
+		}
+	}
+
 	return nil
 }
+
+// placeholders returns the config paths of any values left as a Placeholder
+func (c Config) placeholders() []string {
+	var result []string
+	for _, k := range sortedKeys(c.NetworkMap) {
+		if c.NetworkMap[k] == Placeholder {
+			result = append(result, "networks."+k)
+		}
+	}
+	for _, k := range sortedKeys(c.DatastoreMap) {
+		if c.DatastoreMap[k] == Placeholder {
+			result = append(result, "datastores."+k)
+		}
+	}
+	for i, azs := range [][]ComputeAZ{c.Compute.Source, c.Compute.Target} {
+		section := []string{"source", "target"}[i]
+		for _, az := range azs {
+			prefix := fmt.Sprintf("compute.%s.%s", section, az.Name)
+			if az.VCenter != nil && (az.VCenter.Host == Placeholder || az.VCenter.Username == Placeholder ||
+				az.VCenter.Datacenter == Placeholder) {
+				result = append(result, prefix+".vcenter")
+			}
+			for i, cl := range az.Clusters {
+				if cl.Name == Placeholder || cl.ResourcePool == Placeholder {
+					result = append(result, fmt.Sprintf("%s.clusters[%d]", prefix, i))
+				}
+			}
+		}
+	}
+	return result
+}
@@ -9,9 +9,11 @@ import (
 	"errors"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/inventory"
 )
 
 func runWithEnvVars(fn func()) {
@@ -385,6 +387,43 @@ func TestComputeByAZ(t *testing.T) {
 	})
 }
 
+func TestTargetMappings(t *testing.T) {
+	runWithEnvVars(func() {
+		c, err := config.NewConfigFromFile("./fixtures/config.yml")
+		require.NoError(t, err)
+
+		vc, err := c.Compute.TargetVCenter("sc3-m01-vc01.plat-svcs.pez.vmware.com", "Datacenter1")
+		require.NoError(t, err)
+		require.Equal(t, "sc3-m01-vc02.plat-svcs.pez.vmware.com", vc.Host)
+		require.Equal(t, "Datacenter2", vc.Datacenter)
+		_, err = c.Compute.TargetVCenter("vc-nope", "Datacenter1")
+		require.EqualError(t, err, "could not find vcenter vc-nope datacenter Datacenter1 in the compute source section")
+
+		// the source datacenter may be nested in folders
+		for i := range c.Compute.Source {
+			c.Compute.Source[i].VCenter.Datacenter = "/Region1/Datacenter1"
+		}
+		vc, err = c.Compute.TargetVCenter("sc3-m01-vc01.plat-svcs.pez.vmware.com", "Datacenter1")
+		require.NoError(t, err)
+		require.Equal(t, "Datacenter2", vc.Datacenter)
+
+		ds, err := c.TargetDatastore("ds1")
+		require.NoError(t, err)
+		require.Equal(t, "ssd-ds1", ds)
+		_, err = c.TargetDatastore("ds-nope")
+		require.EqualError(t, err, "could not find a target datastore mapping for ds-nope")
+
+		n, err := c.TargetNetwork("PAS-Deployment")
+		require.NoError(t, err)
+		require.Equal(t, "TAS", n)
+		n, err = c.TargetNetwork("DSwitch/PAS-Services")
+		require.NoError(t, err)
+		require.Equal(t, "DSwitch/Services", n)
+		_, err = c.TargetNetwork("net-nope")
+		require.EqualError(t, err, "could not find a target network mapping for net-nope")
+	})
+}
+
 func TestConfigNoBosh(t *testing.T) {
 	c, err := config.NewConfigFromFile("./fixtures/config-no-bosh.yml")
 	require.NoError(t, err)
@@ -394,6 +433,87 @@ func TestConfigNoBosh(t *testing.T) {
 	require.Nil(t, c.Bosh)
 }
 
+func TestConfigTLS(t *testing.T) {
+	c, err := config.NewConfigFromFile("./fixtures/config-tls.yml")
+	require.NoError(t, err)
+	require.Equal(t, "./fixtures/ca.pem", c.Bosh.CACertFile)
+	require.Equal(t, "./fixtures/ca.pem", c.Compute.Source[0].VCenter.CACertFile)
+	require.False(t, c.Compute.Source[0].VCenter.Insecure)
+	require.Equal(t, "vc02.example.com:8443", c.Compute.Target[0].VCenter.Host)
+	require.Equal(t, "0A:1B:2C:3D:4E:5F:60:71:82:93:A4:B5:C6:D7:E8:F9:0A:1B:2C:3D",
+		c.Compute.Target[0].VCenter.Thumbprint)
+
+	rc := c.Reversed()
+	require.Equal(t, "./fixtures/ca.pem", rc.Bosh.CACertFile)
+}
+
+func TestConfigReversedFolderMap(t *testing.T) {
+	c := config.Config{
+		FolderMap: map[string]string{"/DC1/vm/pcf_vms": "/DC2/vm/foundations/prod-tas"},
+	}
+	require.Equal(t, map[string]string{"/DC2/vm/foundations/prod-tas": "/DC1/vm/pcf_vms"}, c.Reversed().FolderMap)
+	require.Nil(t, config.Config{}.Reversed().FolderMap)
+}
+
+func TestConfigTLSInvalidCACert(t *testing.T) {
+	runWithEnvVars(func() {
+		c, err := config.NewConfigFromFile("./fixtures/config.yml")
+		require.NoError(t, err)
+
+		c.Bosh.CACertFile = "./fixtures/doesnotexist.pem"
+		require.ErrorContains(t, c.Validate(), "invalid bosh CA cert: could not read CA cert file")
+
+		c.Bosh.CACertFile = ""
+		c.Bosh.CACert = "not a PEM cert"
+		require.ErrorContains(t, c.Validate(), "invalid bosh CA cert: could not parse any PEM encoded certificates")
+
+		c.Bosh.CACert = ""
+		c.Bosh.CACertFile = "./fixtures/ca.pem"
+		c.Bosh.Insecure = true
+		require.ErrorContains(t, c.Validate(), "expected optional bosh config section to be insecure or have a ca_cert or ca_cert_file, not both")
+
+		c.Bosh.CACertFile = ""
+		require.NoError(t, c.Validate())
+		c.Bosh.Insecure = false
+		c.Compute.Target[0].VCenter.Insecure = false
+		c.Compute.Target[0].VCenter.CACertFile = "./fixtures/bogus.yml"
+		require.ErrorContains(t, c.Validate(), "invalid vcenter sc3-m01-vc02.plat-svcs.pez.vmware.com CA cert")
+	})
+}
+
+func TestConfigBoshUAAUser(t *testing.T) {
+	c, err := config.NewConfigFromFile("./fixtures/config-bosh-uaa-user.yml")
+	require.NoError(t, err)
+	require.Equal(t, "https://bosh.example.com", c.Bosh.URL)
+	require.Equal(t, "admin", c.Bosh.Username)
+	require.Equal(t, "boshPassword", c.Bosh.Password)
+	require.Equal(t, "https://uaa.example.com:8443", c.Bosh.UAAURL)
+	require.Empty(t, c.Bosh.ClientID)
+
+	rc := c.Reversed()
+	require.Equal(t, c.Bosh, rc.Bosh)
+}
+
+func TestConfigBoshInvalidURLs(t *testing.T) {
+	runWithEnvVars(func() {
+		c, err := config.NewConfigFromFile("./fixtures/config.yml")
+		require.NoError(t, err)
+
+		c.Bosh.Host = ""
+		c.Bosh.URL = "bosh.example.com:25555"
+		require.ErrorContains(t, c.Validate(), "invalid bosh url")
+
+		c.Bosh.URL = "http://bosh.example.com:25555"
+		require.EqualError(t, c.Validate(),
+			"invalid bosh url: expected http://bosh.example.com:25555 to be an absolute https URL")
+
+		c.Bosh.URL = "https://bosh.example.com:25555"
+		c.Bosh.UAAURL = "uaa.example.com"
+		require.EqualError(t, c.Validate(),
+			"invalid bosh uaa_url: expected uaa.example.com to be an absolute https URL")
+	})
+}
+
 func TestConfigFromMarshalledFile(t *testing.T) {
 	runWithEnvVars(func() {
 		c, err := config.NewConfigFromFile("./fixtures/config.yml")
@@ -422,6 +542,268 @@ type configValidateTest struct {
 }
 
 var configValidateTests = []configValidateTest{
+	{
+		name: "cluster path outside the host folder",
+		setupFn: func(c *config.Config) {
+			c.Compute.Target[0].Clusters[0].Name = "/Datacenter2/vm/cf3"
+		},
+		expectedErr: errors.New("expected AZ az1 cluster /Datacenter2/vm/cf3 to be a name or an inventory path like /datacenter/host/folder/cluster"),
+	},
+	{
+		name: "cluster inventory paths",
+		setupFn: func(c *config.Config) {
+			c.Compute.Source[0].Clusters[0].Name = "Folder1/cf1"
+			c.Compute.Target[0].Clusters[0].Name = "/Region1/Datacenter2/host/Folder1/cf3"
+		},
+		expectedErr: nil,
+	},
+	{
+		name: "relative folder mapping",
+		setupFn: func(c *config.Config) {
+			c.FolderMap = map[string]string{"DC1/vm/pcf_vms": "/DC2/vm/pcf_vms"}
+		},
+		expectedErr: errors.New("expected folder mapping DC1/vm/pcf_vms to be an absolute VM folder path like /datacenter/vm/folder"),
+	},
+	{
+		name: "folder mapping target outside the VM folder",
+		setupFn: func(c *config.Config) {
+			c.FolderMap = map[string]string{"/DC1/vm/pcf_vms": "/DC2/host/pcf_vms"}
+		},
+		expectedErr: errors.New("expected folder mapping /DC1/vm/pcf_vms target /DC2/host/pcf_vms to be an absolute VM folder path like /datacenter/vm/folder"),
+	},
+	{
+		name: "folder mapping with nested datacenter",
+		setupFn: func(c *config.Config) {
+			c.FolderMap = map[string]string{"/Datacenter1/vm/pcf_vms": "/Region1/Datacenter2/vm/foundations/prod-tas"}
+		},
+		expectedErr: nil,
+	},
+	{
+		name: "folder mapping target outside the AZ target datacenter",
+		setupFn: func(c *config.Config) {
+			c.FolderMap = map[string]string{"/Datacenter1/vm/pcf_vms": "/Datacenter3/vm/foundations/prod-tas"}
+		},
+		expectedErr: errors.New("expected folder mapping /Datacenter1/vm/pcf_vms target /Datacenter3/vm/foundations/prod-tas to be in AZ az1 target datacenter Datacenter2"),
+	},
+	{
+		name: "health verification without bosh",
+		setupFn: func(c *config.Config) {
+			c.Bosh = nil
+			c.HealthVerification = &config.HealthVerification{}
+		},
+		expectedErr: errors.New("expected a bosh section to use health_verification"),
+	},
+	{
+		name: "health verification negative timeout",
+		setupFn: func(c *config.Config) {
+			c.HealthVerification = &config.HealthVerification{Timeout: -time.Second}
+		},
+		expectedErr: errors.New("expected health_verification timeout and poll_interval >= 0"),
+	},
+	{
+		name: "health verification unknown on_failure",
+		setupFn: func(c *config.Config) {
+			c.HealthVerification = &config.HealthVerification{OnFailure: "ignore"}
+		},
+		expectedErr: errors.New("expected health_verification on_failure to be fail or flag but found ignore"),
+	},
+	{
+		name: "health verification",
+		setupFn: func(c *config.Config) {
+			c.HealthVerification = &config.HealthVerification{Timeout: 5 * time.Minute, OnFailure: "flag"}
+		},
+		expectedErr: nil,
+	},
+	{
+		name: "negative max failures",
+		setupFn: func(c *config.Config) {
+			c.MaxFailures = -1
+		},
+		expectedErr: errors.New("expected max_failures >= 0"),
+	},
+	{
+		name: "max failure percent over 100",
+		setupFn: func(c *config.Config) {
+			c.MaxFailurePercent = 101
+		},
+		expectedErr: errors.New("expected max_failure_percent between 0 and 100"),
+	},
+	{
+		name: "max failures",
+		setupFn: func(c *config.Config) {
+			c.MaxFailures = 5
+			c.MaxFailurePercent = 10
+		},
+		expectedErr: nil,
+	},
+	{
+		name: "negative canaries",
+		setupFn: func(c *config.Config) {
+			c.Canaries = -1
+		},
+		expectedErr: errors.New("expected canaries >= 0"),
+	},
+	{
+		name: "negative auto continue",
+		setupFn: func(c *config.Config) {
+			c.AutoContinueAfter = -time.Minute
+		},
+		expectedErr: errors.New("expected auto_continue_after >= 0"),
+	},
+	{
+		name: "negative wave canaries",
+		setupFn: func(c *config.Config) {
+			c.Waves = []config.Wave{{Name: "cf", Deployments: []string{"cf-*"}, Canaries: -1}}
+		},
+		expectedErr: errors.New("expected wave cf canaries >= 0"),
+	},
+	{
+		name: "wave without name",
+		setupFn: func(c *config.Config) {
+			c.Waves = []config.Wave{{Stemcells: true}}
+		},
+		expectedErr: errors.New("expected wave to have a name"),
+	},
+	{
+		name: "wave without selector",
+		setupFn: func(c *config.Config) {
+			c.Waves = []config.Wave{{Name: "services", HealthCheck: true}}
+		},
+		expectedErr: errors.New("expected wave services to select stemcells, deployments, instance_groups, " +
+			"vms or additional_vms"),
+	},
+	{
+		name: "duplicate wave names",
+		setupFn: func(c *config.Config) {
+			c.Waves = []config.Wave{
+				{Name: "services", Deployments: []string{"p-mysql-*"}},
+				{Name: "services", Deployments: []string{"p-rabbitmq-*"}},
+			}
+		},
+		expectedErr: errors.New("found more than one wave named services"),
+	},
+	{
+		name: "wave health check without bosh",
+		setupFn: func(c *config.Config) {
+			c.Bosh = nil
+			c.Waves = []config.Wave{{Name: "cf", Deployments: []string{"cf-*"}, HealthCheck: true}}
+		},
+		expectedErr: errors.New("expected a bosh section to health check wave cf"),
+	},
+	{
+		name: "waves",
+		setupFn: func(c *config.Config) {
+			c.Waves = []config.Wave{
+				{Name: "stemcells", Stemcells: true},
+				{Name: "cf", Deployments: []string{"cf-*"}, InstanceGroups: []string{"router"}, HealthCheck: true},
+				{Name: "opsman", VMs: []string{"ops-manager*"}, AdditionalVMs: true},
+			}
+		},
+		expectedErr: nil,
+	},
+	{
+		name: "unknown placement strategy",
+		setupFn: func(c *config.Config) {
+			c.Compute.PlacementStrategy = "random"
+		},
+		expectedErr: errors.New("expected compute placement_strategy to be one of round-robin, least-loaded, " +
+			"preserve-cluster-index, spread-instance-group but found random"),
+	},
+	{
+		name: "least-loaded placement strategy",
+		setupFn: func(c *config.Config) {
+			c.Compute.PlacementStrategy = "least-loaded"
+		},
+		expectedErr: nil,
+	},
+	{
+		name: "override without selector",
+		setupFn: func(c *config.Config) {
+			c.Overrides = []config.Override{{Datastore: "all-flash"}}
+		},
+		expectedErr: errors.New("expected override to have a vm, deployment or instance_group"),
+	},
+	{
+		name: "override without placement",
+		setupFn: func(c *config.Config) {
+			c.Overrides = []config.Override{{Deployment: "mysql-*", InstanceGroup: "mysql"}}
+		},
+		expectedErr: errors.New("expected override mysql-*/mysql to have a cluster, resource_pool, folder, " +
+			"datastore or network"),
+	},
+	{
+		name: "override with unknown cluster",
+		setupFn: func(c *config.Config) {
+			c.Overrides = []config.Override{{VM: "vm-1", Cluster: "missing"}}
+		},
+		expectedErr: errors.New("expected override vm-1 cluster missing to be one of the compute target clusters"),
+	},
+	{
+		name: "override",
+		setupFn: func(c *config.Config) {
+			c.Overrides = []config.Override{{InstanceGroup: "mysql", Datastore: "all-flash"}}
+		},
+		expectedErr: nil,
+	},
+	{
+		name: "override cluster name with target cluster path",
+		setupFn: func(c *config.Config) {
+			c.Compute.Target[0].Clusters[0].Name = "/DC1/host/Folder1/" + c.Compute.Target[0].Clusters[0].Name
+			c.Overrides = []config.Override{{VM: "vm-1", Cluster: inventory.Name(c.Compute.Target[0].Clusters[0].Name)}}
+		},
+		expectedErr: nil,
+	},
+	{
+		name: "unknown network mapping mode",
+		setupFn: func(c *config.Config) {
+			c.NetworkMappingMode = "vlan"
+		},
+		expectedErr: errors.New("expected network_mapping_mode to be name or id but found vlan"),
+	},
+	{
+		name: "network target switch without id network mapping mode",
+		setupFn: func(c *config.Config) {
+			c.NetworkTargetSwitch = "DVS1"
+		},
+		expectedErr: errors.New("expected network_target_switch to only be set with network_mapping_mode id"),
+	},
+	{
+		name: "network default with id network mapping mode",
+		setupFn: func(c *config.Config) {
+			c.NetworkMappingMode = config.NetworkMappingModeID
+			c.NetworkDefault = config.IdentityDefault
+		},
+		expectedErr: errors.New("expected network_default to not be set with network_mapping_mode id"),
+	},
+	{
+		name: "id network mapping mode with target switch",
+		setupFn: func(c *config.Config) {
+			c.NetworkMappingMode = config.NetworkMappingModeID
+			c.NetworkTargetSwitch = "DVS1"
+		},
+		expectedErr: nil,
+	},
+	{
+		name: "network rule with pattern and glob",
+		setupFn: func(c *config.Config) {
+			c.NetworkRules = []config.MappingRule{{Pattern: "^a$", Glob: "a", Target: "b"}}
+		},
+		expectedErr: errors.New("expected network rule to have either a pattern or a glob"),
+	},
+	{
+		name: "datastore rule without target",
+		setupFn: func(c *config.Config) {
+			c.DatastoreRules = []config.MappingRule{{Glob: "ds-*"}}
+		},
+		expectedErr: errors.New("expected datastore rule ds-* to have a target"),
+	},
+	{
+		name: "unknown network default",
+		setupFn: func(c *config.Config) {
+			c.NetworkDefault = "same"
+		},
+		expectedErr: errors.New("expected network_default and datastore_default to be identity but found same"),
+	},
 	{
 		name: "zero workers",
 		setupFn: func(c *config.Config) {
@@ -455,7 +837,90 @@ var configValidateTests = []configValidateTest{
 		setupFn: func(c *config.Config) {
 			c.Bosh.Host = ""
 		},
-		expectedErr: errors.New("expected optional bosh config section to have a host"),
+		expectedErr: errors.New("expected optional bosh config section to have a host or url"),
+	},
+	{
+		name: "bosh host and url",
+		setupFn: func(c *config.Config) {
+			c.Bosh.URL = "https://bosh.example.com"
+		},
+		expectedErr: errors.New("expected optional bosh config section to have either a host or url, not both"),
+	},
+	{
+		name: "bosh url and port",
+		setupFn: func(c *config.Config) {
+			c.Bosh.Host = ""
+			c.Bosh.URL = "https://bosh.example.com"
+			c.Bosh.Port = 443
+		},
+		expectedErr: errors.New("expected optional bosh config section to have either a url or port, not both"),
+	},
+	{
+		name: "invalid bosh port",
+		setupFn: func(c *config.Config) {
+			c.Bosh.Port = 70000
+		},
+		expectedErr: errors.New("expected bosh port 70000 to be between 1 and 65535"),
+	},
+	{
+		name: "bosh username without password",
+		setupFn: func(c *config.Config) {
+			c.Bosh.Username = "admin"
+		},
+		expectedErr: errors.New("expected optional bosh config section to have a password"),
+	},
+	{
+		name: "bosh password without username",
+		setupFn: func(c *config.Config) {
+			c.Bosh.Password = "secret"
+		},
+		expectedErr: errors.New("expected optional bosh config section to have a username"),
+	},
+	{
+		name: "bosh username and password without client",
+		setupFn: func(c *config.Config) {
+			c.Bosh.ClientID = ""
+			c.Bosh.ClientSecret = ""
+			c.Bosh.Username = "admin"
+			c.Bosh.Password = "secret"
+		},
+		expectedErr: nil,
+	},
+	{
+		name: "insecure vcenter with a thumbprint",
+		setupFn: func(c *config.Config) {
+			c.Compute.Source[0].VCenter.Thumbprint = "0A:1B:2C:3D:4E:5F:60:71:82:93:A4:B5:C6:D7:E8:F9:0A:1B:2C:3D"
+		},
+		expectedErr: errors.New("vcenter sc3-m01-vc01.plat-svcs.pez.vmware.com cannot be insecure and also have a ca_cert, ca_cert_file or thumbprint"),
+	},
+	{
+		name: "invalid vcenter thumbprint",
+		setupFn: func(c *config.Config) {
+			c.Compute.Source[0].VCenter.Insecure = false
+			c.Compute.Source[0].VCenter.Thumbprint = "0A1B2C3D"
+		},
+		expectedErr: errors.New("expected vcenter sc3-m01-vc01.plat-svcs.pez.vmware.com thumbprint to be a colon separated SHA1 fingerprint"),
+	},
+	{
+		name: "unsupported proxy scheme",
+		setupFn: func(c *config.Config) {
+			c.Proxy = "ftp://proxy.example.com"
+		},
+		expectedErr: errors.New("unsupported proxy scheme ftp, expected http, https, socks5, socks5h or ssh+socks5"),
+	},
+	{
+		name: "ssh proxy without private key",
+		setupFn: func(c *config.Config) {
+			c.Proxy = "ssh+socks5://ubuntu@opsman.example.com:22"
+		},
+		expectedErr: errors.New("expected ssh+socks5 proxy URL ssh+socks5://ubuntu@opsman.example.com:22 to have a private-key query parameter"),
+	},
+	{
+		name: "ssh proxy without known hosts",
+		setupFn: func(c *config.Config) {
+			c.Proxy = "ssh+socks5://ubuntu@opsman.example.com:22?private-key=/tmp/opsman.pem"
+		},
+		expectedErr: errors.New("expected ssh+socks5 proxy URL ssh+socks5://ubuntu@opsman.example.com:22?private-key=/tmp/opsman.pem to have a known-hosts query parameter, or insecure-ignore-host-key=true to skip host key verification"),
 	},
 	{
 		name: "missing additional_vms AZ in compute section",
@@ -524,6 +989,17 @@ var configValidateTests = []configValidateTest{
 		},
 		expectedErr: errors.New("target AZ az1 cluster(s) must be >= 1"),
 	},
+	{
+		name: "TODO placeholders",
+		setupFn: func(c *config.Config) {
+			c.NetworkMap["PAS-Services"] = "TODO"
+			c.DatastoreMap["ds2"] = "TODO"
+			c.Compute.Target[1].Clusters = []config.ComputeCluster{{Name: "tanzu-2", ResourcePool: "TODO"}}
+			c.Compute.Target[2].VCenter = &config.VCenter{Host: "TODO", Datacenter: "TODO"}
+		},
+		expectedErr: errors.New("found TODO placeholders that must be replaced: networks.PAS-Services, " +
+			"datastores.ds2, compute.target.az2.clusters[0], compute.target.az3.vcenter"),
+	},
 }
 
 func TestValidateConfig(t *testing.T) {
@@ -0,0 +1,59 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/inventory"
+)
+
+// isVMFolderPath returns true if the path is an absolute inventory path under a datacenter's VM folder, the
+// datacenter may be nested in folders, e.g. /Region1/DC1/vm/pcf_vms
+func isVMFolderPath(folderPath string) bool {
+	return strings.HasPrefix(folderPath, "/") && vmFolderDatacenter(folderPath) != ""
+}
+
+// vmFolderDatacenter returns the datacenter path of a VM folder path, e.g. Region1/DC1 for /Region1/DC1/vm/pcf_vms
+func vmFolderDatacenter(folderPath string) string {
+	p := strings.Split(strings.Trim(folderPath, "/"), "/")
+	for i := 1; i < len(p); i++ {
+		if p[i] == "vm" {
+			return strings.Join(p[:i], "/")
+		}
+	}
+	return ""
+}
+
+func (c Config) validateFolderMap() error {
+	for _, src := range sortedKeys(c.FolderMap) {
+		target := c.FolderMap[src]
+		if !isVMFolderPath(src) {
+			return fmt.Errorf("expected folder mapping %s to be an absolute VM folder path like /datacenter/vm/folder",
+				src)
+		}
+		if !isVMFolderPath(target) {
+			return fmt.Errorf("expected folder mapping %s target %s to be an absolute VM folder path like "+
+				"/datacenter/vm/folder", src, target)
+		}
+
+		// VMs are placed in the target datacenter of their AZ, so the mapped folder must be in that datacenter
+		srcDC := vmFolderDatacenter(src)
+		targetDC := vmFolderDatacenter(target)
+		for _, saz := range c.Compute.Source {
+			taz := c.Compute.TargetByAZ(saz.Name)
+			if saz.VCenter == nil || taz == nil || taz.VCenter == nil || !inventory.Same(saz.VCenter.Datacenter, srcDC) {
+				continue
+			}
+			if !inventory.Same(taz.VCenter.Datacenter, targetDC) {
+				return fmt.Errorf("expected folder mapping %s target %s to be in AZ %s target datacenter %s",
+					src, target, saz.Name, taz.VCenter.Datacenter)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// HealthOnFailureFail fails the migration of a VM that BOSH doesn't report healthy in time, the default
+	HealthOnFailureFail = "fail"
+	// HealthOnFailureFlag flags a VM that BOSH doesn't report healthy in time in the report but counts it as migrated
+	HealthOnFailureFlag = "flag"
+)
+
+// HealthVerification waits after each BOSH VM is migrated until BOSH reports its instance and processes are running
+type HealthVerification struct {
+	Timeout      time.Duration `yaml:"timeout,omitempty"`
+	PollInterval time.Duration `yaml:"poll_interval,omitempty"`
+	OnFailure    string        `yaml:"on_failure,omitempty"`
+}
+
+// FlagOnly returns true if unhealthy VMs are flagged instead of failed
+func (h *HealthVerification) FlagOnly() bool {
+	return h.OnFailure == HealthOnFailureFlag
+}
+
+func (c Config) validateHealthVerification() error {
+	h := c.HealthVerification
+	if h == nil {
+		return nil
+	}
+	if c.Bosh == nil {
+		return errors.New("expected a bosh section to use health_verification")
+	}
+	if h.Timeout < 0 || h.PollInterval < 0 {
+		return errors.New("expected health_verification timeout and poll_interval >= 0")
+	}
+	if h.OnFailure != "" && h.OnFailure != HealthOnFailureFail && h.OnFailure != HealthOnFailureFlag {
+		return fmt.Errorf("expected health_verification on_failure to be %s or %s but found %s",
+			HealthOnFailureFail, HealthOnFailureFlag, h.OnFailure)
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// IdentityDefault maps any source name without a matching mapping or rule to the same target name
+const IdentityDefault = "identity"
+
+const (
+	// NetworkMappingModeName maps networks by name using the networks, network_rules and network_default sections
+	NetworkMappingModeName = "name"
+	// NetworkMappingModeID maps each network to the target network with the same VLAN ID or NSX segment,
+	// any networks listed in the networks section take precedence
+	NetworkMappingModeID = "id"
+)
+
+// MappingRule maps every source name matching a regex or glob pattern to a target, the target can
+// reference the pattern's capture groups as $1 or ${1}. Each glob wildcard is a capture group.
+type MappingRule struct {
+	Pattern string `yaml:"pattern,omitempty"`
+	Glob    string `yaml:"glob,omitempty"`
+	Target  string `yaml:"target"`
+}
+
+// Regexp returns the rule's compiled pattern, globs are converted to an anchored regex
+func (r MappingRule) Regexp() (*regexp.Regexp, error) {
+	if r.Glob != "" {
+		return regexp.Compile(globToRegex(r.Glob))
+	}
+	return regexp.Compile(r.Pattern)
+}
+
+func (r MappingRule) String() string {
+	if r.Glob != "" {
+		return fmt.Sprintf("glob %s -> %s", r.Glob, r.Target)
+	}
+	return fmt.Sprintf("pattern %s -> %s", r.Pattern, r.Target)
+}
+
+func (r MappingRule) validate(kind string) error {
+	if (r.Pattern == "") == (r.Glob == "") {
+		return fmt.Errorf("expected %s rule to have either a pattern or a glob", kind)
+	}
+	if r.Target == "" {
+		return fmt.Errorf("expected %s rule %s%s to have a target", kind, r.Pattern, r.Glob)
+	}
+	if _, err := r.Regexp(); err != nil {
+		return fmt.Errorf("invalid %s rule %s: %w", kind, r, err)
+	}
+	return nil
+}
+
+// NameMap maps source names to target names. Exact name mappings take precedence, then the rules in the
+// order they're declared, first match wins, then the optional identity default.
+type NameMap struct {
+	Kind     string
+	Exact    map[string]string
+	Rules    []MappingRule
+	Identity bool
+}
+
+// Target returns the target name for the source name
+func (m NameMap) Target(sourceName string) (string, bool) {
+	if t, ok := m.Exact[sourceName]; ok {
+		return t, true
+	}
+	for _, r := range m.Rules {
+		re, err := r.Regexp()
+		if err != nil {
+			continue
+		}
+		if match := re.FindStringSubmatchIndex(sourceName); match != nil {
+			return string(re.ExpandString(nil, r.Target, sourceName, match)), true
+		}
+	}
+	if m.Identity {
+		return sourceName, true
+	}
+	return "", false
+}
+
+// HasRules is true if the map has any rules or a default in addition to exact names
+func (m NameMap) HasRules() bool {
+	return len(m.Rules) > 0 || m.Identity
+}
+
+// EvaluationOrder describes each mapping in the order it's evaluated
+func (m NameMap) EvaluationOrder() []string {
+	var result []string
+	if len(m.Exact) > 0 {
+		result = append(result, fmt.Sprintf("%d exact %s name mappings", len(m.Exact), m.Kind))
+	}
+	for _, r := range m.Rules {
+		result = append(result, r.String())
+	}
+	if m.Identity {
+		result = append(result, "default: same name as the source")
+	}
+	return result
+}
+
+// UnmatchedRules returns the rules that don't match any of the source names
+func (m NameMap) UnmatchedRules(sourceNames []string) []MappingRule {
+	var result []MappingRule
+	for _, r := range m.Rules {
+		re, err := r.Regexp()
+		if err != nil {
+			result = append(result, r)
+			continue
+		}
+		matched := false
+		for _, n := range sourceNames {
+			if re.MatchString(n) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// NetworkNameMap returns the network name mappings, rules and default
+func (c Config) NetworkNameMap() NameMap {
+	return NameMap{
+		Kind:     "network",
+		Exact:    c.NetworkMap,
+		Rules:    c.NetworkRules,
+		Identity: c.NetworkDefault == IdentityDefault,
+	}
+}
+
+// DatastoreNameMap returns the datastore name mappings, rules and default
+func (c Config) DatastoreNameMap() NameMap {
+	return NameMap{
+		Kind:     "datastore",
+		Exact:    c.DatastoreMap,
+		Rules:    c.DatastoreRules,
+		Identity: c.DatastoreDefault == IdentityDefault,
+	}
+}
+
+func (c Config) validateMappingRules() error {
+	for _, d := range []string{c.NetworkDefault, c.DatastoreDefault} {
+		if d != "" && d != IdentityDefault {
+			return fmt.Errorf("expected network_default and datastore_default to be %s but found %s",
+				IdentityDefault, d)
+		}
+	}
+	switch c.NetworkMappingMode {
+	case "", NetworkMappingModeName:
+		if c.NetworkTargetSwitch != "" {
+			return fmt.Errorf("expected network_target_switch to only be set with network_mapping_mode %s",
+				NetworkMappingModeID)
+		}
+	case NetworkMappingModeID:
+		if c.NetworkDefault != "" {
+			return fmt.Errorf("expected network_default to not be set with network_mapping_mode %s",
+				NetworkMappingModeID)
+		}
+	default:
+		return fmt.Errorf("expected network_mapping_mode to be %s or %s but found %s",
+			NetworkMappingModeName, NetworkMappingModeID, c.NetworkMappingMode)
+	}
+	for _, r := range c.NetworkRules {
+		if err := r.validate("network"); err != nil {
+			return err
+		}
+	}
+	for _, r := range c.DatastoreRules {
+		if err := r.validate("datastore"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MapNetworksByID is true when networks without an exact mapping are mapped by VLAN ID or NSX segment
+func (c Config) MapNetworksByID() bool {
+	return c.NetworkMappingMode == NetworkMappingModeID
+}
+
+// ReverseMappingRulesErr returns an error if the config has rules that can't be reversed for a revert
+func (c Config) ReverseMappingRulesErr() error {
+	if len(c.NetworkRules) > 0 || len(c.DatastoreRules) > 0 {
+		return errors.New("network_rules and datastore_rules can't be reversed, " +
+			"use a config with the reverse rules and the migrate command instead")
+	}
+	return nil
+}
+
+// globToRegex converts a glob to an anchored regex with a capture group for each * and ? wildcard
+func globToRegex(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString("(.*)")
+		case '?':
+			b.WriteString("(.)")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
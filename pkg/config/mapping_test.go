@@ -0,0 +1,75 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+)
+
+func TestNameMapTarget(t *testing.T) {
+	m := config.NameMap{
+		Kind:  "network",
+		Exact: map[string]string{"pcf-services": "tas-svc"},
+		Rules: []config.MappingRule{
+			{Pattern: `^pcf-(.*)$`, Target: "tas-${1}"},
+			{Glob: "pks-*", Target: "tkgi-$1"},
+			{Glob: "pcf-*", Target: "never"},
+		},
+		Identity: true,
+	}
+
+	tests := map[string]string{
+		"pcf-services": "tas-svc",
+		"pcf-infra":    "tas-infra",
+		"pks-1234":     "tkgi-1234",
+		"VM Network":   "VM Network",
+	}
+	for source, expected := range tests {
+		target, ok := m.Target(source)
+		require.True(t, ok, source)
+		require.Equal(t, expected, target, source)
+	}
+
+	m.Identity = false
+	_, ok := m.Target("VM Network")
+	require.False(t, ok)
+}
+
+func TestNameMapEvaluationOrder(t *testing.T) {
+	m := config.NameMap{
+		Kind:     "datastore",
+		Exact:    map[string]string{"ds1": "ds2"},
+		Rules:    []config.MappingRule{{Glob: "old-*", Target: "new-$1"}},
+		Identity: true,
+	}
+	require.Equal(t, []string{
+		"1 exact datastore name mappings",
+		"glob old-* -> new-$1",
+		"default: same name as the source",
+	}, m.EvaluationOrder())
+}
+
+func TestNameMapUnmatchedRules(t *testing.T) {
+	m := config.NameMap{
+		Kind: "datastore",
+		Rules: []config.MappingRule{
+			{Glob: "old-*", Target: "new-$1"},
+			{Pattern: "^missing", Target: "x"},
+		},
+	}
+	require.Equal(t, []config.MappingRule{{Pattern: "^missing", Target: "x"}},
+		m.UnmatchedRules([]string{"old-ds1", "ds2"}))
+}
+
+func TestReverseMappingRulesErr(t *testing.T) {
+	c := config.Config{}
+	require.NoError(t, c.ReverseMappingRulesErr())
+	c.NetworkRules = []config.MappingRule{{Glob: "*", Target: "$1"}}
+	require.Error(t, c.ReverseMappingRulesErr())
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/inventory"
+)
+
+// Override pins the VMs matching all of its selectors, a VM name, BOSH deployment or instance group, to a target
+// cluster, resource pool, folder, datastore or network instead of using the mappings. Selectors may contain
+// glob wildcards.
+type Override struct {
+	VM            string `yaml:"vm,omitempty"`
+	Deployment    string `yaml:"deployment,omitempty"`
+	InstanceGroup string `yaml:"instance_group,omitempty"`
+
+	Cluster      string `yaml:"cluster,omitempty"`
+	ResourcePool string `yaml:"resource_pool,omitempty"`
+	Folder       string `yaml:"folder,omitempty"`
+	Datastore    string `yaml:"datastore,omitempty"`
+	Network      string `yaml:"network,omitempty"`
+}
+
+func (o Override) validate(targetClusters []string) error {
+	selectors := []string{o.VM, o.Deployment, o.InstanceGroup}
+	if selectors[0] == "" && selectors[1] == "" && selectors[2] == "" {
+		return errors.New("expected override to have a vm, deployment or instance_group")
+	}
+	for _, s := range selectors {
+		if _, err := path.Match(s, ""); err != nil {
+			return fmt.Errorf("invalid override selector %s: %w", s, err)
+		}
+	}
+	if o.Cluster == "" && o.ResourcePool == "" && o.Folder == "" && o.Datastore == "" && o.Network == "" {
+		return fmt.Errorf("expected override %s to have a cluster, resource_pool, folder, datastore or network",
+			o.selector())
+	}
+	if o.Cluster != "" && !o.isTargetCluster(targetClusters) {
+		return fmt.Errorf("expected override %s cluster %s to be one of the compute target clusters",
+			o.selector(), o.Cluster)
+	}
+	return nil
+}
+
+// isTargetCluster returns true if the override cluster names one of the target clusters by name or inventory path
+func (o Override) isTargetCluster(targetClusters []string) bool {
+	for _, cl := range targetClusters {
+		if inventory.Same(cl, o.Cluster) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o Override) selector() string {
+	s := o.VM
+	for _, p := range []string{o.Deployment, o.InstanceGroup} {
+		if p != "" {
+			if s != "" {
+				s += "/"
+			}
+			s += p
+		}
+	}
+	return s
+}
+
+func (c Config) validateOverrides() error {
+	var targetClusters []string
+	for _, az := range c.Compute.Target {
+		for _, cl := range az.Clusters {
+			targetClusters = append(targetClusters, cl.Name)
+		}
+	}
+	for _, o := range c.Overrides {
+		if err := o.validate(targetClusters); err != nil {
+			return err
+		}
+	}
+	return nil
+}
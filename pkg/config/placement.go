@@ -0,0 +1,22 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package config
+
+const (
+	// PlacementRoundRobin places VMs on each of the source cluster's target clusters in turn
+	PlacementRoundRobin = "round-robin"
+	// PlacementLeastLoaded places VMs on the target cluster with the most free memory
+	PlacementLeastLoaded = "least-loaded"
+	// PlacementPreserveClusterIndex places VMs from the Nth source cluster in the AZ on the Nth target cluster
+	PlacementPreserveClusterIndex = "preserve-cluster-index"
+	// PlacementSpreadInstanceGroup spreads each BOSH instance group's VMs evenly across the target clusters
+	PlacementSpreadInstanceGroup = "spread-instance-group"
+)
+
+// PlacementStrategies are the valid compute placement_strategy names, an empty name is round-robin
+var PlacementStrategies = []string{
+	PlacementRoundRobin, PlacementLeastLoaded, PlacementPreserveClusterIndex, PlacementSpreadInstanceGroup,
+}
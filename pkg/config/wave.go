@@ -0,0 +1,68 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path"
+)
+
+// Wave selects a group of VMs migrated together, waves are migrated in order and each VM belongs to the first
+// wave with a matching selector. Deployment, instance group and VM name selectors may contain glob wildcards.
+type Wave struct {
+	Name           string   `yaml:"name"`
+	Stemcells      bool     `yaml:"stemcells,omitempty"`
+	Deployments    []string `yaml:"deployments,omitempty"`
+	InstanceGroups []string `yaml:"instance_groups,omitempty"`
+	VMs            []string `yaml:"vms,omitempty"`
+	AdditionalVMs  bool     `yaml:"additional_vms,omitempty"`
+
+	// HealthCheck stops the migration after this wave if any of its VMs failed to migrate or any of its
+	// BOSH deployments have instances that aren't running
+	HealthCheck bool `yaml:"health_check,omitempty"`
+
+	// Canaries overrides the global number of canary VMs per AZ for this wave
+	Canaries int `yaml:"canaries,omitempty"`
+}
+
+func (w Wave) validate() error {
+	if w.Name == "" {
+		return errors.New("expected wave to have a name")
+	}
+	if !w.Stemcells && !w.AdditionalVMs && len(w.Deployments) == 0 && len(w.InstanceGroups) == 0 && len(w.VMs) == 0 {
+		return fmt.Errorf("expected wave %s to select stemcells, deployments, instance_groups, vms or additional_vms",
+			w.Name)
+	}
+	if w.Canaries < 0 {
+		return fmt.Errorf("expected wave %s canaries >= 0", w.Name)
+	}
+	for _, selectors := range [][]string{w.Deployments, w.InstanceGroups, w.VMs} {
+		for _, s := range selectors {
+			if _, err := path.Match(s, ""); err != nil {
+				return fmt.Errorf("invalid wave %s selector %s: %w", w.Name, s, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (c Config) validateWaves() error {
+	names := map[string]bool{}
+	for _, w := range c.Waves {
+		if err := w.validate(); err != nil {
+			return err
+		}
+		if names[w.Name] {
+			return fmt.Errorf("found more than one wave named %s", w.Name)
+		}
+		names[w.Name] = true
+		if w.HealthCheck && c.Bosh == nil {
+			return fmt.Errorf("expected a bosh section to health check wave %s", w.Name)
+		}
+	}
+	return nil
+}
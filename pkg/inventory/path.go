@@ -0,0 +1,69 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package inventory
+
+import (
+	"path"
+	"strings"
+)
+
+// Name returns the object name from an inventory path like /Region1/DC1/host/Folder1/Cluster1, names are returned
+// unchanged
+func Name(nameOrPath string) string {
+	p := strings.Trim(nameOrPath, "/")
+	if p == "" {
+		return ""
+	}
+	return path.Base(p)
+}
+
+// Same returns true if the names or inventory paths refer to the same object. A name matches the last element of a
+// path and a relative path, like Folder1/Cluster1, matches the end of an absolute path. Objects found in vCenter carry
+// their full path, so only a configured name or relative path can match loosely.
+func Same(a, b string) bool {
+	a = strings.ToLower(strings.Trim(a, "/"))
+	b = strings.ToLower(strings.Trim(b, "/"))
+	if a == b {
+		return true
+	}
+	if a == "" || b == "" {
+		return false
+	}
+	return strings.HasSuffix(a, "/"+b) || strings.HasSuffix(b, "/"+a)
+}
+
+// HostFolderPath returns a cluster's path relative to its datacenter's host folder, the form BOSH and Operations
+// Manager expect, e.g. Folder1/Cluster1 for /Region1/DC1/host/Folder1/Cluster1. Names and relative paths are
+// returned unchanged.
+func HostFolderPath(clusterNameOrPath string) string {
+	if !strings.HasPrefix(clusterNameOrPath, "/") {
+		return clusterNameOrPath
+	}
+	p := strings.Split(strings.Trim(clusterNameOrPath, "/"), "/")
+	if i := hostFolderIndex(p); i > 0 {
+		return strings.Join(p[i+1:], "/")
+	}
+	return Name(clusterNameOrPath)
+}
+
+// IsClusterPath returns true if the cluster is a name, a path relative to the host folder or an absolute path under
+// a datacenter's host folder
+func IsClusterPath(clusterNameOrPath string) bool {
+	if !strings.HasPrefix(clusterNameOrPath, "/") {
+		return strings.Trim(clusterNameOrPath, "/") != ""
+	}
+	return hostFolderIndex(strings.Split(strings.Trim(clusterNameOrPath, "/"), "/")) > 0
+}
+
+// hostFolderIndex returns the index of the datacenter's host folder in the path elements, or -1
+func hostFolderIndex(p []string) int {
+	for i := 1; i < len(p)-1; i++ {
+		if p[i] == "host" {
+			return i
+		}
+	}
+	return -1
+}
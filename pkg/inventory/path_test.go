@@ -0,0 +1,57 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package inventory_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/inventory"
+)
+
+func TestName(t *testing.T) {
+	require.Equal(t, "Cluster1", inventory.Name("Cluster1"))
+	require.Equal(t, "Cluster1", inventory.Name("/Region1/DC1/host/Folder1/Cluster1"))
+	require.Equal(t, "DC1", inventory.Name("Region1/DC1/"))
+	require.Equal(t, "", inventory.Name("/"))
+}
+
+func TestSame(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"Cluster1", "Cluster1", true},
+		{"cluster1", "Cluster1", true},
+		{"Cluster1", "/DC1/host/Folder1/Cluster1", true},
+		{"Folder1/Cluster1", "/DC1/host/Folder1/Cluster1", true},
+		{"/Region1/DC1", "DC1", true},
+		{"Region1/DC1", "/Region1/DC1/", true},
+		{"Cluster1", "Cluster10", false},
+		{"Folder2/Cluster1", "/DC1/host/Folder1/Cluster1", false},
+		{"Region1/DC1", "Region2/DC1", false},
+		{"", "DC1", false},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, inventory.Same(tt.a, tt.b), "%s and %s", tt.a, tt.b)
+	}
+}
+
+func TestHostFolderPath(t *testing.T) {
+	require.Equal(t, "Cluster1", inventory.HostFolderPath("Cluster1"))
+	require.Equal(t, "Folder1/Cluster1", inventory.HostFolderPath("Folder1/Cluster1"))
+	require.Equal(t, "Folder1/Cluster1", inventory.HostFolderPath("/Region1/DC1/host/Folder1/Cluster1"))
+	require.Equal(t, "Cluster1", inventory.HostFolderPath("/DC1/host/Cluster1"))
+}
+
+func TestIsClusterPath(t *testing.T) {
+	require.True(t, inventory.IsClusterPath("Cluster1"))
+	require.True(t, inventory.IsClusterPath("Folder1/Cluster1"))
+	require.True(t, inventory.IsClusterPath("/Region1/DC1/host/Folder1/Cluster1"))
+	require.False(t, inventory.IsClusterPath("/DC1/Cluster1"))
+	require.False(t, inventory.IsClusterPath("/DC1/host"))
+	require.False(t, inventory.IsClusterPath(""))
+}
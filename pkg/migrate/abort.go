@@ -0,0 +1,56 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate
+
+import (
+	"fmt"
+	"sync"
+)
+
+// AbortThreshold stops any new VM migrations once too many VMs have failed, zero limits are disabled
+type AbortThreshold struct {
+	maxFailures       int
+	maxFailurePercent int
+	total             int
+
+	mu     sync.Mutex
+	failed int
+}
+
+// NewAbortThreshold creates a new AbortThreshold, the failure percentage is of the total VMs to migrate
+func NewAbortThreshold(maxFailures, maxFailurePercent, total int) *AbortThreshold {
+	return &AbortThreshold{
+		maxFailures:       maxFailures,
+		maxFailurePercent: maxFailurePercent,
+		total:             total,
+	}
+}
+
+// Fail records a failed VM migration
+func (t *AbortThreshold) Fail() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.failed++
+}
+
+// Exceeded returns true once the failed VMs exceed either limit
+func (t *AbortThreshold) Exceeded() bool {
+	return t.Reason() != ""
+}
+
+// Reason returns which limit the failed VMs exceeded or an empty string if they haven't exceeded any
+func (t *AbortThreshold) Reason() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.maxFailures > 0 && t.failed > t.maxFailures {
+		return fmt.Sprintf("%d VMs failed to migrate, exceeding max_failures %d", t.failed, t.maxFailures)
+	}
+	if t.maxFailurePercent > 0 && t.total > 0 && t.failed*100 > t.maxFailurePercent*t.total {
+		return fmt.Sprintf("%d of %d VMs failed to migrate, exceeding max_failure_percent %d%%",
+			t.failed, t.total, t.maxFailurePercent)
+	}
+	return ""
+}
@@ -0,0 +1,42 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+)
+
+func TestAbortThresholdMaxFailures(t *testing.T) {
+	a := migrate.NewAbortThreshold(2, 0, 100)
+	a.Fail()
+	a.Fail()
+	require.False(t, a.Exceeded())
+	require.Empty(t, a.Reason())
+	a.Fail()
+	require.True(t, a.Exceeded())
+	require.Equal(t, "3 VMs failed to migrate, exceeding max_failures 2", a.Reason())
+}
+
+func TestAbortThresholdMaxFailurePercent(t *testing.T) {
+	a := migrate.NewAbortThreshold(0, 10, 20)
+	a.Fail()
+	a.Fail()
+	require.False(t, a.Exceeded())
+	a.Fail()
+	require.True(t, a.Exceeded())
+	require.Equal(t, "3 of 20 VMs failed to migrate, exceeding max_failure_percent 10%", a.Reason())
+}
+
+func TestAbortThresholdDisabled(t *testing.T) {
+	a := migrate.NewAbortThreshold(0, 0, 10)
+	for i := 0; i < 10; i++ {
+		a.Fail()
+	}
+	require.False(t, a.Exceeded())
+}
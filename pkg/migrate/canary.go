@@ -0,0 +1,102 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/duration"
+)
+
+// CanaryPrompt asks the operator whether to continue migrating after a wave's canary VMs
+type CanaryPrompt struct {
+	in                io.Reader
+	out               Printer
+	autoContinueAfter time.Duration
+
+	readOnce sync.Once
+	answers  chan string
+}
+
+// NewCanaryPrompt creates a new CanaryPrompt reading answers from in, if autoContinueAfter is greater than zero
+// the prompt continues on its own once that long has passed without an answer
+func NewCanaryPrompt(in io.Reader, out Printer, autoContinueAfter time.Duration) *CanaryPrompt {
+	return &CanaryPrompt{
+		in:                in,
+		out:               out,
+		autoContinueAfter: autoContinueAfter,
+		answers:           make(chan string),
+	}
+}
+
+// Continue prints the question and returns true if the operator answers yes or the auto continue duration elapses,
+// a non-interactive input without auto continue never continues
+func (p *CanaryPrompt) Continue(ctx context.Context, question string) bool {
+	// answers are read in the background for the life of the process as a pending read can't be cancelled
+	p.readOnce.Do(func() {
+		go p.readAnswers()
+	})
+
+	var timeout <-chan time.Time
+	if p.autoContinueAfter > 0 {
+		p.out.Printf("%s? [y/N] continuing automatically in %s",
+			question, duration.HumanReadable(p.autoContinueAfter))
+		t := time.NewTimer(p.autoContinueAfter)
+		defer t.Stop()
+		timeout = t.C
+	} else {
+		p.out.Printf("%s? [y/N]", question)
+	}
+
+	answers := p.answers
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-timeout:
+			return true
+		case answer, ok := <-answers:
+			if !ok {
+				// no more input, wait for the auto continue if there is one
+				if timeout == nil {
+					return false
+				}
+				answers = nil
+				continue
+			}
+			answer = strings.ToLower(strings.TrimSpace(answer))
+			return answer == "y" || answer == "yes"
+		}
+	}
+}
+
+func (p *CanaryPrompt) readAnswers() {
+	scanner := bufio.NewScanner(p.in)
+	for scanner.Scan() {
+		p.answers <- scanner.Text()
+	}
+	close(p.answers)
+}
+
+// SplitCanaries returns the wave's first n VMs in each AZ as the canaries, followed by all the other VMs
+func (w Wave) SplitCanaries(n int) ([]VM, []VM) {
+	var canaries, rest []VM
+	perAZ := map[string]int{}
+	for _, v := range w.VMs {
+		if perAZ[v.AZ] < n {
+			perAZ[v.AZ]++
+			canaries = append(canaries, v)
+		} else {
+			rest = append(rest, v)
+		}
+	}
+	return canaries, rest
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+)
+
+func TestCanaryPromptAnswers(t *testing.T) {
+	out := log.NewBufferedStdout()
+	p := migrate.NewCanaryPrompt(strings.NewReader("y\nno\n YES \n"), out, 0)
+
+	require.True(t, p.Continue(context.Background(), "Continue migrating the remaining 9 VMs in wave cf"))
+	require.Equal(t, "Continue migrating the remaining 9 VMs in wave cf? [y/N]", out.String())
+	require.False(t, p.Continue(context.Background(), "Continue"))
+	require.True(t, p.Continue(context.Background(), "Continue"))
+
+	// no more input and no auto continue
+	require.False(t, p.Continue(context.Background(), "Continue"))
+}
+
+func TestCanaryPromptAutoContinue(t *testing.T) {
+	out := log.NewBufferedStdout()
+
+	// non-interactive input waits for the auto continue
+	p := migrate.NewCanaryPrompt(strings.NewReader(""), out, 10*time.Millisecond)
+	require.True(t, p.Continue(context.Background(), "Continue"))
+	require.Contains(t, out.String(), "Continue? [y/N] continuing automatically in")
+
+	// an answer before the auto continue wins
+	p = migrate.NewCanaryPrompt(strings.NewReader("n\n"), out, time.Hour)
+	require.False(t, p.Continue(context.Background(), "Continue"))
+
+	// a cancelled migration doesn't continue
+	r, w := io.Pipe()
+	defer func() { _ = w.Close() }()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p = migrate.NewCanaryPrompt(r, out, time.Hour)
+	require.False(t, p.Continue(ctx, "Continue"))
+}
+
+func TestWaveSplitCanaries(t *testing.T) {
+	w := migrate.Wave{
+		Name: "cf",
+		VMs: []migrate.VM{
+			{Name: "vm1", AZ: "az1"},
+			{Name: "vm2", AZ: "az2"},
+			{Name: "vm3", AZ: "az1"},
+			{Name: "vm4", AZ: "az2"},
+			{Name: "vm5", AZ: "az1"},
+		},
+	}
+
+	canaries, rest := w.SplitCanaries(1)
+	require.Equal(t, []migrate.VM{{Name: "vm1", AZ: "az1"}, {Name: "vm2", AZ: "az2"}}, canaries)
+	require.Equal(t, []migrate.VM{{Name: "vm3", AZ: "az1"}, {Name: "vm4", AZ: "az2"}, {Name: "vm5", AZ: "az1"}}, rest)
+
+	canaries, rest = w.SplitCanaries(2)
+	require.Len(t, canaries, 4)
+	require.Equal(t, []migrate.VM{{Name: "vm5", AZ: "az1"}}, rest)
+
+	canaries, rest = w.SplitCanaries(0)
+	require.Empty(t, canaries)
+	require.Equal(t, w.VMs, rest)
+}
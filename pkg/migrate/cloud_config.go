@@ -0,0 +1,119 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudfoundry-community/gogobosh"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/bosh"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/proxy"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/yamlnode"
+)
+
+//counterfeiter:generate . CloudConfigBoshClient
+type CloudConfigBoshClient interface {
+	CloudAndCPIConfigs(context.Context) ([]gogobosh.Cfg, error)
+}
+
+// ConvertedConfig is a BOSH cloud or CPI config rewritten to use the migrated vSphere objects
+type ConvertedConfig struct {
+	Name     string
+	Type     string
+	Original string
+	Content  string
+}
+
+// FileName returns the file name to save the converted config to, i.e. cloud-config.yml or cloud-config-vms.yml
+func (c ConvertedConfig) FileName() string {
+	if c.Name == "" || c.Name == "default" {
+		return fmt.Sprintf("%s-config.yml", c.Type)
+	}
+	return fmt.Sprintf("%s-config-%s.yml", c.Type, c.Name)
+}
+
+// UpdateCommand returns the bosh CLI command to apply the converted config saved to the file path
+func (c ConvertedConfig) UpdateCommand(filePath string) string {
+	cmd := fmt.Sprintf("bosh update-%s-config %s", c.Type, filePath)
+	if c.Name != "" && c.Name != "default" {
+		cmd += " --name " + c.Name
+	}
+	return cmd
+}
+
+// Diff returns a unified diff between the director's current config and the converted config
+func (c ConvertedConfig) Diff() (string, error) {
+	// format the original the same way as the converted config so only the mapped values show up
+	original, err := yamlnode.Format([]byte(c.Original))
+	if err != nil {
+		original = []byte(c.Original)
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(original)),
+		B:        difflib.SplitLines(c.Content),
+		FromFile: fmt.Sprintf("%s config %s (current)", c.Type, c.Name),
+		ToFile:   fmt.Sprintf("%s config %s (migrated)", c.Type, c.Name),
+		Context:  3,
+	})
+}
+
+// CloudConfigConverter fetches a BOSH director's cloud and CPI configs and rewrites them using the
+// migrate.yml mappings, for directors not managed by Operations Manager
+type CloudConfigConverter struct {
+	boshClient CloudConfigBoshClient
+	converter  *bosh.ConfigConverter
+	dialer     *proxy.Dialer
+}
+
+func NewCloudConfigConverter(boshClient CloudConfigBoshClient, c config.Config) *CloudConfigConverter {
+	return &CloudConfigConverter{
+		boshClient: boshClient,
+		converter:  bosh.NewConfigConverter(c),
+	}
+}
+
+// NewCloudConfigConverterFromConfig creates a new CloudConfigConverter instance from the specified config
+func NewCloudConfigConverterFromConfig(c config.Config) (*CloudConfigConverter, error) {
+	if c.Bosh == nil {
+		return nil, fmt.Errorf("the bosh section is required to convert the BOSH cloud and CPI configs")
+	}
+	dialer, err := proxy.NewDialer(c.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	cc := NewCloudConfigConverter(newBoshClient(c.Bosh, dialer), c)
+	cc.dialer = dialer
+	return cc, nil
+}
+
+// Convert returns all the director's cloud and CPI configs converted to use the migrated vSphere objects
+func (c *CloudConfigConverter) Convert(ctx context.Context) ([]ConvertedConfig, error) {
+	if c.dialer != nil {
+		defer closeDialer(ctx, c.dialer)
+	}
+	configs, err := c.boshClient.CloudAndCPIConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []ConvertedConfig
+	for _, cfg := range configs {
+		content, err := c.converter.Convert(cfg)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, ConvertedConfig{
+			Name:     cfg.Name,
+			Type:     cfg.Type,
+			Original: cfg.Content,
+			Content:  content,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudfoundry-community/gogobosh"
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/migratefakes"
+)
+
+const cloudConfig = `networks:
+- name: default
+  subnets:
+  - range: 10.0.0.0/24
+    cloud_properties:
+      name: Net1
+disk_types:
+- name: default
+  disk_size: 1024
+`
+
+func TestCloudConfigConverter(t *testing.T) {
+	boshClient := &migratefakes.FakeCloudConfigBoshClient{}
+	boshClient.CloudAndCPIConfigsReturns([]gogobosh.Cfg{
+		{Name: "default", Type: "cloud", Content: cloudConfig},
+		{Name: "vms", Type: "cloud", Content: "disk_types: []\n"},
+	}, nil)
+
+	c := config.Config{NetworkMap: map[string]string{"Net1": "Net2"}}
+	configs, err := migrate.NewCloudConfigConverter(boshClient, c).Convert(context.Background())
+	require.NoError(t, err)
+	require.Len(t, configs, 2)
+
+	require.Equal(t, "cloud-config.yml", configs[0].FileName())
+	require.Equal(t, "bosh update-cloud-config cloud-config.yml", configs[0].UpdateCommand("cloud-config.yml"))
+	require.Contains(t, configs[0].Content, "name: Net2")
+	diff, err := configs[0].Diff()
+	require.NoError(t, err)
+	require.Contains(t, diff, "         cloud_properties:\n-          name: Net1\n+          name: Net2\n")
+
+	require.Equal(t, "cloud-config-vms.yml", configs[1].FileName())
+	require.Equal(t, "bosh update-cloud-config cloud-config-vms.yml --name vms", configs[1].UpdateCommand("cloud-config-vms.yml"))
+	diff, err = configs[1].Diff()
+	require.NoError(t, err)
+	require.Empty(t, diff)
+}
+
+func TestCloudConfigConverter_ReturnsBoshError(t *testing.T) {
+	boshClient := &migratefakes.FakeCloudConfigBoshClient{}
+	boshClient.CloudAndCPIConfigsReturns(nil, errors.New("bosh unavailable"))
+
+	_, err := migrate.NewCloudConfigConverter(boshClient, config.Config{}).Convert(context.Background())
+	require.EqualError(t, err, "bosh unavailable")
+}
+
+func TestNewCloudConfigConverterFromConfig_RequiresBosh(t *testing.T) {
+	_, err := migrate.NewCloudConfigConverterFromConfig(config.Config{})
+	require.EqualError(t, err, "the bosh section is required to convert the BOSH cloud and CPI configs")
+}
@@ -0,0 +1,395 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/cloudfoundry-community/gogobosh"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/bosh"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/inventory"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/proxy"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/yamlnode"
+	"gopkg.in/yaml.v3"
+)
+
+// environment variables the generated config reads secrets from, so they're never written to disk
+const (
+	BoshClientSecretEnv      = "BOSH_CLIENT_SECRET"
+	SourceVCenterPasswordEnv = "SOURCE_VCENTER_PASSWORD"
+	TargetVCenterPasswordEnv = "TARGET_VCENTER_PASSWORD"
+)
+
+//counterfeiter:generate . InitBoshClient
+type InitBoshClient interface {
+	VMsAndStemcells(context.Context) ([]bosh.VM, error)
+	CloudAndCPIConfigs(context.Context) ([]gogobosh.Cfg, error)
+}
+
+//counterfeiter:generate . InventoryVCenterClient
+type InventoryVCenterClient interface {
+	FindVM(ctx context.Context, azName, vmNameOrPath string) (*vcenter.VM, error)
+	Inventory(ctx context.Context) (*vcenter.Inventory, error)
+}
+
+// ConfigInitializer generates a migrate.yml skeleton from the BOSH cloud config and the source vCenter
+// inventory of every BOSH VM, with target placeholders for the user to fill in
+type ConfigInitializer struct {
+	MatchNames bool
+
+	boshClient    InitBoshClient
+	bosh          *config.Bosh
+	proxy         string
+	sourceVCenter config.VCenter
+	sourceClient  func(datacenter string) InventoryVCenterClient
+	targetVCenter *config.VCenter
+	targetClient  InventoryVCenterClient
+	dialer        *proxy.Dialer
+}
+
+func NewConfigInitializer(boshClient InitBoshClient, sourceVCenter config.VCenter,
+	sourceClient func(datacenter string) InventoryVCenterClient) *ConfigInitializer {
+
+	return &ConfigInitializer{
+		boshClient:    boshClient,
+		sourceVCenter: sourceVCenter,
+		sourceClient:  sourceClient,
+	}
+}
+
+// NewConfigInitializerFromConfig creates a new ConfigInitializer that connects to the BOSH director and
+// source vCenter, and to the optional target vCenter used to match target objects by name
+func NewConfigInitializerFromConfig(b *config.Bosh, proxyURL string, source config.VCenter,
+	target *config.VCenter) (*ConfigInitializer, error) {
+
+	dialer, err := proxy.NewDialer(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceClient := func(datacenter string) InventoryVCenterClient {
+		v := source
+		v.Datacenter = datacenter
+		return configToVCenterClient(&v, dialer)
+	}
+	i := NewConfigInitializer(newBoshClient(b, dialer), source, sourceClient).
+		WithBosh(b).
+		WithProxy(proxyURL)
+	if target != nil {
+		i = i.WithTarget(*target, configToVCenterClient(target, dialer))
+	}
+	i.dialer = dialer
+	return i, nil
+}
+
+// WithBosh adds the bosh section to the generated config
+func (i *ConfigInitializer) WithBosh(b *config.Bosh) *ConfigInitializer {
+	i.bosh = b
+	return i
+}
+
+// WithProxy adds the proxy to the generated config
+func (i *ConfigInitializer) WithProxy(proxyURL string) *ConfigInitializer {
+	i.proxy = proxyURL
+	return i
+}
+
+// WithTarget adds the target vCenter to the generated config, its inventory is used to match target names
+func (i *ConfigInitializer) WithTarget(targetVCenter config.VCenter, targetClient InventoryVCenterClient) *ConfigInitializer {
+	i.targetVCenter = &targetVCenter
+	i.targetClient = targetClient
+	return i
+}
+
+func (i *ConfigInitializer) WithMatchNames(matchNames bool) *ConfigInitializer {
+	i.MatchNames = matchNames
+	return i
+}
+
+// Generate returns the migrate.yml skeleton
+func (i *ConfigInitializer) Generate(ctx context.Context) ([]byte, error) {
+	if i.dialer != nil {
+		defer closeDialer(ctx, i.dialer)
+	}
+	if i.MatchNames && i.targetClient == nil {
+		return nil, errors.New("matching target names requires a target vCenter")
+	}
+
+	objects, err := i.sourceObjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = i.addVMObjects(ctx, objects)
+	if err != nil {
+		return nil, err
+	}
+
+	target := &vcenter.Inventory{}
+	if i.MatchNames {
+		target, err = i.targetClient.Inventory(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not get target vCenter inventory: %w", err)
+		}
+	}
+
+	return yamlnode.Encode(&yaml.Node{
+		Kind:    yaml.DocumentNode,
+		Content: []*yaml.Node{i.skeleton(objects, target)},
+	})
+}
+
+// sourceObjects returns the AZs, networks and datastores from all the director's cloud configs
+func (i *ConfigInitializer) sourceObjects(ctx context.Context) (*bosh.CloudConfigObjects, error) {
+	configs, err := i.boshClient.CloudAndCPIConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &bosh.CloudConfigObjects{}
+	for _, cfg := range configs {
+		if cfg.Type != "cloud" {
+			continue
+		}
+		objects, err := bosh.ParseCloudConfigObjects(cfg.Content)
+		if err != nil {
+			return nil, err
+		}
+		for _, az := range objects.AZs {
+			// the same AZ can be declared in more than one cloud config
+			if cloudConfigAZ(result, az.Name) == nil {
+				result.AZs = append(result.AZs, az)
+			}
+		}
+		for _, ds := range objects.Datastores {
+			result.Datastores = appendUnique(result.Datastores, ds)
+		}
+		for _, n := range objects.Networks {
+			// VMs report their network's name without any switch or folder prefix
+			result.Networks = appendUnique(result.Networks, path.Base(n))
+		}
+	}
+	if len(result.AZs) == 0 {
+		return nil, errors.New("could not find any AZs in the BOSH cloud config")
+	}
+	return result, nil
+}
+
+// addVMObjects adds the clusters, resource pools, networks and datastores every BOSH VM currently uses
+func (i *ConfigInitializer) addVMObjects(ctx context.Context, objects *bosh.CloudConfigObjects) error {
+	l := log.FromContext(ctx)
+
+	vms, err := i.boshClient.VMsAndStemcells(ctx)
+	if err != nil {
+		return err
+	}
+
+	clients := map[string]InventoryVCenterClient{}
+	for _, bvm := range vms {
+		az := cloudConfigAZ(objects, bvm.AZ)
+		if az == nil || az.Datacenter == "" {
+			l.Warnf("Skipping BOSH VM %s, could not find AZ %s in the cloud config", bvm.Name, bvm.AZ)
+			continue
+		}
+		client, ok := clients[az.Datacenter]
+		if !ok {
+			client = i.sourceClient(az.Datacenter)
+			clients[az.Datacenter] = client
+		}
+
+		vm, err := client.FindVM(ctx, bvm.AZ, bvm.Name)
+		if err != nil {
+			var e *vcenter.VMNotFoundError
+			if errors.As(err, &e) {
+				l.Warnf("Skipping BOSH VM %s, could not find it in vCenter datacenter %s", bvm.Name, az.Datacenter)
+				continue
+			}
+			return err
+		}
+
+		// cloud config clusters are relative to the datacenter's host folder
+		cluster := inventory.HostFolderPath(vm.Cluster)
+		if !hasCluster(az.Clusters, cluster) {
+			az.Clusters = append(az.Clusters, bosh.CloudConfigCluster{
+				Name:         cluster,
+				ResourcePool: vm.ResourcePool,
+			})
+		}
+		for _, n := range vm.Networks {
+			objects.Networks = appendUnique(objects.Networks, n)
+		}
+		for _, d := range vm.Disks {
+			objects.Datastores = appendUnique(objects.Datastores, d.Datastore)
+		}
+	}
+	return nil
+}
+
+func (i *ConfigInitializer) skeleton(objects *bosh.CloudConfigObjects, target *vcenter.Inventory) *yaml.Node {
+	root := yamlnode.NewMap()
+	if i.bosh != nil {
+		yamlnode.Set(root, "bosh", boshNode(i.bosh))
+	}
+	if i.proxy != "" {
+		yamlnode.Set(root, "proxy", yamlnode.NewScalar(i.proxy))
+	}
+
+	// each vCenter datacenter is declared once and referenced by its AZs
+	vcenters := yamlnode.NewSequence()
+	sourceVCenters := map[string]*yaml.Node{}
+	for _, az := range objects.AZs {
+		if _, ok := sourceVCenters[az.Datacenter]; ok {
+			continue
+		}
+		v := i.sourceVCenter
+		v.Datacenter = az.Datacenter
+		n := vcenterNode(v, SourceVCenterPasswordEnv)
+		n.Anchor = fmt.Sprintf("source_vcenter%d", len(sourceVCenters)+1)
+		sourceVCenters[az.Datacenter] = n
+		vcenters.Content = append(vcenters.Content, vcenterEntry(n))
+	}
+	targetVCenter := vcenterNode(config.VCenter{
+		Host:       config.Placeholder,
+		Username:   config.Placeholder,
+		Datacenter: config.Placeholder,
+	}, TargetVCenterPasswordEnv)
+	if i.targetVCenter != nil {
+		targetVCenter = vcenterNode(*i.targetVCenter, TargetVCenterPasswordEnv)
+	}
+	targetVCenter.Anchor = "target_vcenter"
+	vcenters.Content = append(vcenters.Content, vcenterEntry(targetVCenter))
+	yamlnode.Set(root, "vcenters", vcenters)
+
+	source := yamlnode.NewSequence()
+	targets := yamlnode.NewSequence()
+	for _, az := range objects.AZs {
+		sourceClusters := yamlnode.NewSequence()
+		targetClusters := yamlnode.NewSequence()
+		for _, cl := range az.Clusters {
+			sourceClusters.Content = append(sourceClusters.Content, clusterNode(cl.Name, cl.ResourcePool))
+			rp := ""
+			if cl.ResourcePool != "" {
+				rp = i.match(cl.ResourcePool, target.ResourcePools)
+			}
+			targetClusters.Content = append(targetClusters.Content, clusterNode(i.match(cl.Name, target.Clusters), rp))
+		}
+		source.Content = append(source.Content, azNode(az.Name, sourceVCenters[az.Datacenter], sourceClusters))
+		targets.Content = append(targets.Content, azNode(az.Name, targetVCenter, targetClusters))
+	}
+	compute := yamlnode.NewMap()
+	yamlnode.Set(compute, "source", source)
+	yamlnode.Set(compute, "target", targets)
+	yamlnode.Set(root, "compute", compute)
+
+	yamlnode.Set(root, "datastores", i.mappingNode(objects.Datastores, target.Datastores))
+	yamlnode.Set(root, "networks", i.mappingNode(objects.Networks, target.Networks))
+	return root
+}
+
+// match returns the source name if the target has an object with the same name, otherwise a placeholder
+func (i *ConfigInitializer) match(name string, targetNames []string) string {
+	if i.MatchNames {
+		for _, t := range targetNames {
+			if t == name {
+				return name
+			}
+		}
+	}
+	return config.Placeholder
+}
+
+func (i *ConfigInitializer) mappingNode(sourceNames, targetNames []string) *yaml.Node {
+	names := append([]string{}, sourceNames...)
+	sort.Strings(names)
+	m := yamlnode.NewMap()
+	for _, n := range names {
+		yamlnode.Set(m, n, yamlnode.NewScalar(i.match(n, targetNames)))
+	}
+	return m
+}
+
+func boshNode(b *config.Bosh) *yaml.Node {
+	n := yamlnode.NewMap()
+	yamlnode.SetOptionalScalar(n, "host", b.Host)
+	yamlnode.SetOptionalScalar(n, "url", b.URL)
+	yamlnode.SetScalar(n, "client_id", b.ClientID)
+	yamlnode.SetScalar(n, "client_secret", "${"+BoshClientSecretEnv+"}")
+	yamlnode.SetOptionalScalar(n, "ca_cert", b.CACert)
+	yamlnode.SetOptionalScalar(n, "ca_cert_file", b.CACertFile)
+	if b.Insecure {
+		yamlnode.Set(n, "insecure", yamlnode.NewScalar(true))
+	}
+	return n
+}
+
+func vcenterNode(v config.VCenter, passwordEnv string) *yaml.Node {
+	n := yamlnode.NewMap()
+	yamlnode.SetScalar(n, "host", v.Host)
+	yamlnode.SetScalar(n, "username", v.Username)
+	yamlnode.SetScalar(n, "password", "${"+passwordEnv+"}")
+	yamlnode.Set(n, "insecure", yamlnode.NewScalar(v.Insecure))
+	yamlnode.SetScalar(n, "datacenter", v.Datacenter)
+	yamlnode.SetOptionalScalar(n, "ca_cert_file", v.CACertFile)
+	yamlnode.SetOptionalScalar(n, "thumbprint", v.Thumbprint)
+	return n
+}
+
+func vcenterEntry(v *yaml.Node) *yaml.Node {
+	n := yamlnode.NewMap()
+	yamlnode.Set(n, "vcenter", v)
+	return n
+}
+
+func azNode(name string, v *yaml.Node, clusters *yaml.Node) *yaml.Node {
+	n := yamlnode.NewMap()
+	yamlnode.SetScalar(n, "name", name)
+	yamlnode.Set(n, "vcenter", &yaml.Node{Kind: yaml.AliasNode, Alias: v, Value: v.Anchor})
+	yamlnode.Set(n, "clusters", clusters)
+	return n
+}
+
+func clusterNode(name, resourcePool string) *yaml.Node {
+	n := yamlnode.NewMap()
+	yamlnode.SetScalar(n, "name", name)
+	yamlnode.SetOptionalScalar(n, "resource_pool", resourcePool)
+	return n
+}
+
+func cloudConfigAZ(objects *bosh.CloudConfigObjects, name string) *bosh.CloudConfigAZ {
+	for i := range objects.AZs {
+		if objects.AZs[i].Name == name {
+			return &objects.AZs[i]
+		}
+	}
+	return nil
+}
+
+func hasCluster(clusters []bosh.CloudConfigCluster, name string) bool {
+	for _, cl := range clusters {
+		if cl.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUnique(values []string, v string) []string {
+	if v == "" {
+		return values
+	}
+	for _, existing := range values {
+		if existing == v {
+			return values
+		}
+	}
+	return append(values, v)
+}
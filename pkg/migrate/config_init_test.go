@@ -0,0 +1,187 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudfoundry-community/gogobosh"
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/bosh"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/migratefakes"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+const initCloudConfig = `azs:
+- name: az1
+  cloud_properties:
+    datacenters:
+    - name: DC1
+      clusters:
+      - cf1: {resource_pool: pas-az1}
+- name: az2
+  cloud_properties:
+    datacenters:
+    - name: DC1
+      clusters:
+      - cf2: {}
+disk_types:
+- name: default
+  cloud_properties:
+    datastores: [ds1]
+networks:
+- name: default
+  subnets:
+  - cloud_properties:
+      name: DSwitch/PAS-Deployment
+`
+
+func newInitializer() (*migrate.ConfigInitializer, *migratefakes.FakeInitBoshClient, *migratefakes.FakeInventoryVCenterClient) {
+	boshClient := &migratefakes.FakeInitBoshClient{}
+	boshClient.CloudAndCPIConfigsReturns([]gogobosh.Cfg{
+		{Name: "default", Type: "cloud", Content: initCloudConfig},
+	}, nil)
+	boshClient.VMsAndStemcellsReturns([]bosh.VM{
+		{Name: "vm-1", AZ: "az1"},
+		{Name: "vm-2", AZ: "az2"},
+		{Name: "vm-gone", AZ: "az2"},
+		{Name: "vm-no-az", AZ: "az9"},
+	}, nil)
+
+	sourceClient := &migratefakes.FakeInventoryVCenterClient{}
+	sourceClient.FindVMCalls(func(ctx context.Context, az string, name string) (*vcenter.VM, error) {
+		switch name {
+		case "vm-1":
+			return &vcenter.VM{
+				Name:         name,
+				Cluster:      "cf1",
+				ResourcePool: "pas-az1",
+				Networks:     []string{"PAS-Deployment"},
+				Disks:        []vcenter.Disk{{Datastore: "ds1"}, {Datastore: "ds2"}},
+			}, nil
+		case "vm-2":
+			return &vcenter.VM{
+				Name:     name,
+				Cluster:  "cf3",
+				Networks: []string{"PAS-Services"},
+				Disks:    []vcenter.Disk{{Datastore: "ds2"}},
+			}, nil
+		}
+		return nil, vcenter.NewVMNotFoundError(name, errors.New("not found"))
+	})
+
+	source := config.VCenter{
+		Host:     "vc01.example.com",
+		Username: "admin@vsphere.local",
+		Insecure: true,
+	}
+	i := migrate.NewConfigInitializer(boshClient, source, func(datacenter string) migrate.InventoryVCenterClient {
+		return sourceClient
+	})
+	return i, boshClient, sourceClient
+}
+
+func TestConfigInitializer(t *testing.T) {
+	i, _, sourceClient := newInitializer()
+	i.WithBosh(&config.Bosh{Host: "10.0.0.5", ClientID: "ops_manager"})
+
+	out, err := i.Generate(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, sourceClient.FindVMCallCount())
+	require.Equal(t, `bosh:
+  host: 10.0.0.5
+  client_id: ops_manager
+  client_secret: ${BOSH_CLIENT_SECRET}
+vcenters:
+  - vcenter: &source_vcenter1
+      host: vc01.example.com
+      username: admin@vsphere.local
+      password: ${SOURCE_VCENTER_PASSWORD}
+      insecure: true
+      datacenter: DC1
+  - vcenter: &target_vcenter
+      host: TODO
+      username: TODO
+      password: ${TARGET_VCENTER_PASSWORD}
+      insecure: false
+      datacenter: TODO
+compute:
+  source:
+    - name: az1
+      vcenter: *source_vcenter1
+      clusters:
+        - name: cf1
+          resource_pool: pas-az1
+    - name: az2
+      vcenter: *source_vcenter1
+      clusters:
+        - name: cf2
+        - name: cf3
+  target:
+    - name: az1
+      vcenter: *target_vcenter
+      clusters:
+        - name: TODO
+          resource_pool: TODO
+    - name: az2
+      vcenter: *target_vcenter
+      clusters:
+        - name: TODO
+        - name: TODO
+datastores:
+  ds1: TODO
+  ds2: TODO
+networks:
+  PAS-Deployment: TODO
+  PAS-Services: TODO
+`, string(out))
+}
+
+func TestConfigInitializerMatchNames(t *testing.T) {
+	i, _, _ := newInitializer()
+	targetClient := &migratefakes.FakeInventoryVCenterClient{}
+	targetClient.InventoryReturns(&vcenter.Inventory{
+		Clusters:      []string{"cf1", "cf2"},
+		ResourcePools: []string{"pas-az1"},
+		Datastores:    []string{"ds2"},
+		Networks:      []string{"PAS-Deployment"},
+	}, nil)
+	i.WithTarget(config.VCenter{Host: "vc02.example.com", Username: "admin", Datacenter: "DC2"}, targetClient).
+		WithMatchNames(true)
+
+	out, err := i.Generate(context.Background())
+	require.NoError(t, err)
+
+	configFile := filepath.Join(t.TempDir(), "migrate.yml")
+	require.NoError(t, os.WriteFile(configFile, out, 0600))
+	c, err := config.NewConfigFromFile(configFile)
+	require.ErrorContains(t, err, "found TODO placeholders that must be replaced: "+
+		"networks.PAS-Services, datastores.ds1, compute.target.az2.clusters[1]")
+	require.Equal(t, "vc02.example.com", c.Compute.TargetByAZ("az1").VCenter.Host)
+	require.Equal(t, []config.ComputeCluster{{Name: "cf1", ResourcePool: "pas-az1"}}, c.Compute.TargetByAZ("az1").Clusters)
+	require.Equal(t, []config.ComputeCluster{{Name: "cf2"}, {Name: "TODO"}}, c.Compute.TargetByAZ("az2").Clusters)
+	require.Equal(t, map[string]string{"ds1": "TODO", "ds2": "ds2"}, c.DatastoreMap)
+	require.Equal(t, map[string]string{"PAS-Deployment": "PAS-Deployment", "PAS-Services": "TODO"}, c.NetworkMap)
+}
+
+func TestConfigInitializerMatchNamesRequiresTarget(t *testing.T) {
+	i, _, _ := newInitializer()
+	_, err := i.WithMatchNames(true).Generate(context.Background())
+	require.EqualError(t, err, "matching target names requires a target vCenter")
+}
+
+func TestConfigInitializerBoshError(t *testing.T) {
+	i, boshClient, _ := newInitializer()
+	boshClient.VMsAndStemcellsReturns(nil, errors.New("bosh unavailable"))
+	_, err := i.Generate(context.Background())
+	require.EqualError(t, err, "bosh unavailable")
+}
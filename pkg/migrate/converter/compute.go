@@ -7,15 +7,33 @@ package converter
 
 import (
 	"fmt"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/inventory"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
-	"math/rand"
+	"sort"
 	"strings"
+	"sync"
 )
 
 const defaultResourcePoolName = "Resources"
 
+// placementOverride is the strategy recorded for VMs placed by an override's cluster
+const placementOverride = "override"
+
 type MappedCompute struct {
 	azMappings []AZMapping
+	strategy   PlacementStrategy
+
+	placementsMutex sync.Mutex
+	placements      map[string]Placement
+}
+
+// Placement records the target compute chosen for a VM and the strategy that chose it
+type Placement struct {
+	VM            string
+	SourceAZ      string
+	SourceCluster string
+	Target        AZ
+	Strategy      string
 }
 
 type AZMapping struct {
@@ -33,8 +51,8 @@ type AZ struct {
 func (a AZ) Equals(other AZ) bool {
 	return strings.EqualFold(a.Name, other.Name) &&
 		strings.EqualFold(a.ResourcePool, other.ResourcePool) &&
-		strings.EqualFold(a.Datacenter, other.Datacenter) &&
-		strings.EqualFold(a.Cluster, other.Cluster)
+		inventory.Same(a.Datacenter, other.Datacenter) &&
+		inventory.Same(a.Cluster, other.Cluster)
 }
 
 func NewEmptyMappedCompute() *MappedCompute {
@@ -44,20 +62,92 @@ func NewEmptyMappedCompute() *MappedCompute {
 func NewMappedCompute(azMappings []AZMapping) *MappedCompute {
 	return &MappedCompute{
 		azMappings: azMappings,
+		strategy:   NewRoundRobinPlacement(),
+		placements: map[string]Placement{},
 	}
 }
 
+// WithPlacementStrategy chooses between multiple target clusters using the strategy
+func (c *MappedCompute) WithPlacementStrategy(strategy PlacementStrategy) *MappedCompute {
+	c.strategy = strategy
+	return c
+}
+
+// PlacementStrategy returns the strategy used to choose between multiple target clusters
+func (c *MappedCompute) PlacementStrategy() PlacementStrategy {
+	return c.strategy
+}
+
+// Mappings returns all the source to target AZ mappings
+func (c *MappedCompute) Mappings() []AZMapping {
+	return c.azMappings
+}
+
+// Placements returns the target compute chosen for each VM so far, sorted by AZ and VM name
+func (c *MappedCompute) Placements() []Placement {
+	c.placementsMutex.Lock()
+	defer c.placementsMutex.Unlock()
+
+	var result []Placement
+	for _, p := range c.placements {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].SourceAZ != result[j].SourceAZ {
+			return result[i].SourceAZ < result[j].SourceAZ
+		}
+		return result[i].VM < result[j].VM
+	})
+	return result
+}
+
 func (c *MappedCompute) TargetCompute(sourceVM *vcenter.VM) (AZ, error) {
 	if sourceVM == nil {
 		return AZ{}, fmt.Errorf("expected source VM to be non-nil")
 	}
-	az := AZ{
+	// the choice is made once, so a VM placed before migrating, retried or converted again is placed on the
+	// same target
+	if p, ok := c.placement(sourceVM); ok {
+		return p.Target, nil
+	}
+
+	targets, err := c.TargetComputesFromSourceAZ(AZ{
+		Datacenter:   sourceVM.Datacenter,
+		Cluster:      sourceVM.Cluster,
+		ResourcePool: sourceVM.ResourcePool,
+		Name:         sourceVM.AZ,
+	})
+	if err != nil {
+		return AZ{}, err
+	}
+	target := c.strategy.Place(sourceVM, c.sourceClusters(sourceVM.AZ), targets)
+	c.record(sourceVM, target, c.strategy.Name())
+	return target, nil
+}
+
+// TargetComputeInCluster returns the source VM's target compute in the named target cluster, which must be one
+// of the targets mapped to the VM's source AZ
+func (c *MappedCompute) TargetComputeInCluster(sourceVM *vcenter.VM, cluster string) (AZ, error) {
+	if sourceVM == nil {
+		return AZ{}, fmt.Errorf("expected source VM to be non-nil")
+	}
+	targets, err := c.TargetComputesFromSourceAZ(AZ{
 		Datacenter:   sourceVM.Datacenter,
 		Cluster:      sourceVM.Cluster,
 		ResourcePool: sourceVM.ResourcePool,
 		Name:         sourceVM.AZ,
+	})
+	if err != nil {
+		return AZ{}, err
 	}
-	return c.TargetComputeFromSourceAZ(az)
+	for _, t := range targets {
+		if inventory.Same(t.Cluster, cluster) {
+			c.record(sourceVM, t, placementOverride)
+			return t, nil
+		}
+	}
+	return AZ{}, fmt.Errorf("could not place VM %s in cluster %s: the cluster is not a target of source AZ %s cluster %s",
+		sourceVM.Name, cluster, sourceVM.AZ, sourceVM.Cluster)
 }
 
 func (c *MappedCompute) TargetComputeFromSourceAZ(srcAZ AZ) (AZ, error) {
@@ -66,8 +156,12 @@ func (c *MappedCompute) TargetComputeFromSourceAZ(srcAZ AZ) (AZ, error) {
 		return AZ{}, err
 	}
 
-	// pick a target at random
-	return t[rand.Intn(len(t))], nil
+	return c.strategy.Place(&vcenter.VM{
+		AZ:           srcAZ.Name,
+		Datacenter:   srcAZ.Datacenter,
+		Cluster:      srcAZ.Cluster,
+		ResourcePool: srcAZ.ResourcePool,
+	}, c.sourceClusters(srcAZ.Name), t), nil
 }
 
 func (c *MappedCompute) TargetComputesFromSourceAZ(srcCompute AZ) ([]AZ, error) {
@@ -111,6 +205,42 @@ func (c *MappedCompute) Add(source AZ, target AZ) *MappedCompute {
 	return c
 }
 
+// sourceClusters returns the AZ's unique source clusters in config order
+func (c *MappedCompute) sourceClusters(azName string) []string {
+	var clusters []string
+	seen := map[string]bool{}
+	for _, m := range c.azMappings {
+		if strings.EqualFold(m.Source.Name, azName) && !seen[m.Source.Cluster] {
+			seen[m.Source.Cluster] = true
+			clusters = append(clusters, m.Source.Cluster)
+		}
+	}
+	return clusters
+}
+
+func (c *MappedCompute) placement(sourceVM *vcenter.VM) (Placement, bool) {
+	c.placementsMutex.Lock()
+	defer c.placementsMutex.Unlock()
+	p, ok := c.placements[placementKey(sourceVM)]
+	return p, ok
+}
+
+func (c *MappedCompute) record(sourceVM *vcenter.VM, target AZ, strategy string) {
+	c.placementsMutex.Lock()
+	defer c.placementsMutex.Unlock()
+	c.placements[placementKey(sourceVM)] = Placement{
+		VM:            sourceVM.Name,
+		SourceAZ:      sourceVM.AZ,
+		SourceCluster: sourceVM.Cluster,
+		Target:        target,
+		Strategy:      strategy,
+	}
+}
+
+func placementKey(sourceVM *vcenter.VM) string {
+	return strings.Join([]string{sourceVM.AZ, sourceVM.Cluster, sourceVM.ResourcePool, sourceVM.Name}, "/")
+}
+
 func isDefaultResourcePool(rp string) bool {
 	return rp == defaultResourcePoolName
 }
@@ -19,12 +19,15 @@ type DatastoreMapper interface {
 
 type ComputeMapper interface {
 	TargetCompute(sourceVM *vcenter.VM) (AZ, error)
+	TargetComputeInCluster(sourceVM *vcenter.VM, cluster string) (AZ, error)
 }
 
 type Converter struct {
 	netMapper     NetworkMapper
 	dsMapper      DatastoreMapper
 	computeMapper ComputeMapper
+	folders       *MappedFolder
+	overrides     []Override
 }
 
 func New(net NetworkMapper, ds DatastoreMapper, cm ComputeMapper) *Converter {
@@ -35,22 +38,68 @@ func New(net NetworkMapper, ds DatastoreMapper, cm ComputeMapper) *Converter {
 	}
 }
 
+// WithOverrides places the VMs matching an override using the override instead of the mappings, the first
+// matching override wins
+func (c *Converter) WithOverrides(overrides []Override) *Converter {
+	c.overrides = overrides
+	return c
+}
+
+// WithFolders rewrites the source VM folder paths using the folder mapping prefixes instead of only replacing
+// the datacenter
+func (c *Converter) WithFolders(folders *MappedFolder) *Converter {
+	c.folders = folders
+	return c
+}
+
 func (c *Converter) TargetSpec(sourceVM *vcenter.VM) (*vcenter.TargetSpec, error) {
-	nets, err := c.netMapper.TargetNetworks(sourceVM)
-	if err != nil {
-		return nil, err
+	o := c.override(sourceVM)
+
+	var nets map[string]string
+	var err error
+	if o.Network != "" {
+		nets = map[string]string{}
+		for _, src := range sourceVM.Networks {
+			nets[src] = o.Network
+		}
+	} else {
+		nets, err = c.netMapper.TargetNetworks(sourceVM)
+		if err != nil {
+			return nil, err
+		}
 	}
-	datastores, err := c.dsMapper.TargetDatastores(sourceVM)
-	if err != nil {
-		return nil, err
+
+	var datastores map[string]string
+	if o.Datastore != "" {
+		datastores = map[string]string{}
+		for _, d := range sourceVM.Disks {
+			datastores[d.Datastore] = o.Datastore
+		}
+	} else {
+		datastores, err = c.dsMapper.TargetDatastores(sourceVM)
+		if err != nil {
+			return nil, err
+		}
 	}
-	compute, err := c.computeMapper.TargetCompute(sourceVM)
+
+	compute, err := c.targetCompute(sourceVM, o)
 	if err != nil {
 		return nil, err
 	}
-	targetFolder, err := TargetFolder(sourceVM.Folder, compute.Datacenter)
-	if err != nil {
-		return nil, err
+
+	var targetFolder string
+	if o.Folder != "" {
+		targetFolder = o.TargetFolder(compute.Datacenter)
+	} else if c.folders != nil {
+		targetFolder, err = c.folders.TargetFolder(sourceVM.Folder, compute.Datacenter)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		targetFolder, err = TargetFolder(sourceVM.Folder, compute.Datacenter)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &vcenter.TargetSpec{
@@ -63,3 +112,36 @@ func (c *Converter) TargetSpec(sourceVM *vcenter.VM) (*vcenter.TargetSpec, error
 		Networks:     nets,
 	}, nil
 }
+
+// TargetCompute places the source VM on its target compute using any matching override, otherwise the
+// compute mappings. A VM is always placed on the same target compute, so this can be called before TargetSpec to
+// place the VMs ahead of migrating them.
+func (c *Converter) TargetCompute(sourceVM *vcenter.VM) (AZ, error) {
+	return c.targetCompute(sourceVM, c.override(sourceVM))
+}
+
+func (c *Converter) targetCompute(sourceVM *vcenter.VM, o Override) (AZ, error) {
+	var compute AZ
+	var err error
+	if o.Cluster != "" {
+		compute, err = c.computeMapper.TargetComputeInCluster(sourceVM, o.Cluster)
+	} else {
+		compute, err = c.computeMapper.TargetCompute(sourceVM)
+	}
+	if err != nil {
+		return AZ{}, err
+	}
+	if o.ResourcePool != "" {
+		compute.ResourcePool = o.ResourcePool
+	}
+	return compute, nil
+}
+
+func (c *Converter) override(sourceVM *vcenter.VM) Override {
+	for _, o := range c.overrides {
+		if o.Matches(sourceVM) {
+			return o
+		}
+	}
+	return Override{}
+}
@@ -480,3 +480,71 @@ func TestMappedConverterCrossDatacenter(t *testing.T) {
 		})
 	}
 }
+
+func TestMappedConverterWithFolders(t *testing.T) {
+	cm := converter.NewEmptyMappedCompute()
+	cm.Add(converter.AZ{
+		Datacenter: "sDC",
+		Name:       "az1",
+		Cluster:    "CL",
+	}, converter.AZ{
+		Datacenter: "tDC",
+		Name:       "az1",
+		Cluster:    "CL",
+	})
+	c := converter.New(converter.NewMappedNetwork(map[string]string{}), converter.NewMappedDatastore(map[string]string{}), cm).
+		WithFolders(converter.NewMappedFolder(map[string]string{
+			"/sDC/vm/pcf_vms": "/tDC/vm/foundations/prod-tas",
+		})).
+		WithOverrides([]converter.Override{{VM: "vm-2", Folder: "pinned"}})
+
+	spec, err := c.TargetSpec(&vcenter.VM{Name: "vm-1", AZ: "az1", Datacenter: "sDC", Cluster: "CL", Folder: "/sDC/vm/pcf_vms/guid"})
+	require.NoError(t, err)
+	require.Equal(t, "/tDC/vm/foundations/prod-tas/guid", spec.Folder)
+
+	// unmapped folders keep their path in the target datacenter
+	spec, err = c.TargetSpec(&vcenter.VM{Name: "vm-1", AZ: "az1", Datacenter: "sDC", Cluster: "CL", Folder: "/sDC/vm/other"})
+	require.NoError(t, err)
+	require.Equal(t, "/tDC/vm/other", spec.Folder)
+
+	// an override folder takes precedence over the folder mappings
+	spec, err = c.TargetSpec(&vcenter.VM{Name: "vm-2", AZ: "az1", Datacenter: "sDC", Cluster: "CL", Folder: "/sDC/vm/pcf_vms/guid"})
+	require.NoError(t, err)
+	require.Equal(t, "/tDC/vm/pinned", spec.Folder)
+}
+
+func TestMappedConverterNestedInventory(t *testing.T) {
+	cm := converter.NewEmptyMappedCompute()
+	cm.Add(converter.AZ{
+		Datacenter: "Region1/sDC",
+		Name:       "az1",
+		Cluster:    "/Region1/sDC/host/Folder1/sC",
+	}, converter.AZ{
+		Datacenter: "/Region2/tDC",
+		Name:       "az1",
+		Cluster:    "Folder2/tC",
+	})
+	c := converter.New(converter.NewMappedNetwork(map[string]string{}), converter.NewMappedDatastore(map[string]string{}), cm)
+
+	// vCenter reports the VM's cluster name and folder inventory path
+	spec, err := c.TargetSpec(&vcenter.VM{
+		Name:       "vm-1",
+		AZ:         "az1",
+		Datacenter: "Region1/sDC",
+		Cluster:    "sC",
+		Folder:     "/Region1/sDC/vm/pcf_vms/guid",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "/Region2/tDC", spec.Datacenter)
+	require.Equal(t, "Folder2/tC", spec.Cluster)
+	require.Equal(t, "/Region2/tDC/vm/pcf_vms/guid", spec.Folder)
+
+	_, err = c.TargetSpec(&vcenter.VM{
+		Name:       "vm-2",
+		AZ:         "az1",
+		Datacenter: "Region1/sDC",
+		Cluster:    "Folder2/sC",
+		Folder:     "/Region1/sDC/vm",
+	})
+	require.Error(t, err)
+}
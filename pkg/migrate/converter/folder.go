@@ -7,23 +7,186 @@ package converter
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/inventory"
 )
 
+// TargetFolder returns the source VM folder path in the target datacenter, keeping the path under the datacenter's
+// VM folder. Either datacenter may be nested in folders, e.g. /Region1/DC1/vm/pcf_vms
 func TargetFolder(sourceVMFolder string, targetDatacenter string) (string, error) {
+	_, relative, err := SplitVMFolder(sourceVMFolder)
+	if err != nil {
+		return "", err
+	}
+
+	targetFolder := "/" + strings.Trim(targetDatacenter, "/") + "/vm"
+	if relative != "" {
+		targetFolder += "/" + relative
+	}
+	return targetFolder, nil
+}
+
+// SplitVMFolder splits a VM folder path into its datacenter path and the path relative to the datacenter's VM
+// folder, e.g. /Region1/DC1/vm/pcf_vms/guid is Region1/DC1 and pcf_vms/guid
+func SplitVMFolder(folderPath string) (string, string, error) {
 	splitFn := func(c rune) bool {
 		return c == '/'
 	}
 
-	p := strings.FieldsFunc(sourceVMFolder, splitFn)
+	p := strings.FieldsFunc(folderPath, splitFn)
 	if len(p) < 2 {
-		return "", fmt.Errorf("expected a source VM folder path of at least 2 parts, but got '%s'", sourceVMFolder)
+		return "", "", fmt.Errorf("expected a source VM folder path of at least 2 parts, but got '%s'", folderPath)
+	}
+	for i := 1; i < len(p); i++ {
+		if p[i] == "vm" {
+			return strings.Join(p[:i], "/"), strings.Join(p[i+1:], "/"), nil
+		}
+	}
+	return "", "", fmt.Errorf("expected a source VM folder path to contain 'vm' in path under datacenter, but got '%s'", p[1])
+}
+
+// MappedFolder rewrites VM folder path prefixes, folders without a matching prefix keep their path under the target
+// datacenter's VM folder
+type MappedFolder struct {
+	folderMap map[string]string
+}
+
+func NewEmptyMappedFolder() *MappedFolder {
+	return NewMappedFolder(map[string]string{})
+}
+
+func NewMappedFolder(folderMap map[string]string) *MappedFolder {
+	m := &MappedFolder{
+		folderMap: map[string]string{},
+	}
+	for src, target := range folderMap {
+		m.Add(src, target)
 	}
-	if p[1] != "vm" {
-		return "", fmt.Errorf("expected a source VM folder path to contain 'vm' in path under datacenter, but got '%s'", p[1])
+	return m
+}
+
+func (m *MappedFolder) Add(srcFolder, targetFolder string) *MappedFolder {
+	m.folderMap[cleanFolderPath(srcFolder)] = cleanFolderPath(targetFolder)
+	return m
+}
+
+// TargetFolder replaces the longest source prefix matching whole path elements with its target prefix, a prefix's
+// datacenter may be the name or inventory path of the source folder's datacenter. It returns an error if the mapped
+// folder isn't in the target datacenter, as a VM can't be placed in another datacenter's folder.
+func (m *MappedFolder) TargetFolder(sourceVMFolder string, targetDatacenter string) (string, error) {
+	sourceDC, sourceRelative, err := SplitVMFolder(sourceVMFolder)
+	if err != nil {
+		return "", err
+	}
+
+	var prefixes []string
+	for prefix := range m.folderMap {
+		prefixes = append(prefixes, prefix)
 	}
+	sort.Strings(prefixes)
 
-	p[0] = targetDatacenter
-	targetFolder := "/" + strings.Join(p, "/")
+	longest := -1
+	var matched, targetFolder string
+	for _, prefix := range prefixes {
+		prefixDC, prefixRelative, err := SplitVMFolder(prefix)
+		if err != nil || !inventory.Same(prefixDC, sourceDC) || len(prefixRelative) <= longest {
+			continue
+		}
+		var rest string
+		switch {
+		case prefixRelative == sourceRelative:
+		case prefixRelative == "":
+			rest = "/" + sourceRelative
+		case strings.HasPrefix(sourceRelative, prefixRelative+"/"):
+			rest = strings.TrimPrefix(sourceRelative, prefixRelative)
+		default:
+			continue
+		}
+		longest = len(prefixRelative)
+		matched = prefix
+		targetFolder = m.folderMap[prefix] + rest
+	}
+	if longest < 0 {
+		return TargetFolder(sourceVMFolder, targetDatacenter)
+	}
+	if !InDatacenterVMFolder(targetFolder, targetDatacenter) {
+		return "", fmt.Errorf("folder mapping %s target %s is not in the VM's target datacenter %s",
+			matched, m.folderMap[matched], targetDatacenter)
+	}
 	return targetFolder, nil
 }
+
+// IsTargetFolder returns true if TargetFolder places the VMs of some folder in the source datacenter in the target
+// folder, either because the folder is under a mapped target folder or it keeps the path of an unmapped source folder
+func (m *MappedFolder) IsTargetFolder(targetFolder, sourceDatacenter, targetDatacenter string) bool {
+	targetDC, targetRelative, err := SplitVMFolder(targetFolder)
+	if err != nil {
+		return false
+	}
+
+	// the source folders the target folder could have come from, which TargetFolder must map back to it
+	var sourceFolders []string
+	if inventory.Same(targetDC, targetDatacenter) {
+		sourceFolders = append(sourceFolders, joinFolderPath("/"+strings.Trim(sourceDatacenter, "/")+"/vm", targetRelative))
+	}
+	for prefix, target := range m.folderMap {
+		prefixDC, _, err := SplitVMFolder(prefix)
+		if err != nil || !inventory.Same(prefixDC, sourceDatacenter) {
+			continue
+		}
+		mappedDC, mappedRelative, err := SplitVMFolder(target)
+		if err != nil || !inventory.Same(mappedDC, targetDC) {
+			continue
+		}
+		switch {
+		case mappedRelative == targetRelative:
+			sourceFolders = append(sourceFolders, prefix)
+		case mappedRelative == "":
+			sourceFolders = append(sourceFolders, joinFolderPath(prefix, targetRelative))
+		case strings.HasPrefix(targetRelative, mappedRelative+"/"):
+			sourceFolders = append(sourceFolders, prefix+strings.TrimPrefix(targetRelative, mappedRelative))
+		}
+	}
+
+	for _, src := range sourceFolders {
+		mapped, err := m.TargetFolder(src, targetDatacenter)
+		if err == nil && SameVMFolder(mapped, targetFolder) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinFolderPath(folderPath, relative string) string {
+	if relative == "" {
+		return folderPath
+	}
+	return folderPath + "/" + relative
+}
+
+func cleanFolderPath(folderPath string) string {
+	return "/" + strings.Trim(folderPath, "/")
+}
+
+// InDatacenterVMFolder returns true if the folder is under the datacenter's VM folder, the datacenter may be a name
+// or an inventory path
+func InDatacenterVMFolder(folderPath, datacenter string) bool {
+	dcPath, _, err := SplitVMFolder(folderPath)
+	return err == nil && inventory.Same(dcPath, datacenter)
+}
+
+// SameVMFolder returns true if both VM folder paths are the same folder in the same datacenter, where a datacenter
+// nested in folders matches its name, e.g. /Region1/DC1/vm/pcf_vms and /DC1/vm/pcf_vms
+func SameVMFolder(a, b string) bool {
+	aDC, aRelative, err := SplitVMFolder(a)
+	if err != nil {
+		return false
+	}
+	bDC, bRelative, err := SplitVMFolder(b)
+	if err != nil {
+		return false
+	}
+	return aRelative == bRelative && inventory.Same(aDC, bDC)
+}
@@ -39,6 +39,13 @@ var testTargetFolderTests = []struct {
 		"/tDC/vm/guid/path",
 		"",
 	},
+	{
+		"VM in nested datacenter",
+		"/Region1/sDC/vm/guid/path",
+		"Region2/tDC",
+		"/Region2/tDC/vm/guid/path",
+		"",
+	},
 	{
 		"VM with missing path",
 		"",
@@ -69,3 +76,105 @@ func TestTargetFolder(t *testing.T) {
 		})
 	}
 }
+
+var testMappedFolderTests = []struct {
+	name         string
+	inFolder     string
+	inDatacenter string
+	outPath      string
+}{
+	{
+		"VM in mapped folder",
+		"/sDC/vm/pcf_vms",
+		"tDC",
+		"/tDC/vm/foundations/prod-tas",
+	},
+	{
+		"VM in mapped sub-folder",
+		"/sDC/vm/pcf_vms/guid",
+		"tDC",
+		"/tDC/vm/foundations/prod-tas/guid",
+	},
+	{
+		"VM in longest mapped folder",
+		"/sDC/vm/pcf_vms/special/guid",
+		"tDC",
+		"/tDC/vm/special/guid",
+	},
+	{
+		"VM in folder sharing a name prefix",
+		"/sDC/vm/pcf_vms_old/guid",
+		"tDC",
+		"/tDC/vm/pcf_vms_old/guid",
+	},
+	{
+		"VM in nested datacenter folder",
+		"/Region1/sDC/vm/pcf_templates/guid",
+		"tDC",
+		"/Region2/tDC/vm/templates/guid",
+	},
+	{
+		"VM in folder mapped by datacenter path",
+		"/sDC/vm/pcf_templates/guid",
+		"tDC",
+		"/Region2/tDC/vm/templates/guid",
+	},
+	{
+		"VM in unmapped folder",
+		"/sDC/vm/other",
+		"tDC",
+		"/tDC/vm/other",
+	},
+}
+
+func TestMappedFolderTargetFolder(t *testing.T) {
+	m := converter.NewMappedFolder(map[string]string{
+		"/sDC/vm/pcf_vms":              "/tDC/vm/foundations/prod-tas/",
+		"/sDC/vm/pcf_vms/special":      "/tDC/vm/special",
+		"Region1/sDC/vm/pcf_templates": "/Region2/tDC/vm/templates",
+	})
+	for _, tt := range testMappedFolderTests {
+		t.Run(tt.name, func(t *testing.T) {
+			outPath, err := m.TargetFolder(tt.inFolder, tt.inDatacenter)
+			require.NoError(t, err)
+			require.Equal(t, tt.outPath, outPath)
+		})
+	}
+}
+
+func TestMappedFolderTargetFolderInOtherDatacenter(t *testing.T) {
+	m := converter.NewMappedFolder(map[string]string{
+		"/sDC/vm/pcf_vms": "/tDC2/vm/foundations/prod-tas",
+	})
+	_, err := m.TargetFolder("/sDC/vm/pcf_vms/guid", "tDC")
+	require.EqualError(t, err, "folder mapping /sDC/vm/pcf_vms target /tDC2/vm/foundations/prod-tas is not in the VM's target datacenter tDC")
+}
+
+func TestMappedFolderIsTargetFolder(t *testing.T) {
+	m := converter.NewMappedFolder(map[string]string{
+		"/sDC/vm/pcf_vms":              "/tDC/vm/foundations/prod-tas/",
+		"/sDC/vm/pcf_vms/special":      "/tDC/vm/special",
+		"Region1/sDC/vm/pcf_templates": "/Region2/tDC/vm/templates",
+	})
+	for _, tt := range testMappedFolderTests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.True(t, m.IsTargetFolder(tt.outPath, "sDC", tt.inDatacenter))
+		})
+	}
+
+	// a mapped source folder doesn't keep its path
+	require.False(t, m.IsTargetFolder("/tDC/vm/pcf_vms/guid", "sDC", "tDC"))
+	require.False(t, m.IsTargetFolder("/tDC/vm/pcf_templates/guid", "sDC", "tDC"))
+	require.False(t, m.IsTargetFolder("/tDC2/vm/other", "sDC", "tDC"))
+}
+
+func TestSameVMFolder(t *testing.T) {
+	require.True(t, converter.SameVMFolder("/Region1/DC1/vm/pcf_vms", "/DC1/vm/pcf_vms"))
+	require.True(t, converter.SameVMFolder("/DC1/vm/pcf_vms/", "/DC1/vm/pcf_vms"))
+	require.False(t, converter.SameVMFolder("/DC1/vm/pcf_vms", "/DC2/vm/pcf_vms"))
+	require.False(t, converter.SameVMFolder("/DC1/vm/pcf_vms", "/DC1/vm/pcf_vms/guid"))
+
+	require.True(t, converter.InDatacenterVMFolder("/Region1/DC1/vm/pcf_vms", "DC1"))
+	require.True(t, converter.InDatacenterVMFolder("/Region1/DC1/vm", "/Region1/DC1"))
+	require.False(t, converter.InDatacenterVMFolder("/Region1/DC1/vm/pcf_vms", "DC2"))
+}
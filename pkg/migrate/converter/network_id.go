@@ -0,0 +1,160 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+// NetworkIdentityLister lists the networks in a vCenter datacenter with their VLAN or NSX segment IDs
+type NetworkIdentityLister interface {
+	NetworkIdentities(ctx context.Context) ([]vcenter.NetworkIdentity, error)
+}
+
+// IDMappedNet maps each source network to the target network backed by the same VLAN ID or NSX segment,
+// any exact network mappings take precedence
+type IDMappedNet struct {
+	networkMap   map[string]string
+	targetSwitch string
+	sources      []vcenter.NetworkIdentity
+	targets      []vcenter.NetworkIdentity
+}
+
+func NewIDMappedNetwork(networkMap map[string]string, targetSwitch string) *IDMappedNet {
+	return &IDMappedNet{
+		networkMap:   networkMap,
+		targetSwitch: targetSwitch,
+	}
+}
+
+// Load lists the source and target network identities, this must be called before TargetNetworks
+func (m *IDMappedNet) Load(ctx context.Context, sources, targets []NetworkIdentityLister) error {
+	for _, s := range sources {
+		ids, err := s.NetworkIdentities(ctx)
+		if err != nil {
+			return fmt.Errorf("could not list source network VLAN and segment IDs: %w", err)
+		}
+		m.AddSource(ids...)
+	}
+	for _, t := range targets {
+		ids, err := t.NetworkIdentities(ctx)
+		if err != nil {
+			return fmt.Errorf("could not list target network VLAN and segment IDs: %w", err)
+		}
+		m.AddTarget(ids...)
+	}
+	return nil
+}
+
+func (m *IDMappedNet) AddSource(ids ...vcenter.NetworkIdentity) *IDMappedNet {
+	m.sources = append(m.sources, ids...)
+	return m
+}
+
+func (m *IDMappedNet) AddTarget(ids ...vcenter.NetworkIdentity) *IDMappedNet {
+	m.targets = append(m.targets, ids...)
+	return m
+}
+
+func (m *IDMappedNet) TargetNetworks(sourceVM *vcenter.VM) (map[string]string, error) {
+	targetNetworks := map[string]string{}
+	for _, src := range sourceVM.Networks {
+		if target, ok := m.networkMap[src]; ok {
+			targetNetworks[src] = target
+			continue
+		}
+		id, err := m.sourceID(sourceVM.Datacenter, src)
+		if err != nil {
+			return nil, fmt.Errorf("could not find a target network for VM %s attached to network %s: %w",
+				sourceVM.Name, src, err)
+		}
+		target, err := m.targetWithID(id)
+		if err != nil {
+			return nil, fmt.Errorf("could not find a target network for VM %s attached to network %s: %w",
+				sourceVM.Name, src, err)
+		}
+		targetNetworks[src] = target
+	}
+	return targetNetworks, nil
+}
+
+// MappedTargetNetworks returns the sorted names of every target network a source network can be mapped to, either
+// by an exact mapping or by sharing a VLAN or NSX segment ID with a source network
+func (m *IDMappedNet) MappedTargetNetworks() []string {
+	var names []string
+	for _, t := range m.networkMap {
+		names = appendUnique(names, t)
+	}
+	sourceIDs := map[string]bool{}
+	for _, s := range m.sources {
+		if s.ID != "" {
+			sourceIDs[s.ID] = true
+		}
+	}
+	for _, t := range m.targets {
+		if sourceIDs[t.ID] && (m.targetSwitch == "" || t.Switch == m.targetSwitch) {
+			names = appendUnique(names, t.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (m *IDMappedNet) sourceID(datacenter, name string) (string, error) {
+	var ids []string
+	for _, s := range m.sources {
+		if s.Name == name && strings.EqualFold(s.Datacenter, datacenter) {
+			ids = appendUnique(ids, s.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return "", fmt.Errorf("the source network was not found in datacenter %s", datacenter)
+	}
+	if len(ids) > 1 {
+		return "", fmt.Errorf("found multiple source networks with the same name and different IDs: %s, "+
+			"add a network mapping to the config file", strings.Join(ids, ", "))
+	}
+	if ids[0] == "" {
+		return "", fmt.Errorf("the source network doesn't have a VLAN or NSX segment ID, " +
+			"add a network mapping to the config file")
+	}
+	return ids[0], nil
+}
+
+func (m *IDMappedNet) targetWithID(id string) (string, error) {
+	var names []string
+	for _, t := range m.targets {
+		if t.ID == id && (m.targetSwitch == "" || t.Switch == m.targetSwitch) {
+			names = appendUnique(names, t.Name)
+		}
+	}
+	if len(names) == 0 {
+		if m.targetSwitch != "" {
+			return "", fmt.Errorf("no target network on switch %s has ID %s", m.targetSwitch, id)
+		}
+		return "", fmt.Errorf("no target network has ID %s", id)
+	}
+	if len(names) > 1 {
+		sort.Strings(names)
+		return "", fmt.Errorf("found multiple target networks with ID %s: %s, set network_target_switch "+
+			"or add a network mapping to the config file", id, strings.Join(names, ", "))
+	}
+	return names[0], nil
+}
+
+func appendUnique(items []string, item string) []string {
+	for _, i := range items {
+		if i == item {
+			return items
+		}
+	}
+	return append(items, item)
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/converter"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+func newIDMappedNetwork(targetSwitch string) *converter.IDMappedNet {
+	return converter.NewIDMappedNetwork(map[string]string{"sExact": "tExact"}, targetSwitch).
+		AddSource(
+			vcenter.NetworkIdentity{Datacenter: "sDC", Name: "sNet1", Switch: "sDVS", ID: "vlan-100"},
+			vcenter.NetworkIdentity{Datacenter: "sDC", Name: "sSegment", ID: "nsx-ls-1"},
+			vcenter.NetworkIdentity{Datacenter: "sDC", Name: "sStandard"},
+			vcenter.NetworkIdentity{Datacenter: "sDC", Name: "sNet2", Switch: "sDVS", ID: "vlan-200"},
+		).
+		AddTarget(
+			vcenter.NetworkIdentity{Datacenter: "tDC", Name: "tNet1", Switch: "tDVS", ID: "vlan-100"},
+			vcenter.NetworkIdentity{Datacenter: "tDC", Name: "tSegment", Switch: "tDVS", ID: "nsx-ls-1"},
+			vcenter.NetworkIdentity{Datacenter: "tDC", Name: "tNet2", Switch: "tDVS", ID: "vlan-200"},
+			vcenter.NetworkIdentity{Datacenter: "tDC", Name: "tNet2-old", Switch: "tDVS-old", ID: "vlan-200"},
+		)
+}
+
+func TestIDMappedNetwork(t *testing.T) {
+	m := newIDMappedNetwork("")
+	nets, err := m.TargetNetworks(&vcenter.VM{
+		Name:       "vm1",
+		Datacenter: "sDC",
+		Networks:   []string{"sNet1", "sSegment", "sExact"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"sNet1":    "tNet1",
+		"sSegment": "tSegment",
+		"sExact":   "tExact",
+	}, nets)
+}
+
+func TestIDMappedNetworkAmbiguousTarget(t *testing.T) {
+	m := newIDMappedNetwork("")
+	_, err := m.TargetNetworks(&vcenter.VM{Name: "vm1", Datacenter: "sDC", Networks: []string{"sNet2"}})
+	require.EqualError(t, err, "could not find a target network for VM vm1 attached to network sNet2: "+
+		"found multiple target networks with ID vlan-200: tNet2, tNet2-old, set network_target_switch "+
+		"or add a network mapping to the config file")
+
+	m = newIDMappedNetwork("tDVS")
+	nets, err := m.TargetNetworks(&vcenter.VM{Name: "vm1", Datacenter: "sDC", Networks: []string{"sNet2"}})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"sNet2": "tNet2"}, nets)
+}
+
+func TestIDMappedNetworkErrors(t *testing.T) {
+	m := newIDMappedNetwork("tDVS-old")
+	_, err := m.TargetNetworks(&vcenter.VM{Name: "vm1", Datacenter: "sDC", Networks: []string{"sNet1"}})
+	require.EqualError(t, err, "could not find a target network for VM vm1 attached to network sNet1: "+
+		"no target network on switch tDVS-old has ID vlan-100")
+
+	_, err = m.TargetNetworks(&vcenter.VM{Name: "vm1", Datacenter: "sDC", Networks: []string{"sStandard"}})
+	require.EqualError(t, err, "could not find a target network for VM vm1 attached to network sStandard: "+
+		"the source network doesn't have a VLAN or NSX segment ID, add a network mapping to the config file")
+
+	_, err = m.TargetNetworks(&vcenter.VM{Name: "vm1", Datacenter: "otherDC", Networks: []string{"sNet1"}})
+	require.EqualError(t, err, "could not find a target network for VM vm1 attached to network sNet1: "+
+		"the source network was not found in datacenter otherDC")
+}
+
+func TestIDMappedNetworkMappedTargetNetworks(t *testing.T) {
+	require.Equal(t, []string{"tExact", "tNet1", "tNet2", "tNet2-old", "tSegment"},
+		newIDMappedNetwork("").MappedTargetNetworks())
+	require.Equal(t, []string{"tExact", "tNet1", "tNet2", "tSegment"},
+		newIDMappedNetwork("tDVS").MappedTargetNetworks())
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"path"
+	"strings"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+// Override pins the VMs matching all of its non-empty selectors to its non-empty placement fields, the
+// selectors may contain glob wildcards
+type Override struct {
+	VM            string
+	Deployment    string
+	InstanceGroup string
+
+	Cluster      string
+	ResourcePool string
+	Folder       string
+	Datastore    string
+	Network      string
+}
+
+// Matches returns true if the VM matches all the override's selectors
+func (o Override) Matches(vm *vcenter.VM) bool {
+	if o.VM == "" && o.Deployment == "" && o.InstanceGroup == "" {
+		return false
+	}
+	return globMatches(o.VM, vm.Name) &&
+		globMatches(o.Deployment, vm.Deployment) &&
+		globMatches(o.InstanceGroup, vm.InstanceGroup)
+}
+
+// TargetFolder returns the override folder path in the target datacenter
+func (o Override) TargetFolder(targetDatacenter string) string {
+	return "/" + strings.Trim(targetDatacenter, "/") + "/vm/" + strings.Trim(o.Folder, "/")
+}
+
+func globMatches(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/converter"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+func newOverrideConverter(overrides ...converter.Override) *converter.Converter {
+	net := converter.NewMappedNetwork(map[string]string{"sN": "tN"})
+	ds := converter.NewMappedDatastore(map[string]string{"sDS": "tDS"})
+	cm := converter.NewEmptyMappedCompute()
+	source := converter.AZ{Datacenter: "sDC", Name: "az1", Cluster: "sC"}
+	cm.Add(source, converter.AZ{Datacenter: "tDC", Name: "az1", Cluster: "tC1"})
+	cm.Add(source, converter.AZ{Datacenter: "tDC", Name: "az1", Cluster: "tC2"})
+	return converter.New(net, ds, cm).WithOverrides(overrides)
+}
+
+func overrideSourceVM(deployment, instanceGroup string) *vcenter.VM {
+	return &vcenter.VM{
+		Name:          "vm-1",
+		AZ:            "az1",
+		Datacenter:    "sDC",
+		Cluster:       "sC",
+		Folder:        "/sDC/vm/pcf_vms",
+		Disks:         []vcenter.Disk{{ID: 201, Datastore: "sDS"}, {ID: 202, Datastore: "sDS2"}},
+		Networks:      []string{"sN"},
+		Deployment:    deployment,
+		InstanceGroup: instanceGroup,
+	}
+}
+
+func TestConverterWithOverride(t *testing.T) {
+	c := newOverrideConverter(
+		converter.Override{
+			Deployment:    "pivotal-mysql-*",
+			InstanceGroup: "mysql",
+			Cluster:       "tC2",
+			ResourcePool:  "tRP-mysql",
+			Folder:        "mysql",
+			Datastore:     "all-flash",
+			Network:       "tN-mysql",
+		},
+		converter.Override{
+			Deployment: "pivotal-mysql-*",
+			Cluster:    "tC1",
+		})
+
+	spec, err := c.TargetSpec(overrideSourceVM("pivotal-mysql-guid", "mysql"))
+	require.NoError(t, err)
+	require.Equal(t, &vcenter.TargetSpec{
+		Name:         "vm-1",
+		Datacenter:   "tDC",
+		Cluster:      "tC2",
+		ResourcePool: "tRP-mysql",
+		Folder:       "/tDC/vm/mysql",
+		Datastores:   map[string]string{"sDS": "all-flash", "sDS2": "all-flash"},
+		Networks:     map[string]string{"sN": "tN-mysql"},
+	}, spec)
+
+	// only the cluster is overridden, everything else uses the mappings
+	vm := overrideSourceVM("pivotal-mysql-guid", "mysql-jumpbox")
+	vm.Disks = vm.Disks[:1]
+	spec, err = c.TargetSpec(vm)
+	require.NoError(t, err)
+	require.Equal(t, &vcenter.TargetSpec{
+		Name:       "vm-1",
+		Datacenter: "tDC",
+		Cluster:    "tC1",
+		Folder:     "/tDC/vm/pcf_vms",
+		Datastores: map[string]string{"sDS": "tDS"},
+		Networks:   map[string]string{"sN": "tN"},
+	}, spec)
+}
+
+func TestConverterWithOverrideByVMName(t *testing.T) {
+	c := newOverrideConverter(converter.Override{VM: "vm-1", Cluster: "tC1"})
+	vm := overrideSourceVM("", "")
+	vm.Disks = vm.Disks[:1]
+
+	// without the override the cluster is picked at random
+	for i := 0; i < 10; i++ {
+		spec, err := c.TargetSpec(vm)
+		require.NoError(t, err)
+		require.Equal(t, "tC1", spec.Cluster)
+	}
+}
+
+func TestConverterWithOverrideClusterNotInAZ(t *testing.T) {
+	c := newOverrideConverter(converter.Override{VM: "vm-1", Cluster: "tC3"})
+	vm := overrideSourceVM("", "")
+	vm.Disks = vm.Disks[:1]
+	_, err := c.TargetSpec(vm)
+	require.EqualError(t, err, "could not place VM vm-1 in cluster tC3: the cluster is not a target of "+
+		"source AZ az1 cluster sC")
+}
+
+func TestOverrideMatches(t *testing.T) {
+	vm := overrideSourceVM("cf-guid", "diego_cell")
+	require.True(t, converter.Override{Deployment: "cf-*"}.Matches(vm))
+	require.True(t, converter.Override{Deployment: "cf-guid", InstanceGroup: "diego_cell"}.Matches(vm))
+	require.False(t, converter.Override{Deployment: "cf-guid", InstanceGroup: "router"}.Matches(vm))
+	require.False(t, converter.Override{VM: "vm-2"}.Matches(vm))
+	require.False(t, converter.Override{Cluster: "tC1"}.Matches(vm))
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/inventory"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+// PlacementStrategy chooses the target compute for a VM when its source cluster maps to multiple target clusters
+type PlacementStrategy interface {
+	Name() string
+	Place(sourceVM *vcenter.VM, sourceClusters []string, targets []AZ) AZ
+}
+
+// NewPlacementStrategy returns the named placement strategy, an empty name is round-robin
+func NewPlacementStrategy(name string) (PlacementStrategy, error) {
+	switch name {
+	case "", config.PlacementRoundRobin:
+		return NewRoundRobinPlacement(), nil
+	case config.PlacementLeastLoaded:
+		return NewLeastLoadedPlacement(), nil
+	case config.PlacementPreserveClusterIndex:
+		return &PreserveClusterIndexPlacement{}, nil
+	case config.PlacementSpreadInstanceGroup:
+		return NewSpreadInstanceGroupPlacement(), nil
+	}
+	return nil, fmt.Errorf("unknown placement strategy %s", name)
+}
+
+// RoundRobinPlacement places VMs on each target in turn, separately for every set of targets
+type RoundRobinPlacement struct {
+	mutex sync.Mutex
+	next  map[string]int
+}
+
+func NewRoundRobinPlacement() *RoundRobinPlacement {
+	return &RoundRobinPlacement{
+		next: map[string]int{},
+	}
+}
+
+func (p *RoundRobinPlacement) Name() string {
+	return config.PlacementRoundRobin
+}
+
+func (p *RoundRobinPlacement) Place(_ *vcenter.VM, _ []string, targets []AZ) AZ {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	key := targetsKey(targets)
+	i := p.next[key]
+	p.next[key] = (i + 1) % len(targets)
+	return targets[i]
+}
+
+// LeastLoadedPlacement places VMs on the target cluster with the most free memory, subtracting each placed
+// VM's memory so the VMs are balanced across clusters
+type LeastLoadedPlacement struct {
+	mutex  sync.Mutex
+	freeMB map[string]int64
+}
+
+func NewLeastLoadedPlacement() *LeastLoadedPlacement {
+	return &LeastLoadedPlacement{
+		freeMB: map[string]int64{},
+	}
+}
+
+func (p *LeastLoadedPlacement) Name() string {
+	return config.PlacementLeastLoaded
+}
+
+// SetFreeMemory sets the cluster's free memory, clusters without a free memory are treated as full
+func (p *LeastLoadedPlacement) SetFreeMemory(datacenter, cluster string, freeMB int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.freeMB[clusterKey(datacenter, cluster)] = freeMB
+}
+
+func (p *LeastLoadedPlacement) Place(sourceVM *vcenter.VM, _ []string, targets []AZ) AZ {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	best := targets[0]
+	for _, t := range targets[1:] {
+		if p.freeMB[clusterKey(t.Datacenter, t.Cluster)] > p.freeMB[clusterKey(best.Datacenter, best.Cluster)] {
+			best = t
+		}
+	}
+	p.freeMB[clusterKey(best.Datacenter, best.Cluster)] -= int64(sourceVM.MemoryMB)
+	return best
+}
+
+// PreserveClusterIndexPlacement places VMs from the Nth source cluster in the AZ on the Nth target cluster,
+// wrapping around when there are fewer target clusters
+type PreserveClusterIndexPlacement struct{}
+
+func (p *PreserveClusterIndexPlacement) Name() string {
+	return config.PlacementPreserveClusterIndex
+}
+
+func (p *PreserveClusterIndexPlacement) Place(sourceVM *vcenter.VM, sourceClusters []string, targets []AZ) AZ {
+	for i, c := range sourceClusters {
+		if inventory.Same(c, sourceVM.Cluster) {
+			return targets[i%len(targets)]
+		}
+	}
+	return targets[0]
+}
+
+// SpreadInstanceGroupPlacement places each VM on the target cluster with the fewest VMs from the same BOSH
+// deployment instance group, breaking ties with the cluster with the fewest VMs overall
+type SpreadInstanceGroupPlacement struct {
+	mutex       sync.Mutex
+	groupCounts map[string]map[string]int
+	counts      map[string]int
+}
+
+func NewSpreadInstanceGroupPlacement() *SpreadInstanceGroupPlacement {
+	return &SpreadInstanceGroupPlacement{
+		groupCounts: map[string]map[string]int{},
+		counts:      map[string]int{},
+	}
+}
+
+func (p *SpreadInstanceGroupPlacement) Name() string {
+	return config.PlacementSpreadInstanceGroup
+}
+
+func (p *SpreadInstanceGroupPlacement) Place(sourceVM *vcenter.VM, _ []string, targets []AZ) AZ {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	group := sourceVM.AZ + "/" + sourceVM.Deployment + "/" + sourceVM.InstanceGroup
+	if p.groupCounts[group] == nil {
+		p.groupCounts[group] = map[string]int{}
+	}
+	groupCounts := p.groupCounts[group]
+
+	best := targets[0]
+	for _, t := range targets[1:] {
+		tk, bk := clusterKey(t.Datacenter, t.Cluster), clusterKey(best.Datacenter, best.Cluster)
+		if groupCounts[tk] < groupCounts[bk] || (groupCounts[tk] == groupCounts[bk] && p.counts[tk] < p.counts[bk]) {
+			best = t
+		}
+	}
+	bk := clusterKey(best.Datacenter, best.Cluster)
+	groupCounts[bk]++
+	p.counts[bk]++
+	return best
+}
+
+func clusterKey(datacenter, cluster string) string {
+	return datacenter + "/" + cluster
+}
+
+func targetsKey(targets []AZ) string {
+	var keys []string
+	for _, t := range targets {
+		keys = append(keys, clusterKey(t.Datacenter, t.Cluster)+"/"+t.ResourcePool)
+	}
+	return strings.Join(keys, ",")
+}
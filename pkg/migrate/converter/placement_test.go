@@ -0,0 +1,138 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package converter_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/converter"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+// two source clusters each mapped to three target clusters
+func newPlacementMappedCompute(strategy converter.PlacementStrategy) *converter.MappedCompute {
+	cm := converter.NewEmptyMappedCompute().WithPlacementStrategy(strategy)
+	for _, sc := range []string{"sC1", "sC2"} {
+		for _, tc := range []string{"tC1", "tC2", "tC3"} {
+			cm.Add(converter.AZ{Datacenter: "sDC", Cluster: sc, Name: "az1"},
+				converter.AZ{Datacenter: "tDC", Cluster: tc, Name: "az1"})
+		}
+	}
+	return cm
+}
+
+func placementVM(name, cluster, instanceGroup string) *vcenter.VM {
+	return &vcenter.VM{
+		Name:          name,
+		AZ:            "az1",
+		Datacenter:    "sDC",
+		Cluster:       cluster,
+		Deployment:    "cf",
+		InstanceGroup: instanceGroup,
+		MemoryMB:      1024,
+	}
+}
+
+func targetClusters(t *testing.T, cm *converter.MappedCompute, vms ...*vcenter.VM) []string {
+	var clusters []string
+	for _, vm := range vms {
+		az, err := cm.TargetCompute(vm)
+		require.NoError(t, err)
+		clusters = append(clusters, az.Cluster)
+	}
+	return clusters
+}
+
+func TestNewPlacementStrategy(t *testing.T) {
+	for _, name := range []string{
+		config.PlacementRoundRobin,
+		config.PlacementLeastLoaded,
+		config.PlacementPreserveClusterIndex,
+		config.PlacementSpreadInstanceGroup,
+	} {
+		s, err := converter.NewPlacementStrategy(name)
+		require.NoError(t, err)
+		require.Equal(t, name, s.Name())
+	}
+
+	s, err := converter.NewPlacementStrategy("")
+	require.NoError(t, err)
+	require.Equal(t, config.PlacementRoundRobin, s.Name())
+
+	_, err = converter.NewPlacementStrategy("random")
+	require.EqualError(t, err, "unknown placement strategy random")
+}
+
+func TestRoundRobinPlacement(t *testing.T) {
+	cm := newPlacementMappedCompute(converter.NewRoundRobinPlacement())
+	require.Equal(t, []string{"tC1", "tC2", "tC3", "tC1", "tC1"}, targetClusters(t, cm,
+		placementVM("vm1", "sC1", ""),
+		placementVM("vm2", "sC1", ""),
+		placementVM("vm3", "sC1", ""),
+		placementVM("vm4", "sC1", ""),
+		// the choice is only made once per VM
+		placementVM("vm1", "sC1", "")))
+}
+
+func TestLeastLoadedPlacement(t *testing.T) {
+	s := converter.NewLeastLoadedPlacement()
+	s.SetFreeMemory("tDC", "tC1", 1024)
+	s.SetFreeMemory("tDC", "tC2", 3072)
+	s.SetFreeMemory("tDC", "tC3", 2048)
+	cm := newPlacementMappedCompute(s)
+	require.Equal(t, []string{"tC2", "tC2", "tC3", "tC1"}, targetClusters(t, cm,
+		placementVM("vm1", "sC1", ""),
+		placementVM("vm2", "sC1", ""),
+		placementVM("vm3", "sC1", ""),
+		placementVM("vm4", "sC1", "")))
+}
+
+func TestPreserveClusterIndexPlacement(t *testing.T) {
+	cm := newPlacementMappedCompute(&converter.PreserveClusterIndexPlacement{})
+	require.Equal(t, []string{"tC1", "tC2", "tC1"}, targetClusters(t, cm,
+		placementVM("vm1", "sC1", ""),
+		placementVM("vm2", "sC2", ""),
+		placementVM("vm3", "sC1", "")))
+}
+
+func TestSpreadInstanceGroupPlacement(t *testing.T) {
+	cm := newPlacementMappedCompute(converter.NewSpreadInstanceGroupPlacement())
+	require.Equal(t, []string{"tC1", "tC2", "tC3", "tC1", "tC2", "tC3"}, targetClusters(t, cm,
+		placementVM("router1", "sC1", "router"),
+		placementVM("router2", "sC2", "router"),
+		placementVM("router3", "sC1", "router"),
+		placementVM("router4", "sC2", "router"),
+		placementVM("db1", "sC1", "database"),
+		// tC3 has the fewest VMs overall
+		placementVM("diego1", "sC1", "diego_cell")))
+}
+
+func TestPlacementsAreRecorded(t *testing.T) {
+	cm := newPlacementMappedCompute(converter.NewRoundRobinPlacement())
+	_, err := cm.TargetCompute(placementVM("vm2", "sC1", ""))
+	require.NoError(t, err)
+	_, err = cm.TargetComputeInCluster(placementVM("vm1", "sC2", ""), "tC3")
+	require.NoError(t, err)
+
+	require.Equal(t, []converter.Placement{
+		{
+			VM:            "vm1",
+			SourceAZ:      "az1",
+			SourceCluster: "sC2",
+			Target:        converter.AZ{Datacenter: "tDC", Cluster: "tC3", Name: "az1"},
+			Strategy:      "override",
+		},
+		{
+			VM:            "vm2",
+			SourceAZ:      "az1",
+			SourceCluster: "sC1",
+			Target:        converter.AZ{Datacenter: "tDC", Cluster: "tC1", Name: "az1"},
+			Strategy:      "round-robin",
+		},
+	}, cm.Placements())
+}
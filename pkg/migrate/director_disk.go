@@ -0,0 +1,195 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/bosh"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/proxy"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+//counterfeiter:generate . DirectorDiskVCenterClient
+type DirectorDiskVCenterClient interface {
+	Datacenter() string
+	FindDisk(ctx context.Context, azName, diskPath string, datastores []string) (*vcenter.DatastoreDisk, error)
+	PersistentDisk(ctx context.Context, azName, vmName string) (*vcenter.DatastoreDisk, error)
+	DetachDisk(ctx context.Context, vmName string, disk *vcenter.DatastoreDisk, target *vcenter.DatastoreDisk) error
+}
+
+// DirectorDiskMigrator moves the migrated BOSH director's persistent disk into the CPI disk path and
+// updates the director's BOSH state to match, replacing the manual Operations Manager bosh-state.json edits
+type DirectorDiskMigrator struct {
+	DryRun bool
+
+	clientPool       *vcenter.Pool
+	targetDatastores []string
+	diskPath         string
+	updatableStdout  UpdatableLogger
+	mappingRules     *MappingRules
+	dialer           *proxy.Dialer
+}
+
+func NewDirectorDiskMigrator(clientPool *vcenter.Pool, datastoreMap map[string]string, diskPath string,
+	out UpdatableLogger) *DirectorDiskMigrator {
+
+	if diskPath == "" {
+		diskPath = DefaultDiskPath
+	}
+	return &DirectorDiskMigrator{
+		clientPool:       clientPool,
+		targetDatastores: targetDatastores(datastoreMap),
+		diskPath:         diskPath,
+		updatableStdout:  out,
+	}
+}
+
+// NewDirectorDiskMigratorFromConfig creates a new DirectorDiskMigrator instance from the specified config
+func NewDirectorDiskMigratorFromConfig(c config.Config) (*DirectorDiskMigrator, error) {
+	dialer, err := proxy.NewDialer(c.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	diskPath := ""
+	if c.Bosh != nil {
+		diskPath = c.Bosh.DiskPath
+	}
+	clientPool := ConfigToVCenterClientPool(c, dialer)
+	out := log.NewUpdatableStdout()
+	m := NewDirectorDiskMigrator(clientPool, c.DatastoreMap, diskPath, out).
+		WithMappingRules(NewMappingRules(c.NetworkNameMap(), c.DatastoreNameMap(), out))
+	m.dialer = dialer
+	return m.WithDryRun(c.DryRun), nil
+}
+
+func (m *DirectorDiskMigrator) WithDryRun(dryRun bool) *DirectorDiskMigrator {
+	m.DryRun = dryRun
+	return m
+}
+
+// WithMappingRules resolves the datastore rules against the source vCenters to find the target datastores a
+// previous run may have moved the disk to
+func (m *DirectorDiskMigrator) WithMappingRules(mappingRules *MappingRules) *DirectorDiskMigrator {
+	m.mappingRules = mappingRules
+	return m
+}
+
+// Migrate finds the director VM in the target vCenter(s) and moves its persistent disk, updating the state
+func (m *DirectorDiskMigrator) Migrate(ctx context.Context, state *bosh.State) error {
+	if m.dialer != nil {
+		defer closeDialer(ctx, m.dialer)
+	}
+	defer m.clientPool.Close(ctx)
+
+	var sourceClients []InventoryClient
+	for _, c := range uniqueClients(m.clientPool.GetSourceClients()) {
+		sourceClients = append(sourceClients, c)
+	}
+	err := m.ResolveMappingRules(ctx, sourceClients)
+	if err != nil {
+		return err
+	}
+
+	for _, az := range m.clientPool.TargetAZs() {
+		err := m.MigrateWithClient(ctx, m.clientPool.GetTargetClientByAZ(az), az, state)
+		var e *vcenter.VMNotFoundError
+		if errors.As(err, &e) {
+			continue
+		}
+		return err
+	}
+	return fmt.Errorf("could not find director VM %s in any target vCenter", state.VMCID())
+}
+
+// ResolveMappingRules adds the targets of any datastore rules to the target datastores using the source vCenter clients
+func (m *DirectorDiskMigrator) ResolveMappingRules(ctx context.Context, sourceClients []InventoryClient) error {
+	if m.mappingRules == nil {
+		return nil
+	}
+	resolved, err := m.mappingRules.Resolve(ctx, sourceClients)
+	if err != nil {
+		return err
+	}
+	m.targetDatastores = targetDatastores(resolved.Datastores)
+	return nil
+}
+
+// MigrateWithClient moves the director VM's persistent disk using the specified target vCenter client
+func (m *DirectorDiskMigrator) MigrateWithClient(ctx context.Context, client DirectorDiskVCenterClient, az string, state *bosh.State) error {
+	l := log.FromContext(ctx)
+
+	vmCID := state.VMCID()
+	if vmCID == "" {
+		return fmt.Errorf("could not find current_vm_cid in BOSH state")
+	}
+	stateDisk, err := state.CurrentDisk()
+	if err != nil {
+		return err
+	}
+	diskCID := bosh.DiskCIDWithoutMetadata(stateDisk.CID)
+	diskPath := path.Join(m.diskPath, vcenter.DiskFileName(diskCID))
+
+	l.Debugf("Looking for director VM %s persistent disk in AZ %s", vmCID, az)
+	disk, err := client.PersistentDisk(ctx, az, vmCID)
+	if err != nil {
+		var e *vcenter.DiskNotFoundError
+		if !errors.As(err, &e) {
+			return err
+		}
+
+		// the disk may have already been moved by a previous run
+		existing, findErr := client.FindDisk(ctx, az, diskPath, m.targetDatastores)
+		if findErr != nil {
+			return fmt.Errorf("director VM %s has no persistent disk attached and %s was not found: %w",
+				vmCID, diskPath, findErr)
+		}
+		m.updatableStdout.PrintUpdatablef(vmCID, "Director persistent disk already at %s", existing.DatastorePath())
+	} else {
+		target := &vcenter.DatastoreDisk{
+			AZ:         az,
+			Datacenter: client.Datacenter(),
+			Datastore:  disk.Datastore,
+			Path:       diskPath,
+		}
+		if m.DryRun {
+			m.updatableStdout.PrintUpdatablef(vmCID, "Would detach director persistent disk %s and move it to %s",
+				disk.DatastorePath(), target.DatastorePath())
+		} else {
+			m.updatableStdout.PrintUpdatablef(vmCID, "Detaching director persistent disk %s and moving it to %s",
+				disk.DatastorePath(), target.DatastorePath())
+			err = client.DetachDisk(ctx, vmCID, disk, target)
+			if err != nil {
+				return fmt.Errorf("could not move director persistent disk: %w", err)
+			}
+			m.updatableStdout.PrintUpdatablef(vmCID, "Moved director persistent disk to %s", target.DatastorePath())
+		}
+	}
+
+	if diskCID != stateDisk.CID {
+		m.updatableStdout.PrintUpdatablef(stateDisk.ID, "Updating BOSH state disk CID %s to %s", stateDisk.CID, diskCID)
+	}
+	return state.SetDiskCID(stateDisk.ID, diskCID)
+}
+
+// targetDatastores returns the sorted unique target datastores of the datastore mappings
+func targetDatastores(datastoreMap map[string]string) []string {
+	var result []string
+	for _, ds := range datastoreMap {
+		if !containsString(result, ds) {
+			result = append(result, ds)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
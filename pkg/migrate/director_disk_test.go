@@ -0,0 +1,145 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/bosh"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/migratefakes"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+const directorState = `{
+  "current_vm_cid": "vm-guid",
+  "current_disk_id": "1",
+  "disks": [{"id": "1", "cid": "disk-guid.eyJ0YXJnZXRfZGF0YXN0b3JlX3BhdHRlcm4iOiJEUzEifQ", "size": 65536}]
+}`
+
+func newDirectorDiskClient() *migratefakes.FakeDirectorDiskVCenterClient {
+	client := &migratefakes.FakeDirectorDiskVCenterClient{}
+	client.DatacenterReturns("DC2")
+	client.PersistentDiskReturns(&vcenter.DatastoreDisk{
+		AZ:         "az1",
+		Datacenter: "DC2",
+		Datastore:  "DS2",
+		Path:       "vm-guid/vm-guid_2.vmdk",
+	}, nil)
+	return client
+}
+
+func requireStateDiskCID(t *testing.T, state *bosh.State, expected string) {
+	d, err := state.CurrentDisk()
+	require.NoError(t, err)
+	require.Equal(t, expected, d.CID)
+}
+
+func TestDirectorDiskMigrator_MovesDiskAndUpdatesState(t *testing.T) {
+	state, err := bosh.NewState([]byte(directorState))
+	require.NoError(t, err)
+	client := newDirectorDiskClient()
+
+	m := migrate.NewDirectorDiskMigrator(&vcenter.Pool{}, map[string]string{"DS1": "DS2"}, "", log.NewBufferedStdout())
+	err = m.MigrateWithClient(context.Background(), client, "az1", state)
+	require.NoError(t, err)
+
+	_, az, vmName := client.PersistentDiskArgsForCall(0)
+	require.Equal(t, "az1", az)
+	require.Equal(t, "vm-guid", vmName)
+
+	require.Equal(t, 1, client.DetachDiskCallCount())
+	_, vmName, disk, target := client.DetachDiskArgsForCall(0)
+	require.Equal(t, "vm-guid", vmName)
+	require.Equal(t, "[DS2] vm-guid/vm-guid_2.vmdk", disk.DatastorePath())
+	require.Equal(t, &vcenter.DatastoreDisk{
+		AZ:         "az1",
+		Datacenter: "DC2",
+		Datastore:  "DS2",
+		Path:       "pcf_disk/disk-guid.vmdk",
+	}, target)
+
+	requireStateDiskCID(t, state, "disk-guid")
+}
+
+func TestDirectorDiskMigrator_DiskAlreadyMoved(t *testing.T) {
+	state, err := bosh.NewState([]byte(directorState))
+	require.NoError(t, err)
+	client := newDirectorDiskClient()
+	client.PersistentDiskReturns(nil, vcenter.NewDiskNotFoundError("vm-guid", errors.New("VM has no persistent disk attached")))
+	client.FindDiskReturns(&vcenter.DatastoreDisk{Datastore: "DS2", Path: "custom_disk/disk-guid.vmdk"}, nil)
+
+	m := migrate.NewDirectorDiskMigrator(&vcenter.Pool{}, map[string]string{"DS1": "DS2"}, "custom_disk", log.NewBufferedStdout())
+	err = m.MigrateWithClient(context.Background(), client, "az1", state)
+	require.NoError(t, err)
+
+	_, _, diskPath, datastores := client.FindDiskArgsForCall(0)
+	require.Equal(t, "custom_disk/disk-guid.vmdk", diskPath)
+	require.Equal(t, []string{"DS2"}, datastores)
+	require.Equal(t, 0, client.DetachDiskCallCount())
+
+	requireStateDiskCID(t, state, "disk-guid")
+}
+
+func TestDirectorDiskMigrator_DiskAlreadyMovedByDatastoreRule(t *testing.T) {
+	state, err := bosh.NewState([]byte(directorState))
+	require.NoError(t, err)
+	client := newDirectorDiskClient()
+	client.PersistentDiskReturns(nil, vcenter.NewDiskNotFoundError("vm-guid", errors.New("VM has no persistent disk attached")))
+	client.FindDiskReturns(&vcenter.DatastoreDisk{Datastore: "new-DS1", Path: "pcf_disk/disk-guid.vmdk"}, nil)
+	inventory := &migratefakes.FakeInventoryClient{}
+	inventory.InventoryReturns(&vcenter.Inventory{Datastores: []string{"old-DS1"}}, nil)
+
+	out := log.NewBufferedStdout()
+	datastores := config.NameMap{
+		Kind:  "datastore",
+		Exact: map[string]string{"DS1": "DS2"},
+		Rules: []config.MappingRule{{Glob: "old-*", Target: "new-$1"}},
+	}
+	m := migrate.NewDirectorDiskMigrator(&vcenter.Pool{}, datastores.Exact, "", out).
+		WithMappingRules(migrate.NewMappingRules(config.NameMap{Kind: "network"}, datastores, out))
+	err = m.ResolveMappingRules(context.Background(), []migrate.InventoryClient{inventory})
+	require.NoError(t, err)
+	err = m.MigrateWithClient(context.Background(), client, "az1", state)
+	require.NoError(t, err)
+
+	_, _, _, targetDatastores := client.FindDiskArgsForCall(0)
+	require.Equal(t, []string{"DS2", "new-DS1"}, targetDatastores)
+}
+
+func TestDirectorDiskMigrator_DiskNotFound(t *testing.T) {
+	state, err := bosh.NewState([]byte(directorState))
+	require.NoError(t, err)
+	client := newDirectorDiskClient()
+	client.PersistentDiskReturns(nil, vcenter.NewDiskNotFoundError("vm-guid", errors.New("VM has no persistent disk attached")))
+	client.FindDiskReturns(nil, vcenter.NewDiskNotFoundError("pcf_disk/disk-guid.vmdk", errors.New("not found")))
+
+	m := migrate.NewDirectorDiskMigrator(&vcenter.Pool{}, map[string]string{"DS1": "DS2"}, "", log.NewBufferedStdout())
+	err = m.MigrateWithClient(context.Background(), client, "az1", state)
+	require.ErrorContains(t, err, "director VM vm-guid has no persistent disk attached and pcf_disk/disk-guid.vmdk was not found")
+
+	requireStateDiskCID(t, state, "disk-guid.eyJ0YXJnZXRfZGF0YXN0b3JlX3BhdHRlcm4iOiJEUzEifQ")
+}
+
+func TestDirectorDiskMigrator_DryRun(t *testing.T) {
+	state, err := bosh.NewState([]byte(directorState))
+	require.NoError(t, err)
+	client := newDirectorDiskClient()
+
+	out := log.NewBufferedStdout()
+	m := migrate.NewDirectorDiskMigrator(&vcenter.Pool{}, map[string]string{"DS1": "DS2"}, "", out).WithDryRun(true)
+	err = m.MigrateWithClient(context.Background(), client, "az1", state)
+	require.NoError(t, err)
+
+	require.Equal(t, 0, client.DetachDiskCallCount())
+	require.Contains(t, out.String(), "Would detach director persistent disk [DS2] vm-guid/vm-guid_2.vmdk and move it to [DS2] pcf_disk/disk-guid.vmdk")
+	requireStateDiskCID(t, state, "disk-guid")
+}
@@ -0,0 +1,268 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/bosh"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/converter"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+// DefaultDiskPath is the datastore directory the vSphere CPI places persistent disks in
+const DefaultDiskPath = "pcf_disk"
+
+// diskVMPrefix is prepended to the disk name to create the temporary VM used to move a disk across vCenters
+const diskVMPrefix = "vmotion4bosh-"
+
+//counterfeiter:generate . DiskVCenterClient
+type DiskVCenterClient interface {
+	VCenterClient
+	FindDisk(ctx context.Context, azName, diskPath string, datastores []string) (*vcenter.DatastoreDisk, error)
+	MoveDisk(ctx context.Context, disk *vcenter.DatastoreDisk, target *vcenter.DatastoreDisk) error
+	CreateDiskVM(ctx context.Context, vmName string, disk *vcenter.DatastoreDisk, cluster, resourcePool string) (*vcenter.VM, error)
+	DeleteDiskVM(ctx context.Context, vmName string, target *vcenter.DatastoreDisk) error
+}
+
+// Disk is a BOSH orphaned disk
+type Disk struct {
+	CID string
+	AZ  string
+
+	// true when the disk's AZ isn't in the compute config, the disk is reported but not migrated
+	Unconfigured bool
+}
+
+// DiskMigrator moves BOSH orphaned disks to their mapped target datastore. Disks are moved directly
+// when the source and target are the same vCenter, otherwise they're attached to a temporary VM which
+// is migrated to the target vCenter.
+type DiskMigrator struct {
+	DryRun bool
+
+	clientPool   *vcenter.Pool
+	vmMigrator   *VMMigrator
+	datastoreMap map[string]string
+	computeMap   []converter.AZMapping
+	diskPath     string
+}
+
+func NewDiskMigrator(clientPool *vcenter.Pool, vmMigrator *VMMigrator, datastoreMap map[string]string,
+	computeMap []converter.AZMapping, diskPath string) *DiskMigrator {
+
+	if diskPath == "" {
+		diskPath = DefaultDiskPath
+	}
+	return &DiskMigrator{
+		clientPool:   clientPool,
+		vmMigrator:   vmMigrator,
+		datastoreMap: datastoreMap,
+		computeMap:   computeMap,
+		diskPath:     diskPath,
+	}
+}
+
+func (m *DiskMigrator) WithDryRun(dryRun bool) *DiskMigrator {
+	m.DryRun = dryRun
+	return m
+}
+
+func (m *DiskMigrator) Migrate(ctx context.Context, disk Disk) error {
+	sourceClient := m.clientPool.GetSourceClientByAZ(disk.AZ)
+	if sourceClient == nil {
+		return fmt.Errorf("could not find source vcenter client for disk %s in AZ %s", disk.CID, disk.AZ)
+	}
+	targetClient := m.clientPool.GetTargetClientByAZ(disk.AZ)
+	if targetClient == nil {
+		return fmt.Errorf("could not find target vcenter client for disk %s in AZ %s", disk.CID, disk.AZ)
+	}
+	return m.MigrateDiskToTarget(ctx, sourceClient, targetClient, disk)
+}
+
+func (m *DiskMigrator) MigrateDiskToTarget(ctx context.Context, sourceClient, targetClient DiskVCenterClient, disk Disk) error {
+	out := m.vmMigrator
+	out.printProcessing(ctx, disk.CID, "preparing")
+
+	fileName := vcenter.DiskFileName(disk.CID)
+	diskPath := path.Join(m.diskPath, fileName)
+	vmName := diskVMPrefix + strings.TrimSuffix(fileName, ".vmdk")
+
+	srcDisk, err := sourceClient.FindDisk(ctx, disk.AZ, diskPath, m.sourceDatastores())
+	if err != nil {
+		var e *vcenter.DiskNotFoundError
+		if !errors.As(err, &e) {
+			out.printFailure(ctx, disk.CID, err)
+			return err
+		}
+
+		// a previous run may have been interrupted after migrating the temporary VM
+		_, vmErr := targetClient.FindVMInClusters(ctx, disk.AZ, vmName, m.targetClusters(disk.AZ))
+		if vmErr != nil {
+			out.printSuccess(ctx, disk.CID, "not found in source vCenter, skipping")
+			return nil
+		}
+		return m.deleteDiskVM(ctx, targetClient, vmName, disk, diskPath)
+	}
+
+	targetDS, ok := m.datastoreMap[srcDisk.Datastore]
+	if !ok {
+		err = fmt.Errorf("could not find a target datastore mapping for disk %s on datastore %s",
+			disk.CID, srcDisk.Datastore)
+		out.printFailure(ctx, disk.CID, err)
+		return err
+	}
+	err = checkDatastorePattern(disk.CID, targetDS)
+	if err != nil {
+		out.printFailure(ctx, disk.CID, err)
+		return err
+	}
+	target := &vcenter.DatastoreDisk{
+		AZ:         disk.AZ,
+		Datacenter: targetClient.Datacenter(),
+		Datastore:  targetDS,
+		Path:       diskPath,
+	}
+
+	if m.DryRun {
+		out.printSuccess(ctx, disk.CID, "dry-run, would move to "+target.DatastorePath())
+		return nil
+	}
+
+	if sourceClient.HostName() == targetClient.HostName() {
+		out.printProcessing(ctx, disk.CID, "moving")
+		err = sourceClient.MoveDisk(ctx, srcDisk, target)
+		if err != nil {
+			out.printFailure(ctx, disk.CID, err)
+			return err
+		}
+		out.printSuccess(ctx, disk.CID, "done")
+		return nil
+	}
+
+	// the disk is attached to a temporary VM so it can be migrated across vCenters
+	srcCompute, err := m.sourceCompute(disk.AZ)
+	if err != nil {
+		out.printFailure(ctx, disk.CID, err)
+		return err
+	}
+	_, err = sourceClient.FindVMInClusters(ctx, disk.AZ, vmName, []string{srcCompute.Cluster})
+	if err != nil {
+		var e *vcenter.VMNotFoundError
+		if !errors.As(err, &e) {
+			out.printFailure(ctx, disk.CID, err)
+			return err
+		}
+		out.printProcessing(ctx, disk.CID, "creating temporary VM "+vmName)
+		_, err = sourceClient.CreateDiskVM(ctx, vmName, srcDisk, srcCompute.Cluster, srcCompute.ResourcePool)
+		if err != nil {
+			out.printFailure(ctx, disk.CID, err)
+			return err
+		}
+	}
+
+	out.printProcessing(ctx, disk.CID, "migrating temporary VM "+vmName)
+	err = m.vmMigrator.MigrateVMToTarget(ctx, sourceClient, VM{
+		Name:     vmName,
+		AZ:       disk.AZ,
+		Clusters: []string{srcCompute.Cluster},
+	})
+	if err != nil {
+		out.printFailure(ctx, disk.CID, err)
+		return err
+	}
+
+	return m.deleteDiskVM(ctx, targetClient, vmName, disk, diskPath)
+}
+
+func (m *DiskMigrator) deleteDiskVM(ctx context.Context, targetClient DiskVCenterClient, vmName string, disk Disk, diskPath string) error {
+	out := m.vmMigrator
+	out.printProcessing(ctx, disk.CID, "deleting temporary VM "+vmName)
+
+	targetDS, err := m.targetDatastore(ctx, disk.AZ, targetClient, vmName)
+	if err != nil {
+		out.printFailure(ctx, disk.CID, err)
+		return err
+	}
+	err = targetClient.DeleteDiskVM(ctx, vmName, &vcenter.DatastoreDisk{
+		AZ:         disk.AZ,
+		Datacenter: targetClient.Datacenter(),
+		Datastore:  targetDS,
+		Path:       diskPath,
+	})
+	if err != nil {
+		out.printFailure(ctx, disk.CID, err)
+		return err
+	}
+	out.printSuccess(ctx, disk.CID, "done")
+	return nil
+}
+
+// targetDatastore returns the datastore the migrated temporary VM's disk is on, the disk is
+// moved into the disk path on that same datastore
+func (m *DiskMigrator) targetDatastore(ctx context.Context, az string, targetClient DiskVCenterClient, vmName string) (string, error) {
+	vm, err := targetClient.FindVMInClusters(ctx, az, vmName, m.targetClusters(az))
+	if err != nil {
+		return "", err
+	}
+	if len(vm.Disks) != 1 {
+		return "", fmt.Errorf("expected VM %s to have 1 disk but found %d", vmName, len(vm.Disks))
+	}
+	return vm.Disks[0].Datastore, nil
+}
+
+// checkDatastorePattern returns an error if the disk CID's encoded target_datastore_pattern doesn't match the
+// target datastore, as the CPI would then fail to attach the disk from there
+func checkDatastorePattern(cid, targetDatastore string) error {
+	pattern := bosh.DiskCIDDatastorePattern(cid)
+	if pattern == "" {
+		return nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid disk %s target_datastore_pattern %s: %w", cid, pattern, err)
+	}
+	if !re.MatchString(targetDatastore) {
+		return fmt.Errorf("disk %s target datastore %s does not match its target_datastore_pattern %s",
+			cid, targetDatastore, pattern)
+	}
+	return nil
+}
+
+func (m *DiskMigrator) sourceDatastores() []string {
+	var datastores []string
+	for ds := range m.datastoreMap {
+		datastores = append(datastores, ds)
+	}
+	sort.Strings(datastores)
+	return datastores
+}
+
+func (m *DiskMigrator) sourceCompute(az string) (converter.AZ, error) {
+	for _, cm := range m.computeMap {
+		if cm.Source.Name == az {
+			return cm.Source, nil
+		}
+	}
+	return converter.AZ{}, fmt.Errorf("could not find a source cluster for AZ %s", az)
+}
+
+func (m *DiskMigrator) targetClusters(az string) []string {
+	var clusters []string
+	seen := map[string]bool{}
+	for _, cm := range m.computeMap {
+		if cm.Source.Name == az && !seen[cm.Target.Cluster] {
+			seen[cm.Target.Cluster] = true
+			clusters = append(clusters, cm.Target.Cluster)
+		}
+	}
+	return clusters
+}
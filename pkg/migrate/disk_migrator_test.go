@@ -0,0 +1,195 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/converter"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/migratefakes"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+var diskComputeMap = []converter.AZMapping{
+	{
+		Source: converter.AZ{Name: "az1", Datacenter: "DC1", Cluster: "Cluster1", ResourcePool: "RP1"},
+		Target: converter.AZ{Name: "az1", Datacenter: "DC2", Cluster: "Cluster2", ResourcePool: "RP2"},
+	},
+}
+
+// the CID metadata target_datastore_pattern is ^DS[0-9]$
+var orphanedDisk = migrate.Disk{
+	CID: "disk-guid.eyJ0YXJnZXRfZGF0YXN0b3JlX3BhdHRlcm4iOiJeRFNbMC05XSQifQ",
+	AZ:  "az1",
+}
+
+func newDiskMigrator(vmRelocator migrate.VMRelocator, out migrate.UpdatableLogger) *migrate.DiskMigrator {
+	vmConverter := converter.New(
+		converter.NewEmptyMappedNetwork(),
+		converter.NewEmptyMappedDatastore().Add("DS1", "DS2"),
+		converter.NewMappedCompute(diskComputeMap))
+	vmMigrator := migrate.NewVMMigrator(&vcenter.Pool{}, vmConverter, vmRelocator, out)
+	return migrate.NewDiskMigrator(&vcenter.Pool{}, vmMigrator, map[string]string{"DS1": "DS2"}, diskComputeMap, "")
+}
+
+func newDiskClients(sameVCenter bool) (*migratefakes.FakeDiskVCenterClient, *migratefakes.FakeDiskVCenterClient) {
+	sourceClient := &migratefakes.FakeDiskVCenterClient{}
+	sourceClient.HostNameReturns("vcenter1.example.com")
+	sourceClient.DatacenterReturns("DC1")
+	sourceClient.FindDiskReturns(&vcenter.DatastoreDisk{
+		AZ:         "az1",
+		Datacenter: "DC1",
+		Datastore:  "DS1",
+		Path:       "pcf_disk/disk-guid.vmdk",
+	}, nil)
+
+	targetClient := &migratefakes.FakeDiskVCenterClient{}
+	targetClient.HostNameReturns("vcenter2.example.com")
+	if sameVCenter {
+		targetClient.HostNameReturns("vcenter1.example.com")
+	}
+	targetClient.DatacenterReturns("DC2")
+	return sourceClient, targetClient
+}
+
+func TestDiskMigrator_SameVCenterMovesDisk(t *testing.T) {
+	sourceClient, targetClient := newDiskClients(true)
+	vmRelocator := &migratefakes.FakeVMRelocator{}
+
+	m := newDiskMigrator(vmRelocator, log.NewBufferedStdout())
+	err := m.MigrateDiskToTarget(context.Background(), sourceClient, targetClient, orphanedDisk)
+	require.NoError(t, err)
+
+	_, az, diskPath, datastores := sourceClient.FindDiskArgsForCall(0)
+	require.Equal(t, "az1", az)
+	require.Equal(t, "pcf_disk/disk-guid.vmdk", diskPath)
+	require.Equal(t, []string{"DS1"}, datastores)
+
+	require.Equal(t, 1, sourceClient.MoveDiskCallCount())
+	_, disk, target := sourceClient.MoveDiskArgsForCall(0)
+	require.Equal(t, "[DS1] pcf_disk/disk-guid.vmdk", disk.DatastorePath())
+	require.Equal(t, &vcenter.DatastoreDisk{
+		AZ:         "az1",
+		Datacenter: "DC2",
+		Datastore:  "DS2",
+		Path:       "pcf_disk/disk-guid.vmdk",
+	}, target)
+	require.Equal(t, 0, sourceClient.CreateDiskVMCallCount())
+	require.Equal(t, 0, vmRelocator.RelocateVMCallCount())
+}
+
+func TestDiskMigrator_CrossVCenterMigratesDiskVM(t *testing.T) {
+	sourceClient, targetClient := newDiskClients(false)
+	diskVM := &vcenter.VM{
+		Name:         "vmotion4bosh-disk-guid",
+		AZ:           "az1",
+		Datacenter:   "DC1",
+		Cluster:      "Cluster1",
+		ResourcePool: "RP1",
+		Folder:       "/DC1/vm",
+		Disks:        []vcenter.Disk{{ID: 201, Datastore: "DS1"}},
+	}
+	sourceClient.FindVMInClustersReturnsOnCall(0, nil, vcenter.NewVMNotFoundError("vmotion4bosh-disk-guid", errors.New("not found")))
+	sourceClient.FindVMInClustersReturnsOnCall(1, diskVM, nil)
+	targetClient.FindVMInClustersReturns(&vcenter.VM{
+		Name:  "vmotion4bosh-disk-guid",
+		Disks: []vcenter.Disk{{ID: 201, Datastore: "DS2"}},
+	}, nil)
+	vmRelocator := &migratefakes.FakeVMRelocator{}
+
+	m := newDiskMigrator(vmRelocator, log.NewBufferedStdout())
+	err := m.MigrateDiskToTarget(context.Background(), sourceClient, targetClient, orphanedDisk)
+	require.NoError(t, err)
+
+	require.Equal(t, 0, sourceClient.MoveDiskCallCount())
+	require.Equal(t, 1, sourceClient.CreateDiskVMCallCount())
+	_, vmName, disk, cluster, rp := sourceClient.CreateDiskVMArgsForCall(0)
+	require.Equal(t, "vmotion4bosh-disk-guid", vmName)
+	require.Equal(t, "[DS1] pcf_disk/disk-guid.vmdk", disk.DatastorePath())
+	require.Equal(t, "Cluster1", cluster)
+	require.Equal(t, "RP1", rp)
+
+	require.Equal(t, 1, vmRelocator.RelocateVMCallCount())
+	_, srcVM, targetSpec := vmRelocator.RelocateVMArgsForCall(0)
+	require.Equal(t, "vmotion4bosh-disk-guid", srcVM.Name)
+	require.Equal(t, "Cluster2", targetSpec.Cluster)
+	require.Equal(t, map[string]string{"DS1": "DS2"}, targetSpec.Datastores)
+
+	require.Equal(t, 1, targetClient.DeleteDiskVMCallCount())
+	_, vmName, target := targetClient.DeleteDiskVMArgsForCall(0)
+	require.Equal(t, "vmotion4bosh-disk-guid", vmName)
+	require.Equal(t, &vcenter.DatastoreDisk{
+		AZ:         "az1",
+		Datacenter: "DC2",
+		Datastore:  "DS2",
+		Path:       "pcf_disk/disk-guid.vmdk",
+	}, target)
+}
+
+func TestDiskMigrator_ResumesInterruptedDiskVMMigration(t *testing.T) {
+	sourceClient, targetClient := newDiskClients(false)
+	sourceClient.FindDiskReturns(nil, vcenter.NewDiskNotFoundError("pcf_disk/disk-guid.vmdk", errors.New("not found")))
+	targetClient.FindVMInClustersReturns(&vcenter.VM{
+		Name:  "vmotion4bosh-disk-guid",
+		Disks: []vcenter.Disk{{ID: 201, Datastore: "DS2"}},
+	}, nil)
+	vmRelocator := &migratefakes.FakeVMRelocator{}
+
+	m := newDiskMigrator(vmRelocator, log.NewBufferedStdout())
+	err := m.MigrateDiskToTarget(context.Background(), sourceClient, targetClient, orphanedDisk)
+	require.NoError(t, err)
+
+	require.Equal(t, 0, sourceClient.CreateDiskVMCallCount())
+	require.Equal(t, 0, vmRelocator.RelocateVMCallCount())
+	require.Equal(t, 1, targetClient.DeleteDiskVMCallCount())
+	_, az, _, clusters := targetClient.FindVMInClustersArgsForCall(0)
+	require.Equal(t, "az1", az)
+	require.Equal(t, []string{"Cluster2"}, clusters)
+}
+
+func TestDiskMigrator_DiskNotFound(t *testing.T) {
+	sourceClient, targetClient := newDiskClients(false)
+	sourceClient.FindDiskReturns(nil, vcenter.NewDiskNotFoundError("pcf_disk/disk-guid.vmdk", errors.New("not found")))
+	targetClient.FindVMInClustersReturns(nil, vcenter.NewVMNotFoundError("vmotion4bosh-disk-guid", errors.New("not found")))
+
+	out := log.NewBufferedStdout()
+	m := newDiskMigrator(&migratefakes.FakeVMRelocator{}, out)
+	err := m.MigrateDiskToTarget(context.Background(), sourceClient, targetClient, orphanedDisk)
+	require.NoError(t, err)
+
+	require.Equal(t, 0, targetClient.DeleteDiskVMCallCount())
+	require.Contains(t, out.String(), "not found in source vCenter, skipping")
+}
+
+func TestDiskMigrator_DryRun(t *testing.T) {
+	sourceClient, targetClient := newDiskClients(false)
+
+	out := log.NewBufferedStdout()
+	m := newDiskMigrator(&migratefakes.FakeVMRelocator{}, out).WithDryRun(true)
+	err := m.MigrateDiskToTarget(context.Background(), sourceClient, targetClient, orphanedDisk)
+	require.NoError(t, err)
+
+	require.Equal(t, 0, sourceClient.MoveDiskCallCount())
+	require.Equal(t, 0, sourceClient.CreateDiskVMCallCount())
+	require.Contains(t, out.String(), "would move to [DS2] pcf_disk/disk-guid.vmdk")
+}
+
+func TestDiskMigrator_TargetDatastoreOutsideCIDPattern(t *testing.T) {
+	sourceClient, targetClient := newDiskClients(true)
+
+	// the CID metadata target_datastore_pattern is DS1, but DS1 is mapped to DS2
+	disk := migrate.Disk{CID: "disk-guid.eyJ0YXJnZXRfZGF0YXN0b3JlX3BhdHRlcm4iOiJEUzEifQ", AZ: "az1"}
+	m := newDiskMigrator(&migratefakes.FakeVMRelocator{}, log.NewBufferedStdout())
+	err := m.MigrateDiskToTarget(context.Background(), sourceClient, targetClient, disk)
+	require.EqualError(t, err, "disk disk-guid.eyJ0YXJnZXRfZGF0YXN0b3JlX3BhdHRlcm4iOiJEUzEifQ target datastore DS2 "+
+		"does not match its target_datastore_pattern DS1")
+	require.Equal(t, 0, sourceClient.MoveDiskCallCount())
+}
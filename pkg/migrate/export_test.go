@@ -0,0 +1,11 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate
+
+// HasCanaryPrompt exposes whether the migrator pauses after each wave's canary VMs to the migrate_test package
+func (f *FoundationMigrator) HasCanaryPrompt() bool {
+	return f.canaryPrompt != nil
+}
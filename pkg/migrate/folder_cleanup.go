@@ -0,0 +1,209 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/proxy"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+const (
+	// DefaultVMFolder is the folder under the datacenter's VM folder the vSphere CPI places VMs in
+	DefaultVMFolder = "pcf_vms"
+
+	// DefaultTemplateFolder is the folder under the datacenter's VM folder the vSphere CPI places stemcells in
+	DefaultTemplateFolder = "pcf_templates"
+)
+
+//counterfeiter:generate . FolderCleanupVCenterClient
+type FolderCleanupVCenterClient interface {
+	HostName() string
+	Datacenter() string
+	VMFolders(ctx context.Context, folderPath string) ([]vcenter.VMFolder, error)
+	DeleteFolder(ctx context.Context, folderPath string) error
+}
+
+// MigratedFolders records the source folders VMs were migrated out of, by source vCenter
+type MigratedFolders struct {
+	mutex   sync.Mutex
+	folders map[string]map[string]bool
+}
+
+func NewMigratedFolders() *MigratedFolders {
+	return &MigratedFolders{
+		folders: map[string]map[string]bool{},
+	}
+}
+
+// Add records the folder on the vCenter a VM was migrated out of
+func (m *MigratedFolders) Add(vcenterHost, folderPath string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.folders[vcenterHost] == nil {
+		m.folders[vcenterHost] = map[string]bool{}
+	}
+	m.folders[vcenterHost][folderPath] = true
+}
+
+// Contains returns true if a VM was migrated out of the folder on the vCenter
+func (m *MigratedFolders) Contains(vcenterHost, folderPath string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.folders[vcenterHost][folderPath]
+}
+
+// FolderCleaner deletes the source VM folders left empty by a migration, only folders nested under the BOSH VM and
+// template folder roots are deleted, never the roots themselves
+type FolderCleaner struct {
+	roots         []string
+	sourceClients func() []FolderCleanupVCenterClient
+	out           Printer
+	dryRun        bool
+	migrated      *MigratedFolders
+	close         func(ctx context.Context)
+}
+
+// NewFolderCleaner creates a new FolderCleaner, the roots are relative to each source datacenter's VM folder
+func NewFolderCleaner(roots []string, sourceClients func() []FolderCleanupVCenterClient, out Printer) *FolderCleaner {
+	return &FolderCleaner{
+		roots:         roots,
+		sourceClients: sourceClients,
+		out:           out,
+	}
+}
+
+// NewFolderCleanerFromConfig creates a new FolderCleaner for the config's source vCenters and BOSH folders
+func NewFolderCleanerFromConfig(c config.Config) (*FolderCleaner, error) {
+	dialer, err := proxy.NewDialer(c.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	clientPool := ConfigToVCenterClientPool(c, dialer)
+	fc := newFolderCleanerFromPool(c, clientPool, log.NewUpdatableStdout())
+	fc.close = func(ctx context.Context) {
+		clientPool.Close(ctx)
+		closeDialer(ctx, dialer)
+	}
+	return fc, nil
+}
+
+// newFolderCleanerFromPool creates a new FolderCleaner sharing the pool's source vCenter clients
+func newFolderCleanerFromPool(c config.Config, clientPool *vcenter.Pool, out Printer) *FolderCleaner {
+	return NewFolderCleaner(ConfigToFolderRoots(c), func() []FolderCleanupVCenterClient {
+		var clients []FolderCleanupVCenterClient
+		for _, sc := range uniqueClients(clientPool.GetSourceClients()) {
+			clients = append(clients, sc)
+		}
+		return clients
+	}, out).WithDryRun(c.DryRun)
+}
+
+// ConfigToFolderRoots returns the BOSH VM and template folders, or the vSphere CPI defaults
+func ConfigToFolderRoots(c config.Config) []string {
+	vmFolder := DefaultVMFolder
+	templateFolder := DefaultTemplateFolder
+	if c.Bosh != nil && c.Bosh.VMFolder != "" {
+		vmFolder = c.Bosh.VMFolder
+	}
+	if c.Bosh != nil && c.Bosh.TemplateFolder != "" {
+		templateFolder = c.Bosh.TemplateFolder
+	}
+	return []string{vmFolder, templateFolder}
+}
+
+// WithDryRun only prints the folders that would be deleted
+func (c *FolderCleaner) WithDryRun(dryRun bool) *FolderCleaner {
+	c.dryRun = dryRun
+	return c
+}
+
+// WithMigratedFolders only deletes the empty folders VMs were migrated out of, instead of every empty folder under
+// the roots. Their parent folders are left alone, as nothing was migrated out of them.
+func (c *FolderCleaner) WithMigratedFolders(migrated *MigratedFolders) *FolderCleaner {
+	c.migrated = migrated
+	return c
+}
+
+// Cleanup deletes every folder under the roots that is empty or only contains folders that will be deleted, it
+// returns the deleted folder paths
+func (c *FolderCleaner) Cleanup(ctx context.Context) ([]string, error) {
+	l := log.FromContext(ctx)
+	if c.close != nil {
+		defer c.close(ctx)
+	}
+
+	var deleted []string
+	for _, client := range c.sourceClients() {
+		for _, root := range c.roots {
+			rootPath := "/" + strings.Trim(client.Datacenter(), "/") + "/vm/" + strings.Trim(root, "/")
+			folders, err := client.VMFolders(ctx, rootPath)
+			if err != nil {
+				// a datacenter may not have a root folder if BOSH never placed anything in it
+				l.Debugf("Skipping folder cleanup under %s: %s", rootPath, err)
+				continue
+			}
+
+			var selected func(string) bool
+			if c.migrated != nil {
+				host := client.HostName()
+				selected = func(folderPath string) bool {
+					return c.migrated.Contains(host, folderPath)
+				}
+			}
+			for _, p := range removableFolders(folders, selected) {
+				if c.dryRun {
+					c.out.Printf("Would delete empty folder %s", p)
+					deleted = append(deleted, p)
+					continue
+				}
+				err = client.DeleteFolder(ctx, p)
+				if err != nil {
+					return deleted, fmt.Errorf("failed to clean up source folders: %w", err)
+				}
+				c.out.Printf("Deleted empty folder %s", p)
+				deleted = append(deleted, p)
+			}
+		}
+	}
+	return deleted, nil
+}
+
+// removableFolders returns the folders that are empty or only contain removable folders, deepest first so each
+// folder is empty by the time it's deleted. When selected is set, only the selected folders are removable.
+func removableFolders(folders []vcenter.VMFolder, selected func(folderPath string) bool) []string {
+	sorted := make([]vcenter.VMFolder, len(folders))
+	copy(sorted, folders)
+	sort.Slice(sorted, func(i, j int) bool {
+		di := strings.Count(sorted[i].Path, "/")
+		dj := strings.Count(sorted[j].Path, "/")
+		if di != dj {
+			return di > dj
+		}
+		return sorted[i].Path < sorted[j].Path
+	})
+
+	removableChildren := map[string]int{}
+	var result []string
+	for _, f := range sorted {
+		if f.Children != removableChildren[f.Path] {
+			continue
+		}
+		if selected == nil || selected(f.Path) {
+			result = append(result, f.Path)
+			removableChildren[path.Dir(f.Path)]++
+		}
+	}
+	return result
+}
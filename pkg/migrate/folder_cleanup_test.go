@@ -0,0 +1,108 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/migratefakes"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+func newTestFolderCleaner() (*migrate.FolderCleaner, *migratefakes.FakeFolderCleanupVCenterClient, *log.BufferedStdout) {
+	client := &migratefakes.FakeFolderCleanupVCenterClient{}
+	client.HostNameReturns("vc1.example.com")
+	client.DatacenterReturns("DC1")
+	client.VMFoldersReturnsOnCall(0, []vcenter.VMFolder{
+		{Path: "/DC1/vm/pcf_vms/guid1", Children: 2},
+		{Path: "/DC1/vm/pcf_vms/guid1/a", Children: 0},
+		{Path: "/DC1/vm/pcf_vms/guid1/b", Children: 1},
+		{Path: "/DC1/vm/pcf_vms/guid1/b/c", Children: 0},
+		{Path: "/DC1/vm/pcf_vms/guid2", Children: 1},
+	}, nil)
+	client.VMFoldersReturnsOnCall(1, nil, errors.New("folder '/DC1/vm/pcf_templates' not found"))
+
+	out := log.NewBufferedStdout()
+	fc := migrate.NewFolderCleaner([]string{"pcf_vms", "/pcf_templates/"}, func() []migrate.FolderCleanupVCenterClient {
+		return []migrate.FolderCleanupVCenterClient{client}
+	}, out)
+	return fc, client, out
+}
+
+func TestFolderCleanup(t *testing.T) {
+	fc, client, out := newTestFolderCleaner()
+	deleted, err := fc.Cleanup(context.Background())
+	require.NoError(t, err)
+
+	// sub-folders are deleted before the folders they empty
+	expected := []string{
+		"/DC1/vm/pcf_vms/guid1/b/c",
+		"/DC1/vm/pcf_vms/guid1/a",
+		"/DC1/vm/pcf_vms/guid1/b",
+		"/DC1/vm/pcf_vms/guid1",
+	}
+	require.Equal(t, expected, deleted)
+	require.Equal(t, len(expected), client.DeleteFolderCallCount())
+	for i, p := range expected {
+		_, deletedPath := client.DeleteFolderArgsForCall(i)
+		require.Equal(t, p, deletedPath)
+	}
+	require.Contains(t, out.String(), "Deleted empty folder /DC1/vm/pcf_vms/guid1/b/c")
+
+	_, root := client.VMFoldersArgsForCall(0)
+	require.Equal(t, "/DC1/vm/pcf_vms", root)
+	_, root = client.VMFoldersArgsForCall(1)
+	require.Equal(t, "/DC1/vm/pcf_templates", root)
+}
+
+func TestFolderCleanupMigratedFolders(t *testing.T) {
+	migrated := migrate.NewMigratedFolders()
+	migrated.Add("vc1.example.com", "/DC1/vm/pcf_vms/guid1/b/c")
+	migrated.Add("vc1.example.com", "/DC1/vm/pcf_vms/guid2")
+	migrated.Add("vc2.example.com", "/DC1/vm/pcf_vms/guid1/a")
+
+	fc, client, _ := newTestFolderCleaner()
+	deleted, err := fc.WithMigratedFolders(migrated).Cleanup(context.Background())
+	require.NoError(t, err)
+
+	// folder a was migrated out of on another vCenter, b is left empty but no VM was migrated out of it and guid2
+	// isn't empty
+	require.Equal(t, []string{"/DC1/vm/pcf_vms/guid1/b/c"}, deleted)
+	require.Equal(t, 1, client.DeleteFolderCallCount())
+}
+
+func TestFolderCleanupDryRun(t *testing.T) {
+	fc, client, out := newTestFolderCleaner()
+	deleted, err := fc.WithDryRun(true).Cleanup(context.Background())
+	require.NoError(t, err)
+	require.Len(t, deleted, 4)
+	require.Equal(t, 0, client.DeleteFolderCallCount())
+	require.Contains(t, out.String(), "Would delete empty folder /DC1/vm/pcf_vms/guid1")
+}
+
+func TestFolderCleanupDeleteFails(t *testing.T) {
+	fc, client, _ := newTestFolderCleaner()
+	client.DeleteFolderReturns(errors.New("permission denied"))
+	_, err := fc.Cleanup(context.Background())
+	require.EqualError(t, err, "failed to clean up source folders: permission denied")
+	require.Equal(t, 1, client.DeleteFolderCallCount())
+}
+
+func TestConfigToFolderRoots(t *testing.T) {
+	require.Equal(t, []string{"pcf_vms", "pcf_templates"}, migrate.ConfigToFolderRoots(config.Config{}))
+	require.Equal(t, []string{"vms", "pcf_templates"}, migrate.ConfigToFolderRoots(config.Config{
+		Bosh: &config.Bosh{VMFolder: "vms"},
+	}))
+	require.Equal(t, []string{"vms", "stemcells"}, migrate.ConfigToFolderRoots(config.Config{
+		Bosh: &config.Bosh{VMFolder: "vms", TemplateFolder: "stemcells"},
+	}))
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/inventory"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/converter"
+)
+
+//counterfeiter:generate . FolderInventoryClient
+type FolderInventoryClient interface {
+	Datacenter() string
+	FolderExists(ctx context.Context, folderPath string) (bool, error)
+}
+
+// FolderMappings checks each folder mapping target against the target vCenter inventory before the migration
+// starts, the target's datacenter and VM folder must exist while any folders beneath are created as VMs migrate
+type FolderMappings struct {
+	folderMap map[string]string
+	out       Printer
+}
+
+// NewFolderMappings creates a new FolderMappings for the source to target folder prefixes
+func NewFolderMappings(folderMap map[string]string, out Printer) *FolderMappings {
+	return &FolderMappings{
+		folderMap: folderMap,
+		out:       out,
+	}
+}
+
+// Validate ensures every folder mapping target is in one of the target datacenters
+func (m *FolderMappings) Validate(ctx context.Context, targetClients []FolderInventoryClient) error {
+	var sources []string
+	for src := range m.folderMap {
+		sources = append(sources, src)
+	}
+	sort.Strings(sources)
+
+	for _, src := range sources {
+		target := m.folderMap[src]
+		dcPath, _, err := converter.SplitVMFolder(target)
+		if err != nil {
+			return fmt.Errorf("invalid folder mapping %s target %s: %w", src, target, err)
+		}
+
+		client := targetClientForDatacenter(dcPath, targetClients)
+		if client == nil {
+			return fmt.Errorf("folder mapping %s target %s is not in any of the compute target datacenters",
+				src, target)
+		}
+
+		vmFolder := "/" + dcPath + "/vm"
+		exists, err := client.FolderExists(ctx, vmFolder)
+		if err != nil {
+			return fmt.Errorf("could not validate folder mapping %s: %w", src, err)
+		}
+		if !exists {
+			return fmt.Errorf("could not find folder mapping %s target datacenter VM folder %s", src, vmFolder)
+		}
+
+		exists, err = client.FolderExists(ctx, target)
+		if err != nil {
+			return fmt.Errorf("could not validate folder mapping %s: %w", src, err)
+		}
+		if !exists {
+			m.out.Printf("Target folder %s does not exist yet and will be created", target)
+		}
+	}
+	return nil
+}
+
+// targetClientForDatacenter returns the client for the datacenter path, clients may be configured with either
+// the datacenter's inventory path or only its name
+func targetClientForDatacenter(dcPath string, targetClients []FolderInventoryClient) FolderInventoryClient {
+	for _, c := range targetClients {
+		if inventory.Same(c.Datacenter(), dcPath) {
+			return c
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/migratefakes"
+)
+
+func newTestFolderInventoryClient(datacenter string, folders ...string) *migratefakes.FakeFolderInventoryClient {
+	client := &migratefakes.FakeFolderInventoryClient{}
+	client.DatacenterReturns(datacenter)
+	client.FolderExistsCalls(func(_ context.Context, folderPath string) (bool, error) {
+		return containsFolder(folders, folderPath), nil
+	})
+	return client
+}
+
+func containsFolder(folders []string, folderPath string) bool {
+	for _, f := range folders {
+		if f == folderPath {
+			return true
+		}
+	}
+	return false
+}
+
+func TestFolderMappingsValidate(t *testing.T) {
+	out := log.NewBufferedStdout()
+	m := migrate.NewFolderMappings(map[string]string{
+		"/DC1/vm/pcf_vms":       "/Region1/DC2/vm/foundations/prod-tas",
+		"/DC1/vm/pcf_templates": "/DC3/vm/templates",
+	}, out)
+
+	err := m.Validate(context.Background(), []migrate.FolderInventoryClient{
+		newTestFolderInventoryClient("DC2", "/Region1/DC2/vm", "/Region1/DC2/vm/foundations/prod-tas"),
+		newTestFolderInventoryClient("/DC3", "/DC3/vm"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Target folder /DC3/vm/templates does not exist yet and will be created", out.String())
+}
+
+func TestFolderMappingsValidateUnknownDatacenter(t *testing.T) {
+	m := migrate.NewFolderMappings(map[string]string{
+		"/DC1/vm/pcf_vms": "/DC9/vm/foundations/prod-tas",
+	}, log.NewBufferedStdout())
+
+	err := m.Validate(context.Background(), []migrate.FolderInventoryClient{
+		newTestFolderInventoryClient("DC2", "/DC2/vm"),
+	})
+	require.EqualError(t, err, "folder mapping /DC1/vm/pcf_vms target /DC9/vm/foundations/prod-tas is not in any "+
+		"of the compute target datacenters")
+}
+
+func TestFolderMappingsValidateMissingVMFolder(t *testing.T) {
+	m := migrate.NewFolderMappings(map[string]string{
+		"/DC1/vm/pcf_vms": "/Region1/DC2/vm/prod-tas",
+	}, log.NewBufferedStdout())
+
+	err := m.Validate(context.Background(), []migrate.FolderInventoryClient{
+		newTestFolderInventoryClient("DC2", "/Region2/DC2/vm"),
+	})
+	require.EqualError(t, err, "could not find folder mapping /DC1/vm/pcf_vms target datacenter VM folder "+
+		"/Region1/DC2/vm")
+}
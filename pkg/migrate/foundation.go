@@ -10,12 +10,15 @@ package migrate
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/duration"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/converter"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/proxy"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/worker"
 )
@@ -27,6 +30,9 @@ type migrationResult struct {
 	id     int
 	vmName string
 	err    error
+
+	// skipped is true when the VM wasn't started because the abort threshold was exceeded
+	skipped bool
 }
 
 // Success the migration result is considered successful
@@ -39,9 +45,25 @@ type FoundationMigrator struct {
 	WorkerCount     int
 	updatableStdout *log.UpdatableStdout
 
-	clientPool *vcenter.Pool
-	vmMigrator *VMMigrator
-	vmSource   *VMSource
+	clientPool   *vcenter.Pool
+	vmMigrator   *VMMigrator
+	vmSource     *VMSource
+	diskMigrator *DiskMigrator
+	mappingRules *MappingRules
+	networkMap   map[string]string
+	datastoreMap map[string]string
+	folderMaps   *FolderMappings
+	idMappedNet  *converter.IDMappedNet
+	placement    *Placement
+	canaries     int
+	canaryPrompt *CanaryPrompt
+
+	maxFailures       int
+	maxFailurePercent int
+
+	healthVerifier *HealthVerifier
+	folderCleaner  *FolderCleaner
+	dialer         *proxy.Dialer
 }
 
 // NewFoundationMigrator creates a new initialized FoundationMigrator using the provided instances
@@ -63,8 +85,14 @@ func NewFoundationMigrator(
 // NewFoundationMigratorFromConfig creates a new FoundationMigrator instance from the specified config
 func NewFoundationMigratorFromConfig(c config.Config) (*FoundationMigrator, error) {
 	l := log.WithoutContext()
+	l.Debug("Creating proxy dialer")
+	dialer, err := proxy.NewDialer(c.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
 	l.Debug("Creating vCenter client pool")
-	clientPool := ConfigToVCenterClientPool(c)
+	clientPool := ConfigToVCenterClientPool(c, dialer)
 
 	l.Debug("Creating AZ cluster mappings")
 	computeMap, err := ConfigToAZMapping(c)
@@ -72,11 +100,31 @@ func NewFoundationMigratorFromConfig(c config.Config) (*FoundationMigrator, erro
 		return nil, err
 	}
 
+	// the exact mappings and any resolved rules are added to these maps when the migration starts
+	networkMap, datastoreMap := map[string]string{}, map[string]string{}
+
+	var netMapper converter.NetworkMapper = converter.NewMappedNetwork(networkMap)
+	var idMappedNet *converter.IDMappedNet
+	if c.MapNetworksByID() {
+		idMappedNet = converter.NewIDMappedNetwork(networkMap, c.NetworkTargetSwitch)
+		netMapper = idMappedNet
+	}
+
+	strategy, err := converter.NewPlacementStrategy(c.Compute.PlacementStrategy)
+	if err != nil {
+		return nil, err
+	}
+	mappedCompute := converter.NewMappedCompute(computeMap).WithPlacementStrategy(strategy)
+
 	l.Debug("Creating source VM target spec converter")
 	sourceVMConverter := converter.New(
-		converter.NewMappedNetwork(c.NetworkMap),
-		converter.NewMappedDatastore(c.DatastoreMap),
-		converter.NewMappedCompute(computeMap))
+		netMapper,
+		converter.NewMappedDatastore(datastoreMap),
+		mappedCompute).
+		WithOverrides(ConfigToOverrides(c))
+	if len(c.FolderMap) > 0 {
+		sourceVMConverter.WithFolders(converter.NewMappedFolder(c.FolderMap))
+	}
 
 	l.Debug("Creating VM migrator")
 	hpConfig := ConfigToTargetHostPoolConfig(c)
@@ -85,38 +133,340 @@ func NewFoundationMigratorFromConfig(c config.Config) (*FoundationMigrator, erro
 
 	vmRelocator := vcenter.NewVMRelocator(clientPool, destinationHostPool, out).WithDryRun(c.DryRun)
 	vmMigrator := NewVMMigrator(clientPool, sourceVMConverter, vmRelocator, out)
-	vmSource := NewVMSourceFromConfig(c)
+	vmSource := NewVMSourceFromConfig(c, dialer)
 
 	l.Debug("Creating foundation migrator")
 	fm := NewFoundationMigrator(clientPool, vmMigrator, vmSource, out)
 	fm.WorkerCount = c.WorkerPoolSize
+	fm.dialer = dialer
+	fm.WithMappingRules(NewMappingRules(c.NetworkNameMap(), c.DatastoreNameMap(), out), networkMap, datastoreMap)
+	if len(c.FolderMap) > 0 {
+		fm.WithFolderMappings(NewFolderMappings(c.FolderMap, out))
+	}
+	if idMappedNet != nil {
+		fm.WithNetworkIDMapping(idMappedNet)
+	}
+	fm.WithPlacement(NewPlacement(mappedCompute, func(az string) ClusterMemoryClient {
+		if tc := clientPool.GetTargetClientByAZ(az); tc != nil {
+			return tc
+		}
+		return nil
+	}, out))
+
+	fm.WithAbortThreshold(c.MaxFailures, c.MaxFailurePercent)
+
+	// a dry run doesn't move any VMs so there's nothing to confirm or verify
+	if !c.DryRun {
+		fm.WithCanaries(c.Canaries, NewCanaryPrompt(os.Stdin, out, c.AutoContinueAfter))
+	}
+	if c.HealthVerification != nil && !c.DryRun {
+		fm.WithHealthVerifier(NewHealthVerifier(vmSource.BoshClient).
+			WithTimeout(c.HealthVerification.Timeout).
+			WithPollInterval(c.HealthVerification.PollInterval).
+			WithFlagOnly(c.HealthVerification.FlagOnly()))
+	}
+
+	if c.CleanupSourceFolders {
+		fm.WithFolderCleaner(newFolderCleanerFromPool(c, clientPool, out))
+	}
+
+	if c.Bosh != nil {
+		l.Debug("Creating orphaned disk migrator")
+		fm.WithDiskMigrator(NewDiskMigrator(clientPool, vmMigrator, datastoreMap, computeMap, c.Bosh.DiskPath).
+			WithDryRun(c.DryRun))
+	}
 	return fm, nil
 }
 
+// WithDiskMigrator migrates all BOSH orphaned disks after the VMs using the specified DiskMigrator
+func (f *FoundationMigrator) WithDiskMigrator(diskMigrator *DiskMigrator) *FoundationMigrator {
+	f.diskMigrator = diskMigrator
+	return f
+}
+
+// WithMappingRules resolves the network and datastore rules against the source vCenters before migrating, adding
+// the resolved mappings to the network and datastore maps used by the VM converter and disk migrator
+func (f *FoundationMigrator) WithMappingRules(mappingRules *MappingRules, networkMap, datastoreMap map[string]string) *FoundationMigrator {
+	f.mappingRules = mappingRules
+	f.networkMap = networkMap
+	f.datastoreMap = datastoreMap
+	return f
+}
+
+// WithFolderMappings validates the folder mapping targets against the target vCenters before migrating
+func (f *FoundationMigrator) WithFolderMappings(folderMaps *FolderMappings) *FoundationMigrator {
+	f.folderMaps = folderMaps
+	return f
+}
+
+// WithNetworkIDMapping loads the source and target network VLAN and segment IDs before migrating
+func (f *FoundationMigrator) WithNetworkIDMapping(idMappedNet *converter.IDMappedNet) *FoundationMigrator {
+	f.idMappedNet = idMappedNet
+	return f
+}
+
+// WithPlacement prepares the placement strategy and places the VMs before migrating, and reports the VMs'
+// placement afterwards
+func (f *FoundationMigrator) WithPlacement(placement *Placement) *FoundationMigrator {
+	f.placement = placement
+	return f
+}
+
+// WithCanaries migrates the first canaries VMs per AZ in each wave, then asks the prompt whether to continue
+func (f *FoundationMigrator) WithCanaries(canaries int, prompt *CanaryPrompt) *FoundationMigrator {
+	f.canaries = canaries
+	f.canaryPrompt = prompt
+	return f
+}
+
+// WithAbortThreshold stops starting new VM migrations once more than maxFailures VMs or more than maxFailurePercent
+// of all VMs have failed, zero disables either limit
+func (f *FoundationMigrator) WithAbortThreshold(maxFailures, maxFailurePercent int) *FoundationMigrator {
+	f.maxFailures = maxFailures
+	f.maxFailurePercent = maxFailurePercent
+	return f
+}
+
+// WithHealthVerifier waits for BOSH to report each migrated VM is healthy and reports any flagged VMs afterwards
+func (f *FoundationMigrator) WithHealthVerifier(healthVerifier *HealthVerifier) *FoundationMigrator {
+	f.healthVerifier = healthVerifier
+	f.vmMigrator.WithHealthVerifier(healthVerifier)
+	return f
+}
+
+// WithFolderCleaner deletes the source VM folders emptied by the migration once every VM and disk has migrated, only
+// the folders VMs were migrated out of during this run and the parents they leave empty are deleted
+func (f *FoundationMigrator) WithFolderCleaner(folderCleaner *FolderCleaner) *FoundationMigrator {
+	migrated := NewMigratedFolders()
+	f.folderCleaner = folderCleaner.WithMigratedFolders(migrated)
+	f.vmMigrator.WithMigratedFolders(migrated)
+	return f
+}
+
 // Migrate executes the entire migration for all VMs
 func (f *FoundationMigrator) Migrate(ctx context.Context) error {
 	start := time.Now()
 	l := log.WithoutContext()
 	l.Infof("Starting foundation migration at %s", start.Format(time.RFC1123Z))
 
+	// the vCenter clients log out via any proxy, so the proxy is closed last
+	if f.dialer != nil {
+		defer closeDialer(ctx, f.dialer)
+	}
 	defer f.clientPool.Close(ctx)
 
-	vms, err := f.vmSource.VMsToMigrate(ctx)
+	if f.mappingRules != nil {
+		var sourceClients []InventoryClient
+		for _, c := range uniqueClients(f.clientPool.GetSourceClients()) {
+			sourceClients = append(sourceClients, c)
+		}
+		resolved, err := f.mappingRules.Resolve(ctx, sourceClients)
+		if err != nil {
+			return err
+		}
+		copyMappings(f.networkMap, resolved.Networks)
+		copyMappings(f.datastoreMap, resolved.Datastores)
+	}
+
+	if f.folderMaps != nil {
+		var targetClients []FolderInventoryClient
+		for _, c := range uniqueClients(f.clientPool.GetTargetClients()) {
+			targetClients = append(targetClients, c)
+		}
+		err := f.folderMaps.Validate(ctx, targetClients)
+		if err != nil {
+			return err
+		}
+	}
+
+	if f.idMappedNet != nil {
+		var sourceClients, targetClients []converter.NetworkIdentityLister
+		for _, c := range uniqueClients(f.clientPool.GetSourceClients()) {
+			sourceClients = append(sourceClients, c)
+		}
+		for _, c := range uniqueClients(f.clientPool.GetTargetClients()) {
+			targetClients = append(targetClients, c)
+		}
+		err := f.idMappedNet.Load(ctx, sourceClients, targetClients)
+		if err != nil {
+			return err
+		}
+	}
+
+	if f.placement != nil {
+		err := f.placement.Prepare(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	waves, err := f.vmSource.Waves(ctx)
 	if err != nil {
 		return err
 	}
 
-	vmCount := len(vms)
-	results := make(chan migrationResult, vmCount)
+	if f.placement != nil {
+		var vms []VM
+		for _, w := range waves {
+			vms = append(vms, w.VMs...)
+		}
+		f.placement.Plan(ctx, vms, f.vmMigrator.Place)
+	}
+
+	var disks []Disk
+	if f.diskMigrator != nil {
+		disks, err = f.vmSource.DisksToMigrate(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	vmCount := 0
+	for _, w := range waves {
+		vmCount += len(w.VMs)
+	}
 
 	workers := worker.NewPool(f.WorkerCount)
 	workers.Start(ctx)
+	abort := NewAbortThreshold(f.maxFailures, f.maxFailurePercent, vmCount)
 
-	for i, vm := range vms {
+	// each wave is finished before the next starts, a failed health check stops the migration
+	failCount := 0
+	migratedCount := 0
+	var gateErr error
+	for i, w := range waves {
+		if len(waves) > 1 {
+			f.updatableStdout.Printf("Migrating wave %d of %d: %s (%d VMs)", i+1, len(waves), w.Name, len(w.VMs))
+		}
+
+		vms := w.VMs
+		waveFailCount := 0
+		canaries, rest := w.SplitCanaries(f.waveCanaries(w))
+		if len(canaries) > 0 && len(rest) > 0 {
+			var started int
+			waveFailCount, started = f.migrateVMs(workers, canaries, migratedCount, abort)
+			migratedCount += started
+			gateErr = f.checkCanaries(ctx, w, len(canaries), len(rest), waveFailCount)
+			vms = rest
+		}
+
+		if gateErr == nil {
+			fails, started := f.migrateVMs(workers, vms, migratedCount, abort)
+			waveFailCount += fails
+			migratedCount += started
+			if abort.Exceeded() {
+				gateErr = fmt.Errorf("stopped migrating because %s", abort.Reason())
+			} else if w.HealthCheck && i < len(waves)-1 {
+				gateErr = f.checkWaveHealth(ctx, w, waveFailCount)
+			}
+		}
+
+		failCount += waveFailCount
+		if gateErr != nil {
+			// leave the orphaned disks behind with the VMs that weren't migrated
+			disks = nil
+			break
+		}
+	}
+
+	// orphaned disks are moved after all VMs so a re-attached disk isn't moved out from under its VM
+	diskCount := len(disks)
+	diskResults := make(chan migrationResult, diskCount)
+	for i, disk := range disks {
 		i := i + 1 // closure and make it 1 based
-		v := vm    // closure
+		d := disk  // closure
+		if d.Unconfigured {
+			diskResults <- migrationResult{id: i, vmName: d.CID, skipped: true}
+			continue
+		}
 		workers.AddTask(func(taskCtx context.Context) {
+			err := f.diskMigrator.Migrate(taskCtx, d)
+			diskResults <- migrationResult{
+				id:     i,
+				vmName: d.CID,
+				err:    err,
+			}
+		})
+	}
+
+	diskFailCount := 0
+	diskSkipCount := 0
+	for i := 0; i < diskCount; i++ {
+		res := <-diskResults
+		if res.skipped {
+			diskSkipCount++
+			continue
+		}
+		if !res.Success() {
+			diskFailCount++
+			l.Debugf("Orphaned disk %s failed to migrate: %s", res.vmName, res.err)
+		}
+	}
+	close(diskResults)
+
+	f.updatableStdout.Println()
+	if f.placement != nil {
+		f.placement.Report()
+	}
+	if f.healthVerifier != nil {
+		f.healthVerifier.Report(f.updatableStdout)
+	}
+	f.updatableStdout.Printf("Migrated %d out of %d VMs", migratedCount-failCount, vmCount)
+	if abort.Exceeded() {
+		f.updatableStdout.Printf("Stopped migrating because %s, %d VMs were not started",
+			abort.Reason(), vmCount-migratedCount)
+	}
+	if diskCount > 0 {
+		f.updatableStdout.Printf("Migrated %d out of %d orphaned disks", diskCount-diskFailCount-diskSkipCount, diskCount)
+	}
+	if diskSkipCount > 0 {
+		f.updatableStdout.Printf("Skipped %d orphaned disks in AZs missing from the compute config", diskSkipCount)
+	}
+	f.updatableStdout.Printf("Total runtime: %s", duration.HumanReadable(time.Since(start)))
+
+	if gateErr != nil {
+		return gateErr
+	}
+	if failCount > 0 {
+		return fmt.Errorf("failed to migrate %d VMs, see run output for more details", failCount)
+	}
+	if diskFailCount > 0 {
+		return fmt.Errorf("failed to migrate %d orphaned disks, see run output for more details", diskFailCount)
+	}
+
+	if f.folderCleaner != nil {
+		_, err = f.folderCleaner.Cleanup(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateVMs migrates the VMs and waits for them all to finish, it returns the number that failed and the number
+// started. Once the abort threshold is exceeded no more VMs are started, any already in-flight are allowed to finish.
+func (f *FoundationMigrator) migrateVMs(workers *worker.Pool, vms []VM, previousCount int, abort *AbortThreshold) (int, int) {
+	l := log.WithoutContext()
+	results := make(chan migrationResult, len(vms))
+
+	dispatched := 0
+	for i, vm := range vms {
+		if abort.Exceeded() {
+			break
+		}
+		dispatched++
+		i := previousCount + i + 1 // closure and make it 1 based
+		v := vm                    // closure
+		workers.AddTask(func(taskCtx context.Context) {
+			// the threshold may have been exceeded while this task waited for a worker
+			if abort.Exceeded() {
+				results <- migrationResult{id: i, vmName: v.Name, skipped: true}
+				return
+			}
 			err := f.vmMigrator.Migrate(taskCtx, v)
+			if err != nil {
+				abort.Fail()
+			}
 			results <- migrationResult{
 				id:     i,
 				vmName: v.Name,
@@ -126,23 +476,60 @@ func (f *FoundationMigrator) Migrate(ctx context.Context) error {
 	}
 
 	failCount := 0
-	for i := 0; i < vmCount; i++ {
+	startedCount := 0
+	for i := 0; i < dispatched; i++ {
 		res := <-results
+		if res.skipped {
+			continue
+		}
+		startedCount++
 		if !res.Success() {
 			failCount++
 			l.Debugf("%s failed to migrate: %s", res.vmName, res.err)
 		}
 	}
 	close(results)
+	return failCount, startedCount
+}
 
-	f.updatableStdout.Println()
-	f.updatableStdout.Printf("Migrated %d out of %d VMs", vmCount-failCount, vmCount)
-	f.updatableStdout.Printf("Total runtime: %s", duration.HumanReadable(time.Since(start)))
+// waveCanaries returns the number of canary VMs per AZ for the wave, or zero if there's no way to confirm them
+func (f *FoundationMigrator) waveCanaries(w Wave) int {
+	if f.canaryPrompt == nil {
+		return 0
+	}
+	if w.Canaries > 0 {
+		return w.Canaries
+	}
+	return f.canaries
+}
 
+// checkCanaries returns an error if any of the wave's canary VMs failed or the operator doesn't continue
+func (f *FoundationMigrator) checkCanaries(ctx context.Context, w Wave, canaryCount, restCount, failCount int) error {
+	f.updatableStdout.Printf("Migrated %d out of %d canary VMs in wave %s", canaryCount-failCount, canaryCount, w.Name)
 	if failCount > 0 {
-		return fmt.Errorf("failed to migrate %d VMs, see run output for more details", failCount)
+		return fmt.Errorf("stopped after %d of wave %s's canary VMs failed to migrate", failCount, w.Name)
+	}
+	question := fmt.Sprintf("Continue migrating the remaining %d VMs in wave %s", restCount, w.Name)
+	if !f.canaryPrompt.Continue(ctx, question) {
+		return fmt.Errorf("stopped after wave %s's canary VMs", w.Name)
 	}
+	return nil
+}
 
+// checkWaveHealth returns an error if any of the wave's VMs failed to migrate or its deployments are unhealthy
+func (f *FoundationMigrator) checkWaveHealth(ctx context.Context, w Wave, failCount int) error {
+	if failCount > 0 {
+		return fmt.Errorf("stopped after wave %s because %d of its VMs failed to migrate", w.Name, failCount)
+	}
+	unhealthy, err := f.vmSource.UnhealthyInstances(ctx, w)
+	if err != nil {
+		return fmt.Errorf("stopped after wave %s because its health check failed: %w", w.Name, err)
+	}
+	if len(unhealthy) > 0 {
+		return fmt.Errorf("stopped after wave %s because its instances aren't running: %s",
+			w.Name, strings.Join(unhealthy, ", "))
+	}
+	f.updatableStdout.Printf("Wave %s is healthy", w.Name)
 	return nil
 }
 
@@ -178,6 +565,24 @@ func ConfigToAZMapping(c config.Config) ([]converter.AZMapping, error) {
 	return computeMap, nil
 }
 
+// ConfigToOverrides converts the config placement overrides to converter overrides
+func ConfigToOverrides(c config.Config) []converter.Override {
+	var overrides []converter.Override
+	for _, o := range c.Overrides {
+		overrides = append(overrides, converter.Override{
+			VM:            o.VM,
+			Deployment:    o.Deployment,
+			InstanceGroup: o.InstanceGroup,
+			Cluster:       o.Cluster,
+			ResourcePool:  o.ResourcePool,
+			Folder:        o.Folder,
+			Datastore:     o.Datastore,
+			Network:       o.Network,
+		})
+	}
+	return overrides
+}
+
 // ConfigToTargetHostPoolConfig creates the required configuration format to create a target host pool
 func ConfigToTargetHostPoolConfig(c config.Config) *vcenter.HostPoolConfig {
 	hpConfig := &vcenter.HostPoolConfig{}
@@ -195,13 +600,42 @@ func ConfigToTargetHostPoolConfig(c config.Config) *vcenter.HostPoolConfig {
 }
 
 // ConfigToVCenterClientPool creates a pool of VCenter clients for each AZ source and target
-func ConfigToVCenterClientPool(c config.Config) *vcenter.Pool {
+// All clients connect using the dialer, a nil dialer connects directly
+func ConfigToVCenterClientPool(c config.Config, dialer *proxy.Dialer) *vcenter.Pool {
 	clientPool := vcenter.NewPool()
 	for _, az := range c.Compute.Source {
-		clientPool.AddSource(az.Name, az.VCenter.Host, az.VCenter.Username, az.VCenter.Password, az.VCenter.Datacenter, az.VCenter.Insecure)
+		clientPool.AddSourceClient(az.Name, configToVCenterClient(az.VCenter, dialer))
 	}
 	for _, az := range c.Compute.Target {
-		clientPool.AddTarget(az.Name, az.VCenter.Host, az.VCenter.Username, az.VCenter.Password, az.VCenter.Datacenter, az.VCenter.Insecure)
+		clientPool.AddTargetClient(az.Name, configToVCenterClient(az.VCenter, dialer))
 	}
 	return clientPool
 }
+
+func configToVCenterClient(vc *config.VCenter, dialer *proxy.Dialer) *vcenter.Client {
+	return vcenter.New(vc.Host, vc.Username, vc.Password, vc.Datacenter, vc.Insecure).
+		WithCACert(vc.CACert).
+		WithCACertFile(vc.CACertFile).
+		WithThumbprint(vc.Thumbprint).
+		WithProxy(dialer)
+}
+
+// closeDialer closes any long-lived proxy connection, like the SSH connection to an ssh+socks5 jumpbox
+func closeDialer(ctx context.Context, dialer *proxy.Dialer) {
+	if err := dialer.Close(); err != nil {
+		log.FromContext(ctx).Warnf("Failed to close the proxy connection: %s", err)
+	}
+}
+
+// uniqueClients removes the duplicate clients shared by AZs in the same vCenter datacenter
+func uniqueClients(clients []*vcenter.Client) []*vcenter.Client {
+	var result []*vcenter.Client
+	seen := map[*vcenter.Client]bool{}
+	for _, c := range clients {
+		if !seen[c] {
+			seen[c] = true
+			result = append(result, c)
+		}
+	}
+	return result
+}
@@ -6,9 +6,16 @@
 package migrate_test
 
 import (
+	"context"
+	"fmt"
 	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/bosh"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/converter"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/migratefakes"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
 	"testing"
 )
 
@@ -78,6 +85,82 @@ func TestNewFoundationMigratorFromConfig(t *testing.T) {
 	require.Equal(t, 1, fm.WorkerCount)
 }
 
+func TestNewFoundationMigratorFromConfigDryRunSkipsCanaries(t *testing.T) {
+	c := baseConfig()
+	c.Canaries = 1
+	fm, err := migrate.NewFoundationMigratorFromConfig(c)
+	require.NoError(t, err)
+	require.True(t, fm.HasCanaryPrompt())
+
+	c.DryRun = true
+	fm, err = migrate.NewFoundationMigratorFromConfig(c)
+	require.NoError(t, err)
+	require.False(t, fm.HasCanaryPrompt())
+}
+
+// failingFoundationMigrator creates a FoundationMigrator for 10 BOSH VMs that all fail as there are no vCenter clients
+func failingFoundationMigrator() *migrate.FoundationMigrator {
+	c := baseConfig()
+	c.AdditionalVMs = nil
+	src := migrate.NewVMSourceFromConfig(c, nil)
+	b := &migratefakes.FakeBoshClient{}
+	var vms []bosh.VM
+	for i := 0; i < 10; i++ {
+		vms = append(vms, bosh.VM{Name: fmt.Sprintf("vm%d", i), AZ: "az1", Deployment: "cf", InstanceGroup: "router"})
+	}
+	b.VMsAndStemcellsReturns(vms, nil)
+	src.BoshClient = b
+
+	clientPool := vcenter.NewPool()
+	vmConverter := converter.New(converter.NewEmptyMappedNetwork(), converter.NewEmptyMappedDatastore(),
+		converter.NewEmptyMappedCompute())
+	vmMigrator := migrate.NewVMMigrator(clientPool, vmConverter, &migratefakes.FakeVMRelocator{}, log.NewBufferedStdout())
+	return migrate.NewFoundationMigrator(clientPool, vmMigrator, src, log.NewUpdatableStdout())
+}
+
+func TestMigrateStopsWhenMaxFailuresExceeded(t *testing.T) {
+	fm := failingFoundationMigrator()
+	fm.WorkerCount = 1
+	fm.WithAbortThreshold(2, 0)
+	err := fm.Migrate(context.Background())
+	require.EqualError(t, err, "stopped migrating because 3 VMs failed to migrate, exceeding max_failures 2")
+}
+
+func TestMigrateStopsWhenMaxFailurePercentExceeded(t *testing.T) {
+	fm := failingFoundationMigrator()
+	fm.WorkerCount = 1
+	fm.WithAbortThreshold(0, 40)
+	err := fm.Migrate(context.Background())
+	require.EqualError(t, err,
+		"stopped migrating because 5 of 10 VMs failed to migrate, exceeding max_failure_percent 40%")
+}
+
+func TestMigrateWithoutAbortThresholdMigratesAllVMs(t *testing.T) {
+	fm := failingFoundationMigrator()
+	err := fm.Migrate(context.Background())
+	require.EqualError(t, err, "failed to migrate 10 VMs, see run output for more details")
+}
+
+func TestMigrateSkipsOrphanedDisksInUnconfiguredAZs(t *testing.T) {
+	c := baseConfig()
+	c.AdditionalVMs = nil
+	src := migrate.NewVMSourceFromConfig(c, nil)
+	b := &migratefakes.FakeBoshClient{}
+	b.OrphanedDisksReturns([]bosh.Disk{{CID: "disk-guid1", AZ: "az9"}, {CID: "disk-guid2", AZ: ""}}, nil)
+	src.BoshClient = b
+
+	clientPool := vcenter.NewPool()
+	vmConverter := converter.New(converter.NewEmptyMappedNetwork(), converter.NewEmptyMappedDatastore(),
+		converter.NewEmptyMappedCompute())
+	vmMigrator := migrate.NewVMMigrator(clientPool, vmConverter, &migratefakes.FakeVMRelocator{}, log.NewBufferedStdout())
+	fm := migrate.NewFoundationMigrator(clientPool, vmMigrator, src, log.NewUpdatableStdout()).
+		WithDiskMigrator(migrate.NewDiskMigrator(clientPool, vmMigrator, c.DatastoreMap, nil, ""))
+
+	// the disks would fail to migrate without any vCenter clients
+	err := fm.Migrate(context.Background())
+	require.NoError(t, err)
+}
+
 func TestConfigToVCenterClientPool(t *testing.T) {
 	c := baseConfig()
 	c.Compute.Source = append(c.Compute.Source, config.ComputeAZ{
@@ -111,7 +194,7 @@ func TestConfigToVCenterClientPool(t *testing.T) {
 		},
 	})
 
-	p := migrate.ConfigToVCenterClientPool(c)
+	p := migrate.ConfigToVCenterClientPool(c, nil)
 	require.Contains(t, p.SourceAZs(), "az1")
 	require.Contains(t, p.SourceAZs(), "az2")
 	require.Contains(t, p.TargetAZs(), "az1")
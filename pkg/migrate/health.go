@@ -0,0 +1,172 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/bosh"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/duration"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+)
+
+const (
+	DefaultHealthTimeout      = 10 * time.Minute
+	DefaultHealthPollInterval = 15 * time.Second
+)
+
+//counterfeiter:generate . InstanceHealthClient
+type InstanceHealthClient interface {
+	DeploymentHealth(ctx context.Context, deployment string) (map[string]bosh.InstanceHealth, error)
+}
+
+// HealthVerifier polls BOSH after a VM is migrated until its instance and processes are running. The VMs being
+// verified concurrently share each poll of their deployment's instances.
+type HealthVerifier struct {
+	client       InstanceHealthClient
+	timeout      time.Duration
+	pollInterval time.Duration
+	flagOnly     bool
+
+	mu          sync.Mutex
+	flagged     map[string]string
+	deployments map[string]*deploymentHealth
+}
+
+// deploymentHealth is the last poll of a deployment's instance health
+type deploymentHealth struct {
+	mu        sync.Mutex
+	polled    time.Time
+	instances map[string]bosh.InstanceHealth
+	err       error
+}
+
+// NewHealthVerifier creates a new HealthVerifier with the default timeout and poll interval
+func NewHealthVerifier(client InstanceHealthClient) *HealthVerifier {
+	return &HealthVerifier{
+		client:       client,
+		timeout:      DefaultHealthTimeout,
+		pollInterval: DefaultHealthPollInterval,
+		flagged:      map[string]string{},
+		deployments:  map[string]*deploymentHealth{},
+	}
+}
+
+// WithTimeout waits up to the timeout for a VM to become healthy, zero keeps the default
+func (v *HealthVerifier) WithTimeout(timeout time.Duration) *HealthVerifier {
+	if timeout > 0 {
+		v.timeout = timeout
+	}
+	return v
+}
+
+// WithPollInterval checks the VM's health every poll interval, zero keeps the default
+func (v *HealthVerifier) WithPollInterval(pollInterval time.Duration) *HealthVerifier {
+	if pollInterval > 0 {
+		v.pollInterval = pollInterval
+	}
+	return v
+}
+
+// WithFlagOnly flags unhealthy VMs in the report instead of failing their migration
+func (v *HealthVerifier) WithFlagOnly(flagOnly bool) *HealthVerifier {
+	v.flagOnly = flagOnly
+	return v
+}
+
+// FlagOnly returns true if unhealthy VMs are flagged instead of failed
+func (v *HealthVerifier) FlagOnly() bool {
+	return v.flagOnly
+}
+
+// Verify waits for BOSH to report the VM's instance is healthy, it returns an error with the last known health if
+// that doesn't happen within the timeout. Stemcells and additional VMs aren't known to BOSH and are always healthy.
+func (v *HealthVerifier) Verify(ctx context.Context, vm VM) error {
+	if vm.Deployment == "" {
+		return nil
+	}
+	l := log.FromContext(ctx)
+
+	start := time.Now()
+	timeout := time.NewTimer(v.timeout)
+	defer timeout.Stop()
+	for {
+		h, err := v.instanceHealth(ctx, vm, start)
+		if err == nil && h.Healthy() {
+			return nil
+		}
+		if err == nil {
+			err = fmt.Errorf("%s", h)
+		}
+		l.Debugf("%s is not healthy yet: %s", vm.Name, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout.C:
+			return fmt.Errorf("BOSH did not report a healthy instance within %s: %w",
+				duration.HumanReadable(v.timeout), err)
+		case <-time.After(v.pollInterval):
+		}
+	}
+}
+
+// instanceHealth returns the VM's health from the deployment's last poll, polling BOSH again if that was before the
+// VM's verification started or more than a poll interval ago
+func (v *HealthVerifier) instanceHealth(ctx context.Context, vm VM, start time.Time) (bosh.InstanceHealth, error) {
+	v.mu.Lock()
+	d, ok := v.deployments[vm.Deployment]
+	if !ok {
+		d = &deploymentHealth{}
+		v.deployments[vm.Deployment] = d
+	}
+	v.mu.Unlock()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.polled.Before(start) || time.Since(d.polled) >= v.pollInterval {
+		d.instances, d.err = v.client.DeploymentHealth(ctx, vm.Deployment)
+		d.polled = time.Now()
+	}
+	if d.err != nil {
+		return bosh.InstanceHealth{}, d.err
+	}
+	h, ok := d.instances[vm.Name]
+	if !ok {
+		return bosh.InstanceHealth{}, fmt.Errorf("could not find VM %s in deployment %s", vm.Name, vm.Deployment)
+	}
+	return h, nil
+}
+
+// Flag records the VM as unhealthy for the report
+func (v *HealthVerifier) Flag(vm VM, err error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.flagged[vm.Name] = err.Error()
+}
+
+// Report prints the flagged VMs, if any
+func (v *HealthVerifier) Report(out Printer) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if len(v.flagged) == 0 {
+		return
+	}
+	var names []string
+	for n := range v.flagged {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	out.Printf("%d migrated VMs were not healthy in BOSH:", len(names))
+	for _, n := range names {
+		out.Printf("  %s - %s", n, v.flagged[n])
+	}
+}
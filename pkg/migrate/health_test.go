@@ -0,0 +1,105 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/bosh"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/migratefakes"
+)
+
+func TestHealthVerifierWaitsForHealthyInstance(t *testing.T) {
+	client := &migratefakes.FakeInstanceHealthClient{}
+	client.DeploymentHealthReturnsOnCall(0, nil, errors.New("unresponsive agent"))
+	client.DeploymentHealthReturnsOnCall(1, map[string]bosh.InstanceHealth{}, nil)
+	client.DeploymentHealthReturnsOnCall(2, map[string]bosh.InstanceHealth{
+		"vm-guid": {Instance: "router/guid", JobState: "starting"},
+	}, nil)
+	client.DeploymentHealthReturnsOnCall(3, map[string]bosh.InstanceHealth{
+		"vm-guid": {Instance: "router/guid", JobState: "running"},
+	}, nil)
+
+	v := migrate.NewHealthVerifier(client).WithPollInterval(time.Millisecond)
+	err := v.Verify(context.Background(), migrate.VM{Name: "vm-guid", Deployment: "cf-guid"})
+	require.NoError(t, err)
+	require.Equal(t, 4, client.DeploymentHealthCallCount())
+	_, deployment := client.DeploymentHealthArgsForCall(0)
+	require.Equal(t, "cf-guid", deployment)
+}
+
+func TestHealthVerifierSharesDeploymentPolls(t *testing.T) {
+	client := &migratefakes.FakeInstanceHealthClient{}
+	client.DeploymentHealthStub = func(context.Context, string) (map[string]bosh.InstanceHealth, error) {
+		// both VMs start waiting before the first poll returns
+		time.Sleep(50 * time.Millisecond)
+		return map[string]bosh.InstanceHealth{
+			"vm-guid1": {Instance: "router/guid1", JobState: "running"},
+			"vm-guid2": {Instance: "router/guid2", JobState: "running"},
+		}, nil
+	}
+
+	v := migrate.NewHealthVerifier(client).WithPollInterval(time.Minute)
+	var wg sync.WaitGroup
+	for _, name := range []string{"vm-guid1", "vm-guid2"} {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			require.NoError(t, v.Verify(context.Background(), migrate.VM{Name: name, Deployment: "cf-guid"}))
+		}(name)
+	}
+	wg.Wait()
+	require.Equal(t, 1, client.DeploymentHealthCallCount())
+
+	// a VM verified after the poll gets its own, so it never sees the instances from before it was migrated
+	require.NoError(t, v.Verify(context.Background(), migrate.VM{Name: "vm-guid1", Deployment: "cf-guid"}))
+	require.Equal(t, 2, client.DeploymentHealthCallCount())
+}
+
+func TestHealthVerifierTimesOut(t *testing.T) {
+	client := &migratefakes.FakeInstanceHealthClient{}
+	client.DeploymentHealthReturns(map[string]bosh.InstanceHealth{
+		"vm-guid": {
+			Instance:         "diego_cell/guid",
+			JobState:         "failing",
+			FailingProcesses: []string{"garden is failing"},
+		},
+	}, nil)
+
+	v := migrate.NewHealthVerifier(client).WithTimeout(20 * time.Millisecond).WithPollInterval(time.Millisecond)
+	err := v.Verify(context.Background(), migrate.VM{Name: "vm-guid", Deployment: "cf-guid"})
+	require.ErrorContains(t, err, "BOSH did not report a healthy instance within")
+	require.ErrorContains(t, err, "diego_cell/guid is failing: garden is failing")
+}
+
+func TestHealthVerifierSkipsVMsNotManagedByBOSH(t *testing.T) {
+	client := &migratefakes.FakeInstanceHealthClient{}
+	v := migrate.NewHealthVerifier(client)
+	require.NoError(t, v.Verify(context.Background(), migrate.VM{Name: "sc-guid"}))
+	require.NoError(t, v.Verify(context.Background(), migrate.VM{Name: "ops-manager", Additional: true}))
+	require.Equal(t, 0, client.DeploymentHealthCallCount())
+}
+
+func TestHealthVerifierReport(t *testing.T) {
+	v := migrate.NewHealthVerifier(&migratefakes.FakeInstanceHealthClient{})
+	out := log.NewBufferedStdout()
+	v.Report(out)
+	require.Empty(t, out.String())
+
+	v.Flag(migrate.VM{Name: "vm-2"}, errors.New("uaa/guid is failing"))
+	v.Flag(migrate.VM{Name: "vm-1"}, errors.New("router/guid is starting"))
+	v.Report(out)
+	require.Equal(t, "2 migrated VMs were not healthy in BOSH:"+
+		"  vm-1 - router/guid is starting"+
+		"  vm-2 - uaa/guid is failing", out.String())
+}
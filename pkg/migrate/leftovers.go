@@ -0,0 +1,338 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/bosh"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/proxy"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+// Leftover kinds, in the order they're reported
+const (
+	LeftoverBoshVM           = "BOSH VM"
+	LeftoverStemcell         = "stemcell"
+	LeftoverOrphanedStemcell = "orphaned stemcell"
+	LeftoverAdditionalVM     = "additional VM"
+	LeftoverUnknownVM        = "VM unknown to BOSH"
+	LeftoverEmptyFolder      = "empty folder"
+	LeftoverOrphanedDisk     = "orphaned disk"
+	LeftoverUnknownDisk      = "disk unknown to BOSH"
+)
+
+var leftoverKindOrder = []string{
+	LeftoverBoshVM,
+	LeftoverStemcell,
+	LeftoverOrphanedStemcell,
+	LeftoverAdditionalVM,
+	LeftoverUnknownVM,
+	LeftoverEmptyFolder,
+	LeftoverOrphanedDisk,
+	LeftoverUnknownDisk,
+}
+
+//counterfeiter:generate . LeftoversVCenterClient
+type LeftoversVCenterClient interface {
+	HostName() string
+	Datacenter() string
+	ClusterVMs(ctx context.Context, clusters []string) ([]vcenter.ClusterVM, error)
+	VMFolders(ctx context.Context, folderPath string) ([]vcenter.VMFolder, error)
+	DeleteFolder(ctx context.Context, folderPath string) error
+	DatastoreDisks(ctx context.Context, azName, datastore, dir string) ([]vcenter.DatastoreDisk, error)
+}
+
+// Leftover is a VM, folder or disk still on the source vCenter
+type Leftover struct {
+	Kind   string
+	Name   string
+	Detail string
+}
+
+// LeftoverScanner inventories the source clusters, VM folders and datastores after a migration and reports what
+// remains, cross-referenced against BOSH
+type LeftoverScanner struct {
+	vmSource       *VMSource
+	sourceClusters map[string][]string
+	datastores     []string
+	diskPath       string
+	sourceClient   func(az string) LeftoversVCenterClient
+	out            Printer
+
+	cleanup bool
+	prepare func(ctx context.Context) error
+	close   func(ctx context.Context)
+}
+
+// NewLeftoverScanner creates a new LeftoverScanner for the source clusters by AZ and the source datastores
+func NewLeftoverScanner(vmSource *VMSource, sourceClusters map[string][]string, datastores []string, diskPath string,
+	sourceClient func(az string) LeftoversVCenterClient, out Printer) *LeftoverScanner {
+
+	if diskPath == "" {
+		diskPath = DefaultDiskPath
+	}
+	return &LeftoverScanner{
+		vmSource:       vmSource,
+		sourceClusters: sourceClusters,
+		datastores:     datastores,
+		diskPath:       diskPath,
+		sourceClient:   sourceClient,
+		out:            out,
+	}
+}
+
+// NewLeftoverScannerFromConfig creates a new LeftoverScanner for the config's source vCenters
+func NewLeftoverScannerFromConfig(c config.Config) (*LeftoverScanner, error) {
+	dialer, err := proxy.NewDialer(c.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	clientPool := ConfigToVCenterClientPool(c, dialer)
+
+	var diskPath string
+	if c.Bosh != nil {
+		diskPath = c.Bosh.DiskPath
+	}
+	out := log.NewUpdatableStdout()
+	s := NewLeftoverScanner(NewVMSourceFromConfig(c, dialer), configToSourceClustersByAZ(c), nil, diskPath,
+		func(az string) LeftoversVCenterClient {
+			if sc := clientPool.GetSourceClientByAZ(az); sc != nil {
+				return sc
+			}
+			return nil
+		}, out)
+
+	mappingRules := NewMappingRules(c.NetworkNameMap(), c.DatastoreNameMap(), out)
+	s.close = func(ctx context.Context) {
+		clientPool.Close(ctx)
+		closeDialer(ctx, dialer)
+	}
+	s.prepare = func(ctx context.Context) error {
+		var sourceClients []InventoryClient
+		for _, sc := range uniqueClients(clientPool.GetSourceClients()) {
+			sourceClients = append(sourceClients, sc)
+		}
+		resolved, err := mappingRules.Resolve(ctx, sourceClients)
+		if err != nil {
+			return err
+		}
+		for ds := range resolved.Datastores {
+			s.datastores = append(s.datastores, ds)
+		}
+		sort.Strings(s.datastores)
+		return nil
+	}
+	return s, nil
+}
+
+// WithCleanup deletes any empty VM folders found, including folders left empty by deleting their sub-folders
+func (s *LeftoverScanner) WithCleanup(cleanup bool) *LeftoverScanner {
+	s.cleanup = cleanup
+	return s
+}
+
+// Scan finds and reports everything left on the source vCenters, deleting empty folders when cleanup is enabled
+func (s *LeftoverScanner) Scan(ctx context.Context) ([]Leftover, error) {
+	if s.close != nil {
+		defer s.close(ctx)
+	}
+	if s.prepare != nil {
+		err := s.prepare(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	boshVMs, err := s.vmSource.BoshClient.VMsAndStemcells(ctx)
+	if err != nil {
+		return nil, err
+	}
+	boshDisks, err := s.vmSource.BoshClient.OrphanedDisks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var leftovers []Leftover
+	for _, dc := range s.sourceDatacenters() {
+		found, err := s.scanDatacenter(ctx, dc, boshVMs, boshDisks)
+		if err != nil {
+			return nil, err
+		}
+		leftovers = append(leftovers, found...)
+	}
+	sortLeftovers(leftovers)
+	s.report(leftovers)
+
+	if s.cleanup {
+		for _, dc := range s.sourceDatacenters() {
+			err := s.deleteEmptyFolders(ctx, dc)
+			if err != nil {
+				return leftovers, err
+			}
+		}
+	}
+	return leftovers, nil
+}
+
+// sourceDatacenter is a source vCenter datacenter and the clusters of all the AZs it serves
+type sourceDatacenter struct {
+	az       string
+	client   LeftoversVCenterClient
+	clusters []string
+}
+
+// sourceDatacenters groups the source AZs by vCenter datacenter so each one is only scanned once
+func (s *LeftoverScanner) sourceDatacenters() []sourceDatacenter {
+	var azs []string
+	for az := range s.sourceClusters {
+		azs = append(azs, az)
+	}
+	sort.Strings(azs)
+
+	var result []sourceDatacenter
+	index := map[string]int{}
+	for _, az := range azs {
+		c := s.sourceClient(az)
+		if c == nil {
+			continue
+		}
+		key := c.HostName() + "/" + c.Datacenter()
+		i, ok := index[key]
+		if !ok {
+			i = len(result)
+			index[key] = i
+			result = append(result, sourceDatacenter{az: az, client: c})
+		}
+		for _, cl := range s.sourceClusters[az] {
+			if !containsString(result[i].clusters, cl) {
+				result[i].clusters = append(result[i].clusters, cl)
+			}
+		}
+	}
+	return result
+}
+
+func (s *LeftoverScanner) scanDatacenter(ctx context.Context, dc sourceDatacenter,
+	boshVMs []bosh.VM, boshDisks []bosh.Disk) ([]Leftover, error) {
+
+	l := log.FromContext(ctx)
+	var leftovers []Leftover
+
+	vms, err := dc.client.ClusterVMs(ctx, dc.clusters)
+	if err != nil {
+		return nil, err
+	}
+	for _, vm := range vms {
+		leftovers = append(leftovers, s.classifyVM(vm, boshVMs))
+	}
+
+	folders, err := dc.client.VMFolders(ctx, "/"+strings.Trim(dc.client.Datacenter(), "/")+"/vm")
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range folders {
+		if f.Empty() {
+			leftovers = append(leftovers, Leftover{Kind: LeftoverEmptyFolder, Name: f.Path})
+		}
+	}
+
+	orphaned := map[string]bool{}
+	for _, d := range boshDisks {
+		orphaned[vcenter.DiskFileName(d.CID)] = true
+	}
+	for _, ds := range s.datastores {
+		disks, err := dc.client.DatastoreDisks(ctx, dc.az, ds, s.diskPath)
+		if err != nil {
+			// the datastore may only exist in another datacenter
+			l.Debugf("Skipping datastore %s in %s: %s", ds, dc.client.Datacenter(), err)
+			continue
+		}
+		for _, d := range disks {
+			kind := LeftoverUnknownDisk
+			if orphaned[path.Base(d.Path)] {
+				kind = LeftoverOrphanedDisk
+			}
+			leftovers = append(leftovers, Leftover{Kind: kind, Name: d.DatastorePath()})
+		}
+	}
+	return leftovers, nil
+}
+
+func (s *LeftoverScanner) classifyVM(vm vcenter.ClusterVM, boshVMs []bosh.VM) Leftover {
+	detail := vm.Cluster + " " + vm.Folder
+	for _, bvm := range boshVMs {
+		if bvm.Name != vm.Name {
+			continue
+		}
+		if bvm.Deployment == "" {
+			return Leftover{Kind: LeftoverStemcell, Name: vm.Name, Detail: detail}
+		}
+		return Leftover{
+			Kind:   LeftoverBoshVM,
+			Name:   vm.Name,
+			Detail: bvm.Deployment + "/" + bvm.InstanceGroup + " " + detail,
+		}
+	}
+	for _, avm := range s.vmSource.additionalVMs {
+		if avm.Name == vm.Name {
+			return Leftover{Kind: LeftoverAdditionalVM, Name: vm.Name, Detail: detail}
+		}
+	}
+	if strings.HasPrefix(vm.Name, "sc-") {
+		return Leftover{Kind: LeftoverOrphanedStemcell, Name: vm.Name, Detail: detail}
+	}
+	return Leftover{Kind: LeftoverUnknownVM, Name: vm.Name, Detail: detail}
+}
+
+// deleteEmptyFolders deletes the empty folders, including any parent folders they leave empty
+func (s *LeftoverScanner) deleteEmptyFolders(ctx context.Context, dc sourceDatacenter) error {
+	folders, err := dc.client.VMFolders(ctx, "/"+strings.Trim(dc.client.Datacenter(), "/")+"/vm")
+	if err != nil {
+		return err
+	}
+	for _, p := range removableFolders(folders, nil) {
+		err = dc.client.DeleteFolder(ctx, p)
+		if err != nil {
+			return err
+		}
+		s.out.Printf("Deleted empty folder %s", p)
+	}
+	return nil
+}
+
+func (s *LeftoverScanner) report(leftovers []Leftover) {
+	if len(leftovers) == 0 {
+		s.out.Printf("No leftovers found on the source vCenter")
+		return
+	}
+	s.out.Printf("Found %d leftovers on the source vCenter:", len(leftovers))
+	for _, lo := range leftovers {
+		if lo.Detail == "" {
+			s.out.Printf("  %s: %s", lo.Kind, lo.Name)
+			continue
+		}
+		s.out.Printf("  %s: %s (%s)", lo.Kind, lo.Name, lo.Detail)
+	}
+}
+
+func sortLeftovers(leftovers []Leftover) {
+	order := map[string]int{}
+	for i, k := range leftoverKindOrder {
+		order[k] = i
+	}
+	sort.SliceStable(leftovers, func(i, j int) bool {
+		if leftovers[i].Kind != leftovers[j].Kind {
+			return order[leftovers[i].Kind] < order[leftovers[j].Kind]
+		}
+		return leftovers[i].Name < leftovers[j].Name
+	})
+}
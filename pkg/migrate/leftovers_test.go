@@ -0,0 +1,113 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/bosh"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/migratefakes"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+func newTestLeftoverScanner() (*migrate.LeftoverScanner, *migratefakes.FakeLeftoversVCenterClient, *log.BufferedStdout) {
+	boshClient := &migratefakes.FakeBoshClient{}
+	boshClient.VMsAndStemcellsReturns([]bosh.VM{
+		{Name: "vm-1", AZ: "az1", Deployment: "cf", InstanceGroup: "router"},
+		{Name: "sc-1", AZ: "az1"},
+	}, nil)
+	boshClient.OrphanedDisksReturns([]bosh.Disk{{CID: "disk-1.eyJ0YXJnZXQiOiJ4In0", AZ: "az1"}}, nil)
+
+	client := &migratefakes.FakeLeftoversVCenterClient{}
+	client.HostNameReturns("vcenter1.example.com")
+	client.DatacenterReturns("DC1")
+	client.ClusterVMsReturns([]vcenter.ClusterVM{
+		{Name: "sc-1", Cluster: "Cluster1", Folder: "/DC1/vm/pcf_templates/guid"},
+		{Name: "sc-2", Cluster: "Cluster1", Folder: "/DC1/vm/pcf_templates/guid"},
+		{Name: "vm-1", Cluster: "Cluster1", Folder: "/DC1/vm/pcf_vms/guid"},
+		{Name: "vm-2", Cluster: "Cluster2", Folder: "/DC1/vm/pcf_vms/guid"},
+	}, nil)
+	client.VMFoldersReturns([]vcenter.VMFolder{
+		{Path: "/DC1/vm/pcf_vms", Children: 1},
+		{Path: "/DC1/vm/pcf_vms/guid", Children: 1},
+		{Path: "/DC1/vm/pcf_vms/old-guid", Children: 0},
+	}, nil)
+	client.DatastoreDisksReturns([]vcenter.DatastoreDisk{
+		{Datastore: "ds1", Path: "pcf_disk/disk-1.vmdk"},
+		{Datastore: "ds1", Path: "pcf_disk/disk-2.vmdk"},
+	}, nil)
+
+	out := log.NewBufferedStdout()
+	s := migrate.NewLeftoverScanner(&migrate.VMSource{BoshClient: boshClient},
+		map[string][]string{
+			"az1": {"Cluster1"},
+			"az2": {"Cluster2"},
+		}, []string{"ds1"}, "",
+		func(string) migrate.LeftoversVCenterClient { return client }, out)
+	return s, client, out
+}
+
+func TestLeftoverScan(t *testing.T) {
+	s, client, out := newTestLeftoverScanner()
+	leftovers, err := s.Scan(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []migrate.Leftover{
+		{Kind: migrate.LeftoverBoshVM, Name: "vm-1", Detail: "cf/router Cluster1 /DC1/vm/pcf_vms/guid"},
+		{Kind: migrate.LeftoverStemcell, Name: "sc-1", Detail: "Cluster1 /DC1/vm/pcf_templates/guid"},
+		{Kind: migrate.LeftoverOrphanedStemcell, Name: "sc-2", Detail: "Cluster1 /DC1/vm/pcf_templates/guid"},
+		{Kind: migrate.LeftoverUnknownVM, Name: "vm-2", Detail: "Cluster2 /DC1/vm/pcf_vms/guid"},
+		{Kind: migrate.LeftoverEmptyFolder, Name: "/DC1/vm/pcf_vms/old-guid"},
+		{Kind: migrate.LeftoverOrphanedDisk, Name: "[ds1] pcf_disk/disk-1.vmdk"},
+		{Kind: migrate.LeftoverUnknownDisk, Name: "[ds1] pcf_disk/disk-2.vmdk"},
+	}, leftovers)
+	require.Contains(t, out.String(), "Found 7 leftovers on the source vCenter:")
+	require.Contains(t, out.String(), "  empty folder: /DC1/vm/pcf_vms/old-guid")
+
+	// both AZs share a datacenter so it's only scanned once
+	require.Equal(t, 1, client.ClusterVMsCallCount())
+	_, clusters := client.ClusterVMsArgsForCall(0)
+	require.Equal(t, []string{"Cluster1", "Cluster2"}, clusters)
+	_, _, ds, dir := client.DatastoreDisksArgsForCall(0)
+	require.Equal(t, "ds1", ds)
+	require.Equal(t, "pcf_disk", dir)
+	require.Equal(t, 0, client.DeleteFolderCallCount())
+}
+
+func TestLeftoverScanWithCleanup(t *testing.T) {
+	s, client, out := newTestLeftoverScanner()
+	client.VMFoldersReturnsOnCall(1, []vcenter.VMFolder{
+		{Path: "/DC1/vm/pcf_templates", Children: 1},
+		{Path: "/DC1/vm/pcf_templates/guid", Children: 0},
+		{Path: "/DC1/vm/pcf_vms", Children: 1},
+	}, nil)
+
+	_, err := s.WithCleanup(true).Scan(context.Background())
+	require.NoError(t, err)
+	// deleting the empty sub-folder leaves its parent empty
+	require.Equal(t, 2, client.DeleteFolderCallCount())
+	_, p := client.DeleteFolderArgsForCall(0)
+	require.Equal(t, "/DC1/vm/pcf_templates/guid", p)
+	_, p = client.DeleteFolderArgsForCall(1)
+	require.Equal(t, "/DC1/vm/pcf_templates", p)
+	require.Contains(t, out.String(), "Deleted empty folder /DC1/vm/pcf_templates/guid")
+}
+
+func TestLeftoverScanNothingLeft(t *testing.T) {
+	client := &migratefakes.FakeLeftoversVCenterClient{}
+	out := log.NewBufferedStdout()
+	s := migrate.NewLeftoverScanner(&migrate.VMSource{BoshClient: migrate.NullBoshClient{}},
+		map[string][]string{"az1": {"Cluster1"}}, []string{"ds1"}, "",
+		func(string) migrate.LeftoversVCenterClient { return client }, out)
+
+	leftovers, err := s.Scan(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, leftovers)
+	require.Equal(t, "No leftovers found on the source vCenter", out.String())
+}
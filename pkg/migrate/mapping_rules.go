@@ -0,0 +1,115 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+//counterfeiter:generate . InventoryClient
+type InventoryClient interface {
+	Inventory(ctx context.Context) (*vcenter.Inventory, error)
+}
+
+type Printer interface {
+	Printf(format string, a ...interface{})
+}
+
+// MappingRules resolves the network and datastore rules against the source vCenter inventory before the
+// migration starts, into an exact mapping for every matching source network and datastore
+type MappingRules struct {
+	networks   config.NameMap
+	datastores config.NameMap
+	out        Printer
+}
+
+// ResolvedMappings are the exact network and datastore mappings with every rule resolved
+type ResolvedMappings struct {
+	Networks   map[string]string
+	Datastores map[string]string
+}
+
+// NewMappingRules creates a new MappingRules, the name maps are never modified
+func NewMappingRules(networks, datastores config.NameMap, out Printer) *MappingRules {
+	return &MappingRules{
+		networks:   networks,
+		datastores: datastores,
+		out:        out,
+	}
+}
+
+// Resolve prints the evaluation order of the mappings, ensures every rule matches at least one source object
+// and then returns the exact mappings plus the resolved mapping of every other matching source object
+func (r *MappingRules) Resolve(ctx context.Context, sourceClients []InventoryClient) (ResolvedMappings, error) {
+	resolved := ResolvedMappings{
+		Networks:   copyMappings(map[string]string{}, r.networks.Exact),
+		Datastores: copyMappings(map[string]string{}, r.datastores.Exact),
+	}
+	if !r.networks.HasRules() && !r.datastores.HasRules() {
+		return resolved, nil
+	}
+
+	var networks, datastores []string
+	for _, c := range sourceClients {
+		inv, err := c.Inventory(ctx)
+		if err != nil {
+			return ResolvedMappings{}, fmt.Errorf("could not get source vCenter inventory to resolve mapping rules: %w", err)
+		}
+		networks = append(networks, inv.Networks...)
+		datastores = append(datastores, inv.Datastores...)
+	}
+
+	for _, m := range []struct {
+		nameMap     config.NameMap
+		sourceNames []string
+		resolved    map[string]string
+	}{
+		{r.networks, networks, resolved.Networks},
+		{r.datastores, datastores, resolved.Datastores},
+	} {
+		if !m.nameMap.HasRules() {
+			continue
+		}
+		r.printEvaluationOrder(m.nameMap)
+		if unmatched := m.nameMap.UnmatchedRules(m.sourceNames); len(unmatched) > 0 {
+			var rules []string
+			for _, u := range unmatched {
+				rules = append(rules, u.String())
+			}
+			return ResolvedMappings{}, fmt.Errorf("%s rules did not match any source %s: %s",
+				m.nameMap.Kind, m.nameMap.Kind, strings.Join(rules, ", "))
+		}
+		for _, n := range m.sourceNames {
+			if _, ok := m.resolved[n]; ok {
+				continue
+			}
+			if t, ok := m.nameMap.Target(n); ok {
+				m.resolved[n] = t
+			}
+		}
+	}
+	return resolved, nil
+}
+
+func (r *MappingRules) printEvaluationOrder(m config.NameMap) {
+	r.out.Printf("%s mappings in evaluation order:", strings.ToUpper(m.Kind[:1])+m.Kind[1:])
+	for i, o := range m.EvaluationOrder() {
+		r.out.Printf("  %d. %s", i+1, o)
+	}
+}
+
+// copyMappings adds every source to target mapping to dst and returns it
+func copyMappings(dst, src map[string]string) map[string]string {
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/migratefakes"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+func TestMappingRulesResolve(t *testing.T) {
+	client := &migratefakes.FakeInventoryClient{}
+	client.InventoryReturns(&vcenter.Inventory{
+		Networks:   []string{"pcf-infra", "pcf-services", "VM Network"},
+		Datastores: []string{"ds1", "old-ds2"},
+	}, nil)
+
+	networks := config.NameMap{
+		Kind:  "network",
+		Exact: map[string]string{"pcf-services": "tas-svc"},
+		Rules: []config.MappingRule{{Glob: "pcf-*", Target: "tas-$1"}},
+	}
+	datastores := config.NameMap{
+		Kind:     "datastore",
+		Exact:    map[string]string{},
+		Rules:    []config.MappingRule{{Pattern: "^old-(.*)$", Target: "new-$1"}},
+		Identity: true,
+	}
+	out := log.NewBufferedStdout()
+	resolved, err := migrate.NewMappingRules(networks, datastores, out).Resolve(context.Background(),
+		[]migrate.InventoryClient{client})
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]string{
+		"pcf-infra":    "tas-infra",
+		"pcf-services": "tas-svc",
+	}, resolved.Networks)
+	require.Equal(t, map[string]string{
+		"ds1":     "ds1",
+		"old-ds2": "new-ds2",
+	}, resolved.Datastores)
+
+	// the config's exact mappings are left as they were
+	require.Equal(t, map[string]string{"pcf-services": "tas-svc"}, networks.Exact)
+	require.Empty(t, datastores.Exact)
+	require.Contains(t, out.String(), "Network mappings in evaluation order:")
+	require.Contains(t, out.String(), "  2. glob pcf-* -> tas-$1")
+	require.Contains(t, out.String(), "  2. default: same name as the source")
+}
+
+func TestMappingRulesResolve_UnmatchedRule(t *testing.T) {
+	client := &migratefakes.FakeInventoryClient{}
+	client.InventoryReturns(&vcenter.Inventory{Networks: []string{"net1"}}, nil)
+
+	networks := config.NameMap{
+		Kind:  "network",
+		Exact: map[string]string{},
+		Rules: []config.MappingRule{{Glob: "pcf-*", Target: "tas-$1"}},
+	}
+	_, err := migrate.NewMappingRules(networks, config.NameMap{Kind: "datastore"}, log.NewBufferedStdout()).
+		Resolve(context.Background(), []migrate.InventoryClient{client})
+	require.EqualError(t, err, "network rules did not match any source network: glob pcf-* -> tas-$1")
+}
+
+func TestMappingRulesResolve_InventoryError(t *testing.T) {
+	client := &migratefakes.FakeInventoryClient{}
+	client.InventoryReturns(nil, errors.New("vcenter unavailable"))
+
+	networks := config.NameMap{Kind: "network", Identity: true}
+	_, err := migrate.NewMappingRules(networks, config.NameMap{Kind: "datastore"}, log.NewBufferedStdout()).
+		Resolve(context.Background(), []migrate.InventoryClient{client})
+	require.EqualError(t, err, "could not get source vCenter inventory to resolve mapping rules: vcenter unavailable")
+}
+
+func TestMappingRulesResolve_NoRules(t *testing.T) {
+	client := &migratefakes.FakeInventoryClient{}
+	resolved, err := migrate.NewMappingRules(
+		config.NameMap{Kind: "network", Exact: map[string]string{"net1": "net2"}},
+		config.NameMap{Kind: "datastore"},
+		log.NewBufferedStdout()).Resolve(context.Background(), []migrate.InventoryClient{client})
+	require.NoError(t, err)
+	require.Equal(t, 0, client.InventoryCallCount())
+	require.Equal(t, map[string]string{"net1": "net2"}, resolved.Networks)
+	require.Empty(t, resolved.Datastores)
+}
@@ -10,6 +10,47 @@ import (
 )
 
 type FakeBoshClient struct {
+	DeploymentHealthStub        func(context.Context, string) (map[string]bosh.InstanceHealth, error)
+	deploymentHealthMutex       sync.RWMutex
+	deploymentHealthArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	deploymentHealthReturns struct {
+		result1 map[string]bosh.InstanceHealth
+		result2 error
+	}
+	deploymentHealthReturnsOnCall map[int]struct {
+		result1 map[string]bosh.InstanceHealth
+		result2 error
+	}
+	OrphanedDisksStub        func(context.Context) ([]bosh.Disk, error)
+	orphanedDisksMutex       sync.RWMutex
+	orphanedDisksArgsForCall []struct {
+		arg1 context.Context
+	}
+	orphanedDisksReturns struct {
+		result1 []bosh.Disk
+		result2 error
+	}
+	orphanedDisksReturnsOnCall map[int]struct {
+		result1 []bosh.Disk
+		result2 error
+	}
+	UnhealthyInstancesStub        func(context.Context, string) ([]string, error)
+	unhealthyInstancesMutex       sync.RWMutex
+	unhealthyInstancesArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	unhealthyInstancesReturns struct {
+		result1 []string
+		result2 error
+	}
+	unhealthyInstancesReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
 	VMsAndStemcellsStub        func(context.Context) ([]bosh.VM, error)
 	vMsAndStemcellsMutex       sync.RWMutex
 	vMsAndStemcellsArgsForCall []struct {
@@ -27,6 +68,200 @@ type FakeBoshClient struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeBoshClient) DeploymentHealth(arg1 context.Context, arg2 string) (map[string]bosh.InstanceHealth, error) {
+	fake.deploymentHealthMutex.Lock()
+	ret, specificReturn := fake.deploymentHealthReturnsOnCall[len(fake.deploymentHealthArgsForCall)]
+	fake.deploymentHealthArgsForCall = append(fake.deploymentHealthArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.DeploymentHealthStub
+	fakeReturns := fake.deploymentHealthReturns
+	fake.recordInvocation("DeploymentHealth", []interface{}{arg1, arg2})
+	fake.deploymentHealthMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeBoshClient) DeploymentHealthCallCount() int {
+	fake.deploymentHealthMutex.RLock()
+	defer fake.deploymentHealthMutex.RUnlock()
+	return len(fake.deploymentHealthArgsForCall)
+}
+
+func (fake *FakeBoshClient) DeploymentHealthCalls(stub func(context.Context, string) (map[string]bosh.InstanceHealth, error)) {
+	fake.deploymentHealthMutex.Lock()
+	defer fake.deploymentHealthMutex.Unlock()
+	fake.DeploymentHealthStub = stub
+}
+
+func (fake *FakeBoshClient) DeploymentHealthArgsForCall(i int) (context.Context, string) {
+	fake.deploymentHealthMutex.RLock()
+	defer fake.deploymentHealthMutex.RUnlock()
+	argsForCall := fake.deploymentHealthArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeBoshClient) DeploymentHealthReturns(result1 map[string]bosh.InstanceHealth, result2 error) {
+	fake.deploymentHealthMutex.Lock()
+	defer fake.deploymentHealthMutex.Unlock()
+	fake.DeploymentHealthStub = nil
+	fake.deploymentHealthReturns = struct {
+		result1 map[string]bosh.InstanceHealth
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBoshClient) DeploymentHealthReturnsOnCall(i int, result1 map[string]bosh.InstanceHealth, result2 error) {
+	fake.deploymentHealthMutex.Lock()
+	defer fake.deploymentHealthMutex.Unlock()
+	fake.DeploymentHealthStub = nil
+	if fake.deploymentHealthReturnsOnCall == nil {
+		fake.deploymentHealthReturnsOnCall = make(map[int]struct {
+			result1 map[string]bosh.InstanceHealth
+			result2 error
+		})
+	}
+	fake.deploymentHealthReturnsOnCall[i] = struct {
+		result1 map[string]bosh.InstanceHealth
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBoshClient) OrphanedDisks(arg1 context.Context) ([]bosh.Disk, error) {
+	fake.orphanedDisksMutex.Lock()
+	ret, specificReturn := fake.orphanedDisksReturnsOnCall[len(fake.orphanedDisksArgsForCall)]
+	fake.orphanedDisksArgsForCall = append(fake.orphanedDisksArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.OrphanedDisksStub
+	fakeReturns := fake.orphanedDisksReturns
+	fake.recordInvocation("OrphanedDisks", []interface{}{arg1})
+	fake.orphanedDisksMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeBoshClient) OrphanedDisksCallCount() int {
+	fake.orphanedDisksMutex.RLock()
+	defer fake.orphanedDisksMutex.RUnlock()
+	return len(fake.orphanedDisksArgsForCall)
+}
+
+func (fake *FakeBoshClient) OrphanedDisksCalls(stub func(context.Context) ([]bosh.Disk, error)) {
+	fake.orphanedDisksMutex.Lock()
+	defer fake.orphanedDisksMutex.Unlock()
+	fake.OrphanedDisksStub = stub
+}
+
+func (fake *FakeBoshClient) OrphanedDisksArgsForCall(i int) context.Context {
+	fake.orphanedDisksMutex.RLock()
+	defer fake.orphanedDisksMutex.RUnlock()
+	argsForCall := fake.orphanedDisksArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeBoshClient) OrphanedDisksReturns(result1 []bosh.Disk, result2 error) {
+	fake.orphanedDisksMutex.Lock()
+	defer fake.orphanedDisksMutex.Unlock()
+	fake.OrphanedDisksStub = nil
+	fake.orphanedDisksReturns = struct {
+		result1 []bosh.Disk
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBoshClient) OrphanedDisksReturnsOnCall(i int, result1 []bosh.Disk, result2 error) {
+	fake.orphanedDisksMutex.Lock()
+	defer fake.orphanedDisksMutex.Unlock()
+	fake.OrphanedDisksStub = nil
+	if fake.orphanedDisksReturnsOnCall == nil {
+		fake.orphanedDisksReturnsOnCall = make(map[int]struct {
+			result1 []bosh.Disk
+			result2 error
+		})
+	}
+	fake.orphanedDisksReturnsOnCall[i] = struct {
+		result1 []bosh.Disk
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBoshClient) UnhealthyInstances(arg1 context.Context, arg2 string) ([]string, error) {
+	fake.unhealthyInstancesMutex.Lock()
+	ret, specificReturn := fake.unhealthyInstancesReturnsOnCall[len(fake.unhealthyInstancesArgsForCall)]
+	fake.unhealthyInstancesArgsForCall = append(fake.unhealthyInstancesArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.UnhealthyInstancesStub
+	fakeReturns := fake.unhealthyInstancesReturns
+	fake.recordInvocation("UnhealthyInstances", []interface{}{arg1, arg2})
+	fake.unhealthyInstancesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeBoshClient) UnhealthyInstancesCallCount() int {
+	fake.unhealthyInstancesMutex.RLock()
+	defer fake.unhealthyInstancesMutex.RUnlock()
+	return len(fake.unhealthyInstancesArgsForCall)
+}
+
+func (fake *FakeBoshClient) UnhealthyInstancesCalls(stub func(context.Context, string) ([]string, error)) {
+	fake.unhealthyInstancesMutex.Lock()
+	defer fake.unhealthyInstancesMutex.Unlock()
+	fake.UnhealthyInstancesStub = stub
+}
+
+func (fake *FakeBoshClient) UnhealthyInstancesArgsForCall(i int) (context.Context, string) {
+	fake.unhealthyInstancesMutex.RLock()
+	defer fake.unhealthyInstancesMutex.RUnlock()
+	argsForCall := fake.unhealthyInstancesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeBoshClient) UnhealthyInstancesReturns(result1 []string, result2 error) {
+	fake.unhealthyInstancesMutex.Lock()
+	defer fake.unhealthyInstancesMutex.Unlock()
+	fake.UnhealthyInstancesStub = nil
+	fake.unhealthyInstancesReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBoshClient) UnhealthyInstancesReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.unhealthyInstancesMutex.Lock()
+	defer fake.unhealthyInstancesMutex.Unlock()
+	fake.UnhealthyInstancesStub = nil
+	if fake.unhealthyInstancesReturnsOnCall == nil {
+		fake.unhealthyInstancesReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.unhealthyInstancesReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeBoshClient) VMsAndStemcells(arg1 context.Context) ([]bosh.VM, error) {
 	fake.vMsAndStemcellsMutex.Lock()
 	ret, specificReturn := fake.vMsAndStemcellsReturnsOnCall[len(fake.vMsAndStemcellsArgsForCall)]
@@ -94,6 +329,12 @@ func (fake *FakeBoshClient) VMsAndStemcellsReturnsOnCall(i int, result1 []bosh.V
 func (fake *FakeBoshClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.deploymentHealthMutex.RLock()
+	defer fake.deploymentHealthMutex.RUnlock()
+	fake.orphanedDisksMutex.RLock()
+	defer fake.orphanedDisksMutex.RUnlock()
+	fake.unhealthyInstancesMutex.RLock()
+	defer fake.unhealthyInstancesMutex.RUnlock()
 	fake.vMsAndStemcellsMutex.RLock()
 	defer fake.vMsAndStemcellsMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
@@ -0,0 +1,118 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package migratefakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cloudfoundry-community/gogobosh"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+)
+
+type FakeCloudConfigBoshClient struct {
+	CloudAndCPIConfigsStub        func(context.Context) ([]gogobosh.Cfg, error)
+	cloudAndCPIConfigsMutex       sync.RWMutex
+	cloudAndCPIConfigsArgsForCall []struct {
+		arg1 context.Context
+	}
+	cloudAndCPIConfigsReturns struct {
+		result1 []gogobosh.Cfg
+		result2 error
+	}
+	cloudAndCPIConfigsReturnsOnCall map[int]struct {
+		result1 []gogobosh.Cfg
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeCloudConfigBoshClient) CloudAndCPIConfigs(arg1 context.Context) ([]gogobosh.Cfg, error) {
+	fake.cloudAndCPIConfigsMutex.Lock()
+	ret, specificReturn := fake.cloudAndCPIConfigsReturnsOnCall[len(fake.cloudAndCPIConfigsArgsForCall)]
+	fake.cloudAndCPIConfigsArgsForCall = append(fake.cloudAndCPIConfigsArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.CloudAndCPIConfigsStub
+	fakeReturns := fake.cloudAndCPIConfigsReturns
+	fake.recordInvocation("CloudAndCPIConfigs", []interface{}{arg1})
+	fake.cloudAndCPIConfigsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCloudConfigBoshClient) CloudAndCPIConfigsCallCount() int {
+	fake.cloudAndCPIConfigsMutex.RLock()
+	defer fake.cloudAndCPIConfigsMutex.RUnlock()
+	return len(fake.cloudAndCPIConfigsArgsForCall)
+}
+
+func (fake *FakeCloudConfigBoshClient) CloudAndCPIConfigsCalls(stub func(context.Context) ([]gogobosh.Cfg, error)) {
+	fake.cloudAndCPIConfigsMutex.Lock()
+	defer fake.cloudAndCPIConfigsMutex.Unlock()
+	fake.CloudAndCPIConfigsStub = stub
+}
+
+func (fake *FakeCloudConfigBoshClient) CloudAndCPIConfigsArgsForCall(i int) context.Context {
+	fake.cloudAndCPIConfigsMutex.RLock()
+	defer fake.cloudAndCPIConfigsMutex.RUnlock()
+	argsForCall := fake.cloudAndCPIConfigsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCloudConfigBoshClient) CloudAndCPIConfigsReturns(result1 []gogobosh.Cfg, result2 error) {
+	fake.cloudAndCPIConfigsMutex.Lock()
+	defer fake.cloudAndCPIConfigsMutex.Unlock()
+	fake.CloudAndCPIConfigsStub = nil
+	fake.cloudAndCPIConfigsReturns = struct {
+		result1 []gogobosh.Cfg
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCloudConfigBoshClient) CloudAndCPIConfigsReturnsOnCall(i int, result1 []gogobosh.Cfg, result2 error) {
+	fake.cloudAndCPIConfigsMutex.Lock()
+	defer fake.cloudAndCPIConfigsMutex.Unlock()
+	fake.CloudAndCPIConfigsStub = nil
+	if fake.cloudAndCPIConfigsReturnsOnCall == nil {
+		fake.cloudAndCPIConfigsReturnsOnCall = make(map[int]struct {
+			result1 []gogobosh.Cfg
+			result2 error
+		})
+	}
+	fake.cloudAndCPIConfigsReturnsOnCall[i] = struct {
+		result1 []gogobosh.Cfg
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCloudConfigBoshClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.cloudAndCPIConfigsMutex.RLock()
+	defer fake.cloudAndCPIConfigsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeCloudConfigBoshClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ migrate.CloudConfigBoshClient = new(FakeCloudConfigBoshClient)
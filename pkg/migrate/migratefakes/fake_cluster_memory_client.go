@@ -0,0 +1,119 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package migratefakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+)
+
+type FakeClusterMemoryClient struct {
+	ClusterFreeMemoryMBStub        func(context.Context, string) (int64, error)
+	clusterFreeMemoryMBMutex       sync.RWMutex
+	clusterFreeMemoryMBArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	clusterFreeMemoryMBReturns struct {
+		result1 int64
+		result2 error
+	}
+	clusterFreeMemoryMBReturnsOnCall map[int]struct {
+		result1 int64
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeClusterMemoryClient) ClusterFreeMemoryMB(arg1 context.Context, arg2 string) (int64, error) {
+	fake.clusterFreeMemoryMBMutex.Lock()
+	ret, specificReturn := fake.clusterFreeMemoryMBReturnsOnCall[len(fake.clusterFreeMemoryMBArgsForCall)]
+	fake.clusterFreeMemoryMBArgsForCall = append(fake.clusterFreeMemoryMBArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ClusterFreeMemoryMBStub
+	fakeReturns := fake.clusterFreeMemoryMBReturns
+	fake.recordInvocation("ClusterFreeMemoryMB", []interface{}{arg1, arg2})
+	fake.clusterFreeMemoryMBMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClusterMemoryClient) ClusterFreeMemoryMBCallCount() int {
+	fake.clusterFreeMemoryMBMutex.RLock()
+	defer fake.clusterFreeMemoryMBMutex.RUnlock()
+	return len(fake.clusterFreeMemoryMBArgsForCall)
+}
+
+func (fake *FakeClusterMemoryClient) ClusterFreeMemoryMBCalls(stub func(context.Context, string) (int64, error)) {
+	fake.clusterFreeMemoryMBMutex.Lock()
+	defer fake.clusterFreeMemoryMBMutex.Unlock()
+	fake.ClusterFreeMemoryMBStub = stub
+}
+
+func (fake *FakeClusterMemoryClient) ClusterFreeMemoryMBArgsForCall(i int) (context.Context, string) {
+	fake.clusterFreeMemoryMBMutex.RLock()
+	defer fake.clusterFreeMemoryMBMutex.RUnlock()
+	argsForCall := fake.clusterFreeMemoryMBArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeClusterMemoryClient) ClusterFreeMemoryMBReturns(result1 int64, result2 error) {
+	fake.clusterFreeMemoryMBMutex.Lock()
+	defer fake.clusterFreeMemoryMBMutex.Unlock()
+	fake.ClusterFreeMemoryMBStub = nil
+	fake.clusterFreeMemoryMBReturns = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClusterMemoryClient) ClusterFreeMemoryMBReturnsOnCall(i int, result1 int64, result2 error) {
+	fake.clusterFreeMemoryMBMutex.Lock()
+	defer fake.clusterFreeMemoryMBMutex.Unlock()
+	fake.ClusterFreeMemoryMBStub = nil
+	if fake.clusterFreeMemoryMBReturnsOnCall == nil {
+		fake.clusterFreeMemoryMBReturnsOnCall = make(map[int]struct {
+			result1 int64
+			result2 error
+		})
+	}
+	fake.clusterFreeMemoryMBReturnsOnCall[i] = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClusterMemoryClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.clusterFreeMemoryMBMutex.RLock()
+	defer fake.clusterFreeMemoryMBMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeClusterMemoryClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ migrate.ClusterMemoryClient = new(FakeClusterMemoryClient)
@@ -0,0 +1,357 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package migratefakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+type FakeDirectorDiskVCenterClient struct {
+	DatacenterStub        func() string
+	datacenterMutex       sync.RWMutex
+	datacenterArgsForCall []struct {
+	}
+	datacenterReturns struct {
+		result1 string
+	}
+	datacenterReturnsOnCall map[int]struct {
+		result1 string
+	}
+	DetachDiskStub        func(context.Context, string, *vcenter.DatastoreDisk, *vcenter.DatastoreDisk) error
+	detachDiskMutex       sync.RWMutex
+	detachDiskArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 *vcenter.DatastoreDisk
+		arg4 *vcenter.DatastoreDisk
+	}
+	detachDiskReturns struct {
+		result1 error
+	}
+	detachDiskReturnsOnCall map[int]struct {
+		result1 error
+	}
+	FindDiskStub        func(context.Context, string, string, []string) (*vcenter.DatastoreDisk, error)
+	findDiskMutex       sync.RWMutex
+	findDiskArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 []string
+	}
+	findDiskReturns struct {
+		result1 *vcenter.DatastoreDisk
+		result2 error
+	}
+	findDiskReturnsOnCall map[int]struct {
+		result1 *vcenter.DatastoreDisk
+		result2 error
+	}
+	PersistentDiskStub        func(context.Context, string, string) (*vcenter.DatastoreDisk, error)
+	persistentDiskMutex       sync.RWMutex
+	persistentDiskArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	persistentDiskReturns struct {
+		result1 *vcenter.DatastoreDisk
+		result2 error
+	}
+	persistentDiskReturnsOnCall map[int]struct {
+		result1 *vcenter.DatastoreDisk
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDirectorDiskVCenterClient) Datacenter() string {
+	fake.datacenterMutex.Lock()
+	ret, specificReturn := fake.datacenterReturnsOnCall[len(fake.datacenterArgsForCall)]
+	fake.datacenterArgsForCall = append(fake.datacenterArgsForCall, struct {
+	}{})
+	stub := fake.DatacenterStub
+	fakeReturns := fake.datacenterReturns
+	fake.recordInvocation("Datacenter", []interface{}{})
+	fake.datacenterMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeDirectorDiskVCenterClient) DatacenterCallCount() int {
+	fake.datacenterMutex.RLock()
+	defer fake.datacenterMutex.RUnlock()
+	return len(fake.datacenterArgsForCall)
+}
+
+func (fake *FakeDirectorDiskVCenterClient) DatacenterCalls(stub func() string) {
+	fake.datacenterMutex.Lock()
+	defer fake.datacenterMutex.Unlock()
+	fake.DatacenterStub = stub
+}
+
+func (fake *FakeDirectorDiskVCenterClient) DatacenterReturns(result1 string) {
+	fake.datacenterMutex.Lock()
+	defer fake.datacenterMutex.Unlock()
+	fake.DatacenterStub = nil
+	fake.datacenterReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeDirectorDiskVCenterClient) DatacenterReturnsOnCall(i int, result1 string) {
+	fake.datacenterMutex.Lock()
+	defer fake.datacenterMutex.Unlock()
+	fake.DatacenterStub = nil
+	if fake.datacenterReturnsOnCall == nil {
+		fake.datacenterReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.datacenterReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeDirectorDiskVCenterClient) DetachDisk(arg1 context.Context, arg2 string, arg3 *vcenter.DatastoreDisk, arg4 *vcenter.DatastoreDisk) error {
+	fake.detachDiskMutex.Lock()
+	ret, specificReturn := fake.detachDiskReturnsOnCall[len(fake.detachDiskArgsForCall)]
+	fake.detachDiskArgsForCall = append(fake.detachDiskArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 *vcenter.DatastoreDisk
+		arg4 *vcenter.DatastoreDisk
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.DetachDiskStub
+	fakeReturns := fake.detachDiskReturns
+	fake.recordInvocation("DetachDisk", []interface{}{arg1, arg2, arg3, arg4})
+	fake.detachDiskMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeDirectorDiskVCenterClient) DetachDiskCallCount() int {
+	fake.detachDiskMutex.RLock()
+	defer fake.detachDiskMutex.RUnlock()
+	return len(fake.detachDiskArgsForCall)
+}
+
+func (fake *FakeDirectorDiskVCenterClient) DetachDiskCalls(stub func(context.Context, string, *vcenter.DatastoreDisk, *vcenter.DatastoreDisk) error) {
+	fake.detachDiskMutex.Lock()
+	defer fake.detachDiskMutex.Unlock()
+	fake.DetachDiskStub = stub
+}
+
+func (fake *FakeDirectorDiskVCenterClient) DetachDiskArgsForCall(i int) (context.Context, string, *vcenter.DatastoreDisk, *vcenter.DatastoreDisk) {
+	fake.detachDiskMutex.RLock()
+	defer fake.detachDiskMutex.RUnlock()
+	argsForCall := fake.detachDiskArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeDirectorDiskVCenterClient) DetachDiskReturns(result1 error) {
+	fake.detachDiskMutex.Lock()
+	defer fake.detachDiskMutex.Unlock()
+	fake.DetachDiskStub = nil
+	fake.detachDiskReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDirectorDiskVCenterClient) DetachDiskReturnsOnCall(i int, result1 error) {
+	fake.detachDiskMutex.Lock()
+	defer fake.detachDiskMutex.Unlock()
+	fake.DetachDiskStub = nil
+	if fake.detachDiskReturnsOnCall == nil {
+		fake.detachDiskReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.detachDiskReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDirectorDiskVCenterClient) FindDisk(arg1 context.Context, arg2 string, arg3 string, arg4 []string) (*vcenter.DatastoreDisk, error) {
+	var arg4Copy []string
+	if arg4 != nil {
+		arg4Copy = make([]string, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	fake.findDiskMutex.Lock()
+	ret, specificReturn := fake.findDiskReturnsOnCall[len(fake.findDiskArgsForCall)]
+	fake.findDiskArgsForCall = append(fake.findDiskArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 []string
+	}{arg1, arg2, arg3, arg4Copy})
+	stub := fake.FindDiskStub
+	fakeReturns := fake.findDiskReturns
+	fake.recordInvocation("FindDisk", []interface{}{arg1, arg2, arg3, arg4Copy})
+	fake.findDiskMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDirectorDiskVCenterClient) FindDiskCallCount() int {
+	fake.findDiskMutex.RLock()
+	defer fake.findDiskMutex.RUnlock()
+	return len(fake.findDiskArgsForCall)
+}
+
+func (fake *FakeDirectorDiskVCenterClient) FindDiskCalls(stub func(context.Context, string, string, []string) (*vcenter.DatastoreDisk, error)) {
+	fake.findDiskMutex.Lock()
+	defer fake.findDiskMutex.Unlock()
+	fake.FindDiskStub = stub
+}
+
+func (fake *FakeDirectorDiskVCenterClient) FindDiskArgsForCall(i int) (context.Context, string, string, []string) {
+	fake.findDiskMutex.RLock()
+	defer fake.findDiskMutex.RUnlock()
+	argsForCall := fake.findDiskArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeDirectorDiskVCenterClient) FindDiskReturns(result1 *vcenter.DatastoreDisk, result2 error) {
+	fake.findDiskMutex.Lock()
+	defer fake.findDiskMutex.Unlock()
+	fake.FindDiskStub = nil
+	fake.findDiskReturns = struct {
+		result1 *vcenter.DatastoreDisk
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDirectorDiskVCenterClient) FindDiskReturnsOnCall(i int, result1 *vcenter.DatastoreDisk, result2 error) {
+	fake.findDiskMutex.Lock()
+	defer fake.findDiskMutex.Unlock()
+	fake.FindDiskStub = nil
+	if fake.findDiskReturnsOnCall == nil {
+		fake.findDiskReturnsOnCall = make(map[int]struct {
+			result1 *vcenter.DatastoreDisk
+			result2 error
+		})
+	}
+	fake.findDiskReturnsOnCall[i] = struct {
+		result1 *vcenter.DatastoreDisk
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDirectorDiskVCenterClient) PersistentDisk(arg1 context.Context, arg2 string, arg3 string) (*vcenter.DatastoreDisk, error) {
+	fake.persistentDiskMutex.Lock()
+	ret, specificReturn := fake.persistentDiskReturnsOnCall[len(fake.persistentDiskArgsForCall)]
+	fake.persistentDiskArgsForCall = append(fake.persistentDiskArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.PersistentDiskStub
+	fakeReturns := fake.persistentDiskReturns
+	fake.recordInvocation("PersistentDisk", []interface{}{arg1, arg2, arg3})
+	fake.persistentDiskMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDirectorDiskVCenterClient) PersistentDiskCallCount() int {
+	fake.persistentDiskMutex.RLock()
+	defer fake.persistentDiskMutex.RUnlock()
+	return len(fake.persistentDiskArgsForCall)
+}
+
+func (fake *FakeDirectorDiskVCenterClient) PersistentDiskCalls(stub func(context.Context, string, string) (*vcenter.DatastoreDisk, error)) {
+	fake.persistentDiskMutex.Lock()
+	defer fake.persistentDiskMutex.Unlock()
+	fake.PersistentDiskStub = stub
+}
+
+func (fake *FakeDirectorDiskVCenterClient) PersistentDiskArgsForCall(i int) (context.Context, string, string) {
+	fake.persistentDiskMutex.RLock()
+	defer fake.persistentDiskMutex.RUnlock()
+	argsForCall := fake.persistentDiskArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeDirectorDiskVCenterClient) PersistentDiskReturns(result1 *vcenter.DatastoreDisk, result2 error) {
+	fake.persistentDiskMutex.Lock()
+	defer fake.persistentDiskMutex.Unlock()
+	fake.PersistentDiskStub = nil
+	fake.persistentDiskReturns = struct {
+		result1 *vcenter.DatastoreDisk
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDirectorDiskVCenterClient) PersistentDiskReturnsOnCall(i int, result1 *vcenter.DatastoreDisk, result2 error) {
+	fake.persistentDiskMutex.Lock()
+	defer fake.persistentDiskMutex.Unlock()
+	fake.PersistentDiskStub = nil
+	if fake.persistentDiskReturnsOnCall == nil {
+		fake.persistentDiskReturnsOnCall = make(map[int]struct {
+			result1 *vcenter.DatastoreDisk
+			result2 error
+		})
+	}
+	fake.persistentDiskReturnsOnCall[i] = struct {
+		result1 *vcenter.DatastoreDisk
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDirectorDiskVCenterClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.datacenterMutex.RLock()
+	defer fake.datacenterMutex.RUnlock()
+	fake.detachDiskMutex.RLock()
+	defer fake.detachDiskMutex.RUnlock()
+	fake.findDiskMutex.RLock()
+	defer fake.findDiskMutex.RUnlock()
+	fake.persistentDiskMutex.RLock()
+	defer fake.persistentDiskMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeDirectorDiskVCenterClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ migrate.DirectorDiskVCenterClient = new(FakeDirectorDiskVCenterClient)
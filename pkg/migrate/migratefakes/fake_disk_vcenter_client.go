@@ -0,0 +1,592 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package migratefakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+type FakeDiskVCenterClient struct {
+	CreateDiskVMStub        func(context.Context, string, *vcenter.DatastoreDisk, string, string) (*vcenter.VM, error)
+	createDiskVMMutex       sync.RWMutex
+	createDiskVMArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 *vcenter.DatastoreDisk
+		arg4 string
+		arg5 string
+	}
+	createDiskVMReturns struct {
+		result1 *vcenter.VM
+		result2 error
+	}
+	createDiskVMReturnsOnCall map[int]struct {
+		result1 *vcenter.VM
+		result2 error
+	}
+	DatacenterStub        func() string
+	datacenterMutex       sync.RWMutex
+	datacenterArgsForCall []struct {
+	}
+	datacenterReturns struct {
+		result1 string
+	}
+	datacenterReturnsOnCall map[int]struct {
+		result1 string
+	}
+	DeleteDiskVMStub        func(context.Context, string, *vcenter.DatastoreDisk) error
+	deleteDiskVMMutex       sync.RWMutex
+	deleteDiskVMArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 *vcenter.DatastoreDisk
+	}
+	deleteDiskVMReturns struct {
+		result1 error
+	}
+	deleteDiskVMReturnsOnCall map[int]struct {
+		result1 error
+	}
+	FindDiskStub        func(context.Context, string, string, []string) (*vcenter.DatastoreDisk, error)
+	findDiskMutex       sync.RWMutex
+	findDiskArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 []string
+	}
+	findDiskReturns struct {
+		result1 *vcenter.DatastoreDisk
+		result2 error
+	}
+	findDiskReturnsOnCall map[int]struct {
+		result1 *vcenter.DatastoreDisk
+		result2 error
+	}
+	FindVMInClustersStub        func(context.Context, string, string, []string) (*vcenter.VM, error)
+	findVMInClustersMutex       sync.RWMutex
+	findVMInClustersArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 []string
+	}
+	findVMInClustersReturns struct {
+		result1 *vcenter.VM
+		result2 error
+	}
+	findVMInClustersReturnsOnCall map[int]struct {
+		result1 *vcenter.VM
+		result2 error
+	}
+	HostNameStub        func() string
+	hostNameMutex       sync.RWMutex
+	hostNameArgsForCall []struct {
+	}
+	hostNameReturns struct {
+		result1 string
+	}
+	hostNameReturnsOnCall map[int]struct {
+		result1 string
+	}
+	MoveDiskStub        func(context.Context, *vcenter.DatastoreDisk, *vcenter.DatastoreDisk) error
+	moveDiskMutex       sync.RWMutex
+	moveDiskArgsForCall []struct {
+		arg1 context.Context
+		arg2 *vcenter.DatastoreDisk
+		arg3 *vcenter.DatastoreDisk
+	}
+	moveDiskReturns struct {
+		result1 error
+	}
+	moveDiskReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDiskVCenterClient) CreateDiskVM(arg1 context.Context, arg2 string, arg3 *vcenter.DatastoreDisk, arg4 string, arg5 string) (*vcenter.VM, error) {
+	fake.createDiskVMMutex.Lock()
+	ret, specificReturn := fake.createDiskVMReturnsOnCall[len(fake.createDiskVMArgsForCall)]
+	fake.createDiskVMArgsForCall = append(fake.createDiskVMArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 *vcenter.DatastoreDisk
+		arg4 string
+		arg5 string
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.CreateDiskVMStub
+	fakeReturns := fake.createDiskVMReturns
+	fake.recordInvocation("CreateDiskVM", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.createDiskVMMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDiskVCenterClient) CreateDiskVMCallCount() int {
+	fake.createDiskVMMutex.RLock()
+	defer fake.createDiskVMMutex.RUnlock()
+	return len(fake.createDiskVMArgsForCall)
+}
+
+func (fake *FakeDiskVCenterClient) CreateDiskVMCalls(stub func(context.Context, string, *vcenter.DatastoreDisk, string, string) (*vcenter.VM, error)) {
+	fake.createDiskVMMutex.Lock()
+	defer fake.createDiskVMMutex.Unlock()
+	fake.CreateDiskVMStub = stub
+}
+
+func (fake *FakeDiskVCenterClient) CreateDiskVMArgsForCall(i int) (context.Context, string, *vcenter.DatastoreDisk, string, string) {
+	fake.createDiskVMMutex.RLock()
+	defer fake.createDiskVMMutex.RUnlock()
+	argsForCall := fake.createDiskVMArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeDiskVCenterClient) CreateDiskVMReturns(result1 *vcenter.VM, result2 error) {
+	fake.createDiskVMMutex.Lock()
+	defer fake.createDiskVMMutex.Unlock()
+	fake.CreateDiskVMStub = nil
+	fake.createDiskVMReturns = struct {
+		result1 *vcenter.VM
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDiskVCenterClient) CreateDiskVMReturnsOnCall(i int, result1 *vcenter.VM, result2 error) {
+	fake.createDiskVMMutex.Lock()
+	defer fake.createDiskVMMutex.Unlock()
+	fake.CreateDiskVMStub = nil
+	if fake.createDiskVMReturnsOnCall == nil {
+		fake.createDiskVMReturnsOnCall = make(map[int]struct {
+			result1 *vcenter.VM
+			result2 error
+		})
+	}
+	fake.createDiskVMReturnsOnCall[i] = struct {
+		result1 *vcenter.VM
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDiskVCenterClient) Datacenter() string {
+	fake.datacenterMutex.Lock()
+	ret, specificReturn := fake.datacenterReturnsOnCall[len(fake.datacenterArgsForCall)]
+	fake.datacenterArgsForCall = append(fake.datacenterArgsForCall, struct {
+	}{})
+	stub := fake.DatacenterStub
+	fakeReturns := fake.datacenterReturns
+	fake.recordInvocation("Datacenter", []interface{}{})
+	fake.datacenterMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeDiskVCenterClient) DatacenterCallCount() int {
+	fake.datacenterMutex.RLock()
+	defer fake.datacenterMutex.RUnlock()
+	return len(fake.datacenterArgsForCall)
+}
+
+func (fake *FakeDiskVCenterClient) DatacenterCalls(stub func() string) {
+	fake.datacenterMutex.Lock()
+	defer fake.datacenterMutex.Unlock()
+	fake.DatacenterStub = stub
+}
+
+func (fake *FakeDiskVCenterClient) DatacenterReturns(result1 string) {
+	fake.datacenterMutex.Lock()
+	defer fake.datacenterMutex.Unlock()
+	fake.DatacenterStub = nil
+	fake.datacenterReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeDiskVCenterClient) DatacenterReturnsOnCall(i int, result1 string) {
+	fake.datacenterMutex.Lock()
+	defer fake.datacenterMutex.Unlock()
+	fake.DatacenterStub = nil
+	if fake.datacenterReturnsOnCall == nil {
+		fake.datacenterReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.datacenterReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeDiskVCenterClient) DeleteDiskVM(arg1 context.Context, arg2 string, arg3 *vcenter.DatastoreDisk) error {
+	fake.deleteDiskVMMutex.Lock()
+	ret, specificReturn := fake.deleteDiskVMReturnsOnCall[len(fake.deleteDiskVMArgsForCall)]
+	fake.deleteDiskVMArgsForCall = append(fake.deleteDiskVMArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 *vcenter.DatastoreDisk
+	}{arg1, arg2, arg3})
+	stub := fake.DeleteDiskVMStub
+	fakeReturns := fake.deleteDiskVMReturns
+	fake.recordInvocation("DeleteDiskVM", []interface{}{arg1, arg2, arg3})
+	fake.deleteDiskVMMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeDiskVCenterClient) DeleteDiskVMCallCount() int {
+	fake.deleteDiskVMMutex.RLock()
+	defer fake.deleteDiskVMMutex.RUnlock()
+	return len(fake.deleteDiskVMArgsForCall)
+}
+
+func (fake *FakeDiskVCenterClient) DeleteDiskVMCalls(stub func(context.Context, string, *vcenter.DatastoreDisk) error) {
+	fake.deleteDiskVMMutex.Lock()
+	defer fake.deleteDiskVMMutex.Unlock()
+	fake.DeleteDiskVMStub = stub
+}
+
+func (fake *FakeDiskVCenterClient) DeleteDiskVMArgsForCall(i int) (context.Context, string, *vcenter.DatastoreDisk) {
+	fake.deleteDiskVMMutex.RLock()
+	defer fake.deleteDiskVMMutex.RUnlock()
+	argsForCall := fake.deleteDiskVMArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeDiskVCenterClient) DeleteDiskVMReturns(result1 error) {
+	fake.deleteDiskVMMutex.Lock()
+	defer fake.deleteDiskVMMutex.Unlock()
+	fake.DeleteDiskVMStub = nil
+	fake.deleteDiskVMReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDiskVCenterClient) DeleteDiskVMReturnsOnCall(i int, result1 error) {
+	fake.deleteDiskVMMutex.Lock()
+	defer fake.deleteDiskVMMutex.Unlock()
+	fake.DeleteDiskVMStub = nil
+	if fake.deleteDiskVMReturnsOnCall == nil {
+		fake.deleteDiskVMReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteDiskVMReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDiskVCenterClient) FindDisk(arg1 context.Context, arg2 string, arg3 string, arg4 []string) (*vcenter.DatastoreDisk, error) {
+	var arg4Copy []string
+	if arg4 != nil {
+		arg4Copy = make([]string, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	fake.findDiskMutex.Lock()
+	ret, specificReturn := fake.findDiskReturnsOnCall[len(fake.findDiskArgsForCall)]
+	fake.findDiskArgsForCall = append(fake.findDiskArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 []string
+	}{arg1, arg2, arg3, arg4Copy})
+	stub := fake.FindDiskStub
+	fakeReturns := fake.findDiskReturns
+	fake.recordInvocation("FindDisk", []interface{}{arg1, arg2, arg3, arg4Copy})
+	fake.findDiskMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDiskVCenterClient) FindDiskCallCount() int {
+	fake.findDiskMutex.RLock()
+	defer fake.findDiskMutex.RUnlock()
+	return len(fake.findDiskArgsForCall)
+}
+
+func (fake *FakeDiskVCenterClient) FindDiskCalls(stub func(context.Context, string, string, []string) (*vcenter.DatastoreDisk, error)) {
+	fake.findDiskMutex.Lock()
+	defer fake.findDiskMutex.Unlock()
+	fake.FindDiskStub = stub
+}
+
+func (fake *FakeDiskVCenterClient) FindDiskArgsForCall(i int) (context.Context, string, string, []string) {
+	fake.findDiskMutex.RLock()
+	defer fake.findDiskMutex.RUnlock()
+	argsForCall := fake.findDiskArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeDiskVCenterClient) FindDiskReturns(result1 *vcenter.DatastoreDisk, result2 error) {
+	fake.findDiskMutex.Lock()
+	defer fake.findDiskMutex.Unlock()
+	fake.FindDiskStub = nil
+	fake.findDiskReturns = struct {
+		result1 *vcenter.DatastoreDisk
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDiskVCenterClient) FindDiskReturnsOnCall(i int, result1 *vcenter.DatastoreDisk, result2 error) {
+	fake.findDiskMutex.Lock()
+	defer fake.findDiskMutex.Unlock()
+	fake.FindDiskStub = nil
+	if fake.findDiskReturnsOnCall == nil {
+		fake.findDiskReturnsOnCall = make(map[int]struct {
+			result1 *vcenter.DatastoreDisk
+			result2 error
+		})
+	}
+	fake.findDiskReturnsOnCall[i] = struct {
+		result1 *vcenter.DatastoreDisk
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDiskVCenterClient) FindVMInClusters(arg1 context.Context, arg2 string, arg3 string, arg4 []string) (*vcenter.VM, error) {
+	var arg4Copy []string
+	if arg4 != nil {
+		arg4Copy = make([]string, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	fake.findVMInClustersMutex.Lock()
+	ret, specificReturn := fake.findVMInClustersReturnsOnCall[len(fake.findVMInClustersArgsForCall)]
+	fake.findVMInClustersArgsForCall = append(fake.findVMInClustersArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 []string
+	}{arg1, arg2, arg3, arg4Copy})
+	stub := fake.FindVMInClustersStub
+	fakeReturns := fake.findVMInClustersReturns
+	fake.recordInvocation("FindVMInClusters", []interface{}{arg1, arg2, arg3, arg4Copy})
+	fake.findVMInClustersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDiskVCenterClient) FindVMInClustersCallCount() int {
+	fake.findVMInClustersMutex.RLock()
+	defer fake.findVMInClustersMutex.RUnlock()
+	return len(fake.findVMInClustersArgsForCall)
+}
+
+func (fake *FakeDiskVCenterClient) FindVMInClustersCalls(stub func(context.Context, string, string, []string) (*vcenter.VM, error)) {
+	fake.findVMInClustersMutex.Lock()
+	defer fake.findVMInClustersMutex.Unlock()
+	fake.FindVMInClustersStub = stub
+}
+
+func (fake *FakeDiskVCenterClient) FindVMInClustersArgsForCall(i int) (context.Context, string, string, []string) {
+	fake.findVMInClustersMutex.RLock()
+	defer fake.findVMInClustersMutex.RUnlock()
+	argsForCall := fake.findVMInClustersArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeDiskVCenterClient) FindVMInClustersReturns(result1 *vcenter.VM, result2 error) {
+	fake.findVMInClustersMutex.Lock()
+	defer fake.findVMInClustersMutex.Unlock()
+	fake.FindVMInClustersStub = nil
+	fake.findVMInClustersReturns = struct {
+		result1 *vcenter.VM
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDiskVCenterClient) FindVMInClustersReturnsOnCall(i int, result1 *vcenter.VM, result2 error) {
+	fake.findVMInClustersMutex.Lock()
+	defer fake.findVMInClustersMutex.Unlock()
+	fake.FindVMInClustersStub = nil
+	if fake.findVMInClustersReturnsOnCall == nil {
+		fake.findVMInClustersReturnsOnCall = make(map[int]struct {
+			result1 *vcenter.VM
+			result2 error
+		})
+	}
+	fake.findVMInClustersReturnsOnCall[i] = struct {
+		result1 *vcenter.VM
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDiskVCenterClient) HostName() string {
+	fake.hostNameMutex.Lock()
+	ret, specificReturn := fake.hostNameReturnsOnCall[len(fake.hostNameArgsForCall)]
+	fake.hostNameArgsForCall = append(fake.hostNameArgsForCall, struct {
+	}{})
+	stub := fake.HostNameStub
+	fakeReturns := fake.hostNameReturns
+	fake.recordInvocation("HostName", []interface{}{})
+	fake.hostNameMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeDiskVCenterClient) HostNameCallCount() int {
+	fake.hostNameMutex.RLock()
+	defer fake.hostNameMutex.RUnlock()
+	return len(fake.hostNameArgsForCall)
+}
+
+func (fake *FakeDiskVCenterClient) HostNameCalls(stub func() string) {
+	fake.hostNameMutex.Lock()
+	defer fake.hostNameMutex.Unlock()
+	fake.HostNameStub = stub
+}
+
+func (fake *FakeDiskVCenterClient) HostNameReturns(result1 string) {
+	fake.hostNameMutex.Lock()
+	defer fake.hostNameMutex.Unlock()
+	fake.HostNameStub = nil
+	fake.hostNameReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeDiskVCenterClient) HostNameReturnsOnCall(i int, result1 string) {
+	fake.hostNameMutex.Lock()
+	defer fake.hostNameMutex.Unlock()
+	fake.HostNameStub = nil
+	if fake.hostNameReturnsOnCall == nil {
+		fake.hostNameReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.hostNameReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeDiskVCenterClient) MoveDisk(arg1 context.Context, arg2 *vcenter.DatastoreDisk, arg3 *vcenter.DatastoreDisk) error {
+	fake.moveDiskMutex.Lock()
+	ret, specificReturn := fake.moveDiskReturnsOnCall[len(fake.moveDiskArgsForCall)]
+	fake.moveDiskArgsForCall = append(fake.moveDiskArgsForCall, struct {
+		arg1 context.Context
+		arg2 *vcenter.DatastoreDisk
+		arg3 *vcenter.DatastoreDisk
+	}{arg1, arg2, arg3})
+	stub := fake.MoveDiskStub
+	fakeReturns := fake.moveDiskReturns
+	fake.recordInvocation("MoveDisk", []interface{}{arg1, arg2, arg3})
+	fake.moveDiskMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeDiskVCenterClient) MoveDiskCallCount() int {
+	fake.moveDiskMutex.RLock()
+	defer fake.moveDiskMutex.RUnlock()
+	return len(fake.moveDiskArgsForCall)
+}
+
+func (fake *FakeDiskVCenterClient) MoveDiskCalls(stub func(context.Context, *vcenter.DatastoreDisk, *vcenter.DatastoreDisk) error) {
+	fake.moveDiskMutex.Lock()
+	defer fake.moveDiskMutex.Unlock()
+	fake.MoveDiskStub = stub
+}
+
+func (fake *FakeDiskVCenterClient) MoveDiskArgsForCall(i int) (context.Context, *vcenter.DatastoreDisk, *vcenter.DatastoreDisk) {
+	fake.moveDiskMutex.RLock()
+	defer fake.moveDiskMutex.RUnlock()
+	argsForCall := fake.moveDiskArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeDiskVCenterClient) MoveDiskReturns(result1 error) {
+	fake.moveDiskMutex.Lock()
+	defer fake.moveDiskMutex.Unlock()
+	fake.MoveDiskStub = nil
+	fake.moveDiskReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDiskVCenterClient) MoveDiskReturnsOnCall(i int, result1 error) {
+	fake.moveDiskMutex.Lock()
+	defer fake.moveDiskMutex.Unlock()
+	fake.MoveDiskStub = nil
+	if fake.moveDiskReturnsOnCall == nil {
+		fake.moveDiskReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.moveDiskReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDiskVCenterClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.createDiskVMMutex.RLock()
+	defer fake.createDiskVMMutex.RUnlock()
+	fake.datacenterMutex.RLock()
+	defer fake.datacenterMutex.RUnlock()
+	fake.deleteDiskVMMutex.RLock()
+	defer fake.deleteDiskVMMutex.RUnlock()
+	fake.findDiskMutex.RLock()
+	defer fake.findDiskMutex.RUnlock()
+	fake.findVMInClustersMutex.RLock()
+	defer fake.findVMInClustersMutex.RUnlock()
+	fake.hostNameMutex.RLock()
+	defer fake.hostNameMutex.RUnlock()
+	fake.moveDiskMutex.RLock()
+	defer fake.moveDiskMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeDiskVCenterClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ migrate.DiskVCenterClient = new(FakeDiskVCenterClient)
@@ -0,0 +1,326 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package migratefakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+type FakeFolderCleanupVCenterClient struct {
+	DatacenterStub        func() string
+	datacenterMutex       sync.RWMutex
+	datacenterArgsForCall []struct {
+	}
+	datacenterReturns struct {
+		result1 string
+	}
+	datacenterReturnsOnCall map[int]struct {
+		result1 string
+	}
+	DeleteFolderStub        func(context.Context, string) error
+	deleteFolderMutex       sync.RWMutex
+	deleteFolderArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	deleteFolderReturns struct {
+		result1 error
+	}
+	deleteFolderReturnsOnCall map[int]struct {
+		result1 error
+	}
+	HostNameStub        func() string
+	hostNameMutex       sync.RWMutex
+	hostNameArgsForCall []struct {
+	}
+	hostNameReturns struct {
+		result1 string
+	}
+	hostNameReturnsOnCall map[int]struct {
+		result1 string
+	}
+	VMFoldersStub        func(context.Context, string) ([]vcenter.VMFolder, error)
+	vMFoldersMutex       sync.RWMutex
+	vMFoldersArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	vMFoldersReturns struct {
+		result1 []vcenter.VMFolder
+		result2 error
+	}
+	vMFoldersReturnsOnCall map[int]struct {
+		result1 []vcenter.VMFolder
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeFolderCleanupVCenterClient) Datacenter() string {
+	fake.datacenterMutex.Lock()
+	ret, specificReturn := fake.datacenterReturnsOnCall[len(fake.datacenterArgsForCall)]
+	fake.datacenterArgsForCall = append(fake.datacenterArgsForCall, struct {
+	}{})
+	stub := fake.DatacenterStub
+	fakeReturns := fake.datacenterReturns
+	fake.recordInvocation("Datacenter", []interface{}{})
+	fake.datacenterMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeFolderCleanupVCenterClient) DatacenterCallCount() int {
+	fake.datacenterMutex.RLock()
+	defer fake.datacenterMutex.RUnlock()
+	return len(fake.datacenterArgsForCall)
+}
+
+func (fake *FakeFolderCleanupVCenterClient) DatacenterCalls(stub func() string) {
+	fake.datacenterMutex.Lock()
+	defer fake.datacenterMutex.Unlock()
+	fake.DatacenterStub = stub
+}
+
+func (fake *FakeFolderCleanupVCenterClient) DatacenterReturns(result1 string) {
+	fake.datacenterMutex.Lock()
+	defer fake.datacenterMutex.Unlock()
+	fake.DatacenterStub = nil
+	fake.datacenterReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeFolderCleanupVCenterClient) DatacenterReturnsOnCall(i int, result1 string) {
+	fake.datacenterMutex.Lock()
+	defer fake.datacenterMutex.Unlock()
+	fake.DatacenterStub = nil
+	if fake.datacenterReturnsOnCall == nil {
+		fake.datacenterReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.datacenterReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeFolderCleanupVCenterClient) DeleteFolder(arg1 context.Context, arg2 string) error {
+	fake.deleteFolderMutex.Lock()
+	ret, specificReturn := fake.deleteFolderReturnsOnCall[len(fake.deleteFolderArgsForCall)]
+	fake.deleteFolderArgsForCall = append(fake.deleteFolderArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.DeleteFolderStub
+	fakeReturns := fake.deleteFolderReturns
+	fake.recordInvocation("DeleteFolder", []interface{}{arg1, arg2})
+	fake.deleteFolderMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeFolderCleanupVCenterClient) DeleteFolderCallCount() int {
+	fake.deleteFolderMutex.RLock()
+	defer fake.deleteFolderMutex.RUnlock()
+	return len(fake.deleteFolderArgsForCall)
+}
+
+func (fake *FakeFolderCleanupVCenterClient) DeleteFolderCalls(stub func(context.Context, string) error) {
+	fake.deleteFolderMutex.Lock()
+	defer fake.deleteFolderMutex.Unlock()
+	fake.DeleteFolderStub = stub
+}
+
+func (fake *FakeFolderCleanupVCenterClient) DeleteFolderArgsForCall(i int) (context.Context, string) {
+	fake.deleteFolderMutex.RLock()
+	defer fake.deleteFolderMutex.RUnlock()
+	argsForCall := fake.deleteFolderArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeFolderCleanupVCenterClient) DeleteFolderReturns(result1 error) {
+	fake.deleteFolderMutex.Lock()
+	defer fake.deleteFolderMutex.Unlock()
+	fake.DeleteFolderStub = nil
+	fake.deleteFolderReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFolderCleanupVCenterClient) DeleteFolderReturnsOnCall(i int, result1 error) {
+	fake.deleteFolderMutex.Lock()
+	defer fake.deleteFolderMutex.Unlock()
+	fake.DeleteFolderStub = nil
+	if fake.deleteFolderReturnsOnCall == nil {
+		fake.deleteFolderReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteFolderReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFolderCleanupVCenterClient) HostName() string {
+	fake.hostNameMutex.Lock()
+	ret, specificReturn := fake.hostNameReturnsOnCall[len(fake.hostNameArgsForCall)]
+	fake.hostNameArgsForCall = append(fake.hostNameArgsForCall, struct {
+	}{})
+	stub := fake.HostNameStub
+	fakeReturns := fake.hostNameReturns
+	fake.recordInvocation("HostName", []interface{}{})
+	fake.hostNameMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeFolderCleanupVCenterClient) HostNameCallCount() int {
+	fake.hostNameMutex.RLock()
+	defer fake.hostNameMutex.RUnlock()
+	return len(fake.hostNameArgsForCall)
+}
+
+func (fake *FakeFolderCleanupVCenterClient) HostNameCalls(stub func() string) {
+	fake.hostNameMutex.Lock()
+	defer fake.hostNameMutex.Unlock()
+	fake.HostNameStub = stub
+}
+
+func (fake *FakeFolderCleanupVCenterClient) HostNameReturns(result1 string) {
+	fake.hostNameMutex.Lock()
+	defer fake.hostNameMutex.Unlock()
+	fake.HostNameStub = nil
+	fake.hostNameReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeFolderCleanupVCenterClient) HostNameReturnsOnCall(i int, result1 string) {
+	fake.hostNameMutex.Lock()
+	defer fake.hostNameMutex.Unlock()
+	fake.HostNameStub = nil
+	if fake.hostNameReturnsOnCall == nil {
+		fake.hostNameReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.hostNameReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeFolderCleanupVCenterClient) VMFolders(arg1 context.Context, arg2 string) ([]vcenter.VMFolder, error) {
+	fake.vMFoldersMutex.Lock()
+	ret, specificReturn := fake.vMFoldersReturnsOnCall[len(fake.vMFoldersArgsForCall)]
+	fake.vMFoldersArgsForCall = append(fake.vMFoldersArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.VMFoldersStub
+	fakeReturns := fake.vMFoldersReturns
+	fake.recordInvocation("VMFolders", []interface{}{arg1, arg2})
+	fake.vMFoldersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeFolderCleanupVCenterClient) VMFoldersCallCount() int {
+	fake.vMFoldersMutex.RLock()
+	defer fake.vMFoldersMutex.RUnlock()
+	return len(fake.vMFoldersArgsForCall)
+}
+
+func (fake *FakeFolderCleanupVCenterClient) VMFoldersCalls(stub func(context.Context, string) ([]vcenter.VMFolder, error)) {
+	fake.vMFoldersMutex.Lock()
+	defer fake.vMFoldersMutex.Unlock()
+	fake.VMFoldersStub = stub
+}
+
+func (fake *FakeFolderCleanupVCenterClient) VMFoldersArgsForCall(i int) (context.Context, string) {
+	fake.vMFoldersMutex.RLock()
+	defer fake.vMFoldersMutex.RUnlock()
+	argsForCall := fake.vMFoldersArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeFolderCleanupVCenterClient) VMFoldersReturns(result1 []vcenter.VMFolder, result2 error) {
+	fake.vMFoldersMutex.Lock()
+	defer fake.vMFoldersMutex.Unlock()
+	fake.VMFoldersStub = nil
+	fake.vMFoldersReturns = struct {
+		result1 []vcenter.VMFolder
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFolderCleanupVCenterClient) VMFoldersReturnsOnCall(i int, result1 []vcenter.VMFolder, result2 error) {
+	fake.vMFoldersMutex.Lock()
+	defer fake.vMFoldersMutex.Unlock()
+	fake.VMFoldersStub = nil
+	if fake.vMFoldersReturnsOnCall == nil {
+		fake.vMFoldersReturnsOnCall = make(map[int]struct {
+			result1 []vcenter.VMFolder
+			result2 error
+		})
+	}
+	fake.vMFoldersReturnsOnCall[i] = struct {
+		result1 []vcenter.VMFolder
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFolderCleanupVCenterClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.datacenterMutex.RLock()
+	defer fake.datacenterMutex.RUnlock()
+	fake.deleteFolderMutex.RLock()
+	defer fake.deleteFolderMutex.RUnlock()
+	fake.hostNameMutex.RLock()
+	defer fake.hostNameMutex.RUnlock()
+	fake.vMFoldersMutex.RLock()
+	defer fake.vMFoldersMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeFolderCleanupVCenterClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ migrate.FolderCleanupVCenterClient = new(FakeFolderCleanupVCenterClient)
@@ -0,0 +1,184 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package migratefakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+)
+
+type FakeFolderInventoryClient struct {
+	DatacenterStub        func() string
+	datacenterMutex       sync.RWMutex
+	datacenterArgsForCall []struct {
+	}
+	datacenterReturns struct {
+		result1 string
+	}
+	datacenterReturnsOnCall map[int]struct {
+		result1 string
+	}
+	FolderExistsStub        func(context.Context, string) (bool, error)
+	folderExistsMutex       sync.RWMutex
+	folderExistsArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	folderExistsReturns struct {
+		result1 bool
+		result2 error
+	}
+	folderExistsReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeFolderInventoryClient) Datacenter() string {
+	fake.datacenterMutex.Lock()
+	ret, specificReturn := fake.datacenterReturnsOnCall[len(fake.datacenterArgsForCall)]
+	fake.datacenterArgsForCall = append(fake.datacenterArgsForCall, struct {
+	}{})
+	stub := fake.DatacenterStub
+	fakeReturns := fake.datacenterReturns
+	fake.recordInvocation("Datacenter", []interface{}{})
+	fake.datacenterMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeFolderInventoryClient) DatacenterCallCount() int {
+	fake.datacenterMutex.RLock()
+	defer fake.datacenterMutex.RUnlock()
+	return len(fake.datacenterArgsForCall)
+}
+
+func (fake *FakeFolderInventoryClient) DatacenterCalls(stub func() string) {
+	fake.datacenterMutex.Lock()
+	defer fake.datacenterMutex.Unlock()
+	fake.DatacenterStub = stub
+}
+
+func (fake *FakeFolderInventoryClient) DatacenterReturns(result1 string) {
+	fake.datacenterMutex.Lock()
+	defer fake.datacenterMutex.Unlock()
+	fake.DatacenterStub = nil
+	fake.datacenterReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeFolderInventoryClient) DatacenterReturnsOnCall(i int, result1 string) {
+	fake.datacenterMutex.Lock()
+	defer fake.datacenterMutex.Unlock()
+	fake.DatacenterStub = nil
+	if fake.datacenterReturnsOnCall == nil {
+		fake.datacenterReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.datacenterReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeFolderInventoryClient) FolderExists(arg1 context.Context, arg2 string) (bool, error) {
+	fake.folderExistsMutex.Lock()
+	ret, specificReturn := fake.folderExistsReturnsOnCall[len(fake.folderExistsArgsForCall)]
+	fake.folderExistsArgsForCall = append(fake.folderExistsArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.FolderExistsStub
+	fakeReturns := fake.folderExistsReturns
+	fake.recordInvocation("FolderExists", []interface{}{arg1, arg2})
+	fake.folderExistsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeFolderInventoryClient) FolderExistsCallCount() int {
+	fake.folderExistsMutex.RLock()
+	defer fake.folderExistsMutex.RUnlock()
+	return len(fake.folderExistsArgsForCall)
+}
+
+func (fake *FakeFolderInventoryClient) FolderExistsCalls(stub func(context.Context, string) (bool, error)) {
+	fake.folderExistsMutex.Lock()
+	defer fake.folderExistsMutex.Unlock()
+	fake.FolderExistsStub = stub
+}
+
+func (fake *FakeFolderInventoryClient) FolderExistsArgsForCall(i int) (context.Context, string) {
+	fake.folderExistsMutex.RLock()
+	defer fake.folderExistsMutex.RUnlock()
+	argsForCall := fake.folderExistsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeFolderInventoryClient) FolderExistsReturns(result1 bool, result2 error) {
+	fake.folderExistsMutex.Lock()
+	defer fake.folderExistsMutex.Unlock()
+	fake.FolderExistsStub = nil
+	fake.folderExistsReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFolderInventoryClient) FolderExistsReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.folderExistsMutex.Lock()
+	defer fake.folderExistsMutex.Unlock()
+	fake.FolderExistsStub = nil
+	if fake.folderExistsReturnsOnCall == nil {
+		fake.folderExistsReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.folderExistsReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFolderInventoryClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.datacenterMutex.RLock()
+	defer fake.datacenterMutex.RUnlock()
+	fake.folderExistsMutex.RLock()
+	defer fake.folderExistsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeFolderInventoryClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ migrate.FolderInventoryClient = new(FakeFolderInventoryClient)
@@ -0,0 +1,198 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package migratefakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cloudfoundry-community/gogobosh"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/bosh"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+)
+
+type FakeInitBoshClient struct {
+	CloudAndCPIConfigsStub        func(context.Context) ([]gogobosh.Cfg, error)
+	cloudAndCPIConfigsMutex       sync.RWMutex
+	cloudAndCPIConfigsArgsForCall []struct {
+		arg1 context.Context
+	}
+	cloudAndCPIConfigsReturns struct {
+		result1 []gogobosh.Cfg
+		result2 error
+	}
+	cloudAndCPIConfigsReturnsOnCall map[int]struct {
+		result1 []gogobosh.Cfg
+		result2 error
+	}
+	VMsAndStemcellsStub        func(context.Context) ([]bosh.VM, error)
+	vMsAndStemcellsMutex       sync.RWMutex
+	vMsAndStemcellsArgsForCall []struct {
+		arg1 context.Context
+	}
+	vMsAndStemcellsReturns struct {
+		result1 []bosh.VM
+		result2 error
+	}
+	vMsAndStemcellsReturnsOnCall map[int]struct {
+		result1 []bosh.VM
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeInitBoshClient) CloudAndCPIConfigs(arg1 context.Context) ([]gogobosh.Cfg, error) {
+	fake.cloudAndCPIConfigsMutex.Lock()
+	ret, specificReturn := fake.cloudAndCPIConfigsReturnsOnCall[len(fake.cloudAndCPIConfigsArgsForCall)]
+	fake.cloudAndCPIConfigsArgsForCall = append(fake.cloudAndCPIConfigsArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.CloudAndCPIConfigsStub
+	fakeReturns := fake.cloudAndCPIConfigsReturns
+	fake.recordInvocation("CloudAndCPIConfigs", []interface{}{arg1})
+	fake.cloudAndCPIConfigsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeInitBoshClient) CloudAndCPIConfigsCallCount() int {
+	fake.cloudAndCPIConfigsMutex.RLock()
+	defer fake.cloudAndCPIConfigsMutex.RUnlock()
+	return len(fake.cloudAndCPIConfigsArgsForCall)
+}
+
+func (fake *FakeInitBoshClient) CloudAndCPIConfigsCalls(stub func(context.Context) ([]gogobosh.Cfg, error)) {
+	fake.cloudAndCPIConfigsMutex.Lock()
+	defer fake.cloudAndCPIConfigsMutex.Unlock()
+	fake.CloudAndCPIConfigsStub = stub
+}
+
+func (fake *FakeInitBoshClient) CloudAndCPIConfigsArgsForCall(i int) context.Context {
+	fake.cloudAndCPIConfigsMutex.RLock()
+	defer fake.cloudAndCPIConfigsMutex.RUnlock()
+	argsForCall := fake.cloudAndCPIConfigsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeInitBoshClient) CloudAndCPIConfigsReturns(result1 []gogobosh.Cfg, result2 error) {
+	fake.cloudAndCPIConfigsMutex.Lock()
+	defer fake.cloudAndCPIConfigsMutex.Unlock()
+	fake.CloudAndCPIConfigsStub = nil
+	fake.cloudAndCPIConfigsReturns = struct {
+		result1 []gogobosh.Cfg
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInitBoshClient) CloudAndCPIConfigsReturnsOnCall(i int, result1 []gogobosh.Cfg, result2 error) {
+	fake.cloudAndCPIConfigsMutex.Lock()
+	defer fake.cloudAndCPIConfigsMutex.Unlock()
+	fake.CloudAndCPIConfigsStub = nil
+	if fake.cloudAndCPIConfigsReturnsOnCall == nil {
+		fake.cloudAndCPIConfigsReturnsOnCall = make(map[int]struct {
+			result1 []gogobosh.Cfg
+			result2 error
+		})
+	}
+	fake.cloudAndCPIConfigsReturnsOnCall[i] = struct {
+		result1 []gogobosh.Cfg
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInitBoshClient) VMsAndStemcells(arg1 context.Context) ([]bosh.VM, error) {
+	fake.vMsAndStemcellsMutex.Lock()
+	ret, specificReturn := fake.vMsAndStemcellsReturnsOnCall[len(fake.vMsAndStemcellsArgsForCall)]
+	fake.vMsAndStemcellsArgsForCall = append(fake.vMsAndStemcellsArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.VMsAndStemcellsStub
+	fakeReturns := fake.vMsAndStemcellsReturns
+	fake.recordInvocation("VMsAndStemcells", []interface{}{arg1})
+	fake.vMsAndStemcellsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeInitBoshClient) VMsAndStemcellsCallCount() int {
+	fake.vMsAndStemcellsMutex.RLock()
+	defer fake.vMsAndStemcellsMutex.RUnlock()
+	return len(fake.vMsAndStemcellsArgsForCall)
+}
+
+func (fake *FakeInitBoshClient) VMsAndStemcellsCalls(stub func(context.Context) ([]bosh.VM, error)) {
+	fake.vMsAndStemcellsMutex.Lock()
+	defer fake.vMsAndStemcellsMutex.Unlock()
+	fake.VMsAndStemcellsStub = stub
+}
+
+func (fake *FakeInitBoshClient) VMsAndStemcellsArgsForCall(i int) context.Context {
+	fake.vMsAndStemcellsMutex.RLock()
+	defer fake.vMsAndStemcellsMutex.RUnlock()
+	argsForCall := fake.vMsAndStemcellsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeInitBoshClient) VMsAndStemcellsReturns(result1 []bosh.VM, result2 error) {
+	fake.vMsAndStemcellsMutex.Lock()
+	defer fake.vMsAndStemcellsMutex.Unlock()
+	fake.VMsAndStemcellsStub = nil
+	fake.vMsAndStemcellsReturns = struct {
+		result1 []bosh.VM
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInitBoshClient) VMsAndStemcellsReturnsOnCall(i int, result1 []bosh.VM, result2 error) {
+	fake.vMsAndStemcellsMutex.Lock()
+	defer fake.vMsAndStemcellsMutex.Unlock()
+	fake.VMsAndStemcellsStub = nil
+	if fake.vMsAndStemcellsReturnsOnCall == nil {
+		fake.vMsAndStemcellsReturnsOnCall = make(map[int]struct {
+			result1 []bosh.VM
+			result2 error
+		})
+	}
+	fake.vMsAndStemcellsReturnsOnCall[i] = struct {
+		result1 []bosh.VM
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInitBoshClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.cloudAndCPIConfigsMutex.RLock()
+	defer fake.cloudAndCPIConfigsMutex.RUnlock()
+	fake.vMsAndStemcellsMutex.RLock()
+	defer fake.vMsAndStemcellsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeInitBoshClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ migrate.InitBoshClient = new(FakeInitBoshClient)
@@ -0,0 +1,120 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package migratefakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/bosh"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+)
+
+type FakeInstanceHealthClient struct {
+	DeploymentHealthStub        func(context.Context, string) (map[string]bosh.InstanceHealth, error)
+	deploymentHealthMutex       sync.RWMutex
+	deploymentHealthArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	deploymentHealthReturns struct {
+		result1 map[string]bosh.InstanceHealth
+		result2 error
+	}
+	deploymentHealthReturnsOnCall map[int]struct {
+		result1 map[string]bosh.InstanceHealth
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeInstanceHealthClient) DeploymentHealth(arg1 context.Context, arg2 string) (map[string]bosh.InstanceHealth, error) {
+	fake.deploymentHealthMutex.Lock()
+	ret, specificReturn := fake.deploymentHealthReturnsOnCall[len(fake.deploymentHealthArgsForCall)]
+	fake.deploymentHealthArgsForCall = append(fake.deploymentHealthArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.DeploymentHealthStub
+	fakeReturns := fake.deploymentHealthReturns
+	fake.recordInvocation("DeploymentHealth", []interface{}{arg1, arg2})
+	fake.deploymentHealthMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeInstanceHealthClient) DeploymentHealthCallCount() int {
+	fake.deploymentHealthMutex.RLock()
+	defer fake.deploymentHealthMutex.RUnlock()
+	return len(fake.deploymentHealthArgsForCall)
+}
+
+func (fake *FakeInstanceHealthClient) DeploymentHealthCalls(stub func(context.Context, string) (map[string]bosh.InstanceHealth, error)) {
+	fake.deploymentHealthMutex.Lock()
+	defer fake.deploymentHealthMutex.Unlock()
+	fake.DeploymentHealthStub = stub
+}
+
+func (fake *FakeInstanceHealthClient) DeploymentHealthArgsForCall(i int) (context.Context, string) {
+	fake.deploymentHealthMutex.RLock()
+	defer fake.deploymentHealthMutex.RUnlock()
+	argsForCall := fake.deploymentHealthArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeInstanceHealthClient) DeploymentHealthReturns(result1 map[string]bosh.InstanceHealth, result2 error) {
+	fake.deploymentHealthMutex.Lock()
+	defer fake.deploymentHealthMutex.Unlock()
+	fake.DeploymentHealthStub = nil
+	fake.deploymentHealthReturns = struct {
+		result1 map[string]bosh.InstanceHealth
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInstanceHealthClient) DeploymentHealthReturnsOnCall(i int, result1 map[string]bosh.InstanceHealth, result2 error) {
+	fake.deploymentHealthMutex.Lock()
+	defer fake.deploymentHealthMutex.Unlock()
+	fake.DeploymentHealthStub = nil
+	if fake.deploymentHealthReturnsOnCall == nil {
+		fake.deploymentHealthReturnsOnCall = make(map[int]struct {
+			result1 map[string]bosh.InstanceHealth
+			result2 error
+		})
+	}
+	fake.deploymentHealthReturnsOnCall[i] = struct {
+		result1 map[string]bosh.InstanceHealth
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInstanceHealthClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.deploymentHealthMutex.RLock()
+	defer fake.deploymentHealthMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeInstanceHealthClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ migrate.InstanceHealthClient = new(FakeInstanceHealthClient)
@@ -0,0 +1,118 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package migratefakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+type FakeInventoryClient struct {
+	InventoryStub        func(context.Context) (*vcenter.Inventory, error)
+	inventoryMutex       sync.RWMutex
+	inventoryArgsForCall []struct {
+		arg1 context.Context
+	}
+	inventoryReturns struct {
+		result1 *vcenter.Inventory
+		result2 error
+	}
+	inventoryReturnsOnCall map[int]struct {
+		result1 *vcenter.Inventory
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeInventoryClient) Inventory(arg1 context.Context) (*vcenter.Inventory, error) {
+	fake.inventoryMutex.Lock()
+	ret, specificReturn := fake.inventoryReturnsOnCall[len(fake.inventoryArgsForCall)]
+	fake.inventoryArgsForCall = append(fake.inventoryArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.InventoryStub
+	fakeReturns := fake.inventoryReturns
+	fake.recordInvocation("Inventory", []interface{}{arg1})
+	fake.inventoryMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeInventoryClient) InventoryCallCount() int {
+	fake.inventoryMutex.RLock()
+	defer fake.inventoryMutex.RUnlock()
+	return len(fake.inventoryArgsForCall)
+}
+
+func (fake *FakeInventoryClient) InventoryCalls(stub func(context.Context) (*vcenter.Inventory, error)) {
+	fake.inventoryMutex.Lock()
+	defer fake.inventoryMutex.Unlock()
+	fake.InventoryStub = stub
+}
+
+func (fake *FakeInventoryClient) InventoryArgsForCall(i int) context.Context {
+	fake.inventoryMutex.RLock()
+	defer fake.inventoryMutex.RUnlock()
+	argsForCall := fake.inventoryArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeInventoryClient) InventoryReturns(result1 *vcenter.Inventory, result2 error) {
+	fake.inventoryMutex.Lock()
+	defer fake.inventoryMutex.Unlock()
+	fake.InventoryStub = nil
+	fake.inventoryReturns = struct {
+		result1 *vcenter.Inventory
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInventoryClient) InventoryReturnsOnCall(i int, result1 *vcenter.Inventory, result2 error) {
+	fake.inventoryMutex.Lock()
+	defer fake.inventoryMutex.Unlock()
+	fake.InventoryStub = nil
+	if fake.inventoryReturnsOnCall == nil {
+		fake.inventoryReturnsOnCall = make(map[int]struct {
+			result1 *vcenter.Inventory
+			result2 error
+		})
+	}
+	fake.inventoryReturnsOnCall[i] = struct {
+		result1 *vcenter.Inventory
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInventoryClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.inventoryMutex.RLock()
+	defer fake.inventoryMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeInventoryClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ migrate.InventoryClient = new(FakeInventoryClient)
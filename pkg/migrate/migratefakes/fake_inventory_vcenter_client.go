@@ -0,0 +1,201 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package migratefakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+type FakeInventoryVCenterClient struct {
+	FindVMStub        func(context.Context, string, string) (*vcenter.VM, error)
+	findVMMutex       sync.RWMutex
+	findVMArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	findVMReturns struct {
+		result1 *vcenter.VM
+		result2 error
+	}
+	findVMReturnsOnCall map[int]struct {
+		result1 *vcenter.VM
+		result2 error
+	}
+	InventoryStub        func(context.Context) (*vcenter.Inventory, error)
+	inventoryMutex       sync.RWMutex
+	inventoryArgsForCall []struct {
+		arg1 context.Context
+	}
+	inventoryReturns struct {
+		result1 *vcenter.Inventory
+		result2 error
+	}
+	inventoryReturnsOnCall map[int]struct {
+		result1 *vcenter.Inventory
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeInventoryVCenterClient) FindVM(arg1 context.Context, arg2 string, arg3 string) (*vcenter.VM, error) {
+	fake.findVMMutex.Lock()
+	ret, specificReturn := fake.findVMReturnsOnCall[len(fake.findVMArgsForCall)]
+	fake.findVMArgsForCall = append(fake.findVMArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.FindVMStub
+	fakeReturns := fake.findVMReturns
+	fake.recordInvocation("FindVM", []interface{}{arg1, arg2, arg3})
+	fake.findVMMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeInventoryVCenterClient) FindVMCallCount() int {
+	fake.findVMMutex.RLock()
+	defer fake.findVMMutex.RUnlock()
+	return len(fake.findVMArgsForCall)
+}
+
+func (fake *FakeInventoryVCenterClient) FindVMCalls(stub func(context.Context, string, string) (*vcenter.VM, error)) {
+	fake.findVMMutex.Lock()
+	defer fake.findVMMutex.Unlock()
+	fake.FindVMStub = stub
+}
+
+func (fake *FakeInventoryVCenterClient) FindVMArgsForCall(i int) (context.Context, string, string) {
+	fake.findVMMutex.RLock()
+	defer fake.findVMMutex.RUnlock()
+	argsForCall := fake.findVMArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeInventoryVCenterClient) FindVMReturns(result1 *vcenter.VM, result2 error) {
+	fake.findVMMutex.Lock()
+	defer fake.findVMMutex.Unlock()
+	fake.FindVMStub = nil
+	fake.findVMReturns = struct {
+		result1 *vcenter.VM
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInventoryVCenterClient) FindVMReturnsOnCall(i int, result1 *vcenter.VM, result2 error) {
+	fake.findVMMutex.Lock()
+	defer fake.findVMMutex.Unlock()
+	fake.FindVMStub = nil
+	if fake.findVMReturnsOnCall == nil {
+		fake.findVMReturnsOnCall = make(map[int]struct {
+			result1 *vcenter.VM
+			result2 error
+		})
+	}
+	fake.findVMReturnsOnCall[i] = struct {
+		result1 *vcenter.VM
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInventoryVCenterClient) Inventory(arg1 context.Context) (*vcenter.Inventory, error) {
+	fake.inventoryMutex.Lock()
+	ret, specificReturn := fake.inventoryReturnsOnCall[len(fake.inventoryArgsForCall)]
+	fake.inventoryArgsForCall = append(fake.inventoryArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.InventoryStub
+	fakeReturns := fake.inventoryReturns
+	fake.recordInvocation("Inventory", []interface{}{arg1})
+	fake.inventoryMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeInventoryVCenterClient) InventoryCallCount() int {
+	fake.inventoryMutex.RLock()
+	defer fake.inventoryMutex.RUnlock()
+	return len(fake.inventoryArgsForCall)
+}
+
+func (fake *FakeInventoryVCenterClient) InventoryCalls(stub func(context.Context) (*vcenter.Inventory, error)) {
+	fake.inventoryMutex.Lock()
+	defer fake.inventoryMutex.Unlock()
+	fake.InventoryStub = stub
+}
+
+func (fake *FakeInventoryVCenterClient) InventoryArgsForCall(i int) context.Context {
+	fake.inventoryMutex.RLock()
+	defer fake.inventoryMutex.RUnlock()
+	argsForCall := fake.inventoryArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeInventoryVCenterClient) InventoryReturns(result1 *vcenter.Inventory, result2 error) {
+	fake.inventoryMutex.Lock()
+	defer fake.inventoryMutex.Unlock()
+	fake.InventoryStub = nil
+	fake.inventoryReturns = struct {
+		result1 *vcenter.Inventory
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInventoryVCenterClient) InventoryReturnsOnCall(i int, result1 *vcenter.Inventory, result2 error) {
+	fake.inventoryMutex.Lock()
+	defer fake.inventoryMutex.Unlock()
+	fake.InventoryStub = nil
+	if fake.inventoryReturnsOnCall == nil {
+		fake.inventoryReturnsOnCall = make(map[int]struct {
+			result1 *vcenter.Inventory
+			result2 error
+		})
+	}
+	fake.inventoryReturnsOnCall[i] = struct {
+		result1 *vcenter.Inventory
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInventoryVCenterClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.findVMMutex.RLock()
+	defer fake.findVMMutex.RUnlock()
+	fake.inventoryMutex.RLock()
+	defer fake.inventoryMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeInventoryVCenterClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ migrate.InventoryVCenterClient = new(FakeInventoryVCenterClient)
@@ -0,0 +1,497 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package migratefakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+type FakeLeftoversVCenterClient struct {
+	ClusterVMsStub        func(context.Context, []string) ([]vcenter.ClusterVM, error)
+	clusterVMsMutex       sync.RWMutex
+	clusterVMsArgsForCall []struct {
+		arg1 context.Context
+		arg2 []string
+	}
+	clusterVMsReturns struct {
+		result1 []vcenter.ClusterVM
+		result2 error
+	}
+	clusterVMsReturnsOnCall map[int]struct {
+		result1 []vcenter.ClusterVM
+		result2 error
+	}
+	DatacenterStub        func() string
+	datacenterMutex       sync.RWMutex
+	datacenterArgsForCall []struct {
+	}
+	datacenterReturns struct {
+		result1 string
+	}
+	datacenterReturnsOnCall map[int]struct {
+		result1 string
+	}
+	DatastoreDisksStub        func(context.Context, string, string, string) ([]vcenter.DatastoreDisk, error)
+	datastoreDisksMutex       sync.RWMutex
+	datastoreDisksArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 string
+	}
+	datastoreDisksReturns struct {
+		result1 []vcenter.DatastoreDisk
+		result2 error
+	}
+	datastoreDisksReturnsOnCall map[int]struct {
+		result1 []vcenter.DatastoreDisk
+		result2 error
+	}
+	DeleteFolderStub        func(context.Context, string) error
+	deleteFolderMutex       sync.RWMutex
+	deleteFolderArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	deleteFolderReturns struct {
+		result1 error
+	}
+	deleteFolderReturnsOnCall map[int]struct {
+		result1 error
+	}
+	HostNameStub        func() string
+	hostNameMutex       sync.RWMutex
+	hostNameArgsForCall []struct {
+	}
+	hostNameReturns struct {
+		result1 string
+	}
+	hostNameReturnsOnCall map[int]struct {
+		result1 string
+	}
+	VMFoldersStub        func(context.Context, string) ([]vcenter.VMFolder, error)
+	vMFoldersMutex       sync.RWMutex
+	vMFoldersArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	vMFoldersReturns struct {
+		result1 []vcenter.VMFolder
+		result2 error
+	}
+	vMFoldersReturnsOnCall map[int]struct {
+		result1 []vcenter.VMFolder
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeLeftoversVCenterClient) ClusterVMs(arg1 context.Context, arg2 []string) ([]vcenter.ClusterVM, error) {
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.clusterVMsMutex.Lock()
+	ret, specificReturn := fake.clusterVMsReturnsOnCall[len(fake.clusterVMsArgsForCall)]
+	fake.clusterVMsArgsForCall = append(fake.clusterVMsArgsForCall, struct {
+		arg1 context.Context
+		arg2 []string
+	}{arg1, arg2Copy})
+	stub := fake.ClusterVMsStub
+	fakeReturns := fake.clusterVMsReturns
+	fake.recordInvocation("ClusterVMs", []interface{}{arg1, arg2Copy})
+	fake.clusterVMsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeLeftoversVCenterClient) ClusterVMsCallCount() int {
+	fake.clusterVMsMutex.RLock()
+	defer fake.clusterVMsMutex.RUnlock()
+	return len(fake.clusterVMsArgsForCall)
+}
+
+func (fake *FakeLeftoversVCenterClient) ClusterVMsCalls(stub func(context.Context, []string) ([]vcenter.ClusterVM, error)) {
+	fake.clusterVMsMutex.Lock()
+	defer fake.clusterVMsMutex.Unlock()
+	fake.ClusterVMsStub = stub
+}
+
+func (fake *FakeLeftoversVCenterClient) ClusterVMsArgsForCall(i int) (context.Context, []string) {
+	fake.clusterVMsMutex.RLock()
+	defer fake.clusterVMsMutex.RUnlock()
+	argsForCall := fake.clusterVMsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeLeftoversVCenterClient) ClusterVMsReturns(result1 []vcenter.ClusterVM, result2 error) {
+	fake.clusterVMsMutex.Lock()
+	defer fake.clusterVMsMutex.Unlock()
+	fake.ClusterVMsStub = nil
+	fake.clusterVMsReturns = struct {
+		result1 []vcenter.ClusterVM
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLeftoversVCenterClient) ClusterVMsReturnsOnCall(i int, result1 []vcenter.ClusterVM, result2 error) {
+	fake.clusterVMsMutex.Lock()
+	defer fake.clusterVMsMutex.Unlock()
+	fake.ClusterVMsStub = nil
+	if fake.clusterVMsReturnsOnCall == nil {
+		fake.clusterVMsReturnsOnCall = make(map[int]struct {
+			result1 []vcenter.ClusterVM
+			result2 error
+		})
+	}
+	fake.clusterVMsReturnsOnCall[i] = struct {
+		result1 []vcenter.ClusterVM
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLeftoversVCenterClient) Datacenter() string {
+	fake.datacenterMutex.Lock()
+	ret, specificReturn := fake.datacenterReturnsOnCall[len(fake.datacenterArgsForCall)]
+	fake.datacenterArgsForCall = append(fake.datacenterArgsForCall, struct {
+	}{})
+	stub := fake.DatacenterStub
+	fakeReturns := fake.datacenterReturns
+	fake.recordInvocation("Datacenter", []interface{}{})
+	fake.datacenterMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeLeftoversVCenterClient) DatacenterCallCount() int {
+	fake.datacenterMutex.RLock()
+	defer fake.datacenterMutex.RUnlock()
+	return len(fake.datacenterArgsForCall)
+}
+
+func (fake *FakeLeftoversVCenterClient) DatacenterCalls(stub func() string) {
+	fake.datacenterMutex.Lock()
+	defer fake.datacenterMutex.Unlock()
+	fake.DatacenterStub = stub
+}
+
+func (fake *FakeLeftoversVCenterClient) DatacenterReturns(result1 string) {
+	fake.datacenterMutex.Lock()
+	defer fake.datacenterMutex.Unlock()
+	fake.DatacenterStub = nil
+	fake.datacenterReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeLeftoversVCenterClient) DatacenterReturnsOnCall(i int, result1 string) {
+	fake.datacenterMutex.Lock()
+	defer fake.datacenterMutex.Unlock()
+	fake.DatacenterStub = nil
+	if fake.datacenterReturnsOnCall == nil {
+		fake.datacenterReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.datacenterReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeLeftoversVCenterClient) DatastoreDisks(arg1 context.Context, arg2 string, arg3 string, arg4 string) ([]vcenter.DatastoreDisk, error) {
+	fake.datastoreDisksMutex.Lock()
+	ret, specificReturn := fake.datastoreDisksReturnsOnCall[len(fake.datastoreDisksArgsForCall)]
+	fake.datastoreDisksArgsForCall = append(fake.datastoreDisksArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 string
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.DatastoreDisksStub
+	fakeReturns := fake.datastoreDisksReturns
+	fake.recordInvocation("DatastoreDisks", []interface{}{arg1, arg2, arg3, arg4})
+	fake.datastoreDisksMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeLeftoversVCenterClient) DatastoreDisksCallCount() int {
+	fake.datastoreDisksMutex.RLock()
+	defer fake.datastoreDisksMutex.RUnlock()
+	return len(fake.datastoreDisksArgsForCall)
+}
+
+func (fake *FakeLeftoversVCenterClient) DatastoreDisksCalls(stub func(context.Context, string, string, string) ([]vcenter.DatastoreDisk, error)) {
+	fake.datastoreDisksMutex.Lock()
+	defer fake.datastoreDisksMutex.Unlock()
+	fake.DatastoreDisksStub = stub
+}
+
+func (fake *FakeLeftoversVCenterClient) DatastoreDisksArgsForCall(i int) (context.Context, string, string, string) {
+	fake.datastoreDisksMutex.RLock()
+	defer fake.datastoreDisksMutex.RUnlock()
+	argsForCall := fake.datastoreDisksArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeLeftoversVCenterClient) DatastoreDisksReturns(result1 []vcenter.DatastoreDisk, result2 error) {
+	fake.datastoreDisksMutex.Lock()
+	defer fake.datastoreDisksMutex.Unlock()
+	fake.DatastoreDisksStub = nil
+	fake.datastoreDisksReturns = struct {
+		result1 []vcenter.DatastoreDisk
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLeftoversVCenterClient) DatastoreDisksReturnsOnCall(i int, result1 []vcenter.DatastoreDisk, result2 error) {
+	fake.datastoreDisksMutex.Lock()
+	defer fake.datastoreDisksMutex.Unlock()
+	fake.DatastoreDisksStub = nil
+	if fake.datastoreDisksReturnsOnCall == nil {
+		fake.datastoreDisksReturnsOnCall = make(map[int]struct {
+			result1 []vcenter.DatastoreDisk
+			result2 error
+		})
+	}
+	fake.datastoreDisksReturnsOnCall[i] = struct {
+		result1 []vcenter.DatastoreDisk
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLeftoversVCenterClient) DeleteFolder(arg1 context.Context, arg2 string) error {
+	fake.deleteFolderMutex.Lock()
+	ret, specificReturn := fake.deleteFolderReturnsOnCall[len(fake.deleteFolderArgsForCall)]
+	fake.deleteFolderArgsForCall = append(fake.deleteFolderArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.DeleteFolderStub
+	fakeReturns := fake.deleteFolderReturns
+	fake.recordInvocation("DeleteFolder", []interface{}{arg1, arg2})
+	fake.deleteFolderMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeLeftoversVCenterClient) DeleteFolderCallCount() int {
+	fake.deleteFolderMutex.RLock()
+	defer fake.deleteFolderMutex.RUnlock()
+	return len(fake.deleteFolderArgsForCall)
+}
+
+func (fake *FakeLeftoversVCenterClient) DeleteFolderCalls(stub func(context.Context, string) error) {
+	fake.deleteFolderMutex.Lock()
+	defer fake.deleteFolderMutex.Unlock()
+	fake.DeleteFolderStub = stub
+}
+
+func (fake *FakeLeftoversVCenterClient) DeleteFolderArgsForCall(i int) (context.Context, string) {
+	fake.deleteFolderMutex.RLock()
+	defer fake.deleteFolderMutex.RUnlock()
+	argsForCall := fake.deleteFolderArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeLeftoversVCenterClient) DeleteFolderReturns(result1 error) {
+	fake.deleteFolderMutex.Lock()
+	defer fake.deleteFolderMutex.Unlock()
+	fake.DeleteFolderStub = nil
+	fake.deleteFolderReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeLeftoversVCenterClient) DeleteFolderReturnsOnCall(i int, result1 error) {
+	fake.deleteFolderMutex.Lock()
+	defer fake.deleteFolderMutex.Unlock()
+	fake.DeleteFolderStub = nil
+	if fake.deleteFolderReturnsOnCall == nil {
+		fake.deleteFolderReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteFolderReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeLeftoversVCenterClient) HostName() string {
+	fake.hostNameMutex.Lock()
+	ret, specificReturn := fake.hostNameReturnsOnCall[len(fake.hostNameArgsForCall)]
+	fake.hostNameArgsForCall = append(fake.hostNameArgsForCall, struct {
+	}{})
+	stub := fake.HostNameStub
+	fakeReturns := fake.hostNameReturns
+	fake.recordInvocation("HostName", []interface{}{})
+	fake.hostNameMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeLeftoversVCenterClient) HostNameCallCount() int {
+	fake.hostNameMutex.RLock()
+	defer fake.hostNameMutex.RUnlock()
+	return len(fake.hostNameArgsForCall)
+}
+
+func (fake *FakeLeftoversVCenterClient) HostNameCalls(stub func() string) {
+	fake.hostNameMutex.Lock()
+	defer fake.hostNameMutex.Unlock()
+	fake.HostNameStub = stub
+}
+
+func (fake *FakeLeftoversVCenterClient) HostNameReturns(result1 string) {
+	fake.hostNameMutex.Lock()
+	defer fake.hostNameMutex.Unlock()
+	fake.HostNameStub = nil
+	fake.hostNameReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeLeftoversVCenterClient) HostNameReturnsOnCall(i int, result1 string) {
+	fake.hostNameMutex.Lock()
+	defer fake.hostNameMutex.Unlock()
+	fake.HostNameStub = nil
+	if fake.hostNameReturnsOnCall == nil {
+		fake.hostNameReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.hostNameReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeLeftoversVCenterClient) VMFolders(arg1 context.Context, arg2 string) ([]vcenter.VMFolder, error) {
+	fake.vMFoldersMutex.Lock()
+	ret, specificReturn := fake.vMFoldersReturnsOnCall[len(fake.vMFoldersArgsForCall)]
+	fake.vMFoldersArgsForCall = append(fake.vMFoldersArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.VMFoldersStub
+	fakeReturns := fake.vMFoldersReturns
+	fake.recordInvocation("VMFolders", []interface{}{arg1, arg2})
+	fake.vMFoldersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeLeftoversVCenterClient) VMFoldersCallCount() int {
+	fake.vMFoldersMutex.RLock()
+	defer fake.vMFoldersMutex.RUnlock()
+	return len(fake.vMFoldersArgsForCall)
+}
+
+func (fake *FakeLeftoversVCenterClient) VMFoldersCalls(stub func(context.Context, string) ([]vcenter.VMFolder, error)) {
+	fake.vMFoldersMutex.Lock()
+	defer fake.vMFoldersMutex.Unlock()
+	fake.VMFoldersStub = stub
+}
+
+func (fake *FakeLeftoversVCenterClient) VMFoldersArgsForCall(i int) (context.Context, string) {
+	fake.vMFoldersMutex.RLock()
+	defer fake.vMFoldersMutex.RUnlock()
+	argsForCall := fake.vMFoldersArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeLeftoversVCenterClient) VMFoldersReturns(result1 []vcenter.VMFolder, result2 error) {
+	fake.vMFoldersMutex.Lock()
+	defer fake.vMFoldersMutex.Unlock()
+	fake.VMFoldersStub = nil
+	fake.vMFoldersReturns = struct {
+		result1 []vcenter.VMFolder
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLeftoversVCenterClient) VMFoldersReturnsOnCall(i int, result1 []vcenter.VMFolder, result2 error) {
+	fake.vMFoldersMutex.Lock()
+	defer fake.vMFoldersMutex.Unlock()
+	fake.VMFoldersStub = nil
+	if fake.vMFoldersReturnsOnCall == nil {
+		fake.vMFoldersReturnsOnCall = make(map[int]struct {
+			result1 []vcenter.VMFolder
+			result2 error
+		})
+	}
+	fake.vMFoldersReturnsOnCall[i] = struct {
+		result1 []vcenter.VMFolder
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLeftoversVCenterClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.clusterVMsMutex.RLock()
+	defer fake.clusterVMsMutex.RUnlock()
+	fake.datacenterMutex.RLock()
+	defer fake.datacenterMutex.RUnlock()
+	fake.datastoreDisksMutex.RLock()
+	defer fake.datastoreDisksMutex.RUnlock()
+	fake.deleteFolderMutex.RLock()
+	defer fake.deleteFolderMutex.RUnlock()
+	fake.hostNameMutex.RLock()
+	defer fake.hostNameMutex.RUnlock()
+	fake.vMFoldersMutex.RLock()
+	defer fake.vMFoldersMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeLeftoversVCenterClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ migrate.LeftoversVCenterClient = new(FakeLeftoversVCenterClient)
@@ -0,0 +1,301 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package migratefakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+type FakeVerifyVCenterClient struct {
+	ClusterInventoryStub        func(context.Context, []string) (*vcenter.Inventory, error)
+	clusterInventoryMutex       sync.RWMutex
+	clusterInventoryArgsForCall []struct {
+		arg1 context.Context
+		arg2 []string
+	}
+	clusterInventoryReturns struct {
+		result1 *vcenter.Inventory
+		result2 error
+	}
+	clusterInventoryReturnsOnCall map[int]struct {
+		result1 *vcenter.Inventory
+		result2 error
+	}
+	FindVMInClustersStub        func(context.Context, string, string, []string) (*vcenter.VM, error)
+	findVMInClustersMutex       sync.RWMutex
+	findVMInClustersArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 []string
+	}
+	findVMInClustersReturns struct {
+		result1 *vcenter.VM
+		result2 error
+	}
+	findVMInClustersReturnsOnCall map[int]struct {
+		result1 *vcenter.VM
+		result2 error
+	}
+	VMNICsStub        func(context.Context, string) ([]vcenter.NIC, bool, error)
+	vMNICsMutex       sync.RWMutex
+	vMNICsArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	vMNICsReturns struct {
+		result1 []vcenter.NIC
+		result2 bool
+		result3 error
+	}
+	vMNICsReturnsOnCall map[int]struct {
+		result1 []vcenter.NIC
+		result2 bool
+		result3 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeVerifyVCenterClient) ClusterInventory(arg1 context.Context, arg2 []string) (*vcenter.Inventory, error) {
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.clusterInventoryMutex.Lock()
+	ret, specificReturn := fake.clusterInventoryReturnsOnCall[len(fake.clusterInventoryArgsForCall)]
+	fake.clusterInventoryArgsForCall = append(fake.clusterInventoryArgsForCall, struct {
+		arg1 context.Context
+		arg2 []string
+	}{arg1, arg2Copy})
+	stub := fake.ClusterInventoryStub
+	fakeReturns := fake.clusterInventoryReturns
+	fake.recordInvocation("ClusterInventory", []interface{}{arg1, arg2Copy})
+	fake.clusterInventoryMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeVerifyVCenterClient) ClusterInventoryCallCount() int {
+	fake.clusterInventoryMutex.RLock()
+	defer fake.clusterInventoryMutex.RUnlock()
+	return len(fake.clusterInventoryArgsForCall)
+}
+
+func (fake *FakeVerifyVCenterClient) ClusterInventoryCalls(stub func(context.Context, []string) (*vcenter.Inventory, error)) {
+	fake.clusterInventoryMutex.Lock()
+	defer fake.clusterInventoryMutex.Unlock()
+	fake.ClusterInventoryStub = stub
+}
+
+func (fake *FakeVerifyVCenterClient) ClusterInventoryArgsForCall(i int) (context.Context, []string) {
+	fake.clusterInventoryMutex.RLock()
+	defer fake.clusterInventoryMutex.RUnlock()
+	argsForCall := fake.clusterInventoryArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeVerifyVCenterClient) ClusterInventoryReturns(result1 *vcenter.Inventory, result2 error) {
+	fake.clusterInventoryMutex.Lock()
+	defer fake.clusterInventoryMutex.Unlock()
+	fake.ClusterInventoryStub = nil
+	fake.clusterInventoryReturns = struct {
+		result1 *vcenter.Inventory
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVerifyVCenterClient) ClusterInventoryReturnsOnCall(i int, result1 *vcenter.Inventory, result2 error) {
+	fake.clusterInventoryMutex.Lock()
+	defer fake.clusterInventoryMutex.Unlock()
+	fake.ClusterInventoryStub = nil
+	if fake.clusterInventoryReturnsOnCall == nil {
+		fake.clusterInventoryReturnsOnCall = make(map[int]struct {
+			result1 *vcenter.Inventory
+			result2 error
+		})
+	}
+	fake.clusterInventoryReturnsOnCall[i] = struct {
+		result1 *vcenter.Inventory
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVerifyVCenterClient) FindVMInClusters(arg1 context.Context, arg2 string, arg3 string, arg4 []string) (*vcenter.VM, error) {
+	var arg4Copy []string
+	if arg4 != nil {
+		arg4Copy = make([]string, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	fake.findVMInClustersMutex.Lock()
+	ret, specificReturn := fake.findVMInClustersReturnsOnCall[len(fake.findVMInClustersArgsForCall)]
+	fake.findVMInClustersArgsForCall = append(fake.findVMInClustersArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 []string
+	}{arg1, arg2, arg3, arg4Copy})
+	stub := fake.FindVMInClustersStub
+	fakeReturns := fake.findVMInClustersReturns
+	fake.recordInvocation("FindVMInClusters", []interface{}{arg1, arg2, arg3, arg4Copy})
+	fake.findVMInClustersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeVerifyVCenterClient) FindVMInClustersCallCount() int {
+	fake.findVMInClustersMutex.RLock()
+	defer fake.findVMInClustersMutex.RUnlock()
+	return len(fake.findVMInClustersArgsForCall)
+}
+
+func (fake *FakeVerifyVCenterClient) FindVMInClustersCalls(stub func(context.Context, string, string, []string) (*vcenter.VM, error)) {
+	fake.findVMInClustersMutex.Lock()
+	defer fake.findVMInClustersMutex.Unlock()
+	fake.FindVMInClustersStub = stub
+}
+
+func (fake *FakeVerifyVCenterClient) FindVMInClustersArgsForCall(i int) (context.Context, string, string, []string) {
+	fake.findVMInClustersMutex.RLock()
+	defer fake.findVMInClustersMutex.RUnlock()
+	argsForCall := fake.findVMInClustersArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeVerifyVCenterClient) FindVMInClustersReturns(result1 *vcenter.VM, result2 error) {
+	fake.findVMInClustersMutex.Lock()
+	defer fake.findVMInClustersMutex.Unlock()
+	fake.FindVMInClustersStub = nil
+	fake.findVMInClustersReturns = struct {
+		result1 *vcenter.VM
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVerifyVCenterClient) FindVMInClustersReturnsOnCall(i int, result1 *vcenter.VM, result2 error) {
+	fake.findVMInClustersMutex.Lock()
+	defer fake.findVMInClustersMutex.Unlock()
+	fake.FindVMInClustersStub = nil
+	if fake.findVMInClustersReturnsOnCall == nil {
+		fake.findVMInClustersReturnsOnCall = make(map[int]struct {
+			result1 *vcenter.VM
+			result2 error
+		})
+	}
+	fake.findVMInClustersReturnsOnCall[i] = struct {
+		result1 *vcenter.VM
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeVerifyVCenterClient) VMNICs(arg1 context.Context, arg2 string) ([]vcenter.NIC, bool, error) {
+	fake.vMNICsMutex.Lock()
+	ret, specificReturn := fake.vMNICsReturnsOnCall[len(fake.vMNICsArgsForCall)]
+	fake.vMNICsArgsForCall = append(fake.vMNICsArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.VMNICsStub
+	fakeReturns := fake.vMNICsReturns
+	fake.recordInvocation("VMNICs", []interface{}{arg1, arg2})
+	fake.vMNICsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeVerifyVCenterClient) VMNICsCallCount() int {
+	fake.vMNICsMutex.RLock()
+	defer fake.vMNICsMutex.RUnlock()
+	return len(fake.vMNICsArgsForCall)
+}
+
+func (fake *FakeVerifyVCenterClient) VMNICsCalls(stub func(context.Context, string) ([]vcenter.NIC, bool, error)) {
+	fake.vMNICsMutex.Lock()
+	defer fake.vMNICsMutex.Unlock()
+	fake.VMNICsStub = stub
+}
+
+func (fake *FakeVerifyVCenterClient) VMNICsArgsForCall(i int) (context.Context, string) {
+	fake.vMNICsMutex.RLock()
+	defer fake.vMNICsMutex.RUnlock()
+	argsForCall := fake.vMNICsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeVerifyVCenterClient) VMNICsReturns(result1 []vcenter.NIC, result2 bool, result3 error) {
+	fake.vMNICsMutex.Lock()
+	defer fake.vMNICsMutex.Unlock()
+	fake.VMNICsStub = nil
+	fake.vMNICsReturns = struct {
+		result1 []vcenter.NIC
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeVerifyVCenterClient) VMNICsReturnsOnCall(i int, result1 []vcenter.NIC, result2 bool, result3 error) {
+	fake.vMNICsMutex.Lock()
+	defer fake.vMNICsMutex.Unlock()
+	fake.VMNICsStub = nil
+	if fake.vMNICsReturnsOnCall == nil {
+		fake.vMNICsReturnsOnCall = make(map[int]struct {
+			result1 []vcenter.NIC
+			result2 bool
+			result3 error
+		})
+	}
+	fake.vMNICsReturnsOnCall[i] = struct {
+		result1 []vcenter.NIC
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeVerifyVCenterClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.clusterInventoryMutex.RLock()
+	defer fake.clusterInventoryMutex.RUnlock()
+	fake.findVMInClustersMutex.RLock()
+	defer fake.findVMInClustersMutex.RUnlock()
+	fake.vMNICsMutex.RLock()
+	defer fake.vMNICsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeVerifyVCenterClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ migrate.VerifyVCenterClient = new(FakeVerifyVCenterClient)
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/converter"
+)
+
+//counterfeiter:generate . ClusterMemoryClient
+type ClusterMemoryClient interface {
+	ClusterFreeMemoryMB(ctx context.Context, cluster string) (int64, error)
+}
+
+// Placement prepares the compute placement strategy, places the VMs before migrating and reports where the VMs
+// were placed
+type Placement struct {
+	compute      *converter.MappedCompute
+	targetClient func(az string) ClusterMemoryClient
+	out          Printer
+}
+
+// NewPlacement creates a new Placement, the target client func returns the target vCenter client for an AZ
+func NewPlacement(compute *converter.MappedCompute, targetClient func(az string) ClusterMemoryClient, out Printer) *Placement {
+	return &Placement{
+		compute:      compute,
+		targetClient: targetClient,
+		out:          out,
+	}
+}
+
+// Prepare prints the placement strategy and loads the target cluster free memory used by the least-loaded strategy,
+// it does nothing when every source cluster maps to a single target cluster
+func (p *Placement) Prepare(ctx context.Context) error {
+	if !p.hasMultipleTargets() {
+		return nil
+	}
+	strategy := p.compute.PlacementStrategy()
+	p.out.Printf("Placing VMs across multiple target clusters using the %s strategy", strategy.Name())
+
+	leastLoaded, ok := strategy.(*converter.LeastLoadedPlacement)
+	if !ok {
+		return nil
+	}
+	loaded := map[string]bool{}
+	for _, m := range p.compute.Mappings() {
+		key := m.Target.Datacenter + "/" + m.Target.Cluster
+		if loaded[key] {
+			continue
+		}
+		loaded[key] = true
+
+		client := p.targetClient(m.Target.Name)
+		if client == nil {
+			return fmt.Errorf("could not find target vcenter client for AZ %s", m.Target.Name)
+		}
+		free, err := client.ClusterFreeMemoryMB(ctx, m.Target.Cluster)
+		if err != nil {
+			return fmt.Errorf("could not get target cluster %s free memory: %w", m.Target.Cluster, err)
+		}
+		leastLoaded.SetFreeMemory(m.Target.Datacenter, m.Target.Cluster, free)
+	}
+	return nil
+}
+
+// Plan places the VMs in migration order before any are migrated, so the strategy's choices don't depend on
+// which worker converts a VM first, and prints each VM's target cluster. The place func places a single VM,
+// returning nil for a VM that won't be migrated. It does nothing when every source cluster maps to a single
+// target cluster.
+func (p *Placement) Plan(ctx context.Context, vms []VM, place func(context.Context, VM) (*converter.AZ, error)) {
+	if !p.hasMultipleTargets() {
+		return
+	}
+	l := log.FromContext(ctx)
+	for _, vm := range vms {
+		target, err := place(ctx, vm)
+		if err != nil {
+			// the place func records the error so the VM fails to migrate with it
+			l.Warnf("Could not place VM %s: %s", vm.Name, err)
+			continue
+		}
+		if target == nil {
+			l.Debugf("Not placing VM %s as it wasn't found in the source vCenter", vm.Name)
+			continue
+		}
+		p.out.Printf("  %s: AZ %s cluster %s", vm.Name, target.Name, target.Cluster)
+	}
+}
+
+// Report prints the number of VMs placed on each target cluster, it does nothing when every source cluster
+// maps to a single target cluster
+func (p *Placement) Report() {
+	if !p.hasMultipleTargets() {
+		return
+	}
+
+	type azCluster struct {
+		az      string
+		cluster string
+	}
+	counts := map[azCluster]int{}
+	for _, pl := range p.compute.Placements() {
+		counts[azCluster{az: pl.Target.Name, cluster: pl.Target.Cluster}]++
+	}
+	var keys []azCluster
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].az != keys[j].az {
+			return keys[i].az < keys[j].az
+		}
+		return keys[i].cluster < keys[j].cluster
+	})
+
+	p.out.Printf("VM placement using the %s strategy:", p.compute.PlacementStrategy().Name())
+	for _, k := range keys {
+		p.out.Printf("  AZ %s cluster %s: %d VMs", k.az, k.cluster, counts[k])
+	}
+}
+
+func (p *Placement) hasMultipleTargets() bool {
+	targets := map[converter.AZ]map[string]bool{}
+	for _, m := range p.compute.Mappings() {
+		if targets[m.Source] == nil {
+			targets[m.Source] = map[string]bool{}
+		}
+		targets[m.Source][m.Target.Datacenter+"/"+m.Target.Cluster] = true
+		if len(targets[m.Source]) > 1 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/converter"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/migratefakes"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+func newPlacementCompute(strategy converter.PlacementStrategy) *converter.MappedCompute {
+	source := converter.AZ{Datacenter: "sDC", Cluster: "sC", Name: "az1"}
+	return converter.NewEmptyMappedCompute().WithPlacementStrategy(strategy).
+		Add(source, converter.AZ{Datacenter: "tDC", Cluster: "tC1", Name: "az1"}).
+		Add(source, converter.AZ{Datacenter: "tDC", Cluster: "tC2", Name: "az1"})
+}
+
+func TestPlacementLeastLoaded(t *testing.T) {
+	client := &migratefakes.FakeClusterMemoryClient{}
+	client.ClusterFreeMemoryMBCalls(func(_ context.Context, cluster string) (int64, error) {
+		if cluster == "tC2" {
+			return 4096, nil
+		}
+		return 1024, nil
+	})
+	cm := newPlacementCompute(converter.NewLeastLoadedPlacement())
+	out := log.NewBufferedStdout()
+	p := migrate.NewPlacement(cm, func(string) migrate.ClusterMemoryClient { return client }, out)
+
+	err := p.Prepare(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, client.ClusterFreeMemoryMBCallCount())
+	require.Equal(t, "Placing VMs across multiple target clusters using the least-loaded strategy", out.String())
+
+	for _, name := range []string{"vm1", "vm2", "vm3"} {
+		_, err = cm.TargetCompute(&vcenter.VM{
+			Name: name, AZ: "az1", Datacenter: "sDC", Cluster: "sC", MemoryMB: 2048,
+		})
+		require.NoError(t, err)
+	}
+
+	out = log.NewBufferedStdout()
+	migrate.NewPlacement(cm, nil, out).Report()
+	require.Equal(t, "VM placement using the least-loaded strategy:"+
+		"  AZ az1 cluster tC1: 1 VMs"+
+		"  AZ az1 cluster tC2: 2 VMs", out.String())
+}
+
+func TestPlacementLeastLoadedClientError(t *testing.T) {
+	client := &migratefakes.FakeClusterMemoryClient{}
+	client.ClusterFreeMemoryMBReturns(0, errors.New("vcenter unavailable"))
+	p := migrate.NewPlacement(newPlacementCompute(converter.NewLeastLoadedPlacement()),
+		func(string) migrate.ClusterMemoryClient { return client }, log.NewBufferedStdout())
+
+	err := p.Prepare(context.Background())
+	require.EqualError(t, err, "could not get target cluster tC1 free memory: vcenter unavailable")
+}
+
+func TestPlacementPlan(t *testing.T) {
+	cm := newPlacementCompute(converter.NewRoundRobinPlacement())
+	vmConverter := converter.New(converter.NewEmptyMappedNetwork(), converter.NewEmptyMappedDatastore(), cm)
+	place := func(_ context.Context, vm migrate.VM) (*converter.AZ, error) {
+		switch vm.Name {
+		case "gone":
+			return nil, nil
+		case "broken":
+			return nil, errors.New("vcenter unavailable")
+		}
+		target, err := vmConverter.TargetCompute(&vcenter.VM{Name: vm.Name, AZ: "az1", Datacenter: "sDC", Cluster: "sC"})
+		return &target, err
+	}
+	out := log.NewBufferedStdout()
+	p := migrate.NewPlacement(cm, nil, out)
+
+	p.Plan(context.Background(), []migrate.VM{{Name: "vm1"}, {Name: "gone"}, {Name: "broken"}, {Name: "vm2"}, {Name: "vm3"}}, place)
+	require.Equal(t, "  vm1: AZ az1 cluster tC1"+
+		"  vm2: AZ az1 cluster tC2"+
+		"  vm3: AZ az1 cluster tC1", out.String())
+
+	// converting a planned VM again looks up its placement
+	target, err := vmConverter.TargetCompute(&vcenter.VM{Name: "vm2", AZ: "az1", Datacenter: "sDC", Cluster: "sC"})
+	require.NoError(t, err)
+	require.Equal(t, "tC2", target.Cluster)
+}
+
+func TestPlacementSingleTargetCluster(t *testing.T) {
+	cm := converter.NewEmptyMappedCompute().
+		Add(converter.AZ{Datacenter: "sDC", Cluster: "sC", Name: "az1"},
+			converter.AZ{Datacenter: "tDC", Cluster: "tC1", Name: "az1"})
+	out := log.NewBufferedStdout()
+	p := migrate.NewPlacement(cm, nil, out)
+
+	require.NoError(t, p.Prepare(context.Background()))
+	p.Plan(context.Background(), []migrate.VM{{Name: "vm1"}}, func(context.Context, migrate.VM) (*converter.AZ, error) {
+		return nil, errors.New("unexpected")
+	})
+	p.Report()
+	require.Empty(t, out.String())
+}
@@ -0,0 +1,518 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/duration"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/inventory"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/converter"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/proxy"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+//counterfeiter:generate . VerifyVCenterClient
+type VerifyVCenterClient interface {
+	FindVMInClusters(ctx context.Context, azName, vmNameOrPath string, clusters []string) (*vcenter.VM, error)
+	VMNICs(ctx context.Context, vmNameOrPath string) ([]vcenter.NIC, bool, error)
+	ClusterInventory(ctx context.Context, clusters []string) (*vcenter.Inventory, error)
+}
+
+// VerifyResult holds the problems found verifying a single VM, a verified VM has none
+type VerifyResult struct {
+	VM       string
+	Problems []string
+}
+
+// Verified returns true if no problems were found
+func (r VerifyResult) Verified() bool {
+	return len(r.Problems) == 0
+}
+
+// Verifier checks that every VM to migrate now lives on the target vCenter where the mappings place it and that
+// nothing remains on the source
+type Verifier struct {
+	vmSource     *VMSource
+	computeMap   []converter.AZMapping
+	networkMap   map[string]string
+	datastoreMap map[string]string
+	sourceClient func(az string) VerifyVCenterClient
+	targetClient func(az string) VerifyVCenterClient
+	out          Printer
+
+	overrides    []converter.Override
+	folders      *converter.MappedFolder
+	mappingRules *MappingRules
+	idMappedNet  *converter.IDMappedNet
+	prepare      func(ctx context.Context) error
+	close        func(ctx context.Context)
+
+	// the datastores and networks attached to each VM's source clusters, by AZ and clusters
+	sourceInventories map[string]*vcenter.Inventory
+}
+
+// NewVerifier creates a new Verifier, the client funcs return the source and target vCenter client for an AZ
+func NewVerifier(vmSource *VMSource, computeMap []converter.AZMapping, networkMap, datastoreMap map[string]string,
+	sourceClient, targetClient func(az string) VerifyVCenterClient, out Printer) *Verifier {
+
+	return &Verifier{
+		vmSource:          vmSource,
+		computeMap:        computeMap,
+		networkMap:        networkMap,
+		datastoreMap:      datastoreMap,
+		sourceClient:      sourceClient,
+		targetClient:      targetClient,
+		out:               out,
+		sourceInventories: map[string]*vcenter.Inventory{},
+	}
+}
+
+// NewVerifierFromConfig creates a new Verifier from the config
+func NewVerifierFromConfig(c config.Config) (*Verifier, error) {
+	dialer, err := proxy.NewDialer(c.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	clientPool := ConfigToVCenterClientPool(c, dialer)
+	computeMap, err := ConfigToAZMapping(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// the exact mappings and any resolved rules are added to these maps before verifying
+	networkMap, datastoreMap := map[string]string{}, map[string]string{}
+
+	out := log.NewUpdatableStdout()
+	v := NewVerifier(NewVMSourceFromConfig(c, dialer), computeMap, networkMap, datastoreMap,
+		func(az string) VerifyVCenterClient {
+			if sc := clientPool.GetSourceClientByAZ(az); sc != nil {
+				return sc
+			}
+			return nil
+		},
+		func(az string) VerifyVCenterClient {
+			if tc := clientPool.GetTargetClientByAZ(az); tc != nil {
+				return tc
+			}
+			return nil
+		}, out).
+		WithOverrides(ConfigToOverrides(c)).
+		WithMappingRules(NewMappingRules(c.NetworkNameMap(), c.DatastoreNameMap(), out))
+	if len(c.FolderMap) > 0 {
+		v.WithFolderMappings(converter.NewMappedFolder(c.FolderMap))
+	}
+	if c.MapNetworksByID() {
+		v.WithNetworkIDMapping(converter.NewIDMappedNetwork(networkMap, c.NetworkTargetSwitch))
+	}
+
+	v.close = func(ctx context.Context) {
+		clientPool.Close(ctx)
+		closeDialer(ctx, dialer)
+	}
+	v.prepare = func(ctx context.Context) error {
+		var sourceClients []InventoryClient
+		for _, sc := range uniqueClients(clientPool.GetSourceClients()) {
+			sourceClients = append(sourceClients, sc)
+		}
+		resolved, err := v.mappingRules.Resolve(ctx, sourceClients)
+		if err != nil {
+			return err
+		}
+		copyMappings(networkMap, resolved.Networks)
+		copyMappings(datastoreMap, resolved.Datastores)
+
+		if v.idMappedNet == nil {
+			return nil
+		}
+		var sources, targets []converter.NetworkIdentityLister
+		for _, sc := range uniqueClients(clientPool.GetSourceClients()) {
+			sources = append(sources, sc)
+		}
+		for _, tc := range uniqueClients(clientPool.GetTargetClients()) {
+			targets = append(targets, tc)
+		}
+		return v.idMappedNet.Load(ctx, sources, targets)
+	}
+	return v, nil
+}
+
+// WithOverrides expects VMs matching an override in the override's cluster, resource pool, folder, datastore and
+// network
+func (v *Verifier) WithOverrides(overrides []converter.Override) *Verifier {
+	v.overrides = overrides
+	return v
+}
+
+// WithFolderMappings expects VMs in the folder the mappings place them in rather than any folder in the datacenter
+func (v *Verifier) WithFolderMappings(folders *converter.MappedFolder) *Verifier {
+	v.folders = folders
+	return v
+}
+
+// WithMappingRules resolves the network and datastore rules against the source vCenters before verifying
+func (v *Verifier) WithMappingRules(mappingRules *MappingRules) *Verifier {
+	v.mappingRules = mappingRules
+	return v
+}
+
+// WithNetworkIDMapping also accepts target networks sharing a VLAN or segment ID with a source network
+func (v *Verifier) WithNetworkIDMapping(idMappedNet *converter.IDMappedNet) *Verifier {
+	v.idMappedNet = idMappedNet
+	return v
+}
+
+// Verify checks every VM to migrate, printing each VM's result and a summary. It returns an error if any VM
+// failed verification.
+func (v *Verifier) Verify(ctx context.Context) ([]VerifyResult, error) {
+	start := time.Now()
+	if v.close != nil {
+		defer v.close(ctx)
+	}
+	if v.prepare != nil {
+		err := v.prepare(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	vms, err := v.vmSource.VMsToMigrate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []VerifyResult
+	failed := 0
+	for _, vm := range vms {
+		r := v.VerifyVM(ctx, vm)
+		results = append(results, r)
+		if r.Verified() {
+			v.out.Printf("✅ %s", vm.Name)
+			continue
+		}
+		failed++
+		v.out.Printf("❌ %s", vm.Name)
+		for _, p := range r.Problems {
+			v.out.Printf("    %s", p)
+		}
+	}
+
+	v.out.Printf("Verified %d out of %d VMs in %s", len(vms)-failed, len(vms),
+		duration.HumanReadable(time.Since(start)))
+	if failed > 0 {
+		return results, fmt.Errorf("%d VMs failed verification", failed)
+	}
+	return results, nil
+}
+
+// VerifyVM checks the VM no longer exists on the source and is where the mappings place it on the target
+func (v *Verifier) VerifyVM(ctx context.Context, vm VM) VerifyResult {
+	l := log.FromContext(ctx)
+	r := VerifyResult{VM: vm.Name}
+
+	sc := v.sourceClient(vm.AZ)
+	tc := v.targetClient(vm.AZ)
+	if sc == nil || tc == nil {
+		r.Problems = append(r.Problems, fmt.Sprintf("could not find a source and target vCenter for AZ %s", vm.AZ))
+		return r
+	}
+
+	// when the source and target share a cluster the VM can't be told apart from a leftover by location
+	if clusters := v.sourceOnlyClusters(vm); len(clusters) > 0 {
+		_, err := sc.FindVMInClusters(ctx, vm.AZ, vm.Name, clusters)
+		if err == nil {
+			r.Problems = append(r.Problems, "still exists on the source vCenter")
+		} else if !isVMNotFound(err) {
+			r.Problems = append(r.Problems, fmt.Sprintf("could not check the source vCenter: %s", err))
+		}
+	}
+
+	targetVM, err := tc.FindVMInClusters(ctx, vm.AZ, vm.Name, v.targetClusters(vm.AZ))
+	if err != nil {
+		if isVMNotFound(err) {
+			r.Problems = append(r.Problems, "not found on the target vCenter")
+		} else {
+			r.Problems = append(r.Problems, fmt.Sprintf("could not check the target vCenter: %s", err))
+		}
+		return r
+	}
+	l.Debugf("Found %s on target in %s/%s %s", vm.Name, targetVM.Cluster, targetVM.ResourcePool, targetVM.Folder)
+
+	// the VM's disks and adapters are expected on the mapped targets of its source clusters' datastores and networks
+	source := v.sourceInventory(ctx, sc, vm)
+
+	o, hasOverride := v.override(targetVM)
+	r.Problems = append(r.Problems, v.verifyCompute(vm, targetVM, o, hasOverride)...)
+	r.Problems = append(r.Problems, v.verifyDatastores(targetVM, source, o, hasOverride)...)
+
+	// stemcells are templates that never power on, so their adapters aren't expected to be connected
+	if vm.Stemcell() {
+		return r
+	}
+	nics, poweredOn, err := tc.VMNICs(ctx, targetVM.Name)
+	if err != nil {
+		r.Problems = append(r.Problems, fmt.Sprintf("could not get network adapters: %s", err))
+		return r
+	}
+	r.Problems = append(r.Problems, v.verifyNICs(vm, nics, poweredOn, source, o, hasOverride)...)
+	return r
+}
+
+// sourceInventory returns the datastores and networks attached to the VM's source clusters, or nil if the source
+// vCenter can't list them, e.g. once the source clusters are decommissioned
+func (v *Verifier) sourceInventory(ctx context.Context, sc VerifyVCenterClient, vm VM) *vcenter.Inventory {
+	key := vm.AZ + "/" + strings.Join(vm.Clusters, ",")
+	if inv, ok := v.sourceInventories[key]; ok {
+		return inv
+	}
+	inv, err := sc.ClusterInventory(ctx, vm.Clusters)
+	if err != nil {
+		log.FromContext(ctx).Warnf("Could not list the AZ %s source cluster datastores and networks, "+
+			"accepting any mapped target: %s", vm.AZ, err)
+		inv = nil
+	}
+	v.sourceInventories[key] = inv
+	return inv
+}
+
+func (v *Verifier) verifyCompute(vm VM, targetVM *vcenter.VM, o converter.Override, hasOverride bool) []string {
+	var problems []string
+	rp := targetResourcePool(targetVM.ResourcePool)
+	if hasOverride && o.Cluster != "" {
+		if !inventory.Same(targetVM.Cluster, o.Cluster) {
+			problems = append(problems, fmt.Sprintf("in cluster %s, expected override cluster %s",
+				targetVM.Cluster, o.Cluster))
+		}
+		if o.ResourcePool != "" && rp != o.ResourcePool {
+			problems = append(problems, fmt.Sprintf("in resource pool %s, expected override resource pool %s",
+				displayResourcePool(rp), o.ResourcePool))
+		}
+	} else {
+		var pools []string
+		for _, m := range v.computeMap {
+			if m.Target.Name == vm.AZ && inventory.Same(m.Target.Cluster, targetVM.Cluster) {
+				pools = append(pools, m.Target.ResourcePool)
+			}
+		}
+		if len(pools) == 0 {
+			problems = append(problems, fmt.Sprintf("in cluster %s which isn't a target cluster for AZ %s",
+				targetVM.Cluster, vm.AZ))
+		} else if !containsString(pools, rp) && !(hasOverride && o.ResourcePool == rp) {
+			problems = append(problems, fmt.Sprintf("in resource pool %s, expected %s",
+				displayResourcePool(rp), displayResourcePool(pools[0])))
+		}
+	}
+
+	if hasOverride && o.Folder != "" {
+		expected := o.TargetFolder(targetVM.Datacenter)
+		if !converter.SameVMFolder(targetVM.Folder, expected) {
+			problems = append(problems, fmt.Sprintf("in folder %s, expected override folder %s",
+				targetVM.Folder, expected))
+		}
+	} else if v.folders != nil {
+		sourceDC := v.sourceDatacenter(vm.AZ)
+		if !v.folders.IsTargetFolder(targetVM.Folder, sourceDC, targetVM.Datacenter) {
+			problems = append(problems, fmt.Sprintf("in folder %s which the folder mappings don't place VMs "+
+				"from datacenter %s in", targetVM.Folder, sourceDC))
+		}
+	} else if !converter.InDatacenterVMFolder(targetVM.Folder, targetVM.Datacenter) {
+		problems = append(problems, fmt.Sprintf("in folder %s, expected a folder under /%s/vm",
+			targetVM.Folder, strings.Trim(targetVM.Datacenter, "/")))
+	}
+	return problems
+}
+
+func (v *Verifier) verifyDatastores(targetVM *vcenter.VM, source *vcenter.Inventory, o converter.Override,
+	hasOverride bool) []string {
+
+	var allowed []string
+	if source != nil {
+		for _, ds := range source.Datastores {
+			if target, ok := v.datastoreMap[ds]; ok && !containsString(allowed, target) {
+				allowed = append(allowed, target)
+			}
+		}
+	} else {
+		for _, ds := range v.datastoreMap {
+			allowed = append(allowed, ds)
+		}
+	}
+	if hasOverride && o.Datastore != "" {
+		allowed = []string{o.Datastore}
+	}
+
+	var problems []string
+	for _, d := range targetVM.Disks {
+		if containsString(allowed, d.Datastore) {
+			continue
+		}
+		if source != nil && !(hasOverride && o.Datastore != "") {
+			problems = append(problems, fmt.Sprintf("disk %d on datastore %s which isn't mapped from a source "+
+				"cluster datastore", d.ID, d.Datastore))
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("disk %d on datastore %s which isn't a mapped target datastore",
+			d.ID, d.Datastore))
+	}
+	return problems
+}
+
+func (v *Verifier) verifyNICs(vm VM, nics []vcenter.NIC, poweredOn bool, source *vcenter.Inventory,
+	o converter.Override, hasOverride bool) []string {
+
+	var allowed []string
+	if source != nil {
+		for _, n := range source.Networks {
+			if target, ok := v.targetNetwork(vm, n); ok {
+				allowed = append(allowed, path.Base(target))
+			}
+		}
+	} else {
+		for _, n := range v.networkMap {
+			allowed = append(allowed, path.Base(n))
+		}
+		if v.idMappedNet != nil {
+			allowed = append(allowed, v.idMappedNet.MappedTargetNetworks()...)
+		}
+	}
+	if hasOverride && o.Network != "" {
+		allowed = []string{path.Base(o.Network)}
+	}
+
+	var problems []string
+	for _, n := range nics {
+		if !containsString(allowed, n.Network) {
+			if source != nil && !(hasOverride && o.Network != "") {
+				problems = append(problems, fmt.Sprintf("%s on network %s which isn't mapped from a source "+
+					"cluster network", n.Label, n.Network))
+			} else {
+				problems = append(problems, fmt.Sprintf("%s on network %s which isn't a mapped target network",
+					n.Label, n.Network))
+			}
+		}
+		if !n.StartConnected {
+			problems = append(problems, fmt.Sprintf("%s is not set to connect at power on", n.Label))
+		}
+		if poweredOn && !n.Connected {
+			problems = append(problems, fmt.Sprintf("%s is not connected", n.Label))
+		}
+	}
+	return problems
+}
+
+// targetNetwork returns the target network the source network is mapped to, if any
+func (v *Verifier) targetNetwork(vm VM, sourceNetwork string) (string, bool) {
+	if target, ok := v.networkMap[sourceNetwork]; ok {
+		return target, true
+	}
+	if v.idMappedNet == nil {
+		return "", false
+	}
+	targets, err := v.idMappedNet.TargetNetworks(&vcenter.VM{
+		Name:       vm.Name,
+		Datacenter: v.sourceDatacenter(vm.AZ),
+		Networks:   []string{sourceNetwork},
+	})
+	if err != nil {
+		// not every network attached to the source clusters has a target with the same ID
+		return "", false
+	}
+	return targets[sourceNetwork], true
+}
+
+// sourceDatacenter returns the AZ's source datacenter
+func (v *Verifier) sourceDatacenter(az string) string {
+	for _, m := range v.computeMap {
+		if m.Source.Name == az {
+			return m.Source.Datacenter
+		}
+	}
+	return ""
+}
+
+func (v *Verifier) override(targetVM *vcenter.VM) (converter.Override, bool) {
+	for _, o := range v.overrides {
+		if o.Matches(targetVM) {
+			return o, true
+		}
+	}
+	return converter.Override{}, false
+}
+
+func (v *Verifier) targetClusters(az string) []string {
+	var clusters []string
+	for _, m := range v.computeMap {
+		if m.Target.Name == az && !containsString(clusters, m.Target.Cluster) {
+			clusters = append(clusters, m.Target.Cluster)
+		}
+	}
+	for _, o := range v.overrides {
+		if o.Cluster != "" && !containsString(clusters, o.Cluster) {
+			clusters = append(clusters, o.Cluster)
+		}
+	}
+	return clusters
+}
+
+// sourceOnlyClusters returns the VM's source clusters that aren't also a target cluster in the same datacenter
+func (v *Verifier) sourceOnlyClusters(vm VM) []string {
+	shared := map[string]bool{}
+	for _, m := range v.computeMap {
+		if m.Source.Name != vm.AZ {
+			continue
+		}
+		for _, t := range v.computeMap {
+			if t.Target.Name == vm.AZ && inventory.Same(t.Target.Datacenter, m.Source.Datacenter) &&
+				inventory.Same(t.Target.Cluster, m.Source.Cluster) {
+				shared[m.Source.Cluster] = true
+			}
+		}
+	}
+
+	var clusters []string
+	for _, c := range vm.Clusters {
+		if !shared[c] {
+			clusters = append(clusters, c)
+		}
+	}
+	return clusters
+}
+
+func isVMNotFound(err error) bool {
+	var notFound *vcenter.VMNotFoundError
+	return errors.As(err, &notFound)
+}
+
+// targetResourcePool returns an empty string for the cluster's root resource pool like the compute mappings
+func targetResourcePool(rp string) string {
+	if rp == "Resources" {
+		return ""
+	}
+	return rp
+}
+
+func displayResourcePool(rp string) string {
+	if rp == "" {
+		return "Resources"
+	}
+	return rp
+}
+
+func containsString(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,248 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/converter"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/migratefakes"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+)
+
+func newTestVerifier() (*migrate.Verifier, *migratefakes.FakeVerifyVCenterClient, *migratefakes.FakeVerifyVCenterClient) {
+	source := &migratefakes.FakeVerifyVCenterClient{}
+	source.FindVMInClustersReturns(nil, vcenter.NewVMNotFoundError("vm1", errors.New("not found")))
+	source.ClusterInventoryReturns(&vcenter.Inventory{
+		Clusters:   []string{"Cluster1"},
+		Datastores: []string{"ds1"},
+		Networks:   []string{"Net1"},
+	}, nil)
+	target := &migratefakes.FakeVerifyVCenterClient{}
+	target.FindVMInClustersReturns(&vcenter.VM{
+		Name:         "vm1",
+		Datacenter:   "DC2",
+		Cluster:      "Cluster2",
+		ResourcePool: "RP2",
+		Folder:       "/DC2/vm/pcf_vms",
+		Disks:        []vcenter.Disk{{ID: 201, Datastore: "ds2"}},
+	}, nil)
+	target.VMNICsReturns([]vcenter.NIC{{
+		Label:          "ethernet-0",
+		Network:        "Net2",
+		Connected:      true,
+		StartConnected: true,
+	}}, true, nil)
+
+	computeMap := []converter.AZMapping{{
+		Source: converter.AZ{Datacenter: "DC1", Cluster: "Cluster1", Name: "az1"},
+		Target: converter.AZ{Datacenter: "DC2", Cluster: "Cluster2", ResourcePool: "RP2", Name: "az1"},
+	}}
+	v := migrate.NewVerifier(nil, computeMap,
+		map[string]string{"Net1": "Net2", "Net3": "Net4"},
+		map[string]string{"ds1": "ds2", "ds3": "ds4"},
+		func(string) migrate.VerifyVCenterClient { return source },
+		func(string) migrate.VerifyVCenterClient { return target },
+		log.NewBufferedStdout())
+	return v, source, target
+}
+
+func TestVerifyVM(t *testing.T) {
+	v, source, target := newTestVerifier()
+	r := v.VerifyVM(context.Background(), migrate.VM{
+		Name:       "vm1",
+		AZ:         "az1",
+		Deployment: "cf",
+		Clusters:   []string{"Cluster1"},
+	})
+	require.True(t, r.Verified(), r.Problems)
+
+	_, az, name, clusters := source.FindVMInClustersArgsForCall(0)
+	require.Equal(t, "az1", az)
+	require.Equal(t, "vm1", name)
+	require.Equal(t, []string{"Cluster1"}, clusters)
+	_, _, _, clusters = target.FindVMInClustersArgsForCall(0)
+	require.Equal(t, []string{"Cluster2"}, clusters)
+}
+
+func TestVerifyVMReportsProblems(t *testing.T) {
+	v, source, target := newTestVerifier()
+	source.FindVMInClustersReturns(&vcenter.VM{Name: "vm1"}, nil)
+	target.FindVMInClustersReturns(&vcenter.VM{
+		Name:         "vm1",
+		Datacenter:   "DC2",
+		Cluster:      "Cluster2",
+		ResourcePool: "Resources",
+		Folder:       "/DC1/vm/pcf_vms",
+		Disks:        []vcenter.Disk{{ID: 201, Datastore: "ds1"}},
+	}, nil)
+	target.VMNICsReturns([]vcenter.NIC{{Label: "ethernet-0", Network: "Net1"}}, true, nil)
+
+	r := v.VerifyVM(context.Background(), migrate.VM{
+		Name:       "vm1",
+		AZ:         "az1",
+		Deployment: "cf",
+		Clusters:   []string{"Cluster1"},
+	})
+	require.Equal(t, []string{
+		"still exists on the source vCenter",
+		"in resource pool Resources, expected RP2",
+		"in folder /DC1/vm/pcf_vms, expected a folder under /DC2/vm",
+		"disk 201 on datastore ds1 which isn't mapped from a source cluster datastore",
+		"ethernet-0 on network Net1 which isn't mapped from a source cluster network",
+		"ethernet-0 is not set to connect at power on",
+		"ethernet-0 is not connected",
+	}, r.Problems)
+}
+
+func TestVerifyVMChecksSourceClusterMappings(t *testing.T) {
+	v, source, target := newTestVerifier()
+	target.FindVMInClustersReturns(&vcenter.VM{
+		Name:         "vm1",
+		Datacenter:   "DC2",
+		Cluster:      "Cluster2",
+		ResourcePool: "RP2",
+		Folder:       "/DC2/vm/pcf_vms",
+		Disks:        []vcenter.Disk{{ID: 201, Datastore: "ds4"}},
+	}, nil)
+	target.VMNICsReturns([]vcenter.NIC{{
+		Label:          "ethernet-0",
+		Network:        "Net4",
+		Connected:      true,
+		StartConnected: true,
+	}}, true, nil)
+	vm := migrate.VM{Name: "vm1", AZ: "az1", Deployment: "cf", Clusters: []string{"Cluster1"}}
+
+	// ds4 and Net4 are mapped targets, but not of the datastores and networks attached to the source cluster
+	r := v.VerifyVM(context.Background(), vm)
+	require.Equal(t, []string{
+		"disk 201 on datastore ds4 which isn't mapped from a source cluster datastore",
+		"ethernet-0 on network Net4 which isn't mapped from a source cluster network",
+	}, r.Problems)
+	_, clusters := source.ClusterInventoryArgsForCall(0)
+	require.Equal(t, []string{"Cluster1"}, clusters)
+
+	// the source cluster inventory is only listed once per AZ
+	v.VerifyVM(context.Background(), vm)
+	require.Equal(t, 1, source.ClusterInventoryCallCount())
+}
+
+func TestVerifyVMWithoutSourceClusters(t *testing.T) {
+	v, source, target := newTestVerifier()
+	source.ClusterInventoryReturns(nil, errors.New("cluster not found"))
+	target.FindVMInClustersReturns(&vcenter.VM{
+		Name:         "vm1",
+		Datacenter:   "DC2",
+		Cluster:      "Cluster2",
+		ResourcePool: "RP2",
+		Folder:       "/DC2/vm/pcf_vms",
+		Disks:        []vcenter.Disk{{ID: 201, Datastore: "ds4"}},
+	}, nil)
+
+	// any mapped target is accepted once the source clusters are gone
+	r := v.VerifyVM(context.Background(), migrate.VM{Name: "vm1", AZ: "az1", Deployment: "cf", Clusters: []string{"Cluster1"}})
+	require.True(t, r.Verified(), r.Problems)
+}
+
+func TestVerifyVMWithFolderMappings(t *testing.T) {
+	v, _, target := newTestVerifier()
+	v.WithFolderMappings(converter.NewMappedFolder(map[string]string{
+		"/DC1/vm/pcf_vms": "/DC2/vm/foundations/prod",
+	}))
+	vm := migrate.VM{Name: "vm1", AZ: "az1", Deployment: "cf", Clusters: []string{"Cluster1"}}
+
+	r := v.VerifyVM(context.Background(), vm)
+	require.Equal(t, []string{
+		"in folder /DC2/vm/pcf_vms which the folder mappings don't place VMs from datacenter DC1 in",
+	}, r.Problems)
+
+	target.FindVMInClustersReturns(&vcenter.VM{
+		Name:         "vm1",
+		Datacenter:   "DC2",
+		Cluster:      "Cluster2",
+		ResourcePool: "RP2",
+		Folder:       "/DC2/vm/foundations/prod/guid",
+		Disks:        []vcenter.Disk{{ID: 201, Datastore: "ds2"}},
+	}, nil)
+	r = v.VerifyVM(context.Background(), vm)
+	require.True(t, r.Verified(), r.Problems)
+}
+
+func TestVerifyVMNotOnTarget(t *testing.T) {
+	v, _, target := newTestVerifier()
+	target.FindVMInClustersReturns(nil, vcenter.NewVMNotFoundError("vm1", errors.New("not found")))
+
+	r := v.VerifyVM(context.Background(), migrate.VM{Name: "vm1", AZ: "az1", Clusters: []string{"Cluster1"}})
+	require.Equal(t, []string{"not found on the target vCenter"}, r.Problems)
+	require.Equal(t, 0, target.VMNICsCallCount())
+}
+
+func TestVerifyVMSkipsStemcellNICs(t *testing.T) {
+	v, _, target := newTestVerifier()
+	target.VMNICsReturns([]vcenter.NIC{{Label: "ethernet-0", Network: "Net1"}}, false, nil)
+
+	r := v.VerifyVM(context.Background(), migrate.VM{Name: "sc-1", AZ: "az1", Clusters: []string{"Cluster1"}})
+	require.True(t, r.Verified(), r.Problems)
+	require.Equal(t, 0, target.VMNICsCallCount())
+}
+
+func TestVerifyVMWithOverride(t *testing.T) {
+	v, _, target := newTestVerifier()
+	v.WithOverrides([]converter.Override{{
+		VM:        "vm1",
+		Cluster:   "Cluster3",
+		Folder:    "isolated",
+		Datastore: "ds3",
+		Network:   "Net3",
+	}})
+	target.FindVMInClustersReturns(&vcenter.VM{
+		Name:         "vm1",
+		Datacenter:   "DC2",
+		Cluster:      "Cluster3",
+		ResourcePool: "Resources",
+		Folder:       "/DC2/vm/isolated",
+		Disks:        []vcenter.Disk{{ID: 201, Datastore: "ds3"}},
+	}, nil)
+	target.VMNICsReturns([]vcenter.NIC{{
+		Label:          "ethernet-0",
+		Network:        "Net3",
+		Connected:      true,
+		StartConnected: true,
+	}}, true, nil)
+
+	r := v.VerifyVM(context.Background(), migrate.VM{Name: "vm1", AZ: "az1", Clusters: []string{"Cluster1"}})
+	require.True(t, r.Verified(), r.Problems)
+	_, _, _, clusters := target.FindVMInClustersArgsForCall(0)
+	require.Equal(t, []string{"Cluster2", "Cluster3"}, clusters)
+}
+
+func TestVerifyVMSkipsSourceCheckForSharedCluster(t *testing.T) {
+	source := &migratefakes.FakeVerifyVCenterClient{}
+	target := &migratefakes.FakeVerifyVCenterClient{}
+	target.FindVMInClustersReturns(&vcenter.VM{
+		Name:       "vm1",
+		Datacenter: "DC1",
+		Cluster:    "Cluster1",
+		Folder:     "/DC1/vm",
+	}, nil)
+	computeMap := []converter.AZMapping{{
+		Source: converter.AZ{Datacenter: "DC1", Cluster: "Cluster1", Name: "az1"},
+		Target: converter.AZ{Datacenter: "DC1", Cluster: "Cluster1", Name: "az1"},
+	}}
+	v := migrate.NewVerifier(nil, computeMap, nil, nil,
+		func(string) migrate.VerifyVCenterClient { return source },
+		func(string) migrate.VerifyVCenterClient { return target },
+		log.NewBufferedStdout())
+
+	r := v.VerifyVM(context.Background(), migrate.VM{Name: "vm1", AZ: "az1", Clusters: []string{"Cluster1"}})
+	require.True(t, r.Verified(), r.Problems)
+	require.Equal(t, 0, source.FindVMInClustersCallCount())
+}
@@ -9,6 +9,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/converter"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
@@ -35,6 +37,17 @@ type VMMigrator struct {
 	clientPool        *vcenter.Pool
 	vmRelocator       VMRelocator
 	updatableStdout   UpdatableLogger
+	healthVerifier    *HealthVerifier
+	migratedFolders   *MigratedFolders
+
+	placedMutex sync.Mutex
+	placed      map[string]placedVM
+}
+
+// placedVM is the source VM found, or the error finding or placing it, when the VM was placed before migrating
+type placedVM struct {
+	vm  *vcenter.VM
+	err error
 }
 
 func NewVMMigrator(clientPool *vcenter.Pool, sourceVMConverter *converter.Converter, vmRelocator VMRelocator, updatableStdout UpdatableLogger) *VMMigrator {
@@ -43,9 +56,22 @@ func NewVMMigrator(clientPool *vcenter.Pool, sourceVMConverter *converter.Conver
 		sourceVMConverter: sourceVMConverter,
 		vmRelocator:       vmRelocator,
 		updatableStdout:   updatableStdout,
+		placed:            map[string]placedVM{},
 	}
 }
 
+// WithHealthVerifier waits for BOSH to report each migrated VM is healthy
+func (m *VMMigrator) WithHealthVerifier(healthVerifier *HealthVerifier) *VMMigrator {
+	m.healthVerifier = healthVerifier
+	return m
+}
+
+// WithMigratedFolders records the source folder of each migrated VM
+func (m *VMMigrator) WithMigratedFolders(migratedFolders *MigratedFolders) *VMMigrator {
+	m.migratedFolders = migratedFolders
+	return m
+}
+
 func (m *VMMigrator) Migrate(ctx context.Context, sourceVM VM) error {
 	sourceClient := m.clientPool.GetSourceClientByAZ(sourceVM.AZ)
 	if sourceClient == nil {
@@ -54,51 +80,139 @@ func (m *VMMigrator) Migrate(ctx context.Context, sourceVM VM) error {
 	return m.MigrateVMToTarget(ctx, sourceClient, sourceVM)
 }
 
+// Place finds the source VM and chooses its target compute without migrating it, so the VMs can be placed in a
+// reproducible order before any are migrated. A VM not found in the source vCenter isn't placed. The VM found, or
+// the error placing it, is kept so migrating the VM doesn't look it up again and a VM that couldn't be placed fails.
+func (m *VMMigrator) Place(ctx context.Context, sourceVM VM) (*converter.AZ, error) {
+	sourceClient := m.clientPool.GetSourceClientByAZ(sourceVM.AZ)
+	if sourceClient == nil {
+		return nil, fmt.Errorf("could not find source vcenter client for VM %s in AZ %s", sourceVM.Name, sourceVM.AZ)
+	}
+	return m.PlaceWithClient(ctx, sourceClient, sourceVM)
+}
+
+// PlaceWithClient is the same as Place but finds the VM using the specified source client
+func (m *VMMigrator) PlaceWithClient(ctx context.Context, sourceClient VCenterClient, sourceVM VM) (*converter.AZ, error) {
+	v, err := findSourceVM(ctx, sourceClient, sourceVM)
+	if err != nil {
+		m.setPlaced(sourceVM, placedVM{err: err})
+		var e *vcenter.VMNotFoundError
+		if errors.As(err, &e) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	compute, err := m.sourceVMConverter.TargetCompute(v)
+	if err != nil {
+		m.setPlaced(sourceVM, placedVM{err: fmt.Errorf("could not place VM %s: %w", sourceVM.Name, err)})
+		return nil, err
+	}
+	m.setPlaced(sourceVM, placedVM{vm: v})
+	return &compute, nil
+}
+
 func (m *VMMigrator) MigrateVMToTarget(ctx context.Context, sourceClient VCenterClient, sourceVM VM) error {
-	m.printProcessing(ctx, sourceVM.Name, "preparing")
+	m.printProcessing(ctx, sourceVM.TaskName(), "preparing")
 
-	// find the VM to migrate but only look in the source cluster(s) as it may have already been moved
-	v, err := sourceClient.FindVMInClusters(ctx, sourceVM.AZ, sourceVM.Name, sourceVM.Clusters)
+	v, err := m.placedOrFindSourceVM(ctx, sourceClient, sourceVM)
 	if err != nil {
 		var e *vcenter.VMNotFoundError
 		if errors.As(err, &e) {
-			m.printSuccess(ctx, sourceVM.Name, "not found in source vCenter, skipping")
+			m.printSuccess(ctx, sourceVM.TaskName(), "not found in source vCenter, skipping")
 			return nil
 		}
-		m.printFailure(ctx, sourceVM.Name, err)
+		m.printFailure(ctx, sourceVM.TaskName(), err)
 		return err
 	}
 
 	vmTargetSpec, err := m.sourceVMConverter.TargetSpec(v)
 	if err != nil {
-		m.printFailure(ctx, sourceVM.Name, err)
+		m.printFailure(ctx, sourceVM.TaskName(), err)
 		return err
 	}
 
 	err = m.vmRelocator.RelocateVM(ctx, v, vmTargetSpec)
 	if err != nil {
-		m.printFailure(ctx, sourceVM.Name, err)
+		m.printFailure(ctx, sourceVM.TaskName(), err)
 		return err
 	}
+	if m.migratedFolders != nil {
+		m.migratedFolders.Add(sourceClient.HostName(), v.Folder)
+	}
 
-	m.printSuccess(ctx, sourceVM.Name, "done")
+	if m.healthVerifier != nil && sourceVM.Deployment != "" {
+		m.printProcessing(ctx, sourceVM.TaskName(), "waiting for BOSH to report healthy")
+		err = m.healthVerifier.Verify(ctx, sourceVM)
+		if err != nil {
+			if !m.healthVerifier.FlagOnly() {
+				m.printFailure(ctx, sourceVM.TaskName(), err)
+				return err
+			}
+			m.healthVerifier.Flag(sourceVM, err)
+			m.printWarning(ctx, sourceVM.TaskName(), err)
+			return nil
+		}
+	}
+
+	m.printSuccess(ctx, sourceVM.TaskName(), "done")
 	return nil
 }
 
+// placedOrFindSourceVM returns the VM found when it was placed, only once so a retried migration looks it up again,
+// otherwise it finds the VM
+func (m *VMMigrator) placedOrFindSourceVM(ctx context.Context, sourceClient VCenterClient, sourceVM VM) (*vcenter.VM, error) {
+	m.placedMutex.Lock()
+	p, ok := m.placed[placedKey(sourceVM)]
+	delete(m.placed, placedKey(sourceVM))
+	m.placedMutex.Unlock()
+	if ok {
+		return p.vm, p.err
+	}
+	return findSourceVM(ctx, sourceClient, sourceVM)
+}
+
+func (m *VMMigrator) setPlaced(sourceVM VM, p placedVM) {
+	m.placedMutex.Lock()
+	defer m.placedMutex.Unlock()
+	m.placed[placedKey(sourceVM)] = p
+}
+
+// placedKey identifies a VM, stemcells and additional VMs may share a name across AZs
+func placedKey(sourceVM VM) string {
+	return sourceVM.AZ + "/" + sourceVM.Name
+}
+
+// findSourceVM finds the VM to migrate but only looks in the source cluster(s) as it may have already been moved
+func findSourceVM(ctx context.Context, sourceClient VCenterClient, sourceVM VM) (*vcenter.VM, error) {
+	v, err := sourceClient.FindVMInClusters(ctx, sourceVM.AZ, sourceVM.Name, sourceVM.Clusters)
+	if err != nil {
+		return nil, err
+	}
+	v.Deployment = sourceVM.Deployment
+	v.InstanceGroup = sourceVM.InstanceGroup
+	return v, nil
+}
+
 const greenCheck = "✅"
 const redX = "❌"
+const warningSign = "⚠️"
+
+func (m *VMMigrator) printFailure(ctx context.Context, taskName string, err error) {
+	log.FromContext(ctx).Errorf("%s failed: %s", taskName, err)
+	m.updatableStdout.PrintUpdatablef(taskName, "%s %s - %s", taskName, redX, err)
+}
 
-func (m *VMMigrator) printFailure(ctx context.Context, srcVMName string, err error) {
-	log.FromContext(ctx).Errorf("%s failed: %s", srcVMName, err)
-	m.updatableStdout.PrintUpdatablef(srcVMName, "%s %s - %s", srcVMName, redX, err)
+func (m *VMMigrator) printWarning(ctx context.Context, taskName string, err error) {
+	log.FromContext(ctx).Warnf("%s migrated but unhealthy: %s", taskName, err)
+	m.updatableStdout.PrintUpdatablef(taskName, "%s %s - %s", taskName, warningSign, err)
 }
 
-func (m *VMMigrator) printProcessing(ctx context.Context, srcVMName, msg string) {
-	log.FromContext(ctx).Infof("%s processing: %s", srcVMName, msg)
-	m.updatableStdout.PrintUpdatablef(srcVMName, "%s - %s", srcVMName, fmt.Sprintf("%-40s", msg))
+func (m *VMMigrator) printProcessing(ctx context.Context, taskName, msg string) {
+	log.FromContext(ctx).Infof("%s processing: %s", taskName, msg)
+	m.updatableStdout.PrintUpdatablef(taskName, "%s - %s", taskName, fmt.Sprintf("%-40s", msg))
 }
 
-func (m *VMMigrator) printSuccess(ctx context.Context, srcVMName, msg string) {
-	log.FromContext(ctx).Infof("%s done: %s", srcVMName, msg)
-	m.updatableStdout.PrintUpdatablef(srcVMName, "%s %s - %-40s", srcVMName, greenCheck, msg)
+func (m *VMMigrator) printSuccess(ctx context.Context, taskName, msg string) {
+	log.FromContext(ctx).Infof("%s done: %s", taskName, msg)
+	m.updatableStdout.PrintUpdatablef(taskName, "%s %s - %-40s", taskName, greenCheck, msg)
 }
@@ -7,9 +7,12 @@ package migrate_test
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/bosh"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/migrate/converter"
@@ -56,9 +59,13 @@ func TestVMMigrator_MigrateVMToTarget(t *testing.T) {
 			Name:         "az1",
 		}))
 
+	sourceClient.HostNameReturns("vc1.example.com")
+	migratedFolders := migrate.NewMigratedFolders()
+
 	out := log.NewUpdatableStdout()
 	vmRelocator := &migratefakes.FakeVMRelocator{}
-	vmMigrator := migrate.NewVMMigrator(&vcenter.Pool{}, vmConverter, vmRelocator, out)
+	vmMigrator := migrate.NewVMMigrator(&vcenter.Pool{}, vmConverter, vmRelocator, out).
+		WithMigratedFolders(migratedFolders)
 
 	err := vmMigrator.MigrateVMToTarget(context.Background(), sourceClient, vmToMigrate)
 	require.NoError(t, err)
@@ -71,6 +78,131 @@ func TestVMMigrator_MigrateVMToTarget(t *testing.T) {
 	require.Equal(t, "Cluster2", targetSpec.Cluster)
 	require.Equal(t, map[string]string{"DS1": "DS2"}, targetSpec.Datastores)
 	require.Equal(t, map[string]string{"Net1": "Net2"}, targetSpec.Networks)
+	require.True(t, migratedFolders.Contains("vc1.example.com", "/DC1/vm"))
+}
+
+func TestVMMigrator_PlaceWithClient(t *testing.T) {
+	sourceClient := &migratefakes.FakeVCenterClient{}
+	sourceClient.FindVMInClustersReturnsOnCall(0, &vcenter.VM{
+		Name:       "vm1",
+		AZ:         "az1",
+		Datacenter: "DC1",
+		Cluster:    "Cluster1",
+		Folder:     "/DC1/vm",
+	}, nil)
+	sourceClient.FindVMInClustersReturnsOnCall(1, nil, vcenter.NewVMNotFoundError("vm2", nil))
+
+	source := converter.AZ{Datacenter: "DC1", Cluster: "Cluster1", Name: "az1"}
+	vmConverter := converter.New(converter.NewEmptyMappedNetwork(), converter.NewEmptyMappedDatastore(),
+		converter.NewEmptyMappedCompute().
+			Add(source, converter.AZ{Datacenter: "DC2", Cluster: "Cluster2", Name: "az1"}).
+			Add(source, converter.AZ{Datacenter: "DC2", Cluster: "Cluster3", Name: "az1"}))
+	vmRelocator := &migratefakes.FakeVMRelocator{}
+	vmMigrator := migrate.NewVMMigrator(&vcenter.Pool{}, vmConverter, vmRelocator, log.NewBufferedStdout())
+
+	target, err := vmMigrator.PlaceWithClient(context.Background(), sourceClient, migrate.VM{Name: "vm1", AZ: "az1"})
+	require.NoError(t, err)
+	require.Equal(t, "Cluster2", target.Cluster)
+
+	target, err = vmMigrator.PlaceWithClient(context.Background(), sourceClient, migrate.VM{Name: "vm2", AZ: "az1"})
+	require.NoError(t, err)
+	require.Nil(t, target)
+	require.Equal(t, 0, vmRelocator.RelocateVMCallCount())
+
+	// migrating reuses the VMs found when placing them
+	err = vmMigrator.MigrateVMToTarget(context.Background(), sourceClient, migrate.VM{Name: "vm1", AZ: "az1"})
+	require.NoError(t, err)
+	err = vmMigrator.MigrateVMToTarget(context.Background(), sourceClient, migrate.VM{Name: "vm2", AZ: "az1"})
+	require.NoError(t, err)
+	require.Equal(t, 2, sourceClient.FindVMInClustersCallCount())
+	require.Equal(t, 1, vmRelocator.RelocateVMCallCount())
+	_, _, targetSpec := vmRelocator.RelocateVMArgsForCall(0)
+	require.Equal(t, "Cluster2", targetSpec.Cluster)
+}
+
+func TestVMMigrator_MigrateVMToTargetPlacementFailure(t *testing.T) {
+	sourceClient := &migratefakes.FakeVCenterClient{}
+	sourceClient.FindVMInClustersReturnsOnCall(0, nil, errors.New("vcenter unavailable"))
+	sourceClient.FindVMInClustersReturnsOnCall(1, &vcenter.VM{
+		Name:       "vm1",
+		AZ:         "az1",
+		Datacenter: "DC1",
+		Cluster:    "Cluster1",
+		Folder:     "/DC1/vm",
+	}, nil)
+
+	source := converter.AZ{Datacenter: "DC1", Cluster: "Cluster1", Name: "az1"}
+	vmConverter := converter.New(converter.NewEmptyMappedNetwork(), converter.NewEmptyMappedDatastore(),
+		converter.NewEmptyMappedCompute().
+			Add(source, converter.AZ{Datacenter: "DC2", Cluster: "Cluster2", Name: "az1"}).
+			Add(source, converter.AZ{Datacenter: "DC2", Cluster: "Cluster3", Name: "az1"}))
+	vmRelocator := &migratefakes.FakeVMRelocator{}
+	vmMigrator := migrate.NewVMMigrator(&vcenter.Pool{}, vmConverter, vmRelocator, log.NewBufferedStdout())
+
+	_, err := vmMigrator.PlaceWithClient(context.Background(), sourceClient, migrate.VM{Name: "vm1", AZ: "az1"})
+	require.EqualError(t, err, "vcenter unavailable")
+
+	// the VM fails instead of being placed on the first target when it's migrated
+	err = vmMigrator.MigrateVMToTarget(context.Background(), sourceClient, migrate.VM{Name: "vm1", AZ: "az1"})
+	require.EqualError(t, err, "vcenter unavailable")
+	require.Equal(t, 0, vmRelocator.RelocateVMCallCount())
+
+	// a retry looks the VM up again
+	err = vmMigrator.MigrateVMToTarget(context.Background(), sourceClient, migrate.VM{Name: "vm1", AZ: "az1"})
+	require.NoError(t, err)
+	require.Equal(t, 1, vmRelocator.RelocateVMCallCount())
+}
+
+func TestVMMigrator_MigrateVMToTargetWithInstanceGroupOverride(t *testing.T) {
+	vmToMigrate := migrate.VM{
+		Name:          "vm1",
+		AZ:            "az1",
+		Deployment:    "pivotal-mysql-guid",
+		InstanceGroup: "mysql",
+		Clusters:      []string{"Cluster1"},
+	}
+
+	sourceClient := &migratefakes.FakeVCenterClient{}
+	sourceClient.FindVMInClustersReturnsOnCall(0, &vcenter.VM{
+		Name:       "vm1",
+		AZ:         "az1",
+		Datacenter: "DC1",
+		Cluster:    "Cluster1",
+		Folder:     "/DC1/vm",
+		Disks: []vcenter.Disk{
+			{
+				ID:        201,
+				Datastore: "DS1",
+			},
+		},
+		Networks: []string{"Net1"},
+	}, nil)
+
+	vmConverter := converter.New(
+		converter.NewEmptyMappedNetwork().Add("Net1", "Net2"),
+		converter.NewEmptyMappedDatastore().Add("DS1", "DS2"),
+		converter.NewEmptyMappedCompute().Add(converter.AZ{
+			Datacenter: "DC1",
+			Cluster:    "Cluster1",
+			Name:       "az1",
+		}, converter.AZ{
+			Datacenter: "DC2",
+			Cluster:    "Cluster2",
+			Name:       "az1",
+		})).
+		WithOverrides([]converter.Override{{InstanceGroup: "mysql", Datastore: "all-flash"}})
+
+	vmRelocator := &migratefakes.FakeVMRelocator{}
+	vmMigrator := migrate.NewVMMigrator(&vcenter.Pool{}, vmConverter, vmRelocator, log.NewUpdatableStdout())
+
+	err := vmMigrator.MigrateVMToTarget(context.Background(), sourceClient, vmToMigrate)
+	require.NoError(t, err)
+
+	_, srcVM, targetSpec := vmRelocator.RelocateVMArgsForCall(0)
+	require.Equal(t, "pivotal-mysql-guid", srcVM.Deployment)
+	require.Equal(t, "mysql", srcVM.InstanceGroup)
+	require.Equal(t, map[string]string{"DS1": "all-flash"}, targetSpec.Datastores)
+	require.Equal(t, map[string]string{"Net1": "Net2"}, targetSpec.Networks)
 }
 
 func TestVMMigrator_MigrateVMToTarget_VMNotFound(t *testing.T) {
@@ -108,3 +240,114 @@ func TestVMMigrator_MigrateVMToTarget_VMNotFound(t *testing.T) {
 
 	require.Contains(t, out.String(), "not found in source vCenter, skipping")
 }
+
+func TestVMMigrator_MigrateVMToTarget_StemcellInEachAZ(t *testing.T) {
+	sourceClient := &migratefakes.FakeVCenterClient{}
+	sourceClient.FindVMInClustersReturnsOnCall(0, &vcenter.VM{
+		Name:         "sc-guid",
+		AZ:           "az1",
+		Datacenter:   "DC1",
+		Cluster:      "Cluster1",
+		Folder:       "/DC1/vm/pcf_templates",
+		ResourcePool: "RP1",
+	}, nil)
+	sourceClient.FindVMInClustersReturnsOnCall(1, nil, &vcenter.VMNotFoundError{})
+
+	vmConverter := converter.New(
+		converter.NewEmptyMappedNetwork(),
+		converter.NewEmptyMappedDatastore(),
+		converter.NewEmptyMappedCompute().Add(converter.AZ{
+			Datacenter:   "DC1",
+			Cluster:      "Cluster1",
+			ResourcePool: "RP1",
+			Name:         "az1",
+		}, converter.AZ{
+			Datacenter:   "DC2",
+			Cluster:      "Cluster2",
+			ResourcePool: "RP2",
+			Name:         "az1",
+		}))
+
+	out := log.NewBufferedStdout()
+	vmRelocator := &migratefakes.FakeVMRelocator{}
+	vmMigrator := migrate.NewVMMigrator(&vcenter.Pool{}, vmConverter, vmRelocator, out)
+
+	// the same stemcell is listed for each AZ served by its CPI, each with its own output line
+	for _, az := range []string{"az1", "az2"} {
+		err := vmMigrator.MigrateVMToTarget(context.Background(), sourceClient,
+			migrate.VM{Name: "sc-guid", AZ: az, Clusters: []string{"Cluster1"}})
+		require.NoError(t, err)
+	}
+	require.Equal(t, 1, vmRelocator.RelocateVMCallCount())
+	require.Contains(t, out.String(), "sc-guid (az1) - preparing")
+	require.Contains(t, out.String(), "sc-guid (az2) - preparing")
+	require.Regexp(t, `sc-guid \(az2\) \S+ - not found in source vCenter, skipping`, out.String())
+}
+
+func TestVMMigrator_MigrateVMToTargetVerifiesHealth(t *testing.T) {
+	vmToMigrate := migrate.VM{
+		Name:          "vm1",
+		AZ:            "az1",
+		Deployment:    "cf-guid",
+		InstanceGroup: "router",
+		Clusters:      []string{"Cluster1"},
+	}
+
+	sourceClient := &migratefakes.FakeVCenterClient{}
+	sourceClient.FindVMInClustersReturns(&vcenter.VM{
+		Name:         "vm1",
+		AZ:           "az1",
+		Datacenter:   "DC1",
+		Cluster:      "Cluster1",
+		Folder:       "/DC1/vm",
+		ResourcePool: "RP1",
+		Networks:     []string{"Net1"},
+	}, nil)
+
+	vmConverter := converter.New(
+		converter.NewEmptyMappedNetwork().Add("Net1", "Net2"),
+		converter.NewEmptyMappedDatastore(),
+		converter.NewEmptyMappedCompute().Add(converter.AZ{
+			Datacenter:   "DC1",
+			Cluster:      "Cluster1",
+			ResourcePool: "RP1",
+			Name:         "az1",
+		}, converter.AZ{
+			Datacenter:   "DC2",
+			Cluster:      "Cluster2",
+			ResourcePool: "RP2",
+			Name:         "az1",
+		}))
+
+	healthClient := &migratefakes.FakeInstanceHealthClient{}
+	healthClient.DeploymentHealthReturns(map[string]bosh.InstanceHealth{
+		"vm1": {Instance: "router/guid", JobState: "failing"},
+	}, nil)
+	verifier := migrate.NewHealthVerifier(healthClient).WithTimeout(time.Millisecond).WithPollInterval(time.Millisecond)
+
+	// fails the VM
+	out := log.NewBufferedStdout()
+	vmMigrator := migrate.NewVMMigrator(&vcenter.Pool{}, vmConverter, &migratefakes.FakeVMRelocator{}, out).
+		WithHealthVerifier(verifier)
+	err := vmMigrator.MigrateVMToTarget(context.Background(), sourceClient, vmToMigrate)
+	require.ErrorContains(t, err, "router/guid is failing")
+
+	// flags the VM
+	verifier.WithFlagOnly(true)
+	err = vmMigrator.MigrateVMToTarget(context.Background(), sourceClient, vmToMigrate)
+	require.NoError(t, err)
+	report := log.NewBufferedStdout()
+	verifier.Report(report)
+	require.Contains(t, report.String(), "vm1 - BOSH did not report a healthy instance")
+
+	// healthy
+	healthClient.DeploymentHealthReturns(map[string]bosh.InstanceHealth{
+		"vm1": {Instance: "router/guid", JobState: "running"},
+	}, nil)
+	out = log.NewBufferedStdout()
+	vmMigrator = migrate.NewVMMigrator(&vcenter.Pool{}, vmConverter, &migratefakes.FakeVMRelocator{}, out).
+		WithHealthVerifier(verifier)
+	err = vmMigrator.MigrateVMToTarget(context.Background(), sourceClient, vmToMigrate)
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "waiting for BOSH to report healthy")
+}
@@ -10,12 +10,18 @@ import (
 	"fmt"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/bosh"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/proxy"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
 	"sort"
 )
 
 //counterfeiter:generate . BoshClient
 type BoshClient interface {
 	VMsAndStemcells(context.Context) ([]bosh.VM, error)
+	OrphanedDisks(context.Context) ([]bosh.Disk, error)
+	UnhealthyInstances(ctx context.Context, deployment string) ([]string, error)
+	DeploymentHealth(ctx context.Context, deployment string) (map[string]bosh.InstanceHealth, error)
 }
 
 // NullBoshClient is a null object pattern when no bosh client is specified in the config
@@ -26,54 +32,177 @@ func (c NullBoshClient) VMsAndStemcells(context.Context) ([]bosh.VM, error) {
 	return []bosh.VM{}, nil
 }
 
+// OrphanedDisks returns an empty list
+func (c NullBoshClient) OrphanedDisks(context.Context) ([]bosh.Disk, error) {
+	return []bosh.Disk{}, nil
+}
+
+// UnhealthyInstances returns an empty list
+func (c NullBoshClient) UnhealthyInstances(context.Context, string) ([]string, error) {
+	return []string{}, nil
+}
+
+// DeploymentHealth returns an error as there are no BOSH deployments
+func (c NullBoshClient) DeploymentHealth(_ context.Context, deployment string) (map[string]bosh.InstanceHealth, error) {
+	return nil, fmt.Errorf("could not find deployment %s without a bosh config", deployment)
+}
+
 type VM struct {
 	Name string
 	AZ   string
 
+	// the BOSH deployment and instance group, empty for stemcells and additional VMs
+	Deployment    string
+	InstanceGroup string
+
+	// true for VMs listed in the config additional_vms section
+	Additional bool
+
 	// list of clusters within the source AZ that may contain the VM
 	Clusters []string
 }
 
+// TaskName identifies the VM's migration in the output, see vcenter.TaskName
+func (v VM) TaskName() string {
+	return vcenter.TaskName(v.Name, v.AZ, v.Deployment)
+}
+
+// Stemcell returns true if the VM is a BOSH stemcell
+func (v VM) Stemcell() bool {
+	return !v.Additional && v.Deployment == ""
+}
+
 type VMSource struct {
 	BoshClient BoshClient
 
 	additionalVMs    []VM
 	srcAZsToClusters map[string][]string
+	waves            []waveSelector
 }
 
-func NewVMSourceFromConfig(c config.Config) *VMSource {
+// NewVMSourceFromConfig creates a VMSource from the config, any BOSH client connects using the dialer
+// A nil dialer connects directly
+func NewVMSourceFromConfig(c config.Config, dialer *proxy.Dialer) *VMSource {
 	azToClusters := configToSourceClustersByAZ(c)
 	additionalVMs := configToAdditionalVMs(c, azToClusters)
-	boshClient := configToBoshClient(c)
+	boshClient := configToBoshClient(c, dialer)
 	return &VMSource{
 		BoshClient:       boshClient,
 		additionalVMs:    additionalVMs,
 		srcAZsToClusters: azToClusters,
+		waves:            configToWaves(c),
 	}
 }
 
-// VMsToMigrate returns the list of all BOSH and additional VMs to migrate
+// VMsToMigrate returns the list of all BOSH and additional VMs to migrate in wave order
 func (s *VMSource) VMsToMigrate(ctx context.Context) ([]VM, error) {
+	waves, err := s.Waves(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var vms []VM
+	for _, w := range waves {
+		vms = append(vms, w.VMs...)
+	}
+	return vms, nil
+}
+
+// Waves returns all BOSH and additional VMs to migrate grouped into waves, each wave's VMs are interleaved by AZ.
+// Waves without any VMs are skipped.
+func (s *VMSource) Waves(ctx context.Context) ([]Wave, error) {
+	vms, err := s.allVMs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.groupIntoWaves(vms), nil
+}
+
+// UnhealthyInstances returns the instances of the wave's BOSH deployments that aren't running
+func (s *VMSource) UnhealthyInstances(ctx context.Context, w Wave) ([]string, error) {
+	var result []string
+	for _, d := range w.Deployments() {
+		instances, err := s.BoshClient.UnhealthyInstances(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range instances {
+			result = append(result, d+"/"+i)
+		}
+	}
+	return result, nil
+}
+
+func (s *VMSource) allVMs(ctx context.Context) ([]VM, error) {
 	boshVMs, err := s.BoshClient.VMsAndStemcells(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	var vms []VM
+	searched := map[string]map[string]bool{}
 	for _, bvm := range boshVMs {
 		clusters := s.srcAZsToClusters[bvm.AZ]
 		if len(clusters) == 0 {
 			return nil, fmt.Errorf("found BOSH VM '%s' with AZ '%s' but no source clusters in the config for that AZ",
 				bvm.Name, bvm.AZ)
 		}
+
+		// stemcells are listed once for every AZ served by their CPI, so only search clusters not already
+		// searched via another AZ to avoid concurrently migrating the same stemcell twice
+		clusters = unsearchedClusters(searched, bvm.Name, clusters)
+		if len(clusters) == 0 {
+			continue
+		}
+
 		vms = append(vms, VM{
-			Name:     bvm.Name,
-			AZ:       bvm.AZ,
-			Clusters: clusters,
+			Name:          bvm.Name,
+			AZ:            bvm.AZ,
+			Deployment:    bvm.Deployment,
+			InstanceGroup: bvm.InstanceGroup,
+			Clusters:      clusters,
 		})
 	}
 	vms = append(vms, s.additionalVMs...)
-	return s.interleaveVMsByAZ(vms), nil
+	return vms, nil
+}
+
+// DisksToMigrate returns the list of all BOSH orphaned disks to migrate, disks in AZs missing from the config are
+// marked unconfigured so they're skipped
+func (s *VMSource) DisksToMigrate(ctx context.Context) ([]Disk, error) {
+	boshDisks, err := s.BoshClient.OrphanedDisks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var disks []Disk
+	for _, bd := range boshDisks {
+		unconfigured := len(s.srcAZsToClusters[bd.AZ]) == 0
+		if unconfigured {
+			log.FromContext(ctx).Warnf("Skipping BOSH orphaned disk '%s' with AZ '%s', there are no source "+
+				"clusters in the config for that AZ", bd.CID, bd.AZ)
+		}
+		disks = append(disks, Disk{
+			CID:          bd.CID,
+			AZ:           bd.AZ,
+			Unconfigured: unconfigured,
+		})
+	}
+	return disks, nil
+}
+
+// unsearchedClusters returns the clusters not yet searched for the named VM and records them as searched
+func unsearchedClusters(searched map[string]map[string]bool, name string, clusters []string) []string {
+	if searched[name] == nil {
+		searched[name] = map[string]bool{}
+	}
+	var result []string
+	for _, cl := range clusters {
+		if !searched[name][cl] {
+			searched[name][cl] = true
+			result = append(result, cl)
+		}
+	}
+	return result
 }
 
 func (s *VMSource) interleaveVMsByAZ(vms []VM) []VM {
@@ -115,9 +244,10 @@ func configToAdditionalVMs(c config.Config, srcAZToClusters map[string][]string)
 	for az, vms := range c.AdditionalVMs {
 		for _, v := range vms {
 			additionalVMs = append(additionalVMs, VM{
-				Name:     v,
-				AZ:       az,
-				Clusters: srcAZToClusters[az],
+				Name:       v,
+				AZ:         az,
+				Additional: true,
+				Clusters:   srcAZToClusters[az],
 			})
 		}
 	}
@@ -136,10 +266,22 @@ func configToSourceClustersByAZ(c config.Config) map[string][]string {
 	return azToClusters
 }
 
-func configToBoshClient(c config.Config) BoshClient {
+func configToBoshClient(c config.Config, dialer *proxy.Dialer) BoshClient {
 	// if there's a configured optional BOSH config section then create a client
 	if c.Bosh != nil {
-		return bosh.New(c.Bosh.Host, c.Bosh.ClientID, c.Bosh.ClientSecret)
+		return newBoshClient(c.Bosh, dialer)
 	}
 	return NullBoshClient{}
 }
+
+func newBoshClient(b *config.Bosh, dialer *proxy.Dialer) *bosh.Client {
+	return bosh.New(b.Host, b.ClientID, b.ClientSecret).
+		WithUser(b.Username, b.Password).
+		WithUAAURL(b.UAAURL).
+		WithURL(b.URL).
+		WithPort(b.Port).
+		WithCACert(b.CACert).
+		WithCACertFile(b.CACertFile).
+		WithInsecure(b.Insecure).
+		WithProxy(dialer)
+}
@@ -136,7 +136,7 @@ func baseSourceConfig() config.Config {
 
 func TestNewVMSourceFromConfigBoshClient(t *testing.T) {
 	c := baseSourceConfig()
-	src := migrate.NewVMSourceFromConfig(c)
+	src := migrate.NewVMSourceFromConfig(c, nil)
 	require.IsType(t, migrate.NullBoshClient{}, src.BoshClient)
 
 	c.Bosh = &config.Bosh{
@@ -144,7 +144,7 @@ func TestNewVMSourceFromConfigBoshClient(t *testing.T) {
 		ClientID:     "admin",
 		ClientSecret: "secret",
 	}
-	src = migrate.NewVMSourceFromConfig(c)
+	src = migrate.NewVMSourceFromConfig(c, nil)
 	b := src.BoshClient.(*bosh.Client)
 	require.Equal(t, "192.168.1.2", b.Environment)
 	require.Equal(t, "admin", b.ClientID)
@@ -153,7 +153,7 @@ func TestNewVMSourceFromConfigBoshClient(t *testing.T) {
 
 func TestVMsToMigrateWithMultipleClusters(t *testing.T) {
 	c := baseSourceConfig()
-	src := migrate.NewVMSourceFromConfig(c)
+	src := migrate.NewVMSourceFromConfig(c, nil)
 	vms, err := src.VMsToMigrate(context.Background())
 	require.NoError(t, err)
 	require.Len(t, vms, 1)
@@ -168,7 +168,7 @@ func TestVMsToMigrateInterleavesVMsByAZ(t *testing.T) {
 	c := baseSourceConfig()
 	c.AdditionalVMs = nil
 
-	src := migrate.NewVMSourceFromConfig(c)
+	src := migrate.NewVMSourceFromConfig(c, nil)
 
 	// stub out a fake bosh client result
 	b := &migratefakes.FakeBoshClient{}
@@ -269,9 +269,41 @@ func TestVMsToMigrateInterleavesVMsByAZ(t *testing.T) {
 	require.Equal(t, "vm4az3", vm.Name)
 }
 
+func TestVMsToMigrateOnlySearchesStemcellClustersOnce(t *testing.T) {
+	c := baseSourceConfig()
+	c.AdditionalVMs = nil
+	// az3 shares az2's cluster with a different resource pool
+	c.Compute.Source[2].Clusters = append(c.Compute.Source[2].Clusters, c.Compute.Source[1].Clusters...)
+	c.Compute.Source = append(c.Compute.Source, config.ComputeAZ{
+		Name:     "az4",
+		VCenter:  c.Compute.Source[1].VCenter,
+		Clusters: c.Compute.Source[1].Clusters,
+	})
+
+	src := migrate.NewVMSourceFromConfig(c, nil)
+
+	// the stemcell's CPI serves all AZs
+	b := &migratefakes.FakeBoshClient{}
+	b.VMsAndStemcellsReturns([]bosh.VM{
+		{Name: "sc-guid", AZ: "az1"},
+		{Name: "sc-guid", AZ: "az2"},
+		{Name: "sc-guid", AZ: "az3"},
+		{Name: "sc-guid", AZ: "az4"},
+	}, nil)
+	src.BoshClient = b
+
+	vms, err := src.VMsToMigrate(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []migrate.VM{
+		{Name: "sc-guid", AZ: "az1", Clusters: []string{"Cluster1", "Cluster2"}},
+		{Name: "sc-guid", AZ: "az2", Clusters: []string{"Cluster3"}},
+		{Name: "sc-guid", AZ: "az3", Clusters: []string{"Cluster4"}},
+	}, vms)
+}
+
 func TestConfigSourceBoshError(t *testing.T) {
 	c := baseSourceConfig()
-	src := migrate.NewVMSourceFromConfig(c)
+	src := migrate.NewVMSourceFromConfig(c, nil)
 
 	// stub out a fake bosh client error
 	b := &migratefakes.FakeBoshClient{}
@@ -281,3 +313,140 @@ func TestConfigSourceBoshError(t *testing.T) {
 	_, err := src.VMsToMigrate(context.Background())
 	require.Error(t, err)
 }
+
+func TestDisksToMigrate(t *testing.T) {
+	c := baseSourceConfig()
+	src := migrate.NewVMSourceFromConfig(c, nil)
+
+	b := &migratefakes.FakeBoshClient{}
+	b.OrphanedDisksReturns([]bosh.Disk{
+		{CID: "disk-guid1", AZ: "az1"},
+		{CID: "disk-guid2.eyJ0YXJnZXRfZGF0YXN0b3JlX3BhdHRlcm4iOiJEUzEifQ", AZ: "az2"},
+	}, nil)
+	src.BoshClient = b
+
+	disks, err := src.DisksToMigrate(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []migrate.Disk{
+		{CID: "disk-guid1", AZ: "az1"},
+		{CID: "disk-guid2.eyJ0YXJnZXRfZGF0YXN0b3JlX3BhdHRlcm4iOiJEUzEifQ", AZ: "az2"},
+	}, disks)
+
+	b.OrphanedDisksReturns([]bosh.Disk{{CID: "disk-guid3", AZ: "az9"}, {CID: "disk-guid4", AZ: ""}}, nil)
+	disks, err = src.DisksToMigrate(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []migrate.Disk{
+		{CID: "disk-guid3", AZ: "az9", Unconfigured: true},
+		{CID: "disk-guid4", AZ: "", Unconfigured: true},
+	}, disks)
+}
+
+func waveNames(waves []migrate.Wave) []string {
+	var names []string
+	for _, w := range waves {
+		names = append(names, w.Name)
+	}
+	return names
+}
+
+func waveVMNames(w migrate.Wave) []string {
+	var names []string
+	for _, v := range w.VMs {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+func TestWavesDefaultOrder(t *testing.T) {
+	c := baseSourceConfig()
+	c.AdditionalVMs = map[string][]string{
+		"az1": {"ops-manager-2.10.27", "vm-director"},
+	}
+	src := migrate.NewVMSourceFromConfig(c, nil)
+
+	b := &migratefakes.FakeBoshClient{}
+	b.VMsAndStemcellsReturns([]bosh.VM{
+		{Name: "sc-guid", AZ: "az1"},
+		{Name: "router", AZ: "az1", Deployment: "cf-guid", InstanceGroup: "router"},
+		{Name: "mysql", AZ: "az2", Deployment: "pivotal-mysql-guid", InstanceGroup: "mysql"},
+	}, nil)
+	src.BoshClient = b
+
+	waves, err := src.Waves(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"stemcells", "services", "cf", "director", "opsman"}, waveNames(waves))
+	require.Equal(t, []string{"sc-guid"}, waveVMNames(waves[0]))
+	require.Equal(t, []string{"mysql"}, waveVMNames(waves[1]))
+	require.Equal(t, []string{"router"}, waveVMNames(waves[2]))
+	require.Equal(t, []string{"vm-director"}, waveVMNames(waves[3]))
+	require.Equal(t, []string{"ops-manager-2.10.27"}, waveVMNames(waves[4]))
+	for _, w := range waves {
+		require.False(t, w.HealthCheck)
+	}
+
+	vms, err := src.VMsToMigrate(context.Background())
+	require.NoError(t, err)
+	require.Len(t, vms, 5)
+	require.Equal(t, "ops-manager-2.10.27", vms[4].Name)
+}
+
+func TestWavesFromConfig(t *testing.T) {
+	c := baseSourceConfig()
+	c.Waves = []config.Wave{
+		{Name: "routers", InstanceGroups: []string{"router"}, HealthCheck: true},
+		{Name: "cf", Deployments: []string{"cf-*"}, Stemcells: true},
+		{Name: "additional", AdditionalVMs: true, VMs: []string{"vm-*az3"}},
+	}
+	src := migrate.NewVMSourceFromConfig(c, nil)
+
+	b := &migratefakes.FakeBoshClient{}
+	b.VMsAndStemcellsReturns([]bosh.VM{
+		{Name: "sc-guid", AZ: "az1"},
+		{Name: "vm-1az1", AZ: "az1", Deployment: "cf-guid", InstanceGroup: "router"},
+		{Name: "vm-2az2", AZ: "az2", Deployment: "cf-guid", InstanceGroup: "diego_cell"},
+		{Name: "vm-3az3", AZ: "az3", Deployment: "pivotal-mysql-guid", InstanceGroup: "mysql"},
+		{Name: "vm-4az1", AZ: "az1", Deployment: "pivotal-mysql-guid", InstanceGroup: "mysql"},
+	}, nil)
+	src.BoshClient = b
+
+	waves, err := src.Waves(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"routers", "cf", "additional", "remaining"}, waveNames(waves))
+	require.True(t, waves[0].HealthCheck)
+	require.False(t, waves[1].HealthCheck)
+	require.Equal(t, []string{"vm-1az1"}, waveVMNames(waves[0]))
+	require.Equal(t, []string{"sc-guid", "vm-2az2"}, waveVMNames(waves[1]))
+	require.Equal(t, []string{"additional-vm1", "vm-3az3"}, waveVMNames(waves[2]))
+	require.Equal(t, []string{"vm-4az1"}, waveVMNames(waves[3]))
+	require.Equal(t, []string{"cf-guid"}, waves[1].Deployments())
+	require.Equal(t, []string{"pivotal-mysql-guid"}, waves[2].Deployments())
+}
+
+func TestWaveUnhealthyInstances(t *testing.T) {
+	src := migrate.NewVMSourceFromConfig(baseSourceConfig(), nil)
+
+	b := &migratefakes.FakeBoshClient{}
+	b.UnhealthyInstancesReturnsOnCall(0, []string{"diego_cell/guid2"}, nil)
+	b.UnhealthyInstancesReturnsOnCall(1, nil, nil)
+	src.BoshClient = b
+
+	w := migrate.Wave{
+		Name: "cf",
+		VMs: []migrate.VM{
+			{Name: "vm-1", Deployment: "cf-guid"},
+			{Name: "vm-2", Deployment: "isolation-segment-guid"},
+			{Name: "vm-3", Deployment: "cf-guid"},
+			{Name: "sc-guid"},
+		},
+	}
+	unhealthy, err := src.UnhealthyInstances(context.Background(), w)
+	require.NoError(t, err)
+	require.Equal(t, []string{"cf-guid/diego_cell/guid2"}, unhealthy)
+	require.Equal(t, 2, b.UnhealthyInstancesCallCount())
+	_, d := b.UnhealthyInstancesArgsForCall(1)
+	require.Equal(t, "isolation-segment-guid", d)
+
+	b.UnhealthyInstancesReturnsOnCall(2, nil, errors.New("could not connect to BOSH"))
+	_, err = src.UnhealthyInstances(context.Background(), w)
+	require.EqualError(t, err, "could not connect to BOSH")
+}
@@ -0,0 +1,169 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package migrate
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+)
+
+// remainingWaveName is the implicit last wave holding any VMs not selected by a configured wave
+const remainingWaveName = "remaining"
+
+// opsManagerName matches the usual Ops Manager VM names like ops-manager-2.10.27 or opsman
+var opsManagerName = regexp.MustCompile(`(?i)ops[-_ ]?man`)
+
+// Wave is a group of VMs migrated together before the next wave starts
+type Wave struct {
+	Name string
+	VMs  []VM
+
+	// HealthCheck stops the migration after the wave if any of its VMs failed or its deployments are unhealthy
+	HealthCheck bool
+
+	// Canaries is the number of VMs per AZ migrated before pausing for confirmation, zero uses the global setting
+	Canaries int
+}
+
+// Deployments returns the sorted unique BOSH deployments of the wave's VMs
+func (w Wave) Deployments() []string {
+	var deployments []string
+	for _, v := range w.VMs {
+		if v.Deployment != "" {
+			deployments = appendUnique(deployments, v.Deployment)
+		}
+	}
+	sort.Strings(deployments)
+	return deployments
+}
+
+type waveSelector struct {
+	name        string
+	healthCheck bool
+	canaries    int
+	selects     func(VM) bool
+}
+
+// defaultWaves moves the stemcells first so they're in place for any VM recreated by BOSH, then service
+// deployments, then cf, then the BOSH director and finally Ops Manager
+func defaultWaves() []waveSelector {
+	return []waveSelector{
+		{
+			name:    "stemcells",
+			selects: VM.Stemcell,
+		},
+		{
+			name: "services",
+			selects: func(v VM) bool {
+				return v.Deployment != "" && !isCFDeployment(v.Deployment)
+			},
+		},
+		{
+			name: "cf",
+			selects: func(v VM) bool {
+				return isCFDeployment(v.Deployment)
+			},
+		},
+		{
+			name: "director",
+			selects: func(v VM) bool {
+				return v.Additional && !opsManagerName.MatchString(v.Name)
+			},
+		},
+		{
+			name: "opsman",
+			selects: func(v VM) bool {
+				return v.Additional
+			},
+		},
+	}
+}
+
+func isCFDeployment(deployment string) bool {
+	return deployment == "cf" || strings.HasPrefix(deployment, "cf-")
+}
+
+func configToWaves(c config.Config) []waveSelector {
+	if len(c.Waves) == 0 {
+		return defaultWaves()
+	}
+	var waves []waveSelector
+	for _, w := range c.Waves {
+		w := w // closure
+		waves = append(waves, waveSelector{
+			name:        w.Name,
+			healthCheck: w.HealthCheck,
+			canaries:    w.Canaries,
+			selects: func(v VM) bool {
+				return configWaveSelects(w, v)
+			},
+		})
+	}
+	return waves
+}
+
+// configWaveSelects returns true if any of the wave's selectors match the VM
+func configWaveSelects(w config.Wave, v VM) bool {
+	if w.Stemcells && v.Stemcell() {
+		return true
+	}
+	if w.AdditionalVMs && v.Additional {
+		return true
+	}
+	if v.Deployment != "" && anyGlobMatches(w.Deployments, v.Deployment) {
+		return true
+	}
+	if v.InstanceGroup != "" && anyGlobMatches(w.InstanceGroups, v.InstanceGroup) {
+		return true
+	}
+	return anyGlobMatches(w.VMs, v.Name)
+}
+
+func anyGlobMatches(patterns []string, name string) bool {
+	for _, p := range patterns {
+		// patterns are validated with the config
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// groupIntoWaves puts each VM into the first wave that selects it, any VMs not selected by a wave are migrated last
+func (s *VMSource) groupIntoWaves(vms []VM) []Wave {
+	grouped := make([][]VM, len(s.waves)+1)
+	for _, v := range vms {
+		i := 0
+		for ; i < len(s.waves); i++ {
+			if s.waves[i].selects(v) {
+				break
+			}
+		}
+		grouped[i] = append(grouped[i], v)
+	}
+
+	var waves []Wave
+	for i, g := range grouped {
+		if len(g) == 0 {
+			continue
+		}
+		w := Wave{
+			Name: remainingWaveName,
+			VMs:  s.interleaveVMsByAZ(g),
+		}
+		if i < len(s.waves) {
+			w.Name = s.waves[i].name
+			w.HealthCheck = s.waves[i].healthCheck
+			w.Canaries = s.waves[i].canaries
+		}
+		waves = append(waves, w)
+	}
+	return waves
+}
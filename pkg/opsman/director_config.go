@@ -0,0 +1,194 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package opsman
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/inventory"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/yamlnode"
+	"gopkg.in/yaml.v3"
+)
+
+const tkgiVSpherePrefix = ".properties.cloud_provider.vsphere."
+
+// Converter rewrites Operations Manager director and TKGI product configs, as exported by
+// `om staged-director-config` and `om staged-config`, to use the migrated vSphere objects.
+// The same migrate.yml mappings that drive the VM migration are used so the two never disagree.
+type Converter struct {
+	config config.Config
+}
+
+// NewConverter creates a new Converter using the migrate config's network, datastore and compute mappings
+func NewConverter(c config.Config) *Converter {
+	return &Converter{
+		config: c,
+	}
+}
+
+// Convert rewrites the availability zones, IaaS configurations, networks and any TKGI vCenter properties
+// found in the config, all other content is preserved as-is
+func (c *Converter) Convert(in []byte) ([]byte, error) {
+	doc, root, err := yamlnode.Parse(in)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse Operations Manager config: %w", err)
+	}
+
+	if azs := yamlnode.MapValue(root, "az-configuration"); azs != nil {
+		if err := c.convertAZs(azs); err != nil {
+			return nil, err
+		}
+	}
+	if iaasConfigs := yamlnode.MapValue(root, "iaas-configurations"); iaasConfigs != nil {
+		for _, iaas := range iaasConfigs.Content {
+			if err := c.convertIaaSConfig(iaas); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// older Operations Manager versions only support a single IaaS configuration
+	if iaas := yamlnode.MapValue(yamlnode.MapValue(root, "properties-configuration"), "iaas_configuration"); iaas != nil {
+		if err := c.convertIaaSConfig(iaas); err != nil {
+			return nil, err
+		}
+	}
+	if networks := yamlnode.MapValue(yamlnode.MapValue(root, "networks-configuration"), "networks"); networks != nil {
+		if err := c.convertNetworks(networks); err != nil {
+			return nil, err
+		}
+	}
+	if props := yamlnode.MapValue(root, "product-properties"); props != nil {
+		if err := c.convertTKGIProperties(props); err != nil {
+			return nil, err
+		}
+	}
+
+	return yamlnode.Encode(doc)
+}
+
+func (c *Converter) convertAZs(azs *yaml.Node) error {
+	for _, az := range azs.Content {
+		name := yamlnode.ScalarValue(az, "name")
+		source := c.config.Compute.SourceByAZ(name)
+		target := c.config.Compute.TargetByAZ(name)
+		if source == nil || target == nil {
+			return fmt.Errorf("could not find director AZ %s in the compute section", name)
+		}
+
+		// older Operations Manager versions only support a single cluster per AZ
+		if yamlnode.MapValue(az, "cluster") != nil {
+			if len(target.Clusters) != 1 {
+				return fmt.Errorf("expected target AZ %s to have 1 cluster but found %d", name, len(target.Clusters))
+			}
+			yamlnode.SetScalar(az, "cluster", inventory.HostFolderPath(target.Clusters[0].Name))
+			yamlnode.SetOptionalScalar(az, "resource_pool", target.Clusters[0].ResourcePool)
+			continue
+		}
+
+		clusters := yamlnode.MapValue(az, "clusters")
+		if clusters == nil {
+			continue
+		}
+		var converted []*yaml.Node
+		for i, tcl := range target.Clusters {
+			// keep any other cluster settings like DRS rules and host groups
+			cl := &yaml.Node{Kind: yaml.MappingNode}
+			if i < len(clusters.Content) {
+				cl = clusters.Content[i]
+			}
+			yamlnode.SetScalar(cl, "cluster", inventory.HostFolderPath(tcl.Name))
+			yamlnode.SetOptionalScalar(cl, "resource_pool", tcl.ResourcePool)
+			converted = append(converted, cl)
+		}
+		clusters.Content = converted
+	}
+	return nil
+}
+
+func (c *Converter) convertIaaSConfig(iaas *yaml.Node) error {
+	host := yamlnode.ScalarValue(iaas, "vcenter_host")
+	dc := yamlnode.ScalarValue(iaas, "datacenter")
+	target, err := c.config.Compute.TargetVCenter(host, dc)
+	if err != nil {
+		return err
+	}
+
+	yamlnode.SetScalar(iaas, "vcenter_host", target.Host)
+	yamlnode.SetScalar(iaas, "datacenter", target.Datacenter)
+	yamlnode.SetScalarUnlessVariable(iaas, "vcenter_username", target.Username)
+	yamlnode.SetScalarUnlessVariable(iaas, "vcenter_password", target.Password)
+
+	for _, key := range []string{"persistent_datastore_names", "ephemeral_datastore_names"} {
+		names := yamlnode.ScalarValue(iaas, key)
+		if names == "" {
+			continue
+		}
+		var converted []string
+		for _, ds := range strings.Split(names, ",") {
+			tds, err := c.config.TargetDatastore(strings.TrimSpace(ds))
+			if err != nil {
+				return err
+			}
+			converted = append(converted, tds)
+		}
+		yamlnode.SetScalar(iaas, key, strings.Join(converted, ","))
+	}
+	return nil
+}
+
+func (c *Converter) convertNetworks(networks *yaml.Node) error {
+	for _, network := range networks.Content {
+		subnets := yamlnode.MapValue(network, "subnets")
+		if subnets == nil {
+			continue
+		}
+		for _, subnet := range subnets.Content {
+			id := yamlnode.ScalarValue(subnet, "iaas_identifier")
+			if id == "" {
+				continue
+			}
+			tid, err := c.config.TargetNetwork(id)
+			if err != nil {
+				return err
+			}
+			yamlnode.SetScalar(subnet, "iaas_identifier", tid)
+		}
+	}
+	return nil
+}
+
+func (c *Converter) convertTKGIProperties(props *yaml.Node) error {
+	hostProp := yamlnode.MapValue(props, tkgiVSpherePrefix+"vcenter_ip")
+	if hostProp == nil {
+		return nil
+	}
+	dcProp := yamlnode.MapValue(props, tkgiVSpherePrefix+"vcenter_dc")
+	target, err := c.config.Compute.TargetVCenter(
+		yamlnode.ScalarValue(hostProp, "value"), yamlnode.ScalarValue(dcProp, "value"))
+	if err != nil {
+		return err
+	}
+
+	yamlnode.SetScalar(hostProp, "value", target.Host)
+	if dcProp != nil {
+		yamlnode.SetScalar(dcProp, "value", target.Datacenter)
+	}
+	if dsProp := yamlnode.MapValue(props, tkgiVSpherePrefix+"vcenter_ds"); dsProp != nil {
+		tds, err := c.config.TargetDatastore(yamlnode.ScalarValue(dsProp, "value"))
+		if err != nil {
+			return err
+		}
+		yamlnode.SetScalar(dsProp, "value", tds)
+	}
+	creds := yamlnode.MapValue(yamlnode.MapValue(props, tkgiVSpherePrefix+"vcenter_master_creds"), "value")
+	if creds != nil {
+		yamlnode.SetScalarUnlessVariable(creds, "identity", target.Username)
+		yamlnode.SetScalarUnlessVariable(creds, "password", target.Password)
+	}
+	return nil
+}
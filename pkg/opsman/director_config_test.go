@@ -0,0 +1,195 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package opsman_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/config"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/opsman"
+)
+
+func migrateConfig() config.Config {
+	vc1 := &config.VCenter{
+		Host:       "vc01.example.com",
+		Username:   "administrator@vsphere.local",
+		Password:   "secret",
+		Datacenter: "Datacenter1",
+	}
+	vc2 := &config.VCenter{
+		Host:       "vc02.example.com",
+		Username:   "administrator2@vsphere.local",
+		Password:   "secret2",
+		Datacenter: "Datacenter2",
+	}
+	return config.Config{
+		NetworkMap: map[string]string{
+			"PAS-Infrastructure": "TAS-Infrastructure",
+			"PAS-Deployment-01":  "TAS-Deployment",
+		},
+		DatastoreMap: map[string]string{
+			"irvine-ds1": "ssd_ds1",
+			"irvine-ds2": "ssd_ds2",
+		},
+		Compute: config.Compute{
+			Source: []config.ComputeAZ{
+				{
+					Name:     "az1",
+					VCenter:  vc1,
+					Clusters: []config.ComputeCluster{{Name: "cf1", ResourcePool: "pas-az1"}},
+				},
+				{
+					Name:     "az2",
+					VCenter:  vc1,
+					Clusters: []config.ComputeCluster{{Name: "cf2", ResourcePool: "pas-az2"}},
+				},
+			},
+			Target: []config.ComputeAZ{
+				{
+					Name:     "az1",
+					VCenter:  vc2,
+					Clusters: []config.ComputeCluster{{Name: "tanzu-1", ResourcePool: "tas-az1"}},
+				},
+				{
+					Name:     "az2",
+					VCenter:  vc2,
+					Clusters: []config.ComputeCluster{{Name: "tanzu-2"}, {Name: "tanzu-3"}},
+				},
+			},
+		},
+	}
+}
+
+func requireConverted(t *testing.T, c config.Config, inFile, expectedFile string) {
+	in, err := os.ReadFile(inFile)
+	require.NoError(t, err)
+	expected, err := os.ReadFile(expectedFile)
+	require.NoError(t, err)
+
+	out, err := opsman.NewConverter(c).Convert(in)
+	require.NoError(t, err)
+	require.YAMLEq(t, string(expected), string(out))
+}
+
+func TestConvertDirectorConfig(t *testing.T) {
+	requireConverted(t, migrateConfig(), "fixtures/director-config.yml", "fixtures/director-config-migrated.yml")
+}
+
+func TestConvertDirectorConfigPreservesKeyOrder(t *testing.T) {
+	in, err := os.ReadFile("fixtures/director-config.yml")
+	require.NoError(t, err)
+	out, err := opsman.NewConverter(migrateConfig()).Convert(in)
+	require.NoError(t, err)
+	require.Regexp(t, `(?s)^az-configuration:.*iaas-configurations:.*network-assignment:.*networks-configuration:`, string(out))
+}
+
+func TestConvertDirectorConfigReversed(t *testing.T) {
+	c := migrateConfig()
+	c.Compute.Target[1].Clusters = []config.ComputeCluster{{Name: "tanzu-2", ResourcePool: "tas-az2"}}
+	in, err := opsman.NewConverter(c).Convert(mustRead(t, "fixtures/director-config.yml"))
+	require.NoError(t, err)
+
+	out, err := opsman.NewConverter(c.Reversed()).Convert(in)
+	require.NoError(t, err)
+
+	// datastore names are re-joined without whitespace
+	expected := strings.Replace(string(mustRead(t, "fixtures/director-config.yml")), "irvine-ds1, irvine-ds2", "irvine-ds1,irvine-ds2", 1)
+	require.YAMLEq(t, expected, string(out))
+}
+
+func TestConvertTKGIConfig(t *testing.T) {
+	requireConverted(t, migrateConfig(), "fixtures/tkgi-config.yml", "fixtures/tkgi-config-migrated.yml")
+}
+
+func TestConvertSingleClusterAZ(t *testing.T) {
+	in := `
+az-configuration:
+- name: az1
+  cluster: cf1
+  resource_pool: pas-az1
+- name: az2
+  cluster: cf2
+  resource_pool: pas-az2
+`
+	_, err := opsman.NewConverter(migrateConfig()).Convert([]byte(in))
+	require.EqualError(t, err, "expected target AZ az2 to have 1 cluster but found 2")
+
+	c := migrateConfig()
+	c.Compute.Target[1].Clusters = []config.ComputeCluster{{Name: "tanzu-2"}}
+	out, err := opsman.NewConverter(c).Convert([]byte(in))
+	require.NoError(t, err)
+	require.YAMLEq(t, `
+az-configuration:
+- name: az1
+  cluster: tanzu-1
+  resource_pool: tas-az1
+- name: az2
+  cluster: tanzu-2
+`, string(out))
+}
+
+func TestConvertErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		in          string
+		expectedErr string
+	}{
+		{
+			name:        "not a map",
+			in:          "- foo",
+			expectedErr: "could not parse Operations Manager config: expected a yaml map",
+		},
+		{
+			name:        "unknown AZ",
+			in:          "az-configuration:\n- name: az9\n  clusters: []\n",
+			expectedErr: "could not find director AZ az9 in the compute section",
+		},
+		{
+			name:        "unknown vcenter",
+			in:          "iaas-configurations:\n- vcenter_host: vc09.example.com\n  datacenter: Datacenter1\n",
+			expectedErr: "could not find vcenter vc09.example.com datacenter Datacenter1 in the compute source section",
+		},
+		{
+			name: "unmapped datastore",
+			in: "iaas-configurations:\n- vcenter_host: vc01.example.com\n  datacenter: Datacenter1\n" +
+				"  persistent_datastore_names: irvine-ds3\n",
+			expectedErr: "could not find a target datastore mapping for irvine-ds3",
+		},
+		{
+			name: "unmapped network",
+			in: "networks-configuration:\n  networks:\n  - name: services\n    subnets:\n" +
+				"    - iaas_identifier: PAS-Services\n",
+			expectedErr: "could not find a target network mapping for PAS-Services",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := opsman.NewConverter(migrateConfig()).Convert([]byte(tt.in))
+			require.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestConvertVCenterMappedToMultipleTargets(t *testing.T) {
+	c := migrateConfig()
+	vc3 := *c.Compute.Target[1].VCenter
+	vc3.Host = "vc03.example.com"
+	c.Compute.Target[1].VCenter = &vc3
+
+	in := "iaas-configurations:\n- vcenter_host: vc01.example.com\n  datacenter: Datacenter1\n"
+	_, err := opsman.NewConverter(c).Convert([]byte(in))
+	require.EqualError(t, err,
+		"vcenter vc01.example.com datacenter Datacenter1 is mapped to more than one target vcenter datacenter")
+}
+
+func mustRead(t *testing.T, path string) []byte {
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return b
+}
@@ -0,0 +1,287 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	xproxy "golang.org/x/net/proxy"
+)
+
+const dialTimeout = 30 * time.Second
+
+// Dialer creates network connections either directly or through the configured proxy
+type Dialer struct {
+	proxyURL *url.URL
+	direct   *net.Dialer
+
+	sshMutex  sync.Mutex
+	sshClient *ssh.Client
+}
+
+// NewDialer creates a dialer for the proxy URL. Supported proxy schemes are http, https, socks5, socks5h
+// and ssh+socks5, the latter using the same format as the BOSH CLI's BOSH_ALL_PROXY, for example
+// ssh+socks5://ubuntu@opsman.example.com:22?private-key=/path/to/opsman.pem&known-hosts=/path/to/known_hosts
+// An empty proxy URL creates a dialer which connects directly.
+func NewDialer(proxyURL string) (*Dialer, error) {
+	d := &Dialer{
+		direct: &net.Dialer{
+			Timeout:   dialTimeout,
+			KeepAlive: 30 * time.Second,
+		},
+	}
+	if proxyURL == "" {
+		return d, nil
+	}
+
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("expected proxy URL %s to have a host", u.Redacted())
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	case "ssh+socks5":
+		if u.User == nil || u.User.Username() == "" {
+			return nil, fmt.Errorf("expected ssh+socks5 proxy URL %s to have a user", u.Redacted())
+		}
+		if u.Query().Get("private-key") == "" {
+			return nil, fmt.Errorf("expected ssh+socks5 proxy URL %s to have a private-key query parameter", u.Redacted())
+		}
+		if u.Query().Get("known-hosts") == "" && u.Query().Get("insecure-ignore-host-key") != "true" {
+			return nil, fmt.Errorf("expected ssh+socks5 proxy URL %s to have a known-hosts query parameter, "+
+				"or insecure-ignore-host-key=true to skip host key verification", u.Redacted())
+		}
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %s, expected http, https, socks5, socks5h or ssh+socks5", u.Scheme)
+	}
+
+	d.proxyURL = u
+	return d, nil
+}
+
+// Proxied returns true if connections are made through a proxy
+func (d *Dialer) Proxied() bool {
+	return d.proxyURL != nil
+}
+
+// DialContext connects to the address on the named network via the proxy, if any
+func (d *Dialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if !d.Proxied() {
+		return d.direct.DialContext(ctx, network, addr)
+	}
+
+	var conn net.Conn
+	var err error
+	switch d.proxyURL.Scheme {
+	case "http", "https":
+		conn, err = d.dialHTTPConnect(ctx, addr)
+	case "socks5", "socks5h":
+		conn, err = d.dialSOCKS5(ctx, network, addr)
+	case "ssh+socks5":
+		conn, err = d.dialSSH(ctx, network, addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to %s via proxy %s: %w", addr, d.proxyURL.Redacted(), err)
+	}
+	return conn, nil
+}
+
+// Close closes any long-lived connection to the proxy
+func (d *Dialer) Close() error {
+	d.sshMutex.Lock()
+	defer d.sshMutex.Unlock()
+
+	if d.sshClient == nil {
+		return nil
+	}
+	err := d.sshClient.Close()
+	d.sshClient = nil
+	return err
+}
+
+func (d *Dialer) dialHTTPConnect(ctx context.Context, addr string) (net.Conn, error) {
+	defaultPort := "80"
+	if d.proxyURL.Scheme == "https" {
+		defaultPort = "443"
+	}
+	conn, err := d.direct.DialContext(ctx, "tcp", d.proxyHostPort(defaultPort))
+	if err != nil {
+		return nil, err
+	}
+	if d.proxyURL.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: d.proxyURL.Hostname()})
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if d.proxyURL.User != nil {
+		p, _ := d.proxyURL.User.Password()
+		auth := base64.StdEncoding.EncodeToString([]byte(d.proxyURL.User.Username() + ":" + p))
+		req.Header.Set("Proxy-Authorization", "Basic "+auth)
+	}
+	if err = req.Write(conn); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	// the server doesn't send anything after the CONNECT response until the client does, so the
+	// buffered reader can safely be discarded
+	res, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		_ = conn.Close()
+		return nil, fmt.Errorf("proxy CONNECT failed with %s", res.Status)
+	}
+	return conn, nil
+}
+
+func (d *Dialer) dialSOCKS5(ctx context.Context, network, addr string) (net.Conn, error) {
+	var auth *xproxy.Auth
+	if d.proxyURL.User != nil {
+		p, _ := d.proxyURL.User.Password()
+		auth = &xproxy.Auth{
+			User:     d.proxyURL.User.Username(),
+			Password: p,
+		}
+	}
+
+	dialer, err := xproxy.SOCKS5("tcp", d.proxyHostPort("1080"), auth, d.direct)
+	if err != nil {
+		return nil, err
+	}
+	return dialer.(xproxy.ContextDialer).DialContext(ctx, network, addr)
+}
+
+func (d *Dialer) dialSSH(ctx context.Context, network, addr string) (net.Conn, error) {
+	client, err := d.getOrCreateSSHClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := client.Dial(network, addr)
+	if err != nil {
+		// the jumpbox connection may have dropped during a long migration, so reconnect once
+		log.FromContext(ctx).Debugf("SSH proxy dial to %s failed, reconnecting: %s", addr, err)
+		d.resetSSHClient(client)
+		client, err = d.getOrCreateSSHClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		conn, err = client.Dial(network, addr)
+	}
+	return conn, err
+}
+
+func (d *Dialer) getOrCreateSSHClient(ctx context.Context) (*ssh.Client, error) {
+	d.sshMutex.Lock()
+	defer d.sshMutex.Unlock()
+
+	if d.sshClient != nil {
+		return d.sshClient, nil
+	}
+
+	sshConfig, err := d.sshClientConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hostPort := d.proxyHostPort("22")
+	log.FromContext(ctx).Debugf("Connecting to SSH proxy %s", hostPort)
+	conn, err := d.direct.DialContext(ctx, "tcp", hostPort)
+	if err != nil {
+		return nil, err
+	}
+	c, chans, reqs, err := ssh.NewClientConn(conn, hostPort, sshConfig)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("could not establish SSH connection: %w", err)
+	}
+
+	d.sshClient = ssh.NewClient(c, chans, reqs)
+	return d.sshClient, nil
+}
+
+func (d *Dialer) resetSSHClient(client *ssh.Client) {
+	d.sshMutex.Lock()
+	defer d.sshMutex.Unlock()
+
+	// another caller may have already reconnected
+	if d.sshClient == client {
+		_ = d.sshClient.Close()
+		d.sshClient = nil
+	}
+}
+
+func (d *Dialer) sshClientConfig(ctx context.Context) (*ssh.ClientConfig, error) {
+	q := d.proxyURL.Query()
+	key, err := os.ReadFile(filepath.Clean(q.Get("private-key")))
+	if err != nil {
+		return nil, fmt.Errorf("could not read SSH private key: %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse SSH private key: %w", err)
+	}
+
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if knownHostsFile := q.Get("known-hosts"); knownHostsFile != "" {
+		hostKeyCallback, err = knownhosts.New(filepath.Clean(knownHostsFile))
+		if err != nil {
+			return nil, fmt.Errorf("could not read SSH known hosts: %w", err)
+		}
+	} else {
+		// only reachable with insecure-ignore-host-key=true
+		log.FromContext(ctx).Warnf("Skipping host key verification for SSH proxy %s",
+			d.proxyURL.Hostname())
+	}
+
+	return &ssh.ClientConfig{
+		User:            d.proxyURL.User.Username(),
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         dialTimeout,
+	}, nil
+}
+
+// proxyHostPort returns the proxy host:port, using the scheme's default port when none is specified
+func (d *Dialer) proxyHostPort(defaultPort string) string {
+	port := d.proxyURL.Port()
+	if port == "" {
+		port = defaultPort
+	}
+	return net.JoinHostPort(d.proxyURL.Hostname(), port)
+}
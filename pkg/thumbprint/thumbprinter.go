@@ -10,23 +10,31 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"net"
+	"strconv"
 	"strings"
 )
 
+// RetrieveSHA1 returns the host's certificate SHA1 thumbprint without verifying the certificate chain
 func RetrieveSHA1(host string, port int) (string, error) {
 	conf := &tls.Config{
 		InsecureSkipVerify: true,
 	}
-	conn, err := tls.Dial("tcp", fmt.Sprintf("%s:%d", host, port), conf)
+	conn, err := tls.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(port)), conf)
 	if err != nil {
 		return "", err
 	}
 	defer func() { _ = conn.Close() }()
 
+	return ConnSHA1(conn)
+}
+
+// ConnSHA1 returns the SHA1 thumbprint of the peer certificate on an established TLS connection
+func ConnSHA1(conn *tls.Conn) (string, error) {
 	// Get the ConnectionState struct as that's the one which gives us x509.Certificate struct
 	certs := conn.ConnectionState().PeerCertificates
 	if len(certs) < 1 {
-		return "", fmt.Errorf("expected at least one certificate returned from %s:%d, but got none", host, port)
+		return "", fmt.Errorf("expected at least one certificate returned from %s, but got none", conn.RemoteAddr())
 	}
 
 	return generateSHA1(certs[0]), nil
@@ -6,11 +6,18 @@
 package thumbprint_test
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/thumbprint"
+	"github.com/vmware/govmomi/vim25/soap"
 )
 
 func TestRetrieveSHA1InvalidHost(t *testing.T) {
@@ -26,3 +33,37 @@ func TestRetrieveSHA1(t *testing.T) {
 	require.NotEmpty(t, tp)
 	fmt.Println(tp)
 }
+
+func TestRetrieveSHA1NonDefaultPort(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	host, port := hostAndPort(t, srv)
+
+	tp, err := thumbprint.RetrieveSHA1(host, port)
+	require.NoError(t, err)
+	require.Equal(t, soap.ThumbprintSHA1(srv.Certificate()), tp)
+}
+
+func TestConnSHA1(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	host, port := hostAndPort(t, srv)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	conn, err := tls.Dial("tcp", fmt.Sprintf("%s:%d", host, port), &tls.Config{RootCAs: pool})
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	tp, err := thumbprint.ConnSHA1(conn)
+	require.NoError(t, err)
+	require.Equal(t, soap.ThumbprintSHA1(srv.Certificate()), tp)
+}
+
+func hostAndPort(t *testing.T, srv *httptest.Server) (string, int) {
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(u.Port())
+	require.NoError(t, err)
+	return u.Hostname(), port
+}
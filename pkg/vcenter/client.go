@@ -7,16 +7,20 @@ package vcenter
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"github.com/vmware/govmomi/find"
+	"net"
 	"net/url"
 	"path"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/certs"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/proxy"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/thumbprint"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/session"
@@ -35,11 +39,25 @@ type Client struct {
 	insecure   bool
 	datacenter string
 
-	certThumb  string
-	client     *govmomi.Client
-	clientOnce sync.Once
-	thumbOnce  sync.Once
-	initErr    error
+	// optional TLS trust settings, the pinned thumbprint is on the connection as it's checked once per connection
+	caCert     string
+	caCertFile string
+
+	// optional proxy, otherwise connects directly
+	dialer *proxy.Dialer
+
+	// shared by all the clients of the same vCenter, whichever datacenter they're in
+	conn *connection
+}
+
+// connection is a vCenter session and certificate thumbprint, checked against the pinned thumbprint
+type connection struct {
+	pinnedThumb string
+	certThumb   string
+	thumbErr    error
+	client      *govmomi.Client
+	clientMutex sync.Mutex
+	thumbOnce   sync.Once
 }
 
 func NewFromGovmomiClient(client *govmomi.Client, datacenter string) *Client {
@@ -50,7 +68,7 @@ func NewFromGovmomiClient(client *govmomi.Client, datacenter string) *Client {
 		password:   p,
 		datacenter: datacenter,
 		insecure:   false,
-		client:     client,
+		conn:       &connection{client: client},
 	}
 }
 
@@ -61,9 +79,35 @@ func New(host, username, password, datacenter string, insecure bool) *Client {
 		password:   password,
 		datacenter: datacenter,
 		insecure:   insecure,
+		conn:       &connection{},
 	}
 }
 
+// WithCACert trusts the PEM encoded CA certificate(s) when verifying the vCenter certificate
+func (c *Client) WithCACert(caCert string) *Client {
+	c.caCert = caCert
+	return c
+}
+
+// WithCACertFile trusts the CA certificate(s) in the PEM file when verifying the vCenter certificate
+func (c *Client) WithCACertFile(caCertFile string) *Client {
+	c.caCertFile = caCertFile
+	return c
+}
+
+// WithThumbprint pins the vCenter certificate to the expected SHA1 thumbprint, before the client is added to a Pool
+// that may share its connection
+func (c *Client) WithThumbprint(thumbprint string) *Client {
+	c.conn.pinnedThumb = strings.ToUpper(thumbprint)
+	return c
+}
+
+// WithProxy connects to vCenter using the proxy dialer
+func (c *Client) WithProxy(dialer *proxy.Dialer) *Client {
+	c.dialer = dialer
+	return c
+}
+
 func (c *Client) UserName() string {
 	return c.user
 }
@@ -84,15 +128,31 @@ func (c *Client) Insecure() bool {
 	return c.insecure
 }
 
+// FindVMInClusters returns the named VM if it lives in one of the given clusters. Each cluster is resolved to its
+// full inventory path first, a bare cluster name matching more than one cluster in the datacenter is an error.
 func (c *Client) FindVMInClusters(ctx context.Context, azName, vmNameOrPath string, clusters []string) (*VM, error) {
 	vm, err := c.findVM(ctx, azName, vmNameOrPath)
 	if err != nil {
 		return nil, err
 	}
 
+	client, err := c.getOrCreateUnderlyingClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	f := NewFinder(c.Datacenter(), client)
+
 	found := false
 	for _, cl := range clusters {
-		if strings.EqualFold(vm.Cluster, cl) {
+		cluster, err := f.Cluster(ctx, cl)
+		if err != nil {
+			var notFound *find.NotFoundError
+			if errors.As(err, &notFound) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to find cluster %s: %w", cl, err)
+		}
+		if strings.EqualFold(cluster.InventoryPath, vm.Cluster) {
 			found = true
 		}
 	}
@@ -125,9 +185,16 @@ func (c *Client) CreateFolder(ctx context.Context, folderPath string) error {
 		return fmt.Errorf("expected a folder path with at least 2 base parts, but got %d", len(folderPath))
 	}
 
-	// get the base path /dc/vm and sub-path parts
-	curFolderPath := "/" + strings.Join(folderParts[:2], "/")
-	subPaths := folderParts[2:]
+	// get the base path /dc/vm and sub-path parts, the datacenter may be nested in folders
+	base := 2
+	for i := 1; i < len(folderParts); i++ {
+		if folderParts[i] == "vm" {
+			base = i + 1
+			break
+		}
+	}
+	curFolderPath := "/" + strings.Join(folderParts[:base], "/")
+	subPaths := folderParts[base:]
 
 	// get the base path folder
 	curFolder, err := finder.Folder(ctx, curFolderPath)
@@ -167,9 +234,25 @@ func (c *Client) CreateFolder(ctx context.Context, folderPath string) error {
 	return nil
 }
 
+// FolderExists returns true if the folder exists, the folder path is an absolute inventory path
+func (c *Client) FolderExists(ctx context.Context, folderPath string) (bool, error) {
+	client, err := c.getOrCreateUnderlyingClient(ctx)
+	if err != nil {
+		return false, err
+	}
+	_, err = NewFinder(c.Datacenter(), client).Folder(ctx, folderPath)
+	if err != nil {
+		if isNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to find folder %s: %w", folderPath, err)
+	}
+	return true, nil
+}
+
 func (c *Client) Logout(ctx context.Context) {
-	if c.client != nil {
-		err := c.client.Logout(ctx)
+	if c.conn.client != nil {
+		err := c.conn.client.Logout(ctx)
 		if err != nil {
 			log.FromContext(ctx).Warnf("vSphere logout failed: %s", err)
 		}
@@ -184,8 +267,11 @@ func (c *Client) URL() *url.URL {
 	}
 }
 
-func (c *Client) isSameVCenter(host, username, password string, insecure bool) bool {
-	return c.host == host && c.user == username && c.password == password && c.insecure == insecure
+// isSameVCenter returns true if the clients connect to the same vCenter with the same credentials and TLS trust, so
+// they can share a connection including its session and pinned thumbprint check
+func (c *Client) isSameVCenter(o *Client) bool {
+	return c.host == o.host && c.user == o.user && c.password == o.password && c.insecure == o.insecure &&
+		c.caCert == o.caCert && c.caCertFile == o.caCertFile && c.conn.pinnedThumb == o.conn.pinnedThumb
 }
 
 func (c *Client) findVM(ctx context.Context, azName, vmNameOrPath string) (*VM, error) {
@@ -211,7 +297,7 @@ func (c *Client) findVM(ctx context.Context, azName, vmNameOrPath string) (*VM,
 		return nil, err
 	}
 
-	cluster, err := f.VMClusterName(ctx, vm)
+	cluster, err := f.VMClusterPath(ctx, vm)
 	if err != nil {
 		return nil, err
 	}
@@ -231,6 +317,11 @@ func (c *Client) findVM(ctx context.Context, azName, vmNameOrPath string) (*VM,
 		return nil, err
 	}
 
+	memoryMB, err := f.MemoryMB(ctx, vm)
+	if err != nil {
+		return nil, err
+	}
+
 	return &VM{
 		Name:         vm.Name(),
 		AZ:           azName,
@@ -240,6 +331,7 @@ func (c *Client) findVM(ctx context.Context, azName, vmNameOrPath string) (*VM,
 		Folder:       path.Dir(vm.InventoryPath),
 		Networks:     nets,
 		Disks:        disks,
+		MemoryMB:     memoryMB,
 	}, nil
 }
 
@@ -250,46 +342,54 @@ func (c *Client) urlWithUser() *url.URL {
 }
 
 func (c *Client) getOrCreateUnderlyingClient(ctx context.Context) (*govmomi.Client, error) {
-	c.clientOnce.Do(func() {
-		// in case already pre-populated from an external source
-		if c.client != nil {
-			return
-		}
+	c.conn.clientMutex.Lock()
+	defer c.conn.clientMutex.Unlock()
 
-		l := log.FromContext(ctx)
+	// in case already created or pre-populated from an external source
+	if c.conn.client != nil {
+		return c.conn.client, nil
+	}
 
-		u := c.urlWithUser()
-		l.Debugf("Creating govmomi client: %+v", u)
+	// a failed attempt isn't cached so a transient vCenter outage doesn't permanently break this client
+	l := log.FromContext(ctx)
 
-		soapClient := soap.NewClient(u, c.insecure)
-		vimClient, err := vim25.NewClient(ctx, soapClient)
-		if err != nil {
-			c.initErr = fmt.Errorf("could not create new vim25 govmomi client: %w", err)
-			return
-		}
-		vimClient.RoundTripper = keepalive.NewHandlerSOAP(
-			vimClient.RoundTripper, keepaliveInterval, soapKeepAliveHandler(ctx, vimClient))
+	u := c.urlWithUser()
+	l.Debugf("Creating govmomi client: %+v", u)
 
-		l.Debug("Creating vim client session manager and logging in to activate keep-alive handler")
-		m := session.NewManager(vimClient)
-		err = m.Login(ctx, u.User)
-		if err != nil {
-			c.initErr = fmt.Errorf("could not login via vim25 session manager: %w", err)
-			return
-		}
+	soapClient := soap.NewClient(u, c.insecure)
+	err := c.configureTransport(soapClient)
+	if err != nil {
+		return nil, err
+	}
+
+	vimClient, err := vim25.NewClient(ctx, soapClient)
+	if err != nil {
+		return nil, fmt.Errorf("could not create new vim25 govmomi client: %w", err)
+	}
+	vimClient.RoundTripper = keepalive.NewHandlerSOAP(
+		vimClient.RoundTripper, keepaliveInterval, soapKeepAliveHandler(ctx, vimClient))
 
-		c.client = &govmomi.Client{
-			Client:         vimClient,
-			SessionManager: m,
+	// re-login and retry whenever the session expires or vCenter restarts mid-migration
+	m := session.NewManager(vimClient)
+	vimClient.RoundTripper = newSessionRecoveryRoundTripper(vimClient.RoundTripper, func(ctx context.Context) error {
+		err := m.Login(ctx, u.User)
+		if err != nil {
+			return fmt.Errorf("could not re-login via vim25 session manager: %w", err)
 		}
+		return nil
 	})
 
-	// check for a previous init err
-	if c.initErr != nil {
-		return nil, c.initErr
+	l.Debug("Creating vim client session manager and logging in to activate keep-alive handler")
+	err = m.Login(ctx, u.User)
+	if err != nil {
+		return nil, fmt.Errorf("could not login via vim25 session manager: %w", err)
 	}
 
-	return c.client, nil
+	c.conn.client = &govmomi.Client{
+		Client:         vimClient,
+		SessionManager: m,
+	}
+	return c.conn.client, nil
 }
 
 func soapKeepAliveHandler(ctx context.Context, c *vim25.Client) func() error {
@@ -306,17 +406,133 @@ func soapKeepAliveHandler(ctx context.Context, c *vim25.Client) func() error {
 	}
 }
 
+// configureTransport makes the SOAP client connect via any configured proxy and verify the vCenter
+// certificate using the configured CA certs and/or pinned thumbprint
+func (c *Client) configureTransport(soapClient *soap.Client) error {
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return err
+	}
+
+	t := soapClient.DefaultTransport()
+	t.TLSClientConfig = tlsConfig
+	t.DialContext = c.dial
+	t.DialTLSContext = c.tlsDialer(tlsConfig)
+	if c.dialer != nil && c.dialer.Proxied() {
+		// the configured proxy takes precedence over any proxy environment variables
+		t.Proxy = nil
+	}
+	return nil
+}
+
+func (c *Client) tlsConfig() (*tls.Config, error) {
+	rootCAs, err := certs.NewPool(c.caCert, c.caCertFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not load vCenter %s CA certs: %w", c.host, err)
+	}
+	return &tls.Config{
+		RootCAs:            rootCAs,
+		InsecureSkipVerify: c.insecure,
+	}, nil
+}
+
+// tlsDialer returns a TLS dial func that verifies the vCenter certificate against the TLS config's root CAs, accepting
+// an untrusted certificate when a thumbprint is pinned just like govmomi does. A pinned thumbprint must always match,
+// even when the certificate is trusted or verification is skipped
+func (c *Client) tlsDialer(tlsConfig *tls.Config) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := c.dialTLS(ctx, network, addr, tlsConfig)
+		if err != nil {
+			if c.conn.pinnedThumb == "" || !soap.IsCertificateUntrusted(err) {
+				return nil, err
+			}
+			conn, err = c.dialTLS(ctx, network, addr, &tls.Config{InsecureSkipVerify: true})
+			if err != nil {
+				return nil, err
+			}
+		}
+		if c.conn.pinnedThumb == "" {
+			return conn, nil
+		}
+
+		peer, err := thumbprint.ConnSHA1(conn)
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		if peer != c.conn.pinnedThumb {
+			_ = conn.Close()
+			return nil, fmt.Errorf("host %q thumbprint does not match %q", addr, c.conn.pinnedThumb)
+		}
+		return conn, nil
+	}
+}
+
+func (c *Client) dialTLS(ctx context.Context, network, addr string, tlsConfig *tls.Config) (*tls.Conn, error) {
+	conn, err := c.dial(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	conf := tlsConfig.Clone()
+	if conf.ServerName == "" {
+		conf.ServerName, _, _ = net.SplitHostPort(addr)
+	}
+	tlsConn := tls.Client(conn, conf)
+	if err = tlsConn.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+func (c *Client) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	if c.dialer != nil {
+		return c.dialer.DialContext(ctx, network, addr)
+	}
+	var d net.Dialer
+	return d.DialContext(ctx, network, addr)
+}
+
 func (c *Client) thumbprint(ctx context.Context) (string, error) {
-	var initErr error
-	c.thumbOnce.Do(func() {
-		thumb, err := thumbprint.RetrieveSHA1(c.host, 443)
+	c.conn.thumbOnce.Do(func() {
+		addr := c.hostPort()
+		tlsConfig, err := c.tlsConfig()
+		if err != nil {
+			c.conn.thumbErr = err
+			return
+		}
+
+		// use the same proxy and certificate verification as the SOAP client
+		conn, err := c.tlsDialer(tlsConfig)(ctx, "tcp", addr)
+		if err != nil {
+			c.conn.thumbErr = fmt.Errorf("failed to get %s cert thumbprint: %w", addr, err)
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		thumb, err := thumbprint.ConnSHA1(conn.(*tls.Conn))
 		if err != nil {
-			initErr = fmt.Errorf("failed to get %s:%d cert thumbprint", c.host, 443)
+			c.conn.thumbErr = fmt.Errorf("failed to get %s cert thumbprint: %w", addr, err)
 			return
 		}
-		log.FromContext(ctx).Debugf("Target %s:%d cert thumbprint is: %s", c.host, 443, thumb)
-		c.certThumb = thumb
+		log.FromContext(ctx).Debugf("Target %s cert thumbprint is: %s", addr, thumb)
+
+		if c.conn.pinnedThumb != "" && c.conn.pinnedThumb != thumb {
+			c.conn.thumbErr = fmt.Errorf("%s cert thumbprint %s does not match the configured thumbprint %s",
+				addr, thumb, c.conn.pinnedThumb)
+			return
+		}
+		c.conn.certThumb = thumb
 	})
 
-	return c.certThumb, initErr
+	return c.conn.certThumb, c.conn.thumbErr
+}
+
+// hostPort returns the vCenter host:port, defaulting to port 443
+func (c *Client) hostPort() string {
+	if _, _, err := net.SplitHostPort(c.host); err == nil {
+		return c.host
+	}
+	return net.JoinHostPort(c.host, "443")
 }
@@ -7,13 +7,27 @@ package vcenter_test
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
 	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/proxy"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
 	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/property"
 	"github.com/vmware/govmomi/session"
 	"github.com/vmware/govmomi/simulator"
 	"github.com/vmware/govmomi/vim25"
 	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 )
 
@@ -31,6 +45,23 @@ func VPXTest(f func(context.Context, *govmomi.Client)) {
 	}, model)
 }
 
+// NestedVPXTest runs the test against a vCenter with the datacenter in folder F0, and its VMs, clusters, datastores
+// and networks each in an F0 folder, e.g. /F0/DC0/host/F0/DC0_C0
+func NestedVPXTest(f func(context.Context, *govmomi.Client)) {
+	model := simulator.VPX()
+	defer model.Remove()
+	model.Pool = 1
+	model.Folder = 1
+
+	simulator.Test(func(ctx context.Context, vimClient *vim25.Client) {
+		c := &govmomi.Client{
+			Client:         vimClient,
+			SessionManager: session.NewManager(vimClient),
+		}
+		f(ctx, c)
+	}, model)
+}
+
 func findSimulatorObject(kind, name string) mo.Entity {
 	for _, o := range simulator.Map.All(kind) {
 		if o.Entity().Name == name {
@@ -78,3 +109,253 @@ func TestCreateFolder(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestCreateFolderNestedDatacenter(t *testing.T) {
+	NestedVPXTest(func(ctx context.Context, client *govmomi.Client) {
+		for _, dc := range []string{"F0/DC0", "DC0"} {
+			c := vcenter.NewFromGovmomiClient(client, dc)
+
+			err := c.CreateFolder(ctx, "/"+dc+"/vm/foundations/prod-tas")
+			require.NoError(t, err, dc)
+
+			exists, err := c.FolderExists(ctx, "/F0/DC0/vm/foundations/prod-tas")
+			require.NoError(t, err, dc)
+			require.True(t, exists, dc)
+		}
+	})
+}
+
+func TestFindVMInNestedCluster(t *testing.T) {
+	NestedVPXTest(func(ctx context.Context, client *govmomi.Client) {
+		c := vcenter.NewFromGovmomiClient(client, "DC0")
+		for _, cluster := range []string{"DC0_C0", "F0/DC0_C0", "/F0/DC0/host/F0/DC0_C0"} {
+			vm, err := c.FindVMInClusters(ctx, "az1", "DC0_C0_RP1_VM0", []string{cluster})
+			require.NoError(t, err, cluster)
+			require.Equal(t, "/F0/DC0/host/F0/DC0_C0", vm.Cluster)
+			require.Equal(t, "DC0_C0_RP1", vm.ResourcePool)
+			require.Equal(t, "/F0/DC0/vm/F0", vm.Folder)
+		}
+
+		_, err := c.FindVMInClusters(ctx, "az1", "DC0_C0_RP1_VM0", []string{"F0/DC0_C1"})
+		require.Error(t, err)
+	})
+}
+
+func TestFindVMInSameNamedClusters(t *testing.T) {
+	NestedVPXTest(func(ctx context.Context, client *govmomi.Client) {
+		// create a second DC0_C0 cluster in another host folder
+		dc, err := find.NewFinder(client.Client).Datacenter(ctx, "DC0")
+		require.NoError(t, err)
+		folders, err := dc.Folders(ctx)
+		require.NoError(t, err)
+		f1, err := folders.HostFolder.CreateFolder(ctx, "F1")
+		require.NoError(t, err)
+		_, err = f1.CreateCluster(ctx, "DC0_C0", types.ClusterConfigSpecEx{})
+		require.NoError(t, err)
+
+		c := vcenter.NewFromGovmomiClient(client, "DC0")
+		vm, err := c.FindVMInClusters(ctx, "az1", "DC0_C0_RP1_VM0", []string{"F0/DC0_C0"})
+		require.NoError(t, err)
+		require.Equal(t, "/F0/DC0/host/F0/DC0_C0", vm.Cluster)
+
+		_, err = c.FindVMInClusters(ctx, "az1", "DC0_C0_RP1_VM0", []string{"F1/DC0_C0"})
+		require.Error(t, err)
+		var notFound *vcenter.VMNotFoundError
+		require.ErrorAs(t, err, &notFound)
+
+		_, err = c.FindVMInClusters(ctx, "az1", "DC0_C0_RP1_VM0", []string{"DC0_C0"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "resolves to multiple clusters")
+	})
+}
+
+func TestFolderExists(t *testing.T) {
+	VPXTest(func(ctx context.Context, client *govmomi.Client) {
+		c := vcenter.NewFromGovmomiClient(client, "DC0")
+
+		exists, err := c.FolderExists(ctx, "/DC0/vm")
+		require.NoError(t, err)
+		require.True(t, exists)
+
+		exists, err = c.FolderExists(ctx, "/DC0/vm/doesnotexist")
+		require.NoError(t, err)
+		require.False(t, exists)
+	})
+}
+
+func TestSessionRecovery(t *testing.T) {
+	VPXTest(func(ctx context.Context, client *govmomi.Client) {
+		c := vcenter.New(client.URL().Host, "user", "pass", "DC0", true)
+		defer c.Logout(ctx)
+
+		vm, err := c.FindVMInClusters(ctx, "az1", "DC0_C0_RP1_VM0", []string{"DC0_C0"})
+		require.NoError(t, err)
+		require.Equal(t, "DC0_C0_RP1_VM0", vm.Name)
+
+		// simulate a session timeout or vCenter restart mid-run
+		terminateOtherSessions(ctx, t, client)
+
+		vm, err = c.FindVMInClusters(ctx, "az1", "DC0_C0_RP1_VM0", []string{"DC0_C0"})
+		require.NoError(t, err)
+		require.Equal(t, "DC0_C0_RP1_VM0", vm.Name)
+		require.Equal(t, "/DC0/host/DC0_C0", vm.Cluster)
+
+		terminateOtherSessions(ctx, t, client)
+
+		err = c.CreateFolder(ctx, "/DC0/vm/a/b")
+		require.NoError(t, err)
+
+		terminateOtherSessions(ctx, t, client)
+
+		_, err = c.FindVMInClusters(ctx, "az1", "DC0_C0_RP1_VM0", []string{"DC0_C1"})
+		require.Error(t, err)
+		var notFound *vcenter.VMNotFoundError
+		require.ErrorAs(t, err, &notFound)
+	})
+}
+
+func TestSessionRecoveryWithFinder(t *testing.T) {
+	VPXTest(func(ctx context.Context, client *govmomi.Client) {
+		c := vcenter.New(client.URL().Host, "user", "pass", "DC0", true)
+		defer c.Logout(ctx)
+
+		err := c.CreateFolder(ctx, "/DC0/vm/x/y")
+		require.NoError(t, err)
+
+		// a finder built on the client's own govmomi client shares its session recovery
+		underlying, err := c.UnderlyingClient(ctx)
+		require.NoError(t, err)
+		finder := vcenter.NewFinder("DC0", underlying)
+		folder, err := finder.Folder(ctx, "/DC0/vm/x")
+		require.NoError(t, err)
+		require.Equal(t, "x", folder.Name())
+
+		terminateOtherSessions(ctx, t, client)
+
+		folder, err = finder.Folder(ctx, "/DC0/vm/x/y")
+		require.NoError(t, err)
+		require.Equal(t, "y", folder.Name())
+		vm, err := finder.VirtualMachine(ctx, "DC0_C0_RP1_VM0")
+		require.NoError(t, err)
+		require.Equal(t, "DC0_C0_RP1_VM0", vm.Name())
+	})
+}
+
+// terminateOtherSessions invalidates every vCenter session except the one used by client
+func terminateOtherSessions(ctx context.Context, t *testing.T, client *govmomi.Client) {
+	current, err := client.SessionManager.UserSession(ctx)
+	require.NoError(t, err)
+
+	var sm mo.SessionManager
+	pc := property.DefaultCollector(client.Client)
+	err = pc.RetrieveOne(ctx, *client.ServiceContent.SessionManager, []string{"sessionList"}, &sm)
+	require.NoError(t, err)
+
+	var ids []string
+	for _, s := range sm.SessionList {
+		if s.Key != current.Key {
+			ids = append(ids, s.Key)
+		}
+	}
+	require.NotEmpty(t, ids)
+	require.NoError(t, client.SessionManager.TerminateSession(ctx, ids))
+}
+
+func TestClientVerifiesCertificate(t *testing.T) {
+	VPXTest(func(ctx context.Context, client *govmomi.Client) {
+		c := vcenter.New(client.URL().Host, "user", "pass", "DC0", false)
+		_, err := c.FindVMInClusters(ctx, "az1", "DC0_C0_RP1_VM0", []string{"DC0_C0"})
+		require.ErrorContains(t, err, "certificate")
+	})
+}
+
+func TestClientWithCACert(t *testing.T) {
+	VPXTest(func(ctx context.Context, client *govmomi.Client) {
+		cert := simulatorCertificate(t, client)
+		caCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+
+		c := vcenter.New(client.URL().Host, "user", "pass", "DC0", false).WithCACert(caCert)
+		defer c.Logout(ctx)
+		vm, err := c.FindVMInClusters(ctx, "az1", "DC0_C0_RP1_VM0", []string{"DC0_C0"})
+		require.NoError(t, err)
+		require.Equal(t, "DC0_C0_RP1_VM0", vm.Name)
+	})
+}
+
+func TestClientWithThumbprint(t *testing.T) {
+	VPXTest(func(ctx context.Context, client *govmomi.Client) {
+		thumb := soap.ThumbprintSHA1(simulatorCertificate(t, client))
+
+		c := vcenter.New(client.URL().Host, "user", "pass", "DC0", false).WithThumbprint(strings.ToLower(thumb))
+		defer c.Logout(ctx)
+		vm, err := c.FindVMInClusters(ctx, "az1", "DC0_C0_RP1_VM0", []string{"DC0_C0"})
+		require.NoError(t, err)
+		require.Equal(t, "DC0_C0_RP1_VM0", vm.Name)
+
+		c = vcenter.New(client.URL().Host, "user", "pass", "DC0", false).
+			WithThumbprint("0A:1B:2C:3D:4E:5F:60:71:82:93:A4:B5:C6:D7:E8:F9:0A:1B:2C:3D")
+		_, err = c.FindVMInClusters(ctx, "az1", "DC0_C0_RP1_VM0", []string{"DC0_C0"})
+		require.ErrorContains(t, err, "thumbprint does not match")
+	})
+}
+
+func TestClientWithThumbprintChecksTrustedCertificate(t *testing.T) {
+	VPXTest(func(ctx context.Context, client *govmomi.Client) {
+		cert := simulatorCertificate(t, client)
+		caCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+		wrongThumb := "0A:1B:2C:3D:4E:5F:60:71:82:93:A4:B5:C6:D7:E8:F9:0A:1B:2C:3D"
+
+		// a CA trusted certificate still has to match the pinned thumbprint
+		c := vcenter.New(client.URL().Host, "user", "pass", "DC0", false).
+			WithCACert(caCert).
+			WithThumbprint(wrongThumb)
+		_, err := c.FindVMInClusters(ctx, "az1", "DC0_C0_RP1_VM0", []string{"DC0_C0"})
+		require.ErrorContains(t, err, "thumbprint does not match")
+
+		// as does any certificate when verification is skipped
+		c = vcenter.New(client.URL().Host, "user", "pass", "DC0", true).WithThumbprint(wrongThumb)
+		_, err = c.FindVMInClusters(ctx, "az1", "DC0_C0_RP1_VM0", []string{"DC0_C0"})
+		require.ErrorContains(t, err, "thumbprint does not match")
+	})
+}
+
+func simulatorCertificate(t *testing.T, client *govmomi.Client) *x509.Certificate {
+	conn, err := tls.Dial("tcp", client.URL().Host, &tls.Config{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	return conn.ConnectionState().PeerCertificates[0]
+}
+
+func TestClientWithProxy(t *testing.T) {
+	VPXTest(func(ctx context.Context, client *govmomi.Client) {
+		var connects int32
+		p := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&connects, 1)
+			targetConn, err := net.Dial("tcp", r.Host)
+			require.NoError(t, err)
+			clientConn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			_, err = clientConn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+			require.NoError(t, err)
+			go func() {
+				_, _ = io.Copy(targetConn, clientConn)
+				_ = targetConn.Close()
+			}()
+			go func() {
+				_, _ = io.Copy(clientConn, targetConn)
+				_ = clientConn.Close()
+			}()
+		}))
+		defer p.Close()
+
+		dialer, err := proxy.NewDialer(p.URL)
+		require.NoError(t, err)
+
+		c := vcenter.New(client.URL().Host, "user", "pass", "DC0", true).WithProxy(dialer)
+		defer c.Logout(ctx)
+		vm, err := c.FindVMInClusters(ctx, "az1", "DC0_C0_RP1_VM0", []string{"DC0_C0"})
+		require.NoError(t, err)
+		require.Equal(t, "DC0_C0_RP1_VM0", vm.Name)
+		require.NotZero(t, atomic.LoadInt32(&connects))
+	})
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package vcenter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware/govmomi/vim25/mo"
+)
+
+const bytesPerMB = 1024 * 1024
+
+// ClusterFreeMemoryMB returns the memory not currently used on the cluster's hosts that aren't in maintenance mode
+func (c *Client) ClusterFreeMemoryMB(ctx context.Context, clusterName string) (int64, error) {
+	l := log.FromContext(ctx)
+
+	client, err := c.getOrCreateUnderlyingClient(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	f := NewFinder(c.Datacenter(), client)
+	hosts, err := f.HostsInCluster(ctx, clusterName)
+	if err != nil {
+		return 0, err
+	}
+
+	var free int64
+	for _, h := range hosts {
+		var hmo mo.HostSystem
+		err = h.Properties(ctx, h.Reference(), []string{"summary"}, &hmo)
+		if err != nil {
+			return 0, fmt.Errorf("could not get summary properties for host %s: %w", h.Name(), err)
+		}
+		if hmo.Summary.Runtime != nil && hmo.Summary.Runtime.InMaintenanceMode {
+			continue
+		}
+		if hmo.Summary.Hardware == nil {
+			continue
+		}
+		hostFree := hmo.Summary.Hardware.MemorySize/bytesPerMB - int64(hmo.Summary.QuickStats.OverallMemoryUsage)
+		if hostFree > 0 {
+			free += hostFree
+		}
+	}
+	l.Debugf("Cluster %s has %dMB of free memory", clusterName, free)
+	return free, nil
+}
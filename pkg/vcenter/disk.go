@@ -0,0 +1,377 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package vcenter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// DatastoreDisk is a virtual disk (vmdk) on a datastore which isn't attached to any VM
+type DatastoreDisk struct {
+	AZ         string
+	Datacenter string
+	Datastore  string
+	Path       string
+}
+
+// DatastorePath returns the disk's datastore path, e.g. [ds1] pcf_disk/disk-guid.vmdk
+func (d *DatastoreDisk) DatastorePath() string {
+	return fmt.Sprintf("[%s] %s", d.Datastore, d.Path)
+}
+
+type DiskNotFoundError struct {
+	Path string
+	Err  error
+}
+
+func NewDiskNotFoundError(path string, err error) error {
+	return &DiskNotFoundError{
+		Path: path,
+		Err:  err,
+	}
+}
+
+func (e *DiskNotFoundError) Error() string {
+	return fmt.Sprintf("%s disk not found: %s", e.Path, e.Err)
+}
+
+// DiskFileName returns the vmdk file name for a vSphere CPI disk CID, newer CPIs append a base64 encoded
+// suffix to the disk CID that's not part of the file name
+func DiskFileName(diskCID string) string {
+	name, _, _ := strings.Cut(diskCID, ".")
+	return name + ".vmdk"
+}
+
+// FindDisk looks for the disk on each of the datastores in order, returning the first found
+func (c *Client) FindDisk(ctx context.Context, azName, diskPath string, datastores []string) (*DatastoreDisk, error) {
+	client, err := c.getOrCreateUnderlyingClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	f := NewFinder(c.Datacenter(), client)
+	for _, dsName := range datastores {
+		ds, err := f.Datastore(ctx, dsName)
+		if err != nil {
+			// the datastore may only exist in another datacenter
+			log.FromContext(ctx).Debugf("Skipping disk %s search on datastore %s: %s", diskPath, dsName, err)
+			continue
+		}
+
+		_, err = ds.Stat(ctx, diskPath)
+		if err != nil {
+			var noSuchFile object.DatastoreNoSuchFileError
+			var noSuchDir object.DatastoreNoSuchDirectoryError
+			if errors.As(err, &noSuchFile) || errors.As(err, &noSuchDir) {
+				continue
+			}
+			return nil, fmt.Errorf("could not search datastore %s for disk %s: %w", dsName, diskPath, err)
+		}
+
+		return &DatastoreDisk{
+			AZ:         azName,
+			Datacenter: c.Datacenter(),
+			Datastore:  dsName,
+			Path:       diskPath,
+		}, nil
+	}
+
+	return nil, NewDiskNotFoundError(diskPath,
+		fmt.Errorf("disk does not exist on datastores %s", strings.Join(datastores, ", ")))
+}
+
+// MoveDisk moves the disk to the target datastore path within this vCenter, which may be in another datacenter
+func (c *Client) MoveDisk(ctx context.Context, disk *DatastoreDisk, target *DatastoreDisk) error {
+	l := log.FromContext(ctx)
+
+	client, err := c.getOrCreateUnderlyingClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	sourceDC, err := NewFinder(disk.Datacenter, client).DatacenterObject(ctx)
+	if err != nil {
+		return err
+	}
+	targetDC, err := NewFinder(target.Datacenter, client).DatacenterObject(ctx)
+	if err != nil {
+		return err
+	}
+
+	dir := fmt.Sprintf("[%s] %s", target.Datastore, path.Dir(target.Path))
+	l.Debugf("Creating directory %s", dir)
+	err = object.NewFileManager(client.Client).MakeDirectory(ctx, dir, targetDC, true)
+	if err != nil && !isFileAlreadyExists(err) {
+		return fmt.Errorf("could not create directory %s: %w", dir, err)
+	}
+
+	l.Debugf("Moving disk %s to %s", disk.DatastorePath(), target.DatastorePath())
+	dm := object.NewVirtualDiskManager(client.Client)
+	t, err := dm.MoveVirtualDisk(ctx, disk.DatastorePath(), sourceDC, target.DatastorePath(), targetDC, false)
+	if err != nil {
+		return fmt.Errorf("failed to move disk %s: %w", disk.DatastorePath(), err)
+	}
+	err = t.Wait(ctx)
+	if err != nil {
+		return fmt.Errorf("error moving disk %s to %s: %w", disk.DatastorePath(), target.DatastorePath(), err)
+	}
+	return nil
+}
+
+// CreateDiskVM creates a VM without any devices except the existing disk, allowing the disk to be
+// migrated with the VM to another vCenter. The VM is created in the datacenter's root VM folder.
+func (c *Client) CreateDiskVM(ctx context.Context, vmName string, disk *DatastoreDisk, cluster, resourcePool string) (*VM, error) {
+	l := log.FromContext(ctx)
+
+	client, err := c.getOrCreateUnderlyingClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	f := NewFinder(disk.Datacenter, client)
+
+	cl, err := f.Cluster(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	rpPath := cl.InventoryPath + "/Resources"
+	if resourcePool != "" {
+		rpPath += "/" + resourcePool
+	}
+	rp, err := f.ResourcePool(ctx, rpPath)
+	if err != nil {
+		return nil, err
+	}
+	folder, err := f.Folder(ctx, "/"+disk.Datacenter+"/vm")
+	if err != nil {
+		return nil, err
+	}
+	ds, err := f.Datastore(ctx, disk.Datastore)
+	if err != nil {
+		return nil, err
+	}
+
+	var devices object.VirtualDeviceList
+	controller, err := devices.CreateSCSIController("pvscsi")
+	if err != nil {
+		return nil, err
+	}
+	devices = append(devices, controller)
+	vmdk := devices.CreateDisk(controller.(types.BaseVirtualController), ds.Reference(), disk.DatastorePath())
+	devices = append(devices, vmdk)
+
+	deviceChange, err := devices.ConfigSpec(types.VirtualDeviceConfigSpecOperationAdd)
+	if err != nil {
+		return nil, err
+	}
+	// attach the existing disk rather than creating a new one
+	for _, dc := range deviceChange {
+		if dc.GetVirtualDeviceConfigSpec().Device == vmdk {
+			dc.GetVirtualDeviceConfigSpec().FileOperation = ""
+		}
+	}
+
+	spec := types.VirtualMachineConfigSpec{
+		Name:         vmName,
+		GuestId:      string(types.VirtualMachineGuestOsIdentifierOtherGuest64),
+		NumCPUs:      1,
+		MemoryMB:     128,
+		DeviceChange: deviceChange,
+		Files: &types.VirtualMachineFileInfo{
+			VmPathName: fmt.Sprintf("[%s]", disk.Datastore),
+		},
+	}
+
+	l.Debugf("Creating VM %s with disk %s", vmName, disk.DatastorePath())
+	t, err := folder.CreateVM(ctx, spec, rp, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create VM %s: %w", vmName, err)
+	}
+	err = t.Wait(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error creating VM %s: %w", vmName, err)
+	}
+
+	return c.findVM(ctx, disk.AZ, vmName)
+}
+
+// DeleteDiskVM detaches the disk from a VM created by CreateDiskVM, moves the disk to the
+// target path on the same datastore and then deletes the VM
+func (c *Client) DeleteDiskVM(ctx context.Context, vmName string, target *DatastoreDisk) error {
+	l := log.FromContext(ctx)
+
+	client, err := c.getOrCreateUnderlyingClient(ctx)
+	if err != nil {
+		return err
+	}
+	vm, err := NewFinder(target.Datacenter, client).VirtualMachine(ctx, vmName)
+	if err != nil {
+		return err
+	}
+
+	disks, err := attachedDisks(ctx, vm)
+	if err != nil {
+		return err
+	}
+	if len(disks) != 1 {
+		return fmt.Errorf("expected VM %s to have 1 disk but found %d", vmName, len(disks))
+	}
+
+	err = c.detachAndMoveDisk(ctx, vm, disks[0], target)
+	if err != nil {
+		return err
+	}
+
+	l.Debugf("Deleting VM %s", vmName)
+	t, err := vm.Destroy(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete VM %s: %w", vmName, err)
+	}
+	err = t.Wait(ctx)
+	if err != nil {
+		return fmt.Errorf("error deleting VM %s: %w", vmName, err)
+	}
+	return nil
+}
+
+// PersistentDisk returns the VM's BOSH persistent disk, which the vSphere CPI attaches in independent
+// persistent mode
+func (c *Client) PersistentDisk(ctx context.Context, azName, vmName string) (*DatastoreDisk, error) {
+	client, err := c.getOrCreateUnderlyingClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	vm, err := NewFinder(c.Datacenter(), client).VirtualMachine(ctx, vmName)
+	if err != nil {
+		return nil, NewVMNotFoundError(vmName, err)
+	}
+
+	disks, err := attachedDisks(ctx, vm)
+	if err != nil {
+		return nil, err
+	}
+	var persistent []attachedDisk
+	for _, d := range disks {
+		if d.mode == string(types.VirtualDiskModeIndependent_persistent) {
+			persistent = append(persistent, d)
+		}
+	}
+	if len(persistent) == 0 {
+		return nil, NewDiskNotFoundError(vmName, errors.New("VM has no persistent disk attached"))
+	}
+	if len(persistent) > 1 {
+		return nil, fmt.Errorf("expected VM %s to have 1 persistent disk but found %d", vmName, len(persistent))
+	}
+
+	return &DatastoreDisk{
+		AZ:         azName,
+		Datacenter: c.Datacenter(),
+		Datastore:  persistent[0].path.Datastore,
+		Path:       persistent[0].path.Path,
+	}, nil
+}
+
+// DetachDisk detaches the disk from the powered off VM and moves it to the target path
+func (c *Client) DetachDisk(ctx context.Context, vmName string, disk *DatastoreDisk, target *DatastoreDisk) error {
+	client, err := c.getOrCreateUnderlyingClient(ctx)
+	if err != nil {
+		return err
+	}
+	vm, err := NewFinder(disk.Datacenter, client).VirtualMachine(ctx, vmName)
+	if err != nil {
+		return err
+	}
+
+	state, err := vm.PowerState(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get VM %s power state: %w", vmName, err)
+	}
+	if state != types.VirtualMachinePowerStatePoweredOff {
+		return fmt.Errorf("expected VM %s to be powered off but it is %s", vmName, state)
+	}
+
+	disks, err := attachedDisks(ctx, vm)
+	if err != nil {
+		return err
+	}
+	for _, d := range disks {
+		if d.path.String() == disk.DatastorePath() {
+			return c.detachAndMoveDisk(ctx, vm, d, target)
+		}
+	}
+	return NewDiskNotFoundError(disk.DatastorePath(), fmt.Errorf("disk is not attached to VM %s", vmName))
+}
+
+// attachedDisk is a virtual disk device attached to a VM
+type attachedDisk struct {
+	device *types.VirtualDisk
+	path   object.DatastorePath
+	mode   string
+}
+
+func attachedDisks(ctx context.Context, vm *object.VirtualMachine) ([]attachedDisk, error) {
+	devices, err := vm.Device(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get VM %s devices: %w", vm.Name(), err)
+	}
+
+	var disks []attachedDisk
+	for _, device := range devices.SelectByType((*types.VirtualDisk)(nil)) {
+		disk := device.(*types.VirtualDisk)
+		backing, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+		if !ok {
+			return nil, fmt.Errorf("expected VM %s disk %d to have a flat file backing", vm.Name(), disk.Key)
+		}
+		var p object.DatastorePath
+		if !p.FromString(backing.FileName) {
+			return nil, fmt.Errorf("could not parse VM %s disk path %s", vm.Name(), backing.FileName)
+		}
+		disks = append(disks, attachedDisk{
+			device: disk,
+			path:   p,
+			mode:   backing.DiskMode,
+		})
+	}
+	return disks, nil
+}
+
+// detachAndMoveDisk detaches the disk from the VM, keeping the disk files, then moves it to the target path
+func (c *Client) detachAndMoveDisk(ctx context.Context, vm *object.VirtualMachine, disk attachedDisk, target *DatastoreDisk) error {
+	l := log.FromContext(ctx)
+
+	l.Debugf("Detaching disk %s from VM %s", disk.path.String(), vm.Name())
+	err := vm.RemoveDevice(ctx, true, disk.device)
+	if err != nil {
+		return fmt.Errorf("could not detach disk from VM %s: %w", vm.Name(), err)
+	}
+
+	source := &DatastoreDisk{
+		AZ:         target.AZ,
+		Datacenter: target.Datacenter,
+		Datastore:  disk.path.Datastore,
+		Path:       disk.path.Path,
+	}
+	if source.DatastorePath() == target.DatastorePath() {
+		return nil
+	}
+	return c.MoveDisk(ctx, source, target)
+}
+
+func isFileAlreadyExists(err error) bool {
+	if soap.IsSoapFault(err) {
+		_, ok := soap.ToSoapFault(err).VimFault().(types.FileAlreadyExists)
+		return ok
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package vcenter_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/session"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func multiDatastoreVPXTest(f func(context.Context, *govmomi.Client)) {
+	model := simulator.VPX()
+	defer model.Remove()
+	model.Pool = 1
+	model.Datastore = 2
+
+	simulator.Test(func(ctx context.Context, vimClient *vim25.Client) {
+		c := &govmomi.Client{
+			Client:         vimClient,
+			SessionManager: session.NewManager(vimClient),
+		}
+		f(ctx, c)
+	}, model)
+}
+
+func createOrphanedDisk(ctx context.Context, t *testing.T, client *govmomi.Client, datastorePath string) {
+	finder := find.NewFinder(client.Client)
+	dc, err := finder.Datacenter(ctx, "DC0")
+	require.NoError(t, err)
+
+	fm := object.NewFileManager(client.Client)
+	require.NoError(t, fm.MakeDirectory(ctx, "[LocalDS_0] pcf_disk", dc, true))
+
+	dm := object.NewVirtualDiskManager(client.Client)
+	task, err := dm.CreateVirtualDisk(ctx, datastorePath, dc, &types.FileBackedVirtualDiskSpec{
+		VirtualDiskSpec: types.VirtualDiskSpec{
+			DiskType:    string(types.VirtualDiskTypeThin),
+			AdapterType: string(types.VirtualDiskAdapterTypeLsiLogic),
+		},
+		CapacityKb: 1024,
+	})
+	require.NoError(t, err)
+	require.NoError(t, task.Wait(ctx))
+}
+
+func TestDiskFileName(t *testing.T) {
+	require.Equal(t, "disk-0f8ea1b5.vmdk", vcenter.DiskFileName("disk-0f8ea1b5"))
+	require.Equal(t, "disk-0f8ea1b5.vmdk",
+		vcenter.DiskFileName("disk-0f8ea1b5.eyJ0YXJnZXRfZGF0YXN0b3JlX3BhdHRlcm4iOiJeKExvY2FsRFNfMSkkIn0"))
+}
+
+func TestFindDisk(t *testing.T) {
+	multiDatastoreVPXTest(func(ctx context.Context, client *govmomi.Client) {
+		createOrphanedDisk(ctx, t, client, "[LocalDS_0] pcf_disk/disk-guid.vmdk")
+
+		c := vcenter.NewFromGovmomiClient(client, "DC0")
+		disk, err := c.FindDisk(ctx, "az1", "pcf_disk/disk-guid.vmdk", []string{"does-not-exist", "LocalDS_1", "LocalDS_0"})
+		require.NoError(t, err)
+		require.Equal(t, &vcenter.DatastoreDisk{
+			AZ:         "az1",
+			Datacenter: "DC0",
+			Datastore:  "LocalDS_0",
+			Path:       "pcf_disk/disk-guid.vmdk",
+		}, disk)
+		require.Equal(t, "[LocalDS_0] pcf_disk/disk-guid.vmdk", disk.DatastorePath())
+
+		_, err = c.FindDisk(ctx, "az1", "pcf_disk/disk-guid.vmdk", []string{"LocalDS_1"})
+		var notFoundErr *vcenter.DiskNotFoundError
+		require.ErrorAs(t, err, &notFoundErr)
+	})
+}
+
+func TestMoveDisk(t *testing.T) {
+	multiDatastoreVPXTest(func(ctx context.Context, client *govmomi.Client) {
+		createOrphanedDisk(ctx, t, client, "[LocalDS_0] pcf_disk/disk-guid.vmdk")
+
+		c := vcenter.NewFromGovmomiClient(client, "DC0")
+		disk, err := c.FindDisk(ctx, "az1", "pcf_disk/disk-guid.vmdk", []string{"LocalDS_0"})
+		require.NoError(t, err)
+
+		target := &vcenter.DatastoreDisk{
+			AZ:         "az1",
+			Datacenter: "DC0",
+			Datastore:  "LocalDS_1",
+			Path:       "pcf_disk/disk-guid.vmdk",
+		}
+		require.NoError(t, c.MoveDisk(ctx, disk, target))
+
+		_, err = c.FindDisk(ctx, "az1", "pcf_disk/disk-guid.vmdk", []string{"LocalDS_0"})
+		require.Error(t, err)
+		moved, err := c.FindDisk(ctx, "az1", "pcf_disk/disk-guid.vmdk", []string{"LocalDS_1"})
+		require.NoError(t, err)
+		require.Equal(t, target, moved)
+	})
+}
+
+func TestCreateAndDeleteDiskVM(t *testing.T) {
+	multiDatastoreVPXTest(func(ctx context.Context, client *govmomi.Client) {
+		createOrphanedDisk(ctx, t, client, "[LocalDS_0] pcf_disk/disk-guid.vmdk")
+
+		c := vcenter.NewFromGovmomiClient(client, "DC0")
+		disk, err := c.FindDisk(ctx, "az1", "pcf_disk/disk-guid.vmdk", []string{"LocalDS_0"})
+		require.NoError(t, err)
+
+		vm, err := c.CreateDiskVM(ctx, "vmotion4bosh-disk-guid", disk, "DC0_C0", "")
+		require.NoError(t, err)
+		require.Equal(t, "vmotion4bosh-disk-guid", vm.Name)
+		require.Equal(t, "/DC0/host/DC0_C0", vm.Cluster)
+		require.Equal(t, "/DC0/vm", vm.Folder)
+		require.Len(t, vm.Disks, 1)
+		require.Equal(t, "LocalDS_0", vm.Disks[0].Datastore)
+		require.Empty(t, vm.Networks)
+
+		target := &vcenter.DatastoreDisk{
+			AZ:         "az1",
+			Datacenter: "DC0",
+			Datastore:  "LocalDS_0",
+			Path:       "pcf_disk/disk-guid-moved.vmdk",
+		}
+		require.NoError(t, c.DeleteDiskVM(ctx, "vmotion4bosh-disk-guid", target))
+
+		_, err = c.FindVMInClusters(ctx, "az1", "vmotion4bosh-disk-guid", []string{"DC0_C0"})
+		var vmNotFoundErr *vcenter.VMNotFoundError
+		require.ErrorAs(t, err, &vmNotFoundErr)
+
+		_, err = c.FindDisk(ctx, "az1", "pcf_disk/disk-guid-moved.vmdk", []string{"LocalDS_0"})
+		require.NoError(t, err)
+	})
+}
+
+func TestPersistentDiskAndDetachDisk(t *testing.T) {
+	multiDatastoreVPXTest(func(ctx context.Context, client *govmomi.Client) {
+		createOrphanedDisk(ctx, t, client, "[LocalDS_0] pcf_disk/disk-guid.vmdk")
+		vm := attachIndependentDisk(ctx, t, client, "DC0_H0_VM0", "[LocalDS_0] pcf_disk/disk-guid.vmdk")
+
+		c := vcenter.NewFromGovmomiClient(client, "DC0")
+		disk, err := c.PersistentDisk(ctx, "az1", "DC0_H0_VM0")
+		require.NoError(t, err)
+		require.Equal(t, &vcenter.DatastoreDisk{
+			AZ:         "az1",
+			Datacenter: "DC0",
+			Datastore:  "LocalDS_0",
+			Path:       "pcf_disk/disk-guid.vmdk",
+		}, disk)
+
+		target := &vcenter.DatastoreDisk{
+			AZ:         "az1",
+			Datacenter: "DC0",
+			Datastore:  "LocalDS_0",
+			Path:       "pcf_disk/disk-guid-moved.vmdk",
+		}
+		err = c.DetachDisk(ctx, "DC0_H0_VM0", disk, target)
+		require.ErrorContains(t, err, "expected VM DC0_H0_VM0 to be powered off but it is poweredOn")
+
+		task, err := vm.PowerOff(ctx)
+		require.NoError(t, err)
+		require.NoError(t, task.Wait(ctx))
+		require.NoError(t, c.DetachDisk(ctx, "DC0_H0_VM0", disk, target))
+
+		_, err = c.PersistentDisk(ctx, "az1", "DC0_H0_VM0")
+		var notFoundErr *vcenter.DiskNotFoundError
+		require.ErrorAs(t, err, &notFoundErr)
+		_, err = c.FindDisk(ctx, "az1", "pcf_disk/disk-guid-moved.vmdk", []string{"LocalDS_0"})
+		require.NoError(t, err)
+
+		_, err = c.PersistentDisk(ctx, "az1", "does-not-exist")
+		var vmNotFoundErr *vcenter.VMNotFoundError
+		require.ErrorAs(t, err, &vmNotFoundErr)
+	})
+}
+
+func attachIndependentDisk(ctx context.Context, t *testing.T, client *govmomi.Client, vmName, datastorePath string) *object.VirtualMachine {
+	finder := find.NewFinder(client.Client)
+	dc, err := finder.Datacenter(ctx, "DC0")
+	require.NoError(t, err)
+	vm, err := finder.SetDatacenter(dc).VirtualMachine(ctx, vmName)
+	require.NoError(t, err)
+	devices, err := vm.Device(ctx)
+	require.NoError(t, err)
+	controller, err := devices.FindDiskController("")
+	require.NoError(t, err)
+
+	disk := devices.CreateDisk(controller, types.ManagedObjectReference{}, datastorePath)
+	disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo).DiskMode = string(types.VirtualDiskModeIndependent_persistent)
+	require.NoError(t, vm.AddDevice(ctx, disk))
+	return vm
+}
@@ -0,0 +1,17 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package vcenter
+
+import (
+	"context"
+
+	"github.com/vmware/govmomi"
+)
+
+// UnderlyingClient exposes the session recovering govmomi client to the vcenter_test package
+func (c *Client) UnderlyingClient(ctx context.Context) (*govmomi.Client, error) {
+	return c.getOrCreateUnderlyingClient(ctx)
+}
@@ -14,12 +14,15 @@ import (
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
+	"path"
+	"strings"
 )
 
 type Finder struct {
 	Datacenter string
 	client     *govmomi.Client
 	finder     *find.Finder
+	datacenter *object.Datacenter
 }
 
 func NewFinder(datacenter string, client *govmomi.Client) *Finder {
@@ -39,7 +42,7 @@ func (f *Finder) HostsInCluster(ctx context.Context, clusterName string) ([]*obj
 	}
 
 	l.Debugf("Finding cluster %s", clusterName)
-	destinationCluster, err := finder.ClusterComputeResource(ctx, clusterName)
+	destinationCluster, err := finder.ClusterComputeResource(ctx, f.inventoryPath(clusterName))
 	if err != nil {
 		return nil, fmt.Errorf("failed to find cluster %s: %w", clusterName, err)
 	}
@@ -60,7 +63,7 @@ func (f *Finder) VirtualMachine(ctx context.Context, vmNameOrPath string) (*obje
 		return nil, err
 	}
 
-	vm, err := finder.VirtualMachine(ctx, vmNameOrPath)
+	vm, err := finder.VirtualMachine(ctx, f.inventoryPath(vmNameOrPath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to find virtual machine %s: %w", vmNameOrPath, err)
 	}
@@ -76,7 +79,7 @@ func (f *Finder) ResourcePool(ctx context.Context, fullyQualifiedResourcePoolNam
 		return nil, err
 	}
 
-	resourcePool, err := finder.ResourcePool(ctx, fullyQualifiedResourcePoolName)
+	resourcePool, err := finder.ResourcePool(ctx, f.inventoryPath(fullyQualifiedResourcePoolName))
 	if err != nil {
 		return nil, fmt.Errorf("failed to find resource pool %s: %w", fullyQualifiedResourcePoolName, err)
 	}
@@ -109,7 +112,7 @@ func (f *Finder) Datastore(ctx context.Context, datastoreName string) (*object.D
 		return nil, err
 	}
 
-	ds, err := finder.Datastore(ctx, datastoreName)
+	ds, err := finder.Datastore(ctx, f.inventoryPath(datastoreName))
 	if err != nil {
 		return nil, fmt.Errorf("failed to find datastore %s: %w", datastoreName, err)
 	}
@@ -171,10 +174,12 @@ func (f *Finder) Cluster(ctx context.Context, clusterName string) (*object.Clust
 		return nil, err
 	}
 
-	return finder.ClusterComputeResource(ctx, clusterName)
+	return finder.ClusterComputeResource(ctx, f.inventoryPath(clusterName))
 }
 
-func (f *Finder) VMClusterName(ctx context.Context, vm *object.VirtualMachine) (string, error) {
+// VMClusterPath returns the full inventory path of the cluster the VM's host belongs to, so same named
+// clusters in different host folders are never mistaken for each other
+func (f *Finder) VMClusterPath(ctx context.Context, vm *object.VirtualMachine) (string, error) {
 	l := log.FromContext(ctx)
 	l.Debugf("Getting VM %s cluster", vm.Name())
 
@@ -200,19 +205,19 @@ func (f *Finder) VMClusterName(ctx context.Context, vm *object.VirtualMachine) (
 		return "", fmt.Errorf("failed to get VM %s cluster reference for host %s", vm.Name(), mh.Name)
 	}
 
-	var clusterName string
+	var clusterPath string
 	switch t := clusterRef.(type) {
 	case *object.ClusterComputeResource:
-		clusterName = (clusterRef.(*object.ClusterComputeResource)).Name()
+		clusterPath = t.InventoryPath
 	default:
 		return "", fmt.Errorf("found unsupported compute type %s", t)
 	}
 
-	if clusterName == "" {
-		return "", fmt.Errorf("should never happen, but found an empty cluster name for %s", clusterRef.Reference().Value)
+	if clusterPath == "" {
+		return "", fmt.Errorf("should never happen, but found an empty cluster path for %s", clusterRef.Reference().Value)
 	}
 
-	return clusterName, nil
+	return clusterPath, nil
 }
 
 func (f *Finder) Networks(ctx context.Context, vm *object.VirtualMachine) ([]string, error) {
@@ -258,13 +263,28 @@ func (f *Finder) Networks(ctx context.Context, vm *object.VirtualMachine) ([]str
 	return nets, nil
 }
 
+// MemoryMB returns the VM's configured memory size
+func (f *Finder) MemoryMB(ctx context.Context, vm *object.VirtualMachine) (int32, error) {
+	log.FromContext(ctx).Debugf("Getting VM %s memory size", vm.Name())
+
+	var o mo.VirtualMachine
+	err := vm.Properties(ctx, vm.Reference(), []string{"config.hardware.memoryMB"}, &o)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get VM %s memory size: %w", vm.Name(), err)
+	}
+	if o.Config == nil {
+		return 0, nil
+	}
+	return o.Config.Hardware.MemoryMB, nil
+}
+
 func (f *Finder) AdapterBackingInfo(ctx context.Context, networkName string) (types.BaseVirtualDeviceBackingInfo, error) {
 	finder, err := f.getUnderlyingFinderOrCreate(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	network, err := finder.Network(ctx, networkName)
+	network, err := finder.Network(ctx, f.inventoryPath(networkName))
 	if err != nil {
 		return nil, fmt.Errorf("failed to find target network %s: %w", networkName, err)
 	}
@@ -288,12 +308,12 @@ func (f *Finder) Adapter(ctx context.Context, vmNameOrPath, networkName string)
 		return nil, err
 	}
 
-	network, err := finder.Network(ctx, networkName)
+	network, err := finder.Network(ctx, f.inventoryPath(networkName))
 	if err != nil {
 		return nil, fmt.Errorf("failed to find target network %s: %w", networkName, err)
 	}
 
-	vm, err := finder.VirtualMachine(ctx, vmNameOrPath)
+	vm, err := finder.VirtualMachine(ctx, f.inventoryPath(vmNameOrPath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to find VM %s: %w", vmNameOrPath, err)
 	}
@@ -349,7 +369,33 @@ func (f *Finder) Folder(ctx context.Context, folderPath string) (*object.Folder,
 	if err != nil {
 		return nil, err
 	}
-	return finder.Folder(ctx, folderPath)
+	return finder.Folder(ctx, f.inventoryPath(folderPath))
+}
+
+func (f *Finder) DatacenterObject(ctx context.Context) (*object.Datacenter, error) {
+	_, err := f.getUnderlyingFinderOrCreate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return f.datacenter, nil
+}
+
+// DatacenterPath returns the datacenter's inventory path, e.g. /Region1/DC1 for a datacenter nested in a folder
+func (f *Finder) DatacenterPath(ctx context.Context) (string, error) {
+	_, err := f.getUnderlyingFinderOrCreate(ctx)
+	if err != nil {
+		return "", err
+	}
+	return f.datacenter.InventoryPath, nil
+}
+
+// inventoryPath resolves an absolute path starting with the datacenter name or path to the datacenter's inventory
+// path, names and relative paths are unchanged
+func (f *Finder) inventoryPath(nameOrPath string) string {
+	if f.datacenter == nil {
+		return nameOrPath
+	}
+	return resolveDatacenterPath(nameOrPath, f.Datacenter, f.datacenter.InventoryPath)
 }
 
 func (f *Finder) getUnderlyingFinderOrCreate(ctx context.Context) (*find.Finder, error) {
@@ -365,5 +411,25 @@ func (f *Finder) getUnderlyingFinderOrCreate(ctx context.Context) (*find.Finder,
 	finder.SetDatacenter(destinationDataCenter)
 
 	f.finder = finder
+	f.datacenter = destinationDataCenter
 	return f.finder, nil
 }
+
+// resolveDatacenterPath replaces the datacenter name or path at the start of an absolute path with the datacenter's
+// inventory path, so /DC1/vm/pcf_vms is found when DC1 is nested in folders at /Region1/DC1
+func resolveDatacenterPath(p, datacenter, datacenterPath string) string {
+	if !strings.HasPrefix(p, "/") {
+		return p
+	}
+	p = path.Clean(p)
+	datacenterPath = "/" + strings.Trim(datacenterPath, "/")
+	if strings.HasPrefix(p+"/", datacenterPath+"/") {
+		return p
+	}
+	for _, dc := range []string{"/" + strings.Trim(datacenter, "/"), "/" + path.Base(datacenterPath)} {
+		if dc != "/" && (p == dc || strings.HasPrefix(p, dc+"/")) {
+			return datacenterPath + strings.TrimPrefix(p, dc)
+		}
+	}
+	return p
+}
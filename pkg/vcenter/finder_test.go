@@ -68,28 +68,67 @@ func TestCluster(t *testing.T) {
 	})
 }
 
-func TestVMClusterName(t *testing.T) {
+func TestNestedInventoryPaths(t *testing.T) {
+	NestedVPXTest(func(ctx context.Context, client *govmomi.Client) {
+		for _, dc := range []string{"DC0", "F0/DC0", "/F0/DC0"} {
+			finder := vcenter.NewFinder(dc, client)
+
+			dcPath, err := finder.DatacenterPath(ctx)
+			require.NoError(t, err, dc)
+			require.Equal(t, "/F0/DC0", dcPath, dc)
+
+			for _, name := range []string{"DC0_C0", "F0/DC0_C0", "/F0/DC0/host/F0/DC0_C0", "/DC0/host/F0/DC0_C0"} {
+				cluster, err := finder.Cluster(ctx, name)
+				require.NoError(t, err, name)
+				require.Equal(t, "/F0/DC0/host/F0/DC0_C0", cluster.InventoryPath)
+
+				hosts, err := finder.HostsInCluster(ctx, name)
+				require.NoError(t, err, name)
+				require.Len(t, hosts, 3)
+			}
+
+			rp, err := finder.ResourcePool(ctx, "/DC0/host/F0/DC0_C0/Resources/DC0_C0_RP1")
+			require.NoError(t, err, dc)
+			require.Equal(t, "DC0_C0_RP1", rp.Name())
+
+			for _, name := range []string{"DC0_C0_RP1_VM0", "/DC0/vm/F0/DC0_C0_RP1_VM0", "/F0/DC0/vm/F0/DC0_C0_RP1_VM0"} {
+				vm, err := finder.VirtualMachine(ctx, name)
+				require.NoError(t, err, name)
+				require.Equal(t, "/F0/DC0/vm/F0/DC0_C0_RP1_VM0", vm.InventoryPath)
+			}
+
+			folder, err := finder.Folder(ctx, "/DC0/vm/F0")
+			require.NoError(t, err, dc)
+			require.Equal(t, "/F0/DC0/vm/F0", folder.InventoryPath)
+
+			_, err = finder.Datastore(ctx, "LocalDS_0")
+			require.NoError(t, err, dc)
+		}
+	})
+}
+
+func TestVMClusterPath(t *testing.T) {
 	VPXTest(func(ctx context.Context, client *govmomi.Client) {
 		finder := vcenter.NewFinder("DC0", client)
 
 		t.Run("Find cluster", func(t *testing.T) {
 			vm0, err := finder.VirtualMachine(ctx, "DC0_C0_RP1_VM0")
 			require.NoError(t, err)
-			cluster, err := finder.VMClusterName(ctx, vm0)
+			cluster, err := finder.VMClusterPath(ctx, vm0)
 			require.NoError(t, err)
-			require.Equal(t, "DC0_C0", cluster)
+			require.Equal(t, "/DC0/host/DC0_C0", cluster)
 
 			vm1, err := finder.VirtualMachine(ctx, "DC0_C0_RP1_VM1")
 			require.NoError(t, err)
-			cluster, err = finder.VMClusterName(ctx, vm1)
+			cluster, err = finder.VMClusterPath(ctx, vm1)
 			require.NoError(t, err)
-			require.Equal(t, "DC0_C0", cluster)
+			require.Equal(t, "/DC0/host/DC0_C0", cluster)
 		})
 
 		t.Run("Non-existent cluster", func(t *testing.T) {
 			vm0, err := finder.VirtualMachine(ctx, "DC0_H0_VM0")
 			require.NoError(t, err)
-			_, err = finder.VMClusterName(ctx, vm0)
+			_, err = finder.VMClusterPath(ctx, vm0)
 			require.Error(t, err)
 			require.Contains(t, err.Error(), "found unsupported compute type ComputeResource")
 		})
@@ -12,17 +12,31 @@ import (
 	"sync"
 	"time"
 
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/inventory"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
-	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
 	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// the custom attributes the BOSH vSphere CPI sets on the VMs it creates, older CPIs set job instead of instance_group
+const (
+	boshDeploymentField    = "deployment"
+	boshInstanceGroupField = "instance_group"
+	boshJobField           = "job"
 )
 
 type hostRef struct {
 	host         *object.HostSystem
+	clusterPath  string
 	leaseCount   int
 	leaseStart   time.Time
 	leaseRelease time.Time
+
+	// number of VMs placed on the host from each anti-affinity group
+	groups map[string]int
 }
 
 func (hr *hostRef) StartLease() {
@@ -85,10 +99,29 @@ func (hp *HostPool) Initialize(ctx context.Context) error {
 	return hp.initErr
 }
 
+// HostLeaseRequest describes the VM a target host is leased for
+type HostLeaseRequest struct {
+	AZ string
+
+	// Cluster limits the hosts to a single AZ cluster, empty considers the hosts in all the AZ's clusters
+	Cluster string
+
+	// AntiAffinityGroup spreads VMs in the same group, like the members of a BOSH instance group, across
+	// different hosts when possible, empty places the VM without regard to other VMs
+	AntiAffinityGroup string
+}
+
 // LeaseAvailableHost returns the best host system to copy a VM to
 // If no hosts are currently available a nil host will be returned, the caller should wait and retry later
 // Release should be called by the caller when done with the host
 func (hp *HostPool) LeaseAvailableHost(ctx context.Context, azName string) (*object.HostSystem, error) {
+	return hp.LeaseHost(ctx, HostLeaseRequest{AZ: azName})
+}
+
+// LeaseHost is the same as LeaseAvailableHost but only considers the hosts in any requested cluster. When the
+// request has an anti-affinity group, hosts with the fewest VMs from that group are preferred, and a nil host is
+// returned while the only available hosts have more group VMs than a currently leased host
+func (hp *HostPool) LeaseHost(ctx context.Context, req HostLeaseRequest) (*object.HostSystem, error) {
 	hp.leaseMutex.Lock()
 	defer hp.leaseMutex.Unlock()
 
@@ -97,10 +130,21 @@ func (hp *HostPool) LeaseAvailableHost(ctx context.Context, azName string) (*obj
 		return nil, fmt.Errorf("host pool not initialized")
 	}
 
+	azName := req.AZ
 	hostRefs, ok := hp.azToHosts[azName]
 	if !ok {
 		return nil, fmt.Errorf("found no hosts in az %s", azName)
 	}
+	if req.Cluster != "" {
+		hostRefs = hostsInCluster(hostRefs, req.Cluster)
+		if len(hostRefs) == 0 {
+			return nil, fmt.Errorf("found no hosts in az %s cluster %s", azName, req.Cluster)
+		}
+	}
+
+	if req.AntiAffinityGroup != "" {
+		hostRefs = hp.antiAffinityCandidates(ctx, hostRefs, req.AntiAffinityGroup)
+	}
 
 	// find all hosts with the least amount of leases
 	// if none found with zero leases, try hosts with 1 and so on up to max
@@ -130,6 +174,9 @@ func (hp *HostPool) LeaseAvailableHost(ctx context.Context, azName string) (*obj
 	})
 	h := hostCandidates[0]
 	h.StartLease()
+	if req.AntiAffinityGroup != "" {
+		h.groups[req.AntiAffinityGroup]++
+	}
 	return h.host, nil
 }
 
@@ -137,6 +184,11 @@ func (hp *HostPool) LeaseAvailableHost(ctx context.Context, azName string) (*obj
 // If no hosts are currently available this func will block until one is available or configured timeout
 // Release should be called by the caller when done with the host
 func (hp *HostPool) WaitForLeaseAvailableHost(ctx context.Context, azName string) (*object.HostSystem, error) {
+	return hp.WaitForLeaseHost(ctx, HostLeaseRequest{AZ: azName})
+}
+
+// WaitForLeaseHost is the same as WaitForLeaseAvailableHost but leases a host using LeaseHost
+func (hp *HostPool) WaitForLeaseHost(ctx context.Context, req HostLeaseRequest) (*object.HostSystem, error) {
 	timeout := time.After(time.Minute * time.Duration(hp.LeaseWaitTimeoutInMinutes))
 	ticker := time.NewTicker(time.Second * time.Duration(hp.LeaseCheckIntervalInSeconds))
 	defer ticker.Stop()
@@ -145,9 +197,9 @@ func (hp *HostPool) WaitForLeaseAvailableHost(ctx context.Context, azName string
 		select {
 		case <-timeout:
 			return nil, fmt.Errorf("unable to find a target host on az %s after %d minutes, giving up",
-				azName, hp.LeaseWaitTimeoutInMinutes)
+				req.AZ, hp.LeaseWaitTimeoutInMinutes)
 		case <-ticker.C:
-			targetHost, err := hp.LeaseAvailableHost(ctx, azName)
+			targetHost, err := hp.LeaseHost(ctx, req)
 			if err != nil {
 				return nil, err
 			}
@@ -158,8 +210,45 @@ func (hp *HostPool) WaitForLeaseAvailableHost(ctx context.Context, azName string
 	}
 }
 
+// antiAffinityCandidates returns the hosts below the max lease count with the fewest VMs in the group, or none if
+// a fully leased host has fewer group VMs so the caller waits for that host instead of co-locating the VM
+func (hp *HostPool) antiAffinityCandidates(ctx context.Context, hostRefs []*hostRef, group string) []*hostRef {
+	minAll, minAvailable := -1, -1
+	for _, r := range hostRefs {
+		n := r.groups[group]
+		if minAll < 0 || n < minAll {
+			minAll = n
+		}
+		if r.leaseCount < hp.MaxLeasePerHost && (minAvailable < 0 || n < minAvailable) {
+			minAvailable = n
+		}
+	}
+	if minAvailable > minAll {
+		log.FromContext(ctx).Debugf("Waiting for a host with %d %s VMs to become available", minAll, group)
+		return nil
+	}
+
+	var result []*hostRef
+	for _, r := range hostRefs {
+		if r.groups[group] == minAvailable {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
 // Release releases the specified host back into the pool and makes it available for lease again
 func (hp *HostPool) Release(ctx context.Context, host *object.HostSystem) {
+	hp.release(ctx, host, "")
+}
+
+// ReleaseUnused is the same as Release but also stops counting the leased VM in its anti-affinity group, for when
+// the VM didn't end up on the host, e.g. because moving it failed
+func (hp *HostPool) ReleaseUnused(ctx context.Context, host *object.HostSystem, antiAffinityGroup string) {
+	hp.release(ctx, host, antiAffinityGroup)
+}
+
+func (hp *HostPool) release(ctx context.Context, host *object.HostSystem, antiAffinityGroup string) {
 	if host == nil {
 		return
 	}
@@ -174,6 +263,9 @@ func (hp *HostPool) Release(ctx context.Context, host *object.HostSystem) {
 			if hRef.host == host {
 				l.Debugf("Releasing lease on host %s", host.Name())
 				hRef.ReleaseLease()
+				if antiAffinityGroup != "" && hRef.groups[antiAffinityGroup] > 0 {
+					hRef.groups[antiAffinityGroup]--
+				}
 				return
 			}
 		}
@@ -190,18 +282,17 @@ func (hp *HostPool) initializeHostPoolForAZ(ctx context.Context, az string, clus
 		return err
 	}
 
-	finder := find.NewFinder(c.Client)
+	finder := NewFinder(client.Datacenter(), c)
 	l.Debugf("Finding az %s datacenter %s", az, client.Datacenter())
-	destinationDataCenter, err := finder.Datacenter(ctx, client.Datacenter())
+	_, err = finder.DatacenterObject(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to find az %s datacenter %s: %w", az, client.Datacenter(), err)
+		return fmt.Errorf("failed to find az %s datacenter: %w", az, err)
 	}
-	finder.SetDatacenter(destinationDataCenter)
 
-	// only include the clusters explicitly listed in the config
+	// only include the clusters explicitly listed in the config, by name or inventory path
 	var clusters []*object.ClusterComputeResource
 	for _, n := range clusterNames {
-		cc, err := finder.ClusterComputeResource(ctx, n)
+		cc, err := finder.Cluster(ctx, n)
 		if err != nil {
 			return fmt.Errorf("failed to get az %s cluster %s on datacenter %s: %w", az, n, client.Datacenter(), err)
 		}
@@ -219,7 +310,7 @@ func (hp *HostPool) initializeHostPoolForAZ(ctx context.Context, az string, clus
 		// find all the hosts in the cluster that are not in maintenance mode
 		for _, h := range hosts {
 			var hmo mo.HostSystem
-			err = h.Properties(ctx, h.Reference(), []string{"runtime"}, &hmo)
+			err = h.Properties(ctx, h.Reference(), []string{"runtime", "vm"}, &hmo)
 			if err != nil {
 				return fmt.Errorf("could not get runtime properties for host %s: %w", h.Name(), err)
 			}
@@ -227,10 +318,16 @@ func (hp *HostPool) initializeHostPoolForAZ(ctx context.Context, az string, clus
 				l.Debugf("Found host %s in maintenance mode, ignoring", h.Name())
 				continue
 			}
+			groups, err := existingAntiAffinityGroups(ctx, c, hmo.Vm)
+			if err != nil {
+				return fmt.Errorf("could not get the BOSH VMs on host %s: %w", h.Name(), err)
+			}
 			l.Debugf("Adding ESXi host %s to host pool", h.Name())
 			azHosts := hp.azToHosts[az]
 			azHosts = append(azHosts, &hostRef{
-				host: h,
+				host:        h,
+				clusterPath: cluster.InventoryPath,
+				groups:      groups,
 			})
 			hp.azToHosts[az] = azHosts
 		}
@@ -238,3 +335,68 @@ func (hp *HostPool) initializeHostPoolForAZ(ctx context.Context, az string, clus
 
 	return nil
 }
+
+// existingAntiAffinityGroups counts the VMs already on a host in each anti-affinity group, using the BOSH
+// deployment and instance group custom attributes, so VMs migrated by an earlier run are spread against too
+func existingAntiAffinityGroups(ctx context.Context, c *govmomi.Client, vmRefs []types.ManagedObjectReference) (map[string]int, error) {
+	groups := map[string]int{}
+	if len(vmRefs) == 0 {
+		return groups, nil
+	}
+
+	fieldNames, err := customFieldNames(ctx, c)
+	if err != nil || len(fieldNames) == 0 {
+		// without custom attributes there are no BOSH VMs to count
+		log.FromContext(ctx).Debugf("Found no custom attributes to find BOSH VMs: %v", err)
+		return groups, nil
+	}
+
+	var vms []mo.VirtualMachine
+	err = property.DefaultCollector(c.Client).Retrieve(ctx, vmRefs, []string{"customValue"}, &vms)
+	if err != nil {
+		return nil, err
+	}
+	for _, vm := range vms {
+		values := map[string]string{}
+		for _, cv := range vm.CustomValue {
+			if v, ok := cv.(*types.CustomFieldStringValue); ok {
+				values[fieldNames[v.Key]] = v.Value
+			}
+		}
+		instanceGroup := values[boshInstanceGroupField]
+		if instanceGroup == "" {
+			instanceGroup = values[boshJobField]
+		}
+		if group := antiAffinityGroupKey(values[boshDeploymentField], instanceGroup); group != "" {
+			groups[group]++
+		}
+	}
+	return groups, nil
+}
+
+func customFieldNames(ctx context.Context, c *govmomi.Client) (map[int32]string, error) {
+	m, err := object.GetCustomFieldsManager(c.Client)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := m.Field(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := map[int32]string{}
+	for _, f := range fields {
+		names[f.Key] = f.Name
+	}
+	return names, nil
+}
+
+// hostsInCluster returns the hosts in the cluster, which can be a name or an inventory path
+func hostsInCluster(hostRefs []*hostRef, clusterNameOrPath string) []*hostRef {
+	var result []*hostRef
+	for _, r := range hostRefs {
+		if inventory.Same(r.clusterPath, clusterNameOrPath) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
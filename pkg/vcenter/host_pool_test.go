@@ -13,6 +13,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
 	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
 )
 
 func TestLeaseAvailableHost(t *testing.T) {
@@ -117,6 +119,173 @@ func TestLeaseAvailableHostInNonExistentAZ(t *testing.T) {
 	})
 }
 
+func TestLeaseAvailableHostInCluster(t *testing.T) {
+	VPXTest(func(ctx context.Context, client *govmomi.Client) {
+		vcenterClient := vcenter.NewFromGovmomiClient(client, "DC0")
+		azToVCenterMap := map[string]*vcenter.Client{
+			"az1": vcenterClient,
+		}
+		vcenterPool := vcenter.NewPoolWithExternalClients(azToVCenterMap, azToVCenterMap)
+		hpc := &vcenter.HostPoolConfig{
+			AZs: map[string]vcenter.HostPoolAZ{"az1": {
+				Clusters: []string{
+					"DC0_C0",
+				},
+			}},
+		}
+
+		hostPool := vcenter.NewHostPool(vcenterPool, hpc)
+		err := hostPool.Initialize(ctx)
+		require.NoError(t, err)
+
+		host, err := hostPool.LeaseHost(ctx, vcenter.HostLeaseRequest{AZ: "az1", Cluster: "DC0_C0"})
+		require.NoError(t, err)
+		require.NotNil(t, host)
+
+		_, err = hostPool.LeaseHost(ctx, vcenter.HostLeaseRequest{AZ: "az1", Cluster: "DC0_C1"})
+		require.EqualError(t, err, "found no hosts in az az1 cluster DC0_C1")
+	})
+}
+
+func TestLeaseHostAntiAffinity(t *testing.T) {
+	VPXTest(func(ctx context.Context, client *govmomi.Client) {
+		vcenterClient := vcenter.NewFromGovmomiClient(client, "DC0")
+		azToVCenterMap := map[string]*vcenter.Client{
+			"az1": vcenterClient,
+		}
+		vcenterPool := vcenter.NewPoolWithExternalClients(azToVCenterMap, azToVCenterMap)
+		hpc := &vcenter.HostPoolConfig{
+			AZs: map[string]vcenter.HostPoolAZ{"az1": {
+				Clusters: []string{
+					"DC0_C0",
+				},
+			}},
+		}
+
+		hostPool := vcenter.NewHostPool(vcenterPool, hpc)
+		err := hostPool.Initialize(ctx)
+		require.NoError(t, err)
+
+		// each of the 3 consul VMs lands on a different host even though every host is released
+		consul := vcenter.HostLeaseRequest{AZ: "az1", AntiAffinityGroup: "cf/consul"}
+		consulHosts := map[string]bool{}
+		for i := 0; i < 3; i++ {
+			host, err := hostPool.LeaseHost(ctx, consul)
+			require.NoError(t, err)
+			require.NotNil(t, host)
+			consulHosts[host.Name()] = true
+			hostPool.Release(ctx, host)
+		}
+		require.Len(t, consulHosts, 3)
+
+		// a 4th consul VM has to share a host
+		host, err := hostPool.LeaseHost(ctx, consul)
+		require.NoError(t, err)
+		require.NotNil(t, host)
+		hostPool.Release(ctx, host)
+
+		// the first etcd VM goes on one host, then the other two hosts are leased by VMs without a group
+		etcd := vcenter.HostLeaseRequest{AZ: "az1", AntiAffinityGroup: "cf/etcd"}
+		etcdHost, err := hostPool.LeaseHost(ctx, etcd)
+		require.NoError(t, err)
+		hostPool.Release(ctx, etcdHost)
+		for i := 0; i < 2; i++ {
+			host, err = hostPool.LeaseHost(ctx, vcenter.HostLeaseRequest{AZ: "az1"})
+			require.NoError(t, err)
+			require.NotNil(t, host)
+			require.NotEqual(t, etcdHost.Name(), host.Name())
+		}
+
+		// the only available host already has an etcd VM, so wait for another host
+		host, err = hostPool.LeaseHost(ctx, etcd)
+		require.NoError(t, err)
+		require.Nil(t, host)
+	})
+}
+
+func TestLeaseHostAntiAffinityReleaseUnused(t *testing.T) {
+	VPXTest(func(ctx context.Context, client *govmomi.Client) {
+		vcenterClient := vcenter.NewFromGovmomiClient(client, "DC0")
+		azToVCenterMap := map[string]*vcenter.Client{
+			"az1": vcenterClient,
+		}
+		vcenterPool := vcenter.NewPoolWithExternalClients(azToVCenterMap, azToVCenterMap)
+		hpc := &vcenter.HostPoolConfig{
+			AZs: map[string]vcenter.HostPoolAZ{"az1": {
+				Clusters: []string{
+					"DC0_C0",
+				},
+			}},
+		}
+
+		hostPool := vcenter.NewHostPool(vcenterPool, hpc)
+		err := hostPool.Initialize(ctx)
+		require.NoError(t, err)
+
+		// one consul VM on each host
+		consul := vcenter.HostLeaseRequest{AZ: "az1", AntiAffinityGroup: "cf/consul"}
+		for i := 0; i < 3; i++ {
+			host, err := hostPool.LeaseHost(ctx, consul)
+			require.NoError(t, err)
+			hostPool.Release(ctx, host)
+		}
+
+		// a failed move doesn't leave a second consul VM counted on the host
+		host, err := hostPool.LeaseHost(ctx, consul)
+		require.NoError(t, err)
+		hostPool.ReleaseUnused(ctx, host, consul.AntiAffinityGroup)
+		for i := 0; i < 3; i++ {
+			host, err = hostPool.LeaseHost(ctx, consul)
+			require.NoError(t, err)
+			require.NotNil(t, host)
+		}
+	})
+}
+
+func TestLeaseHostAntiAffinityCountsExistingVMs(t *testing.T) {
+	VPXTest(func(ctx context.Context, client *govmomi.Client) {
+		// an already migrated consul VM tagged by the BOSH CPI
+		vm, err := find.NewFinder(client.Client).VirtualMachine(ctx, "/DC0/vm/DC0_C0_RP1_VM0")
+		require.NoError(t, err)
+		existingHost, err := vm.HostSystem(ctx)
+		require.NoError(t, err)
+		fields, err := object.GetCustomFieldsManager(client.Client)
+		require.NoError(t, err)
+		for name, value := range map[string]string{"deployment": "cf", "instance_group": "consul"} {
+			def, err := fields.Add(ctx, name, "VirtualMachine", nil, nil)
+			require.NoError(t, err)
+			require.NoError(t, fields.Set(ctx, vm.Reference(), def.Key, value))
+		}
+
+		vcenterClient := vcenter.NewFromGovmomiClient(client, "DC0")
+		azToVCenterMap := map[string]*vcenter.Client{
+			"az1": vcenterClient,
+		}
+		vcenterPool := vcenter.NewPoolWithExternalClients(azToVCenterMap, azToVCenterMap)
+		hpc := &vcenter.HostPoolConfig{
+			AZs: map[string]vcenter.HostPoolAZ{"az1": {
+				Clusters: []string{
+					"DC0_C0",
+				},
+			}},
+		}
+
+		hostPool := vcenter.NewHostPool(vcenterPool, hpc)
+		err = hostPool.Initialize(ctx)
+		require.NoError(t, err)
+
+		// the next two consul VMs go on the hosts without the existing consul VM
+		consul := vcenter.HostLeaseRequest{AZ: "az1", AntiAffinityGroup: "cf/consul"}
+		for i := 0; i < 2; i++ {
+			host, err := hostPool.LeaseHost(ctx, consul)
+			require.NoError(t, err)
+			require.NotNil(t, host)
+			require.NotEqual(t, existingHost.Reference(), host.Reference())
+			hostPool.Release(ctx, host)
+		}
+	})
+}
+
 func TestLeaseAvailableHostSkipsHostsInMaintenanceMode(t *testing.T) {
 	VPXTest(func(ctx context.Context, client *govmomi.Client) {
 		// put first host in maintenance mode
@@ -154,3 +323,62 @@ func TestLeaseAvailableHostSkipsHostsInMaintenanceMode(t *testing.T) {
 		require.Nil(t, host)
 	})
 }
+
+func TestHostPoolNestedCluster(t *testing.T) {
+	NestedVPXTest(func(ctx context.Context, client *govmomi.Client) {
+		vcenterClient := vcenter.NewFromGovmomiClient(client, "DC0")
+		azToVCenterMap := map[string]*vcenter.Client{
+			"az1": vcenterClient,
+		}
+		vcenterPool := vcenter.NewPoolWithExternalClients(azToVCenterMap, azToVCenterMap)
+		hpc := &vcenter.HostPoolConfig{
+			AZs: map[string]vcenter.HostPoolAZ{"az1": {
+				Clusters: []string{
+					"/F0/DC0/host/F0/DC0_C0",
+				},
+			}},
+		}
+
+		hostPool := vcenter.NewHostPool(vcenterPool, hpc)
+		err := hostPool.Initialize(ctx)
+		require.NoError(t, err)
+
+		host, err := hostPool.LeaseAvailableHost(ctx, "az1")
+		require.NoError(t, err)
+		require.NotNil(t, host)
+		require.Contains(t, host.Name(), "DC0_C0_H")
+		hostPool.Release(ctx, host)
+	})
+}
+
+func TestLeaseHostInNestedClusterByPath(t *testing.T) {
+	NestedVPXTest(func(ctx context.Context, client *govmomi.Client) {
+		vcenterClient := vcenter.NewFromGovmomiClient(client, "DC0")
+		azToVCenterMap := map[string]*vcenter.Client{
+			"az1": vcenterClient,
+		}
+		vcenterPool := vcenter.NewPoolWithExternalClients(azToVCenterMap, azToVCenterMap)
+		hpc := &vcenter.HostPoolConfig{
+			AZs: map[string]vcenter.HostPoolAZ{"az1": {
+				Clusters: []string{
+					"/F0/DC0/host/F0/DC0_C0",
+				},
+			}},
+		}
+
+		hostPool := vcenter.NewHostPool(vcenterPool, hpc)
+		err := hostPool.Initialize(ctx)
+		require.NoError(t, err)
+
+		for _, cluster := range []string{"/F0/DC0/host/F0/DC0_C0", "/DC0/host/F0/DC0_C0", "F0/DC0_C0", "DC0_C0"} {
+			host, err := hostPool.LeaseHost(ctx, vcenter.HostLeaseRequest{AZ: "az1", Cluster: cluster})
+			require.NoError(t, err, cluster)
+			require.NotNil(t, host, cluster)
+			require.Contains(t, host.Name(), "DC0_C0_H")
+			hostPool.Release(ctx, host)
+		}
+
+		_, err = hostPool.LeaseHost(ctx, vcenter.HostLeaseRequest{AZ: "az1", Cluster: "F1/DC0_C0"})
+		require.EqualError(t, err, "found no hosts in az az1 cluster F1/DC0_C0")
+	})
+}
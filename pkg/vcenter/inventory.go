@@ -0,0 +1,197 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package vcenter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+)
+
+// rootResourcePool is the name of every cluster's hidden top level resource pool
+const rootResourcePool = "Resources"
+
+// Inventory is the names of the compute, storage and network objects in a vCenter datacenter
+type Inventory struct {
+	Clusters      []string
+	ResourcePools []string
+	Datastores    []string
+	Networks      []string
+}
+
+// FindVM returns the named VM's placement, disks and networks
+func (c *Client) FindVM(ctx context.Context, azName, vmNameOrPath string) (*VM, error) {
+	vm, err := c.findVM(ctx, azName, vmNameOrPath)
+	if err != nil {
+		return nil, err
+	}
+	if vm.ResourcePool == rootResourcePool {
+		vm.ResourcePool = ""
+	}
+	return vm, nil
+}
+
+// Inventory returns the sorted names of all clusters, resource pools, datastores and networks in the datacenter,
+// including any nested in folders
+func (c *Client) Inventory(ctx context.Context) (*Inventory, error) {
+	l := log.FromContext(ctx)
+
+	client, err := c.getOrCreateUnderlyingClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	f := NewFinder(c.Datacenter(), client)
+	finder, err := f.getUnderlyingFinderOrCreate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Debugf("Listing datacenter %s inventory", c.Datacenter())
+	inv := &Inventory{}
+
+	clusters, err := finder.ClusterComputeResourceList(ctx, "./...")
+	if err != nil && !isNotFound(err) {
+		return nil, fmt.Errorf("failed to list clusters: %w", err)
+	}
+	for _, cl := range clusters {
+		inv.Clusters = append(inv.Clusters, cl.Name())
+	}
+
+	pools, err := finder.ResourcePoolList(ctx, "./...")
+	if err != nil && !isNotFound(err) {
+		return nil, fmt.Errorf("failed to list resource pools: %w", err)
+	}
+	for _, rp := range pools {
+		if rp.Name() != rootResourcePool {
+			inv.ResourcePools = append(inv.ResourcePools, rp.Name())
+		}
+	}
+
+	datastores, err := finder.DatastoreList(ctx, "./...")
+	if err != nil && !isNotFound(err) {
+		return nil, fmt.Errorf("failed to list datastores: %w", err)
+	}
+	for _, ds := range datastores {
+		inv.Datastores = append(inv.Datastores, ds.Name())
+	}
+
+	networks, err := finder.NetworkList(ctx, "./...")
+	if err != nil && !isNotFound(err) {
+		return nil, fmt.Errorf("failed to list networks: %w", err)
+	}
+	for _, n := range networks {
+		name, err := networkName(n)
+		if err != nil {
+			return nil, err
+		}
+		inv.Networks = append(inv.Networks, name)
+	}
+
+	inv.Clusters = sortedUnique(inv.Clusters)
+	inv.ResourcePools = sortedUnique(inv.ResourcePools)
+	inv.Datastores = sortedUnique(inv.Datastores)
+	inv.Networks = sortedUnique(inv.Networks)
+	return inv, nil
+}
+
+// ClusterInventory returns the clusters and the sorted names of the datastores and networks attached to them, the
+// clusters can be names or inventory paths
+func (c *Client) ClusterInventory(ctx context.Context, clusters []string) (*Inventory, error) {
+	l := log.FromContext(ctx)
+
+	client, err := c.getOrCreateUnderlyingClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	f := NewFinder(c.Datacenter(), client)
+	finder, err := f.getUnderlyingFinderOrCreate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &Inventory{}
+	for _, name := range clusters {
+		cluster, err := f.Cluster(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find cluster %s: %w", name, err)
+		}
+		l.Debugf("Listing cluster %s datastores and networks", name)
+		var o mo.ClusterComputeResource
+		err = cluster.Properties(ctx, cluster.Reference(), []string{"datastore", "network"}, &o)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cluster %s datastores and networks: %w", name, err)
+		}
+		inv.Clusters = append(inv.Clusters, cluster.Name())
+
+		for _, ref := range o.Datastore {
+			r, err := finder.ObjectReference(ctx, ref)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get %s datastore reference", ref.Value)
+			}
+			if ds, ok := r.(*object.Datastore); ok {
+				inv.Datastores = append(inv.Datastores, ds.Name())
+			}
+		}
+		for _, ref := range o.Network {
+			r, err := finder.ObjectReference(ctx, ref)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get %s network reference", ref.Value)
+			}
+			n, ok := r.(object.NetworkReference)
+			if !ok {
+				continue
+			}
+			name, err := networkName(n)
+			if err != nil {
+				return nil, err
+			}
+			inv.Networks = append(inv.Networks, name)
+		}
+	}
+
+	inv.Clusters = sortedUnique(inv.Clusters)
+	inv.Datastores = sortedUnique(inv.Datastores)
+	inv.Networks = sortedUnique(inv.Networks)
+	return inv, nil
+}
+
+func networkName(n object.NetworkReference) (string, error) {
+	switch t := n.(type) {
+	case *object.DistributedVirtualPortgroup:
+		return t.Name(), nil
+	case *object.Network:
+		return t.Name(), nil
+	case *object.OpaqueNetwork:
+		return t.Name(), nil
+	case *object.DistributedVirtualSwitch, *object.VmwareDistributedVirtualSwitch:
+		// switches aren't networks a VM can be attached to
+		return "", nil
+	}
+	return "", fmt.Errorf("found unsupported network type %T", n)
+}
+
+func isNotFound(err error) bool {
+	_, ok := err.(*find.NotFoundError)
+	return ok
+}
+
+func sortedUnique(names []string) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, n := range names {
+		if n != "" && !seen[n] {
+			seen[n] = true
+			result = append(result, n)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
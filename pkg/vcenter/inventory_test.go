@@ -0,0 +1,89 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package vcenter_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+	"github.com/vmware/govmomi"
+)
+
+func TestInventory(t *testing.T) {
+	VPXTest(func(ctx context.Context, client *govmomi.Client) {
+		c := vcenter.NewFromGovmomiClient(client, "DC0")
+		inv, err := c.Inventory(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []string{"DC0_C0"}, inv.Clusters)
+		require.Equal(t, []string{"DC0_C0_RP1"}, inv.ResourcePools)
+		require.Equal(t, []string{"LocalDS_0"}, inv.Datastores)
+		require.Equal(t, []string{"DC0_DVPG0", "DVS0-DVUplinks-9", "VM Network"}, inv.Networks)
+	})
+}
+
+func TestClusterInventory(t *testing.T) {
+	VPXTest(func(ctx context.Context, client *govmomi.Client) {
+		c := vcenter.NewFromGovmomiClient(client, "DC0")
+		inv, err := c.ClusterInventory(ctx, []string{"DC0_C0"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"DC0_C0"}, inv.Clusters)
+		require.Equal(t, []string{"LocalDS_0"}, inv.Datastores)
+		require.Contains(t, inv.Networks, "DC0_DVPG0")
+
+		_, err = c.ClusterInventory(ctx, []string{"does-not-exist"})
+		require.Error(t, err)
+	})
+}
+
+func TestFindVM(t *testing.T) {
+	VPXTest(func(ctx context.Context, client *govmomi.Client) {
+		c := vcenter.NewFromGovmomiClient(client, "DC0")
+		vm, err := c.FindVM(ctx, "az1", "DC0_C0_RP1_VM0")
+		require.NoError(t, err)
+		require.Equal(t, "az1", vm.AZ)
+		require.Equal(t, "/DC0/host/DC0_C0", vm.Cluster)
+		require.Equal(t, "DC0_C0_RP1", vm.ResourcePool)
+		require.Equal(t, []string{"DC0_DVPG0"}, vm.Networks)
+		require.Len(t, vm.Disks, 1)
+		require.Equal(t, "LocalDS_0", vm.Disks[0].Datastore)
+		require.Equal(t, int32(32), vm.MemoryMB)
+
+		_, err = c.FindVM(ctx, "az1", "does-not-exist")
+		var e *vcenter.VMNotFoundError
+		require.ErrorAs(t, err, &e)
+	})
+}
+
+func TestClusterFreeMemoryMB(t *testing.T) {
+	VPXTest(func(ctx context.Context, client *govmomi.Client) {
+		c := vcenter.NewFromGovmomiClient(client, "DC0")
+		free, err := c.ClusterFreeMemoryMB(ctx, "DC0_C0")
+		require.NoError(t, err)
+		require.Greater(t, free, int64(0))
+
+		_, err = c.ClusterFreeMemoryMB(ctx, "does-not-exist")
+		require.Error(t, err)
+	})
+}
+
+func TestVMNICs(t *testing.T) {
+	VPXTest(func(ctx context.Context, client *govmomi.Client) {
+		c := vcenter.NewFromGovmomiClient(client, "DC0")
+		nics, poweredOn, err := c.VMNICs(ctx, "DC0_C0_RP1_VM0")
+		require.NoError(t, err)
+		require.True(t, poweredOn)
+		require.Len(t, nics, 1)
+		require.Equal(t, "ethernet-0", nics[0].Label)
+		require.Equal(t, "DC0_DVPG0", nics[0].Network)
+		require.True(t, nics[0].Connected)
+		require.True(t, nics[0].StartConnected)
+
+		_, _, err = c.VMNICs(ctx, "does-not-exist")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,286 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package vcenter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/task"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// ClusterVM is a VM found running on one of a cluster's hosts
+type ClusterVM struct {
+	Name    string
+	Cluster string
+	Folder  string
+}
+
+// VMFolder is a VM folder and the number of VMs, templates and folders it directly contains
+type VMFolder struct {
+	Path     string
+	Children int
+}
+
+// Empty returns true if the folder doesn't contain anything
+func (f VMFolder) Empty() bool {
+	return f.Children == 0
+}
+
+// ClusterVMs returns the name, cluster and folder of every VM on the clusters' hosts, sorted by name
+func (c *Client) ClusterVMs(ctx context.Context, clusters []string) ([]ClusterVM, error) {
+	l := log.FromContext(ctx)
+
+	client, err := c.getOrCreateUnderlyingClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	f := NewFinder(c.Datacenter(), client)
+	finder, err := f.getUnderlyingFinderOrCreate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hostClusters := map[types.ManagedObjectReference]string{}
+	for _, cluster := range clusters {
+		hosts, err := f.HostsInCluster(ctx, cluster)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range hosts {
+			hostClusters[h.Reference()] = cluster
+		}
+	}
+
+	l.Debugf("Listing VMs in clusters %s", strings.Join(clusters, ", "))
+	vms, err := finder.VirtualMachineList(ctx, f.inventoryPath("/"+c.Datacenter()+"/vm")+"/...")
+	if err != nil && !isNotFound(err) {
+		return nil, fmt.Errorf("failed to list VMs: %w", err)
+	}
+	if len(vms) == 0 {
+		return nil, nil
+	}
+
+	var refs []types.ManagedObjectReference
+	paths := map[types.ManagedObjectReference]string{}
+	for _, vm := range vms {
+		refs = append(refs, vm.Reference())
+		paths[vm.Reference()] = vm.InventoryPath
+	}
+	var props []mo.VirtualMachine
+	err = property.DefaultCollector(client.Client).Retrieve(ctx, refs, []string{"name", "runtime.host"}, &props)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VM hosts: %w", err)
+	}
+
+	var result []ClusterVM
+	for _, p := range props {
+		if p.Runtime.Host == nil {
+			continue
+		}
+		cluster, ok := hostClusters[*p.Runtime.Host]
+		if !ok {
+			continue
+		}
+		result = append(result, ClusterVM{
+			Name:    p.Name,
+			Cluster: cluster,
+			Folder:  path.Dir(paths[p.Reference()]),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// VMFolders returns every folder nested under the folder path, sorted by path
+func (c *Client) VMFolders(ctx context.Context, folderPath string) ([]VMFolder, error) {
+	l := log.FromContext(ctx)
+
+	client, err := c.getOrCreateUnderlyingClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	f := NewFinder(c.Datacenter(), client)
+	finder, err := f.getUnderlyingFinderOrCreate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	folderPath = f.inventoryPath(strings.TrimSuffix(folderPath, "/"))
+	l.Debugf("Listing folders under %s", folderPath)
+	folders, err := finder.FolderList(ctx, folderPath+"/...")
+	if err != nil && !isNotFound(err) {
+		return nil, fmt.Errorf("failed to list folders under %s: %w", folderPath, err)
+	}
+
+	var result []VMFolder
+	for _, folder := range folders {
+		if folder.InventoryPath == folderPath {
+			continue
+		}
+		var o mo.Folder
+		err = folder.Properties(ctx, folder.Reference(), []string{"childEntity"}, &o)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get folder %s contents: %w", folder.InventoryPath, err)
+		}
+		result = append(result, VMFolder{
+			Path:     folder.InventoryPath,
+			Children: len(o.ChildEntity),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result, nil
+}
+
+// deletingFolderSuffix is appended to a folder's name while it's being deleted
+const deletingFolderSuffix = "-vmotion4bosh-deleting"
+
+// DeleteFolder deletes the folder, which must be empty. vSphere has no delete if empty operation and destroying a
+// folder destroys everything in it, so the folder is renamed first, which stops the vSphere CPI from placing
+// anything in it as the CPI finds folders by path, then it's checked again and only destroyed if it's still empty.
+// A folder that's no longer empty is renamed back. Anything moved into the folder by reference between that check and
+// the destroy is destroyed with it, so only delete folders nothing is being migrated or deployed into.
+func (c *Client) DeleteFolder(ctx context.Context, folderPath string) error {
+	l := log.FromContext(ctx)
+
+	client, err := c.getOrCreateUnderlyingClient(ctx)
+	if err != nil {
+		return err
+	}
+	folder, err := NewFinder(c.Datacenter(), client).Folder(ctx, folderPath)
+	if err != nil {
+		return err
+	}
+	err = checkFolderEmpty(ctx, folder, folderPath)
+	if err != nil {
+		return err
+	}
+
+	name := path.Base(folderPath)
+	err = renameFolder(ctx, folder, name+deletingFolderSuffix)
+	if err != nil {
+		return fmt.Errorf("failed to rename folder %s before deleting it: %w", folderPath, err)
+	}
+	restore := func() {
+		if rerr := renameFolder(ctx, folder, name); rerr != nil {
+			l.Warnf("Could not rename folder %s back from %s: %s", folderPath, name+deletingFolderSuffix, rerr)
+		}
+	}
+	err = checkFolderEmpty(ctx, folder, folderPath)
+	if err != nil {
+		restore()
+		return err
+	}
+
+	l.Debugf("Deleting folder %s", folderPath)
+	t, err := folder.Destroy(ctx)
+	if err != nil {
+		restore()
+		return fmt.Errorf("failed to delete folder %s: %w", folderPath, err)
+	}
+	err = t.Wait(ctx)
+	if err != nil {
+		restore()
+		return fmt.Errorf("error deleting folder %s: %w", folderPath, err)
+	}
+	return nil
+}
+
+func checkFolderEmpty(ctx context.Context, folder *object.Folder, folderPath string) error {
+	var o mo.Folder
+	err := folder.Properties(ctx, folder.Reference(), []string{"childEntity"}, &o)
+	if err != nil {
+		return fmt.Errorf("failed to get folder %s contents: %w", folderPath, err)
+	}
+	if len(o.ChildEntity) > 0 {
+		return fmt.Errorf("folder %s is not empty", folderPath)
+	}
+	return nil
+}
+
+func renameFolder(ctx context.Context, folder *object.Folder, name string) error {
+	t, err := folder.Rename(ctx, name)
+	if err != nil {
+		return err
+	}
+	return t.Wait(ctx)
+}
+
+// DatastoreDisks returns the virtual disks in the datastore directory, an empty list if the directory doesn't exist
+func (c *Client) DatastoreDisks(ctx context.Context, azName, datastore, dir string) ([]DatastoreDisk, error) {
+	l := log.FromContext(ctx)
+
+	client, err := c.getOrCreateUnderlyingClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ds, err := NewFinder(c.Datacenter(), client).Datastore(ctx, datastore)
+	if err != nil {
+		return nil, err
+	}
+	browser, err := ds.Browser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get datastore %s browser: %w", datastore, err)
+	}
+
+	l.Debugf("Listing disks in [%s] %s", datastore, dir)
+	t, err := browser.SearchDatastore(ctx, ds.Path(dir), &types.HostDatastoreBrowserSearchSpec{
+		MatchPattern: []string{"*.vmdk"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search [%s] %s: %w", datastore, dir, err)
+	}
+	info, err := t.WaitForResult(ctx, nil)
+	if err != nil {
+		var noSuchDir object.DatastoreNoSuchDirectoryError
+		if errors.As(err, &noSuchDir) || isFileNotFoundFault(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error searching [%s] %s: %w", datastore, dir, err)
+	}
+
+	var disks []DatastoreDisk
+	results := info.Result.(types.HostDatastoreBrowserSearchResults)
+	for _, f := range results.File {
+		name := f.GetFileInfo().Path
+		// the descriptor file is the disk, the extents hold its data
+		if strings.HasSuffix(name, "-flat.vmdk") || strings.HasSuffix(name, "-delta.vmdk") {
+			continue
+		}
+		disks = append(disks, DatastoreDisk{
+			AZ:         azName,
+			Datacenter: c.Datacenter(),
+			Datastore:  datastore,
+			Path:       path.Join(dir, name),
+		})
+	}
+	sort.Slice(disks, func(i, j int) bool {
+		return disks[i].Path < disks[j].Path
+	})
+	return disks, nil
+}
+
+func isFileNotFoundFault(err error) bool {
+	var te task.Error
+	if errors.As(err, &te) {
+		_, ok := te.Fault().(*types.FileNotFound)
+		return ok
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package vcenter_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+	"github.com/vmware/govmomi"
+)
+
+func TestClusterVMs(t *testing.T) {
+	VPXTest(func(ctx context.Context, client *govmomi.Client) {
+		c := vcenter.NewFromGovmomiClient(client, "DC0")
+		vms, err := c.ClusterVMs(ctx, []string{"DC0_C0"})
+		require.NoError(t, err)
+		require.Equal(t, []vcenter.ClusterVM{
+			{Name: "DC0_C0_RP1_VM0", Cluster: "DC0_C0", Folder: "/DC0/vm"},
+			{Name: "DC0_C0_RP1_VM1", Cluster: "DC0_C0", Folder: "/DC0/vm"},
+		}, vms)
+
+		_, err = c.ClusterVMs(ctx, []string{"does-not-exist"})
+		require.Error(t, err)
+	})
+}
+
+func TestVMFoldersAndDeleteFolder(t *testing.T) {
+	VPXTest(func(ctx context.Context, client *govmomi.Client) {
+		c := vcenter.NewFromGovmomiClient(client, "DC0")
+		require.NoError(t, c.CreateFolder(ctx, "/DC0/vm/pcf_vms/guid1"))
+
+		folders, err := c.VMFolders(ctx, "/DC0/vm/")
+		require.NoError(t, err)
+		require.Equal(t, []vcenter.VMFolder{
+			{Path: "/DC0/vm/pcf_vms", Children: 1},
+			{Path: "/DC0/vm/pcf_vms/guid1", Children: 0},
+		}, folders)
+
+		err = c.DeleteFolder(ctx, "/DC0/vm/pcf_vms")
+		require.EqualError(t, err, "folder /DC0/vm/pcf_vms is not empty")
+		require.NoError(t, c.DeleteFolder(ctx, "/DC0/vm/pcf_vms/guid1"))
+
+		folders, err = c.VMFolders(ctx, "/DC0/vm")
+		require.NoError(t, err)
+		require.Equal(t, []vcenter.VMFolder{{Path: "/DC0/vm/pcf_vms", Children: 0}}, folders)
+	})
+}
+
+func TestClusterVMsAndVMFoldersNested(t *testing.T) {
+	NestedVPXTest(func(ctx context.Context, client *govmomi.Client) {
+		c := vcenter.NewFromGovmomiClient(client, "DC0")
+		vms, err := c.ClusterVMs(ctx, []string{"F0/DC0_C0"})
+		require.NoError(t, err)
+		require.Equal(t, []vcenter.ClusterVM{
+			{Name: "DC0_C0_RP1_VM0", Cluster: "F0/DC0_C0", Folder: "/F0/DC0/vm/F0"},
+			{Name: "DC0_C0_RP1_VM1", Cluster: "F0/DC0_C0", Folder: "/F0/DC0/vm/F0"},
+		}, vms)
+
+		require.NoError(t, c.CreateFolder(ctx, "/DC0/vm/pcf_vms"))
+		folders, err := c.VMFolders(ctx, "/DC0/vm")
+		require.NoError(t, err)
+		require.Equal(t, []vcenter.VMFolder{
+			{Path: "/F0/DC0/vm/F0", Children: 4},
+			{Path: "/F0/DC0/vm/pcf_vms", Children: 0},
+		}, folders)
+		require.NoError(t, c.DeleteFolder(ctx, "/DC0/vm/pcf_vms"))
+	})
+}
+
+func TestDatastoreDisks(t *testing.T) {
+	VPXTest(func(ctx context.Context, client *govmomi.Client) {
+		c := vcenter.NewFromGovmomiClient(client, "DC0")
+		disks, err := c.DatastoreDisks(ctx, "az1", "LocalDS_0", "pcf_disk")
+		require.NoError(t, err)
+		require.Empty(t, disks)
+
+		createOrphanedDisk(ctx, t, client, "[LocalDS_0] pcf_disk/disk-1.vmdk")
+		disks, err = c.DatastoreDisks(ctx, "az1", "LocalDS_0", "pcf_disk")
+		require.NoError(t, err)
+		require.Equal(t, []vcenter.DatastoreDisk{{
+			AZ:         "az1",
+			Datacenter: "DC0",
+			Datastore:  "LocalDS_0",
+			Path:       "pcf_disk/disk-1.vmdk",
+		}}, disks)
+	})
+}
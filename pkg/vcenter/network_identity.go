@@ -0,0 +1,148 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package vcenter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// NetworkIdentity is a network's name and the VLAN or NSX segment backing it, which stays the same when a network
+// is recreated with a different name in another vCenter
+type NetworkIdentity struct {
+	Datacenter string
+	Name       string
+	Switch     string
+	ID         string
+}
+
+// VLANNetworkID returns the ID of a portgroup with a single VLAN
+func VLANNetworkID(vlanID int32) string {
+	return "vlan-" + strconv.Itoa(int(vlanID))
+}
+
+// SegmentNetworkID returns the ID of an NSX segment, either an opaque network or an NSX backed portgroup
+func SegmentNetworkID(logicalSwitchUUID string) string {
+	return "nsx-" + logicalSwitchUUID
+}
+
+// NetworkIdentities returns the identity of every network in the datacenter. Networks without a single VLAN or
+// NSX segment, like trunk portgroups and standard switch portgroups, have an empty ID. Uplink portgroups are skipped.
+func (c *Client) NetworkIdentities(ctx context.Context) ([]NetworkIdentity, error) {
+	l := log.FromContext(ctx)
+
+	client, err := c.getOrCreateUnderlyingClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	f := NewFinder(c.Datacenter(), client)
+	finder, err := f.getUnderlyingFinderOrCreate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Debugf("Listing datacenter %s network VLAN and segment IDs", c.Datacenter())
+	networks, err := finder.NetworkList(ctx, "./...")
+	if err != nil && !isNotFound(err) {
+		return nil, fmt.Errorf("failed to list networks: %w", err)
+	}
+
+	switches := map[string]*mo.DistributedVirtualSwitch{}
+	var result []NetworkIdentity
+	for _, n := range networks {
+		id := NetworkIdentity{
+			Datacenter: c.Datacenter(),
+		}
+		switch t := n.(type) {
+		case *object.DistributedVirtualPortgroup:
+			var pg mo.DistributedVirtualPortgroup
+			err = t.Properties(ctx, t.Reference(), []string{"name", "config"}, &pg)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get portgroup %s config: %w", t.Name(), err)
+			}
+			dvs, err := c.distributedSwitch(ctx, t, pg.Config.DistributedVirtualSwitch, switches)
+			if err != nil {
+				return nil, err
+			}
+			if dvs != nil && isUplink(dvs, pg.Self) {
+				continue
+			}
+			id.Name = pg.Name
+			if dvs != nil {
+				id.Switch = dvs.Name
+			}
+			id.ID = portgroupID(pg.Config)
+		case *object.OpaqueNetwork:
+			var on mo.OpaqueNetwork
+			err = t.Properties(ctx, t.Reference(), []string{"name", "summary"}, &on)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get opaque network %s summary: %w", t.Name(), err)
+			}
+			id.Name = on.Name
+			if s, ok := on.Summary.(*types.OpaqueNetworkSummary); ok {
+				id.ID = SegmentNetworkID(s.OpaqueNetworkId)
+			}
+		case *object.Network:
+			id.Name = t.Name()
+		default:
+			// switches aren't networks a VM can be attached to
+			continue
+		}
+		result = append(result, id)
+	}
+	return result, nil
+}
+
+func (c *Client) distributedSwitch(ctx context.Context, pg *object.DistributedVirtualPortgroup,
+	ref *types.ManagedObjectReference, cache map[string]*mo.DistributedVirtualSwitch) (*mo.DistributedVirtualSwitch, error) {
+
+	if ref == nil {
+		return nil, nil
+	}
+	if dvs, ok := cache[ref.Value]; ok {
+		return dvs, nil
+	}
+	var dvs mo.DistributedVirtualSwitch
+	err := pg.Properties(ctx, *ref, []string{"name", "config"}, &dvs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portgroup %s switch: %w", pg.Name(), err)
+	}
+	cache[ref.Value] = &dvs
+	return &dvs, nil
+}
+
+func isUplink(dvs *mo.DistributedVirtualSwitch, pg types.ManagedObjectReference) bool {
+	if dvs.Config == nil {
+		return false
+	}
+	for _, u := range dvs.Config.GetDVSConfigInfo().UplinkPortgroup {
+		if u.Value == pg.Value {
+			return true
+		}
+	}
+	return false
+}
+
+func portgroupID(config types.DVPortgroupConfigInfo) string {
+	if config.LogicalSwitchUuid != "" {
+		return SegmentNetworkID(config.LogicalSwitchUuid)
+	}
+	setting, ok := config.DefaultPortConfig.(*types.VMwareDVSPortSetting)
+	if !ok || setting.Vlan == nil {
+		return ""
+	}
+	if vlan, ok := setting.Vlan.(*types.VmwareDistributedVirtualSwitchVlanIdSpec); ok {
+		return VLANNetworkID(vlan.VlanId)
+	}
+	// trunk and private VLANs don't identify a single network
+	return ""
+}
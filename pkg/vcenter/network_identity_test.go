@@ -0,0 +1,76 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package vcenter_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestNetworkIdentities(t *testing.T) {
+	VPXTest(func(ctx context.Context, client *govmomi.Client) {
+		finder := find.NewFinder(client.Client)
+		dc, err := finder.Datacenter(ctx, "DC0")
+		require.NoError(t, err)
+		finder.SetDatacenter(dc)
+		net, err := finder.Network(ctx, "DVS0")
+		require.NoError(t, err)
+		dvs := net.(*object.DistributedVirtualSwitch)
+
+		task, err := dvs.AddPortgroup(ctx, []types.DVPortgroupConfigSpec{
+			{
+				Name: "vlan-pg",
+				Type: string(types.DistributedVirtualPortgroupPortgroupTypeEarlyBinding),
+				DefaultPortConfig: &types.VMwareDVSPortSetting{
+					Vlan: &types.VmwareDistributedVirtualSwitchVlanIdSpec{VlanId: 100},
+				},
+			},
+			{
+				Name:              "segment-pg",
+				Type:              string(types.DistributedVirtualPortgroupPortgroupTypeEarlyBinding),
+				LogicalSwitchUuid: "ls-1234",
+			},
+			{
+				Name: "trunk-pg",
+				Type: string(types.DistributedVirtualPortgroupPortgroupTypeEarlyBinding),
+				DefaultPortConfig: &types.VMwareDVSPortSetting{
+					Vlan: &types.VmwareDistributedVirtualSwitchTrunkVlanSpec{
+						VlanId: []types.NumericRange{{Start: 1, End: 100}},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, task.Wait(ctx))
+
+		c := vcenter.NewFromGovmomiClient(client, "DC0")
+		ids, err := c.NetworkIdentities(ctx)
+		require.NoError(t, err)
+
+		byName := map[string]vcenter.NetworkIdentity{}
+		for _, id := range ids {
+			byName[id.Name] = id
+		}
+		// the simulator doesn't add its uplink portgroup to the switch config, but uplinks never have an ID
+		require.Equal(t, "", byName["DVS0-DVUplinks-9"].ID)
+		require.Equal(t, vcenter.NetworkIdentity{
+			Datacenter: "DC0", Name: "vlan-pg", Switch: "DVS0", ID: "vlan-100",
+		}, byName["vlan-pg"])
+		require.Equal(t, vcenter.NetworkIdentity{
+			Datacenter: "DC0", Name: "segment-pg", Switch: "DVS0", ID: "nsx-ls-1234",
+		}, byName["segment-pg"])
+		require.Equal(t, "", byName["trunk-pg"].ID)
+		require.Equal(t, "vlan-0", byName["DC0_DVPG0"].ID)
+		require.Equal(t, vcenter.NetworkIdentity{Datacenter: "DC0", Name: "VM Network"}, byName["VM Network"])
+	})
+}
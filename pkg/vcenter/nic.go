@@ -0,0 +1,108 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package vcenter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// NIC is a VM network adapter and the network it's attached to
+type NIC struct {
+	Label          string
+	Network        string
+	Connected      bool
+	StartConnected bool
+}
+
+// VMNICs returns the VM's network adapters and whether the VM is powered on
+func (c *Client) VMNICs(ctx context.Context, vmNameOrPath string) ([]NIC, bool, error) {
+	l := log.FromContext(ctx)
+
+	client, err := c.getOrCreateUnderlyingClient(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	f := NewFinder(c.Datacenter(), client)
+	vm, err := f.VirtualMachine(ctx, vmNameOrPath)
+	if err != nil {
+		return nil, false, err
+	}
+	finder, err := f.getUnderlyingFinderOrCreate(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	l.Debugf("Getting VM %s network adapters", vmNameOrPath)
+	var o mo.VirtualMachine
+	err = vm.Properties(ctx, vm.Reference(), []string{"network", "runtime.powerState"}, &o)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get VM %s networks: %w", vmNameOrPath, err)
+	}
+
+	// adapters refer to their network by portgroup key, network moref or opaque network ID
+	names := map[string]string{}
+	for _, ref := range o.Network {
+		netRef, err := finder.ObjectReference(ctx, ref)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get %s network reference", ref.Value)
+		}
+		switch t := netRef.(type) {
+		case *object.DistributedVirtualPortgroup:
+			names[ref.Value] = t.Name()
+		case *object.Network:
+			names[ref.Value] = t.Name()
+		case *object.OpaqueNetwork:
+			var on mo.OpaqueNetwork
+			err = t.Properties(ctx, t.Reference(), []string{"summary"}, &on)
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to get opaque network %s summary: %w", t.Name(), err)
+			}
+			if s, ok := on.Summary.(*types.OpaqueNetworkSummary); ok {
+				names[s.OpaqueNetworkId] = t.Name()
+			}
+		}
+	}
+
+	devices, err := vm.Device(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list devices for VM %s: %w", vmNameOrPath, err)
+	}
+
+	var nics []NIC
+	for _, d := range devices.SelectByType((*types.VirtualEthernetCard)(nil)) {
+		card := d.(types.BaseVirtualEthernetCard).GetVirtualEthernetCard()
+		nic := NIC{
+			Label:   devices.Name(d),
+			Network: names[backingNetworkID(card.Backing)],
+		}
+		if card.Connectable != nil {
+			nic.Connected = card.Connectable.Connected
+			nic.StartConnected = card.Connectable.StartConnected
+		}
+		nics = append(nics, nic)
+	}
+	return nics, o.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn, nil
+}
+
+func backingNetworkID(backing types.BaseVirtualDeviceBackingInfo) string {
+	switch b := backing.(type) {
+	case *types.VirtualEthernetCardDistributedVirtualPortBackingInfo:
+		return b.Port.PortgroupKey
+	case *types.VirtualEthernetCardNetworkBackingInfo:
+		if b.Network != nil {
+			return b.Network.Value
+		}
+	case *types.VirtualEthernetCardOpaqueNetworkBackingInfo:
+		return b.OpaqueNetworkId
+	}
+	return ""
+}
@@ -7,7 +7,8 @@ package vcenter
 
 import "context"
 
-// Pool is a pool of vcenter client instances
+// Pool is a pool of vcenter client instances, one per vCenter datacenter
+// AZs in different datacenters of the same vCenter each get their own client sharing a single vCenter connection
 type Pool struct {
 	sourceClientsByAZ map[string]*Client
 	targetClientsByAZ map[string]*Client
@@ -33,30 +34,32 @@ func NewPoolWithExternalClients(sourceClientsByAZ, targetClientsByAZ map[string]
 }
 
 // AddSource adds a new source az/client pair
-// If the AZ's vcenter matches another AZ's source vcenter then the client is re-used
+// If the AZ's vcenter and datacenter match another AZ's then the client is re-used
 func (p *Pool) AddSource(az, host, username, password, datacenter string, insecure bool) {
-	if p.GetSourceClientByAZ(az) == nil {
-		// reuse clients between AZs
-		c := p.getClient(host, username, password, insecure)
-		if c == nil {
-			c = New(host, username, password, datacenter, insecure)
-			p.clients = append(p.clients, c)
-		}
-		p.sourceClientsByAZ[az] = c
-	}
+	p.AddSourceClient(az, New(host, username, password, datacenter, insecure))
 }
 
 // AddTarget adds a new target az/client pair
-// If the AZ's vcenter matches another AZ's target vcenter then the client is re-used
+// If the AZ's vcenter and datacenter match another AZ's then the client is re-used
 func (p *Pool) AddTarget(az, host, username, password, datacenter string, insecure bool) {
+	p.AddTargetClient(az, New(host, username, password, datacenter, insecure))
+}
+
+// AddSourceClient adds a new source az/client pair
+// If the client's vcenter and datacenter match another AZ's then the existing client is re-used, otherwise
+// a client of the same vcenter shares the existing client's vcenter connection
+func (p *Pool) AddSourceClient(az string, client *Client) {
+	if p.GetSourceClientByAZ(az) == nil {
+		p.sourceClientsByAZ[az] = p.getOrAddClient(client)
+	}
+}
+
+// AddTargetClient adds a new target az/client pair
+// If the client's vcenter and datacenter match another AZ's then the existing client is re-used, otherwise
+// a client of the same vcenter shares the existing client's vcenter connection
+func (p *Pool) AddTargetClient(az string, client *Client) {
 	if p.GetTargetClientByAZ(az) == nil {
-		// reuse clients between AZs
-		c := p.getClient(host, username, password, insecure)
-		if c == nil {
-			c = New(host, username, password, datacenter, insecure)
-			p.clients = append(p.clients, c)
-		}
-		p.targetClientsByAZ[az] = c
+		p.targetClientsByAZ[az] = p.getOrAddClient(client)
 	}
 }
 
@@ -113,18 +116,32 @@ func (p *Pool) TargetAZs() []string {
 	return azs
 }
 
-// Close calls logout on all managed vcenter clients
+// Close calls logout once on each managed vcenter connection
 func (p *Pool) Close(ctx context.Context) {
+	loggedOut := map[*connection]bool{}
 	for _, c := range p.clients {
-		c.Logout(ctx)
+		if !loggedOut[c.conn] {
+			loggedOut[c.conn] = true
+			c.Logout(ctx)
+		}
 	}
 }
 
-func (p *Pool) getClient(host, username, password string, insecure bool) *Client {
+func (p *Pool) getOrAddClient(client *Client) *Client {
+	// reuse clients between AZs in the same datacenter, and connections between AZs in the same vcenter
+	var conn *connection
 	for _, c := range p.clients {
-		if c.isSameVCenter(host, username, password, insecure) {
+		if !c.isSameVCenter(client) {
+			continue
+		}
+		if c.datacenter == client.datacenter {
 			return c
 		}
+		conn = c.conn
+	}
+	if conn != nil {
+		client.conn = conn
 	}
-	return nil
+	p.clients = append(p.clients, client)
+	return client
 }
@@ -6,8 +6,18 @@
 package vcenter_test
 
 import (
+	"context"
+	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/require"
 	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/vcenter"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/session"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
 	"testing"
 )
 
@@ -87,6 +97,132 @@ func TestPoolWithSeparateVCenterAccountsForEachAZ(t *testing.T) {
 	require.NotSame(t, c3, c4)
 }
 
+func TestPoolWithSingleVCenterAndDatacenterForEachAZ(t *testing.T) {
+	p := vcenter.NewPool()
+	p.AddSource("az1", "vc01.example.com", "admin", "secret", "dc1", true)
+	p.AddSource("az2", "vc01.example.com", "admin", "secret", "dc2", true)
+	p.AddSource("az3", "vc01.example.com", "admin", "secret", "dc1", true)
+	p.AddTarget("az1", "vc01.example.com", "admin", "secret", "dc2", true)
+
+	sc := p.GetClients()
+	require.Len(t, sc, 2)
+
+	c1 := p.GetSourceClientByAZ("az1")
+	c2 := p.GetSourceClientByAZ("az2")
+	c3 := p.GetSourceClientByAZ("az3")
+	c4 := p.GetTargetClientByAZ("az1")
+	require.NotSame(t, c1, c2)
+	require.Same(t, c1, c3)
+	require.Same(t, c2, c4)
+	require.Equal(t, "dc1", c1.Datacenter())
+	require.Equal(t, "dc2", c2.Datacenter())
+}
+
+func TestPoolSharesVCenterConnectionBetweenDatacenters(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	model.Datacenter = 2
+	model.Pool = 1
+
+	simulator.Test(func(ctx context.Context, vimClient *vim25.Client) {
+		client := &govmomi.Client{
+			Client:         vimClient,
+			SessionManager: session.NewManager(vimClient),
+		}
+		u := client.URL()
+		password, _ := u.User.Password()
+
+		p := vcenter.NewPool()
+		p.AddSourceClient("az1", vcenter.NewFromGovmomiClient(client, "DC0"))
+		// never logs in itself, so it can only find the DC1 cluster VMs via the az1 connection
+		p.AddSourceClient("az2", vcenter.New(u.Host, u.User.Username(), password, "DC1", false))
+		require.Len(t, p.GetClients(), 2)
+
+		vms, err := p.GetSourceClientByAZ("az2").ClusterVMs(ctx, []string{"DC1_C0"})
+		require.NoError(t, err)
+		require.Len(t, vms, 2)
+		require.Equal(t, "/DC1/vm", vms[0].Folder)
+
+		vms, err = p.GetSourceClientByAZ("az1").ClusterVMs(ctx, []string{"DC0_C0"})
+		require.NoError(t, err)
+		require.Len(t, vms, 2)
+		require.Equal(t, "/DC0/vm", vms[0].Folder)
+
+		_, err = p.GetSourceClientByAZ("az1").ClusterVMs(ctx, []string{"DC1_C0"})
+		require.Error(t, err)
+	}, model)
+}
+
+func TestPoolLogsInAndOutOnceForDatacentersOfTheSameVCenter(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	model.Datacenter = 2
+	model.Pool = 1
+
+	simulator.Test(func(ctx context.Context, vimClient *vim25.Client) {
+		client := &govmomi.Client{
+			Client:         vimClient,
+			SessionManager: session.NewManager(vimClient),
+		}
+		thumb := soap.ThumbprintSHA1(simulatorCertificate(t, client))
+		hook := test.NewGlobal()
+		defer hook.Reset()
+
+		p := vcenter.NewPool()
+		p.AddSourceClient("az1", vcenter.New(client.URL().Host, "user", "pass", "DC0", false).WithThumbprint(thumb))
+		p.AddSourceClient("az2", vcenter.New(client.URL().Host, "user", "pass", "DC1", false).WithThumbprint(thumb))
+		require.Len(t, p.GetClients(), 2)
+
+		_, err := p.GetSourceClientByAZ("az1").ClusterVMs(ctx, []string{"DC0_C0"})
+		require.NoError(t, err)
+		_, err = p.GetSourceClientByAZ("az2").ClusterVMs(ctx, []string{"DC1_C0"})
+		require.NoError(t, err)
+		require.Len(t, sessions(ctx, t, client), 2, "the test's session and one pool session")
+
+		p.Close(ctx)
+		require.Len(t, sessions(ctx, t, client), 1, "only the test's session")
+		for _, e := range hook.AllEntries() {
+			require.NotContains(t, e.Message, "logout failed")
+		}
+	}, model)
+}
+
+func TestPoolDoesNotShareConnectionsWithDifferentThumbprints(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	model.Datacenter = 2
+	model.Pool = 1
+
+	simulator.Test(func(ctx context.Context, vimClient *vim25.Client) {
+		client := &govmomi.Client{
+			Client:         vimClient,
+			SessionManager: session.NewManager(vimClient),
+		}
+		thumb := soap.ThumbprintSHA1(simulatorCertificate(t, client))
+
+		p := vcenter.NewPool()
+		p.AddSourceClient("az1", vcenter.New(client.URL().Host, "user", "pass", "DC0", false).WithThumbprint(thumb))
+		p.AddSourceClient("az2", vcenter.New(client.URL().Host, "user", "pass", "DC1", false).
+			WithThumbprint("0A:1B:2C:3D:4E:5F:60:71:82:93:A4:B5:C6:D7:E8:F9:0A:1B:2C:3D"))
+		defer p.Close(ctx)
+
+		_, err := p.GetSourceClientByAZ("az1").ClusterVMs(ctx, []string{"DC0_C0"})
+		require.NoError(t, err)
+
+		// the az1 session must not be reused by a client pinned to another certificate
+		_, err = p.GetSourceClientByAZ("az2").ClusterVMs(ctx, []string{"DC1_C0"})
+		require.ErrorContains(t, err, "thumbprint does not match")
+	}, model)
+}
+
+func sessions(ctx context.Context, t *testing.T, client *govmomi.Client) []types.UserSession {
+	var sm mo.SessionManager
+	err := property.DefaultCollector(client.Client).RetrieveOne(ctx, *client.ServiceContent.SessionManager,
+		[]string{"sessionList"}, &sm)
+	require.NoError(t, err)
+	return sm.SessionList
+}
+
 func TestAddingSameAZTwice(t *testing.T) {
 	p := vcenter.NewPool()
 	p.AddSource("az1", "vc01.example.com", "admin1", "secret", "dc", true)
@@ -0,0 +1,130 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package vcenter
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/log"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// sessionRecoveryRoundTripper transparently logs back in and retries the SOAP call once whenever
+// vCenter reports the current session is no longer authenticated, for example after a session
+// idle timeout or a vCenter service restart during a long migration
+type sessionRecoveryRoundTripper struct {
+	roundTripper soap.RoundTripper
+	login        func(ctx context.Context) error
+
+	mu         sync.Mutex
+	generation uint64
+}
+
+func newSessionRecoveryRoundTripper(rt soap.RoundTripper, login func(ctx context.Context) error) *sessionRecoveryRoundTripper {
+	return &sessionRecoveryRoundTripper{
+		roundTripper: rt,
+		login:        login,
+	}
+}
+
+func (s *sessionRecoveryRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	// never try to recover the session management calls themselves
+	switch req.(type) {
+	case *methods.LoginBody, *methods.LogoutBody:
+		return s.roundTripper.RoundTrip(ctx, req, res)
+	}
+
+	gen := s.currentGeneration()
+	err := s.roundTripper.RoundTrip(ctx, req, res)
+	if !isNotAuthenticated(err, res) {
+		return err
+	}
+
+	log.FromContext(ctx).Info("vCenter session is no longer authenticated, logging in again")
+	if err := s.relogin(ctx, gen); err != nil {
+		return err
+	}
+
+	// the failed response was already decoded into res, so reset it before decoding the retry
+	v := reflect.ValueOf(res).Elem()
+	v.Set(reflect.Zero(v.Type()))
+
+	return s.roundTripper.RoundTrip(ctx, req, res)
+}
+
+func (s *sessionRecoveryRoundTripper) currentGeneration() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.generation
+}
+
+// relogin establishes a new session unless another caller already did so since gen was observed
+func (s *sessionRecoveryRoundTripper) relogin(ctx context.Context, gen uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.generation != gen {
+		log.FromContext(ctx).Debug("vCenter session was already re-established by another caller")
+		return nil
+	}
+
+	if err := s.login(ctx); err != nil {
+		return err
+	}
+	s.generation++
+	return nil
+}
+
+// isNotAuthenticated returns true if the SOAP call failed because the session is not authenticated,
+// either as a method fault or as a missing property fault on an otherwise successful retrieval
+func isNotAuthenticated(err error, res soap.HasFault) bool {
+	if err != nil {
+		if soap.IsSoapFault(err) {
+			return isNotAuthenticatedFault(soap.ToSoapFault(err).VimFault())
+		}
+		if soap.IsVimFault(err) {
+			return isNotAuthenticatedFault(soap.ToVimFault(err))
+		}
+		return false
+	}
+
+	var content []types.ObjectContent
+	switch r := res.(type) {
+	case *methods.RetrievePropertiesBody:
+		if r.Res != nil {
+			content = r.Res.Returnval
+		}
+	case *methods.RetrievePropertiesExBody:
+		if r.Res != nil && r.Res.Returnval != nil {
+			content = r.Res.Returnval.Objects
+		}
+	case *methods.ContinueRetrievePropertiesExBody:
+		if r.Res != nil {
+			content = r.Res.Returnval.Objects
+		}
+	}
+
+	for _, o := range content {
+		for _, p := range o.MissingSet {
+			if isNotAuthenticatedFault(p.Fault.Fault) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isNotAuthenticatedFault(fault types.AnyType) bool {
+	switch fault.(type) {
+	case types.NotAuthenticated, *types.NotAuthenticated:
+		return true
+	}
+	return false
+}
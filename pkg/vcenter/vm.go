@@ -16,6 +16,20 @@ type VM struct {
 	Folder       string
 	Disks        []Disk
 	Networks     []string
+	MemoryMB     int32
+
+	// the BOSH deployment and instance group, empty for stemcells and VMs not managed by BOSH
+	Deployment    string
+	InstanceGroup string
+}
+
+// TaskName identifies the VM's migration in the output. BOSH deployment VM names are unique but stemcells and VMs
+// not managed by BOSH can have the same name in several AZs, so their AZ is included
+func TaskName(name, az, deployment string) string {
+	if deployment != "" || az == "" {
+		return name
+	}
+	return name + " (" + az + ")"
 }
 
 type Disk struct {
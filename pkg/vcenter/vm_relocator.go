@@ -41,19 +41,31 @@ func (r *VMRelocator) WithDryRun(dryRun bool) *VMRelocator {
 	return r
 }
 
-func (r *VMRelocator) RelocateVM(ctx context.Context, srcVM *VM, vmTargetSpec *TargetSpec) error {
+func (r *VMRelocator) RelocateVM(ctx context.Context, srcVM *VM, vmTargetSpec *TargetSpec) (err error) {
 	l := log.FromContext(ctx)
 	l.Infof("Starting %s migration", srcVM.Name)
 
-	err := r.destinationHostPool.Initialize(ctx)
+	err = r.destinationHostPool.Initialize(ctx)
 	if err != nil {
 		return err
 	}
-	targetHost, err := r.destinationHostPool.WaitForLeaseAvailableHost(ctx, srcVM.AZ)
+	group := antiAffinityGroupKey(srcVM.Deployment, srcVM.InstanceGroup)
+	targetHost, err := r.destinationHostPool.WaitForLeaseHost(ctx, HostLeaseRequest{
+		AZ:                srcVM.AZ,
+		Cluster:           vmTargetSpec.Cluster,
+		AntiAffinityGroup: group,
+	})
 	if err != nil {
 		return err
 	}
-	defer r.destinationHostPool.Release(ctx, targetHost)
+	defer func() {
+		// the VM stays on its source host when the move fails, so it no longer counts against the target host
+		if err != nil {
+			r.destinationHostPool.ReleaseUnused(ctx, targetHost, group)
+			return
+		}
+		r.destinationHostPool.Release(ctx, targetHost)
+	}()
 
 	sourceClient := r.clientPool.GetSourceClientByAZ(srcVM.AZ)
 	if sourceClient == nil {
@@ -103,10 +115,10 @@ func (r *VMRelocator) RelocateVM(ctx context.Context, srcVM *VM, vmTargetSpec *T
 		l.Errorf("Could not eject %s CD-ROM, attempting migration anyway: %s", sourceVM.Name(), err)
 	}
 
-	return r.moveVM(ctx, sourceVM, spec)
+	return r.moveVM(ctx, sourceVM, TaskName(srcVM.Name, srcVM.AZ, srcVM.Deployment), spec)
 }
 
-func (r *VMRelocator) moveVM(ctx context.Context, sourceVM *object.VirtualMachine, spec *types.VirtualMachineRelocateSpec) error {
+func (r *VMRelocator) moveVM(ctx context.Context, sourceVM *object.VirtualMachine, taskName string, spec *types.VirtualMachineRelocateSpec) error {
 	// start vMotion
 	t, err := sourceVM.Relocate(ctx, *spec, types.VirtualMachineMovePriorityHighPriority)
 	if err != nil {
@@ -115,7 +127,7 @@ func (r *VMRelocator) moveVM(ctx context.Context, sourceVM *object.VirtualMachin
 
 	// monitor vMotion task progress
 	progressLogger := NewProgressLogger(r.updatableStdout)
-	progressSink := progressLogger.NewProgressSink(sourceVM.Name())
+	progressSink := progressLogger.NewProgressSink(taskName)
 	_, err = t.WaitForResult(ctx, progressSink)
 	if err != nil {
 		// attempt to unroll the hidden SOAP error details
@@ -183,3 +195,11 @@ func debugLogRelocateSpec(l *logrus.Entry, spec types.VirtualMachineRelocateSpec
 	l.Debugln("VirtualMachineRelocateSpec:")
 	l.Debugln(string(j))
 }
+
+// antiAffinityGroupKey returns the VM's BOSH deployment instance group, or empty for VMs not managed by BOSH
+func antiAffinityGroupKey(deployment, instanceGroup string) string {
+	if instanceGroup == "" {
+		return ""
+	}
+	return deployment + "/" + instanceGroup
+}
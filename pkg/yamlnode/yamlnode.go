@@ -0,0 +1,152 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package yamlnode edits yaml documents in place, preserving key order, comments and any content it doesn't touch
+package yamlnode
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Parse parses the yaml document, returning the document and its root map
+func Parse(in []byte) (*yaml.Node, *yaml.Node, error) {
+	var doc yaml.Node
+	err := yaml.Unmarshal(in, &doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("expected a yaml map")
+	}
+	return &doc, doc.Content[0], nil
+}
+
+// Encode returns the yaml document using 2 space indentation
+func Encode(doc *yaml.Node) ([]byte, error) {
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	err := enc.Encode(doc)
+	if err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// Format re-encodes the yaml document the same way Encode does, so it can be compared with edited documents
+func Format(in []byte) ([]byte, error) {
+	var doc yaml.Node
+	err := yaml.Unmarshal(in, &doc)
+	if err != nil {
+		return nil, err
+	}
+	return Encode(&doc)
+}
+
+// MapValue returns the value node for the key, or nil if m isn't a map or doesn't contain the key
+func MapValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// ScalarValue returns the scalar value for the key, or an empty string if there isn't one
+func ScalarValue(m *yaml.Node, key string) string {
+	v := MapValue(m, key)
+	if v == nil || v.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return v.Value
+}
+
+// Items returns the sequence items, or nil if s isn't a sequence
+func Items(s *yaml.Node) []*yaml.Node {
+	if s == nil || s.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return s.Content
+}
+
+// SetScalar sets the key's string value, adding the key if it doesn't exist
+func SetScalar(m *yaml.Node, key, value string) {
+	if v := MapValue(m, key); v != nil {
+		v.Kind = yaml.ScalarNode
+		v.Tag = "!!str"
+		v.Value = value
+		v.Style = 0
+		v.Content = nil
+		return
+	}
+	m.Content = append(m.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value})
+}
+
+// SetScalarUnlessVariable sets the key's string value unless the existing value is a BOSH or om
+// interpolation variable like ((vcenter_password)), which is kept so the secret is still resolved at deploy time
+func SetScalarUnlessVariable(m *yaml.Node, key, value string) {
+	if strings.HasPrefix(strings.TrimSpace(ScalarValue(m, key)), "((") {
+		return
+	}
+	SetScalar(m, key, value)
+}
+
+// SetOptionalScalar sets the value, or removes the key when the value is empty
+func SetOptionalScalar(m *yaml.Node, key, value string) {
+	if value != "" {
+		SetScalar(m, key, value)
+		return
+	}
+	Remove(m, key)
+}
+
+// Remove removes the key from the map if it exists
+func Remove(m *yaml.Node, key string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// Set sets the key's value, adding the key if it doesn't exist
+func Set(m *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = value
+			return
+		}
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// NewMap returns an empty map
+func NewMap() *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+}
+
+// NewSequence returns a sequence of the items
+func NewSequence(items ...*yaml.Node) *yaml.Node {
+	return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: items}
+}
+
+// NewScalar returns a scalar node for a string, number or bool value
+func NewScalar(value interface{}) *yaml.Node {
+	n := &yaml.Node{}
+	// encoding a scalar never fails
+	_ = n.Encode(value)
+	return n
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright 2023 VMware, Inc.
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package yamlnode_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-tanzu/vmotion-migration-tool-for-bosh-deployments/pkg/yamlnode"
+)
+
+func TestEditPreservesOrderAndComments(t *testing.T) {
+	doc, root, err := yamlnode.Parse([]byte(`# header
+b: 1
+a:
+  name: foo # the name
+  rp: rp1
+list:
+- x
+- y
+`))
+	require.NoError(t, err)
+
+	require.Nil(t, yamlnode.MapValue(root, "missing"))
+	require.Equal(t, "foo", yamlnode.ScalarValue(yamlnode.MapValue(root, "a"), "name"))
+	require.Equal(t, "", yamlnode.ScalarValue(root, "a"))
+	require.Len(t, yamlnode.Items(yamlnode.MapValue(root, "list")), 2)
+	require.Nil(t, yamlnode.Items(root))
+
+	a := yamlnode.MapValue(root, "a")
+	yamlnode.SetScalar(a, "name", "bar")
+	yamlnode.SetOptionalScalar(a, "rp", "")
+	yamlnode.SetOptionalScalar(a, "cluster", "cl1")
+
+	out, err := yamlnode.Encode(doc)
+	require.NoError(t, err)
+	require.Equal(t, `# header
+b: 1
+a:
+  name: bar # the name
+  cluster: cl1
+list:
+  - x
+  - y
+`, string(out))
+}
+
+func TestParseNotAMap(t *testing.T) {
+	_, _, err := yamlnode.Parse([]byte("- foo"))
+	require.EqualError(t, err, "expected a yaml map")
+}
+
+func TestFormat(t *testing.T) {
+	out, err := yamlnode.Format([]byte("a:\n- b: 1\n  c: [x, y]\n"))
+	require.NoError(t, err)
+	require.Equal(t, "a:\n  - b: 1\n    c: [x, y]\n", string(out))
+}